@@ -0,0 +1,386 @@
+package main
+
+// Compares two sets of evaluation parameters for the bot: plays a match between them from the same
+// openings with each side, and optionally runs both on an EPD test suite, then prints a one-page
+// report of the differences
+// Every search is limited to a number of nodes rather than a time, and the openings are generated
+// from a seed, so running the same comparison again gives exactly the same results on any machine
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"gogm/botv1"
+	"gogm/chess"
+	"math"
+	"math/rand"
+	"os"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// One of the two sets of parameters under test
+type contender struct {
+    path   string
+    params *botv1.EvalParams
+}
+
+// A game of the match, starting from an opening with one of the contenders as white
+type matchGame struct {
+    opening  *chess.Board
+    bIsWhite bool
+    result   chess.Result
+}
+
+// A position of the test suite and whether each contender solved it
+type suiteResult struct {
+    id      string
+    solvedA bool
+    solvedB bool
+}
+
+func main() {
+    openings := flag.Int("openings", 50, "number of openings to play, each played twice with the colours swapped")
+    nodes := flag.Uint64("nodes", 20000, "nodes searched per move and per suite position")
+    seed := flag.Int64("seed", 1, "seed for generating the openings")
+    maxPly := flag.Int("max-ply", 300, "length in ply after which a game is adjudicated a draw")
+    suitePath := flag.String("suite", "", "EPD test suite (bm/am) to run both sets of parameters on")
+    workers := flag.Int("j", runtime.NumCPU(), "number of games and positions to search in parallel")
+    flag.Usage = func() {
+        fmt.Fprintf(flag.CommandLine.Output(), "usage: %v [flags] a.json b.json\n", os.Args[0])
+        fmt.Fprintln(flag.CommandLine.Output(), "Each argument is a file of evaluation parameters, or \"default\" for the built-in ones")
+        flag.PrintDefaults()
+    }
+    flag.Parse()
+
+    if flag.NArg() != 2 {
+        flag.Usage()
+        os.Exit(2)
+    }
+
+    if *workers < 1 {
+        *workers = 1
+    }
+
+    a, err := loadContender(flag.Arg(0))
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+    }
+
+    b, err := loadContender(flag.Arg(1))
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+    }
+
+    var suite []chess.EpdRecord
+    if *suitePath != "" {
+        if suite, err = chess.ReadEpdFile(*suitePath); err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+    }
+
+    games := generateGames(*openings, *seed)
+    playMatch(games, a, b, *nodes, *maxPly, *workers)
+    suiteResults := runSuite(suite, a, b, *nodes, *workers)
+
+    fmt.Printf("A: %v\nB: %v\n", a.path, b.path)
+    for _, line := range paramsDiff(a.params, b.params) {
+        fmt.Printf("  %v\n", line)
+    }
+
+    fmt.Printf("\nMatch: %v games from %v openings (seed %v), %v nodes per move\n", len(games), *openings, *seed, *nodes)
+    printMatchReport(games)
+
+    if *suitePath != "" {
+        fmt.Printf("\nSuite: %v, %v positions, %v nodes per position\n", *suitePath, len(suiteResults), *nodes)
+        printSuiteReport(suiteResults)
+    }
+}
+
+// Load the parameters from the file, or the built-in parameters for "default"
+func loadContender(path string) (contender, error) {
+    if path == "default" {
+        return contender { path: path, params: botv1.DefaultEvalParams() }, nil
+    }
+
+    params, err := botv1.LoadEvalParams(path)
+    return contender { path: path, params: params }, err
+}
+
+// Returns a bot using the contender's parameters, searching the given number of nodes per move
+func (contender contender) newBot(nodes uint64) *botv1.BotV1 {
+    bot := &botv1.BotV1 {}
+    bot.SetEvalParams(contender.params)
+    bot.SetMaxNodes(nodes)
+    bot.SetMaxDepth(64)
+    bot.Warmup()
+
+    return bot
+}
+
+// Generate the openings from the seed, each a short random walk from the starting position, and
+// return a game from each with either contender as white
+func generateGames(count int, seed int64) []matchGame {
+    rng := rand.New(rand.NewSource(seed))
+
+    var games []matchGame
+    for len(games) < 2 * count {
+        board, _ := chess.LoadFen(chess.StartingPositionFen)
+
+        numMoves := 4 + rng.Intn(5)
+        for i := 0; i < numMoves && !board.Outcome().IsOver(); i++ {
+            moves := board.GetLegalMoves(false)
+            board.MakeMove(moves[rng.Intn(len(moves))])
+        }
+
+        if board.Outcome().IsOver() {
+            continue
+        }
+
+        games = append(games, matchGame { opening: board, bIsWhite: false }, matchGame { opening: board, bIsWhite: true })
+    }
+
+    return games
+}
+
+// Play every game of the match, with `workers` goroutines each taking the next game until none are
+// left. Each worker has its own bots, which start every game with a cleared transposition table so
+// that the result does not depend on which games the worker played before
+func playMatch(games []matchGame, a contender, b contender, nodes uint64, maxPly int, workers int) {
+    forEachInParallel(len(games), workers, func() func(int) {
+        botA, botB := a.newBot(nodes), b.newBot(nodes)
+
+        return func(index int) {
+            game := &games[index]
+
+            white, black := botA, botB
+            if game.bIsWhite {
+                white, black = botB, botA
+            }
+
+            game.result = playGame(game.opening.Copy(), white, black, maxPly)
+            fmt.Fprintf(os.Stderr, "game %v: %v\n", index + 1, game.result)
+        }
+    })
+}
+
+// Play the game to the end from the position, adjudicating it a draw after `maxPly` moves
+func playGame(board chess.Board, white chess.Bot, black chess.Bot, maxPly int) chess.Result {
+    chess.StartNewGame(white)
+    chess.StartNewGame(black)
+
+    for ply := 0; ply < maxPly; ply++ {
+        if outcome := board.Outcome(); outcome.IsOver() {
+            return outcome.Result
+        }
+
+        bot := white
+        if board.IsBlackToMove() {
+            bot = black
+        }
+
+        board.MakeMove(bot.Think(&board))
+    }
+
+    return chess.Draw
+}
+
+// Run both contenders on each position of the suite
+func runSuite(suite []chess.EpdRecord, a contender, b contender, nodes uint64, workers int) []suiteResult {
+    results := make([]suiteResult, len(suite))
+
+    forEachInParallel(len(suite), workers, func() func(int) {
+        botA, botB := a.newBot(nodes), b.newBot(nodes)
+
+        return func(index int) {
+            record := suite[index]
+
+            results[index].id = record.ID()
+            if results[index].id == "" {
+                results[index].id = fmt.Sprintf("#%v", index + 1)
+            }
+
+            botA.ClearHash()
+            botB.ClearHash()
+            results[index].solvedA = record.IsSolution(botA.Think(record.Board))
+            results[index].solvedB = record.IsSolution(botB.Think(record.Board))
+        }
+    })
+
+    return results
+}
+
+// Call the function returned by `newWorker` for each index from 0 to count - 1, with `workers`
+// goroutines each taking the next index until none are left
+func forEachInParallel(count int, workers int, newWorker func() func(int)) {
+    var wait sync.WaitGroup
+    indices := make(chan int)
+
+    for worker := 0; worker < workers; worker++ {
+        wait.Add(1)
+
+        go func() {
+            defer wait.Done()
+
+            work := newWorker()
+            for index := range indices {
+                work(index)
+            }
+        }()
+    }
+
+    for index := 0; index < count; index++ {
+        indices <- index
+    }
+    close(indices)
+
+    wait.Wait()
+}
+
+// Print B's results against A, and the Elo difference they suggest with a 95% confidence interval
+func printMatchReport(games []matchGame) {
+    wins, draws, losses := 0, 0, 0
+    var scores []float64
+
+    for _, game := range games {
+        isBlack, decisive := chess.Outcome { Result: game.result }.Winner()
+
+        switch {
+        case !decisive:
+            draws++
+            scores = append(scores, 0.5)
+        case isBlack != game.bIsWhite:
+            wins++
+            scores = append(scores, 1)
+        default:
+            losses++
+            scores = append(scores, 0)
+        }
+    }
+
+    if len(scores) == 0 {
+        return
+    }
+
+    mean := 0.0
+    for _, score := range scores {
+        mean += score
+    }
+    mean /= float64(len(scores))
+
+    variance := 0.0
+    for _, score := range scores {
+        variance += (score - mean) * (score - mean)
+    }
+    margin := 1.96 * math.Sqrt(variance / float64(len(scores))) / math.Sqrt(float64(len(scores)))
+
+    fmt.Printf("  B vs A: +%v =%v -%v, score %.1f%%\n", wins, draws, losses, mean * 100)
+    fmt.Printf("  Elo difference: %+.0f (95%% interval %+.0f to %+.0f)\n", eloDifference(mean), eloDifference(mean - margin), eloDifference(mean + margin))
+}
+
+// Returns the difference in Elo rating that gives the expected score
+func eloDifference(score float64) float64 {
+    score = math.Max(0.001, math.Min(0.999, score))
+    return 400 * math.Log10(score / (1 - score))
+}
+
+func printSuiteReport(results []suiteResult) {
+    solvedA, solvedB := 0, 0
+    var onlyA, onlyB []string
+
+    for _, result := range results {
+        if result.solvedA {
+            solvedA++
+        }
+        if result.solvedB {
+            solvedB++
+        }
+
+        if result.solvedA && !result.solvedB {
+            onlyA = append(onlyA, result.id)
+        }
+        if result.solvedB && !result.solvedA {
+            onlyB = append(onlyB, result.id)
+        }
+    }
+
+    fmt.Printf("  A solved %v, B solved %v\n", solvedA, solvedB)
+    fmt.Printf("  Solved only by A: %v\n", listOrNone(onlyA))
+    fmt.Printf("  Solved only by B: %v\n", listOrNone(onlyB))
+}
+
+func listOrNone(items []string) string {
+    if len(items) == 0 {
+        return "none"
+    }
+
+    return strings.Join(items, " ")
+}
+
+// Describe the parameters that differ between the two sets, e.g. "knightValue: 3 -> 3.1" or
+// "pawnTables.endgame: 4 of 64 entries changed"
+func paramsDiff(a *botv1.EvalParams, b *botv1.EvalParams) []string {
+    toMap := func(params *botv1.EvalParams) map[string]interface{} {
+        data, _ := json.Marshal(params)
+
+        var result map[string]interface{}
+        json.Unmarshal(data, &result)
+        return result
+    }
+
+    lines := diffJson("", toMap(a), toMap(b))
+    if len(lines) == 0 {
+        return []string{"parameters are identical"}
+    }
+
+    return lines
+}
+
+func diffJson(path string, a interface{}, b interface{}) (lines []string) {
+    switch a := a.(type) {
+    case map[string]interface{}:
+        b, _ := b.(map[string]interface{})
+
+        keys := make([]string, 0, len(a))
+        for key := range a {
+            keys = append(keys, key)
+        }
+        sort.Strings(keys)
+
+        for _, key := range keys {
+            name := key
+            if path != "" {
+                name = path + "." + key
+            }
+
+            lines = append(lines, diffJson(name, a[key], b[key])...)
+        }
+
+    case []interface{}:
+        b, _ := b.([]interface{})
+
+        changed := 0
+        for index := range a {
+            if index >= len(b) || !reflect.DeepEqual(a[index], b[index]) {
+                changed++
+            }
+        }
+
+        if changed > 0 {
+            lines = append(lines, fmt.Sprintf("%v: %v of %v entries changed", path, changed, len(a)))
+        }
+
+    default:
+        if !reflect.DeepEqual(a, b) {
+            lines = append(lines, fmt.Sprintf("%v: %v -> %v", path, a, b))
+        }
+    }
+
+    return lines
+}
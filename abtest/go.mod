@@ -0,0 +1,12 @@
+module gogm/abtest
+
+go 1.22.5
+
+replace gogm/chess => ../chess
+
+replace gogm/botv1 => ../botv1
+
+require (
+	gogm/botv1 v0.0.0-00010101000000-000000000000
+	gogm/chess v0.0.0-00010101000000-000000000000
+)
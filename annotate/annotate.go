@@ -0,0 +1,375 @@
+package main
+
+// Annotates games read as PGN from the files given or standard input with the bot's evaluation of
+// each move, marking inaccuracies, mistakes and blunders along with the move the bot preferred
+// The annotated games are written to standard output and a summary of the accuracy of each player
+// to a JSON file. Games are analysed in parallel with a bot per worker
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"gogm/botv1"
+	"gogm/chess"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Drops in the chance of winning (percent) at which a move is an inaccuracy, mistake or blunder
+const (
+    inaccuracyThreshold float64 = 5
+    mistakeThreshold    float64 = 10
+    blunderThreshold    float64 = 15
+)
+
+// A game to annotate and the results of annotating it
+type job struct {
+    game *chess.Game
+    err  error
+
+    white playerStats
+    black playerStats
+}
+
+// How well a player played in one or more games
+type playerStats struct {
+    Games        int     `json:"games"`
+    Moves        int     `json:"moves"`
+    Accuracy     float64 `json:"accuracy"`
+    Inaccuracies int     `json:"inaccuracies"`
+    Mistakes     int     `json:"mistakes"`
+    Blunders     int     `json:"blunders"`
+
+    // Sum of the accuracy of each move, from which Accuracy is the mean
+    accuracySum float64
+}
+
+type gameSummary struct {
+    Event  string      `json:"event"`
+    Round  string      `json:"round"`
+    White  string      `json:"white"`
+    Black  string      `json:"black"`
+    Result string      `json:"result"`
+    Stats  struct {
+        White playerStats `json:"white"`
+        Black playerStats `json:"black"`
+    } `json:"stats"`
+}
+
+type summary struct {
+    Games   []gameSummary          `json:"games"`
+    Players map[string]playerStats `json:"players"`
+}
+
+// Limits on the search of each position
+type searchLimits struct {
+    depth    int
+    moveTime time.Duration
+}
+
+func main() {
+    depth := flag.Int("depth", 0, "maximum search depth per move in ply (0 for the bot's default)")
+    moveTime := flag.Duration("time", 0, "time budget per move, e.g. 2s (0 for no limit)")
+    workers := flag.Int("j", runtime.NumCPU(), "number of games to annotate in parallel")
+    summaryPath := flag.String("summary", "summary.json", "file to write the JSON summary of each player's accuracy to")
+    flag.Usage = func() {
+        fmt.Fprintf(flag.CommandLine.Output(), "usage: %v [flags] [file.pgn ...]\n", os.Args[0])
+        fmt.Fprintln(flag.CommandLine.Output(), "Reads games from the PGN files, or standard input if none are given, and writes")
+        fmt.Fprintln(flag.CommandLine.Output(), "them annotated with the bot's evaluation of each move")
+        flag.PrintDefaults()
+    }
+    flag.Parse()
+
+    if *workers < 1 {
+        *workers = 1
+    }
+
+    jobs, failed := readGames(flag.Args())
+
+    annotateAll(jobs, *workers, searchLimits { depth: *depth, moveTime: *moveTime })
+
+    output := bufio.NewWriter(os.Stdout)
+
+    result := summary { Players: make(map[string]playerStats) }
+    for _, job := range jobs {
+        if job.err != nil {
+            fmt.Fprintf(os.Stderr, "%v vs %v: %v\n", job.game.Tag("White"), job.game.Tag("Black"), job.err)
+            failed = true
+            continue
+        }
+
+        fmt.Fprintln(output, job.game.Pgn())
+        result.add(job)
+    }
+
+    output.Flush()
+
+    if err := writeSummary(*summaryPath, result); err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        failed = true
+    }
+
+    if failed {
+        os.Exit(1)
+    }
+}
+
+// Read the games in the files, or standard input if there are no files, reporting games that cannot
+// be read and carrying on with the rest
+func readGames(paths []string) (jobs []job, failed bool) {
+    readAll := func(name string, reader io.Reader) {
+        pgn := chess.NewPgnReader(reader)
+
+        for {
+            game, err := pgn.Read()
+            if err == io.EOF {
+                return
+            }
+            if err != nil {
+                fmt.Fprintf(os.Stderr, "%v: %v\n", name, err)
+                failed = true
+                continue
+            }
+
+            jobs = append(jobs, job { game: game })
+        }
+    }
+
+    if len(paths) == 0 {
+        readAll("stdin", os.Stdin)
+    }
+
+    for _, path := range paths {
+        file, err := os.Open(path)
+        if err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            failed = true
+            continue
+        }
+
+        readAll(path, file)
+        file.Close()
+    }
+
+    return
+}
+
+// Annotate every game, with `workers` goroutines each taking the next game until none are left
+// Bots keep state between searches, so each worker has its own
+func annotateAll(jobs []job, workers int, limits searchLimits) {
+    var wait sync.WaitGroup
+    indices := make(chan int)
+
+    for worker := 0; worker < workers; worker++ {
+        wait.Add(1)
+
+        go func() {
+            defer wait.Done()
+
+            bot := &botv1.BotV1 {}
+            if limits.depth > 0 {
+                bot.SetMaxDepth(limits.depth)
+            }
+
+            for index := range indices {
+                annotate(bot, &jobs[index], limits)
+            }
+        }()
+    }
+
+    for index := range jobs {
+        indices <- index
+    }
+    close(indices)
+
+    wait.Wait()
+}
+
+// Search every position of the main line, then annotate each move with the evaluation after it and
+// how much worse it was than the best move
+func annotate(bot *botv1.BotV1, job *job, limits searchLimits) {
+    game := job.game
+    bot.NewGame()
+
+    board, err := game.StartingBoard()
+    if err != nil {
+        job.err = err
+        return
+    }
+
+    // Score of each position from the perspective of the side to move, and the best move in it
+    scores := make([]float64, len(game.Moves) + 1)
+    bestMoves := make([]chess.Move, len(game.Moves) + 1)
+
+    for index := range scores {
+        scores[index], bestMoves[index] = evaluate(bot, board, limits)
+
+        if index < len(game.Moves) {
+            board.MakeMove(game.Moves[index].Move)
+        }
+    }
+
+    board, _ = game.StartingBoard()
+
+    for index := range game.Moves {
+        move := &game.Moves[index]
+        isBlack := board.IsBlackToMove()
+
+        stats := &job.white
+        if isBlack {
+            stats = &job.black
+        }
+
+        before := scores[index]
+        after := -scores[index + 1]
+        loss := math.Max(0, winPercent(before) - winPercent(after))
+
+        stats.Moves++
+        stats.accuracySum += moveAccuracy(loss)
+
+        var nag int
+        var judgement string
+
+        switch {
+        case loss >= blunderThreshold:
+            nag, judgement = chess.NagBlunder, "Blunder"
+            stats.Blunders++
+        case loss >= mistakeThreshold:
+            nag, judgement = chess.NagMistake, "Mistake"
+            stats.Mistakes++
+        case loss >= inaccuracyThreshold:
+            nag, judgement = chess.NagDubiousMove, "Inaccuracy"
+            stats.Inaccuracies++
+        }
+
+        // Evaluations in PGN are from white's perspective, with #N for mate in N moves. There is no
+        // evaluation once the game is over
+        whiteScore := after
+        if isBlack {
+            whiteScore = -whiteScore
+        }
+
+        var comment string
+        if mateIn, ok := chess.MateIn(whiteScore); !ok {
+            comment = fmt.Sprintf("[%%eval %.2f]", whiteScore)
+        } else if mateIn != 0 {
+            comment = fmt.Sprintf("[%%eval #%v]", mateIn)
+        }
+
+        if nag != 0 {
+            move.Nags = append(move.Nags, nag)
+            comment = joinComments(comment, fmt.Sprintf("%v. %v was best.", judgement, board.San(bestMoves[index])))
+        }
+
+        move.Comment = joinComments(move.Comment, comment)
+
+        board.MakeMove(move.Move)
+    }
+
+    for _, stats := range []*playerStats{&job.white, &job.black} {
+        stats.Games = 1
+        stats.finish()
+    }
+
+    game.SetTag("Annotator", chess.GetBotInfo(bot).FullName())
+}
+
+// Returns the score of the position from the perspective of the side to move in pawns, or a mate
+// score for a forced mate, and the best move if the game is not over
+func evaluate(bot *botv1.BotV1, board *chess.Board, limits searchLimits) (float64, chess.Move) {
+    outcome := board.Outcome()
+    if outcome.IsOver() {
+        if _, ok := outcome.Winner(); ok {
+            // The side to move has been checkmated
+            return chess.MatedInPly(0), chess.Move {}
+        }
+
+        return 0, chess.Move {}
+    }
+
+    if limits.moveTime > 0 {
+        timer := time.AfterFunc(limits.moveTime, bot.Stop)
+        defer timer.Stop()
+    }
+
+    move := bot.Think(board)
+    return bot.RootMoves()[0].Score, move
+}
+
+// Returns the chance of winning (percent) for a score in pawns, using the model fitted by Lichess
+// to the results of rated games
+func winPercent(score float64) float64 {
+    centipawns := score * 100
+    return 50 + 50 * (2 / (1 + math.Exp(-0.00368208 * centipawns)) - 1)
+}
+
+// Returns the accuracy (percent) of a move that lost the given chance of winning (percent), using
+// the formula fitted by Lichess
+func moveAccuracy(loss float64) float64 {
+    accuracy := 103.1668 * math.Exp(-0.04354 * loss) - 3.1669
+    return math.Max(0, math.Min(100, accuracy))
+}
+
+func joinComments(a string, b string) string {
+    if a == "" {
+        return b
+    }
+    if b == "" {
+        return a
+    }
+
+    return a + " " + b
+}
+
+// Work out the mean accuracy from the sum over moves
+func (stats *playerStats) finish() {
+    if stats.Moves > 0 {
+        stats.Accuracy = math.Round(stats.accuracySum / float64(stats.Moves) * 10) / 10
+    }
+}
+
+// Add the stats of both players in the game to the summary
+func (result *summary) add(job job) {
+    game := gameSummary {
+        Event:  job.game.Tag("Event"),
+        Round:  job.game.Tag("Round"),
+        White:  job.game.Tag("White"),
+        Black:  job.game.Tag("Black"),
+        Result: job.game.Result.String(),
+    }
+    game.Stats.White = job.white
+    game.Stats.Black = job.black
+    result.Games = append(result.Games, game)
+
+    for _, player := range []struct { name string; stats playerStats } { { game.White, job.white }, { game.Black, job.black } } {
+        total := result.Players[player.name]
+        total.Games += player.stats.Games
+        total.Moves += player.stats.Moves
+        total.Inaccuracies += player.stats.Inaccuracies
+        total.Mistakes += player.stats.Mistakes
+        total.Blunders += player.stats.Blunders
+        total.accuracySum += player.stats.accuracySum
+        total.finish()
+
+        result.Players[player.name] = total
+    }
+}
+
+func writeSummary(path string, result summary) error {
+    data, err := json.MarshalIndent(result, "", "  ")
+    if err != nil {
+        return err
+    }
+
+    if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+        return errors.New(fmt.Sprintf("failed to write summary: %v", err))
+    }
+
+    return nil
+}
@@ -0,0 +1,173 @@
+package main
+
+// Evaluates many positions, reading one FEN per line from the files given or standard input and
+// writing the evaluation and best move for each as tab-separated values, in the same order
+// Positions are searched in parallel with a bot per worker, which makes it useful for labelling
+// datasets
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"gogm/botv1"
+	"gogm/chess"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// A position to evaluate and the results of evaluating it
+type job struct {
+    fen      string
+    score    float64
+    bestMove chess.Move
+    err      error
+}
+
+func main() {
+    depth := flag.Int("depth", 0, "maximum search depth per position in ply (0 for the bot's default)")
+    workers := flag.Int("j", runtime.NumCPU(), "number of positions to search in parallel")
+    flag.Usage = func() {
+        fmt.Fprintf(flag.CommandLine.Output(), "usage: %v [flags] [file ...]\n", os.Args[0])
+        fmt.Fprintln(flag.CommandLine.Output(), "Reads FENs from the files, or standard input if none are given, and writes")
+        fmt.Fprintln(flag.CommandLine.Output(), "fen<TAB>score<TAB>best move for each, with the score in pawns from white's perspective")
+        flag.PrintDefaults()
+    }
+    flag.Parse()
+
+    if *workers < 1 {
+        *workers = 1
+    }
+
+    fens, err := readFens(flag.Args())
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+    }
+
+    jobs := make([]job, len(fens))
+    for index, fen := range fens {
+        jobs[index].fen = fen
+    }
+
+    evaluateAll(jobs, *workers, *depth)
+
+    output := bufio.NewWriter(os.Stdout)
+    defer output.Flush()
+
+    failed := false
+    for _, job := range jobs {
+        if job.err != nil {
+            fmt.Fprintf(os.Stderr, "%v: %v\n", job.fen, job.err)
+            failed = true
+            continue
+        }
+
+        fmt.Fprintf(output, "%v\t%v\t%v\n", job.fen, formatScore(job.score), job.bestMove)
+    }
+
+    if failed {
+        output.Flush()
+        os.Exit(1)
+    }
+}
+
+// Read the non-empty lines of the files, or standard input if there are no files
+func readFens(paths []string) (fens []string, err error) {
+    if len(paths) == 0 {
+        return readLines(os.Stdin, fens)
+    }
+
+    for _, path := range paths {
+        file, err := os.Open(path)
+        if err != nil {
+            return nil, err
+        }
+
+        fens, err = readLines(file, fens)
+        file.Close()
+
+        if err != nil {
+            return nil, errors.New(fmt.Sprintf("%v: %v", path, err))
+        }
+    }
+
+    return fens, nil
+}
+
+func readLines(reader io.Reader, lines []string) ([]string, error) {
+    scanner := bufio.NewScanner(reader)
+
+    for scanner.Scan() {
+        if line := strings.TrimSpace(scanner.Text()); line != "" {
+            lines = append(lines, line)
+        }
+    }
+
+    return lines, scanner.Err()
+}
+
+// Evaluate every job, with `workers` goroutines each taking the next job until none are left
+// Bots keep state between searches, so each worker has its own
+func evaluateAll(jobs []job, workers int, depth int) {
+    var wait sync.WaitGroup
+    indices := make(chan int)
+
+    for worker := 0; worker < workers; worker++ {
+        wait.Add(1)
+
+        go func() {
+            defer wait.Done()
+
+            bot := &botv1.BotV1 {}
+            if depth > 0 {
+                bot.SetMaxDepth(depth)
+            }
+
+            for index := range indices {
+                evaluate(bot, &jobs[index])
+            }
+        }()
+    }
+
+    for index := range jobs {
+        indices <- index
+    }
+    close(indices)
+
+    wait.Wait()
+}
+
+func evaluate(bot *botv1.BotV1, job *job) {
+    board, err := chess.LoadFen(job.fen)
+    if err != nil {
+        job.err = err
+        return
+    }
+
+    if len(board.GetLegalMoves(false)) == 0 {
+        job.err = errors.New("no legal moves")
+        return
+    }
+
+    job.bestMove = bot.Think(board)
+
+    // Root move scores are from the perspective of the side to move
+    job.score = bot.RootMoves()[0].Score
+    if board.IsBlackToMove() {
+        job.score = -job.score
+    }
+}
+
+// Returns the score in pawns with two decimal places, or the number of moves to mate for a forced
+// mate, e.g. #3 if white mates in 3 and #-3 if black does
+func formatScore(score float64) string {
+    if mateIn, ok := chess.MateIn(score); ok {
+        return fmt.Sprintf("#%v", mateIn)
+    }
+
+    return fmt.Sprintf("%.2f", score)
+}
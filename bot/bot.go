@@ -0,0 +1,79 @@
+// Package bot defines how programs run bots: a bot thinks about a position within search limits
+// until it has decided or its context is cancelled, and reports how its search went along with its
+// move. Bots written against the older chess.Bot interface are run through FromChessBot
+package bot
+
+import (
+	"context"
+	"gogm/chess"
+	"time"
+)
+
+// Limits on a bot's search, as set by a time control or the UCI go command (see
+// chess.SearchLimits)
+type Limits = chess.SearchLimits
+
+// A bot as seen by the programs running it
+type Bot interface {
+	// Choose a move for the position within the limits, or if the context is cancelled first, the
+	// best move found so far, and return it with the final progress of the search. The board is
+	// left as it was
+	Think(ctx context.Context, board *chess.Board, limits Limits) (chess.Move, chess.SearchInfo)
+}
+
+// Implemented by chess.Bots that can think within a context themselves, which FromChessBot uses
+// rather than stopping them from outside
+type ContextBot interface {
+	chess.Bot
+	ThinkContext(ctx context.Context, board *chess.Board, limits Limits) (chess.Move, chess.SearchInfo)
+}
+
+// Returns a Bot that runs the chess.Bot
+// ContextBots think within the context themselves. Other bots think within the limits as far as
+// they can (see chess.ThinkWithLimits) and are stopped when the context is cancelled if they are
+// StoppableBots, and their search info has only the move and the time taken
+func FromChessBot(bot chess.Bot) Bot {
+	return chessBot{bot}
+}
+
+type chessBot struct {
+	bot chess.Bot
+}
+
+func (adapter chessBot) Think(ctx context.Context, board *chess.Board, limits Limits) (chess.Move, chess.SearchInfo) {
+	if contextBot, ok := adapter.bot.(ContextBot); ok {
+		return contextBot.ThinkContext(ctx, board, limits)
+	}
+
+	start := time.Now()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	if stoppableBot, ok := adapter.bot.(chess.StoppableBot); ok {
+		stop := context.AfterFunc(ctx, func() { keepStopping(stoppableBot, done) })
+		defer stop()
+	}
+
+	move := chess.ThinkWithLimits(adapter.bot, board, limits)
+
+	return move, chess.SearchInfo{
+		BestMove:           move,
+		PrincipalVariation: []chess.Move{move},
+		Time:               time.Since(start),
+	}
+}
+
+// Ask the bot to stop until `done` is closed. A stop requested before the search has started may be
+// forgotten when it starts, so one request is not enough
+func keepStopping(bot chess.StoppableBot, done <-chan struct{}) {
+	for {
+		bot.Stop()
+
+		select {
+		case <-done:
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package bot_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"gogm/bot"
+	"gogm/chess"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Thinks until it is stopped, then plays its first legal move
+type stoppableBot struct {
+	stopped atomic.Bool
+}
+
+func (b *stoppableBot) Think(board *chess.Board) chess.Move {
+	for !b.stopped.Load() {
+		time.Sleep(time.Millisecond)
+	}
+
+	return board.GetLegalMoves(false)[0]
+}
+
+func (b *stoppableBot) Stop() {
+	b.stopped.Store(true)
+}
+
+// Records the limits and context it was given
+type contextBot struct {
+	limits bot.Limits
+	ctx    context.Context
+}
+
+func (b *contextBot) Think(board *chess.Board) chess.Move {
+	return chess.Move{}
+}
+
+func (b *contextBot) ThinkContext(ctx context.Context, board *chess.Board, limits bot.Limits) (chess.Move, chess.SearchInfo) {
+	b.ctx, b.limits = ctx, limits
+	return chess.Move{Source: chess.E2, Destination: chess.E4}, chess.SearchInfo{Depth: 7}
+}
+
+func TestFromChessBotStopsOnCancel(t *testing.T) {
+	assert := assert.New(t)
+
+	board, _ := chess.LoadFen(chess.StartingPositionFen)
+	ctx, cancel := context.WithTimeout(context.Background(), 20 * time.Millisecond)
+	defer cancel()
+
+	move, info := bot.FromChessBot(&stoppableBot{}).Think(ctx, board, bot.Limits{Infinite: true})
+
+	assert.Equal(board.GetLegalMoves(false)[0], move)
+	assert.Equal(move, info.BestMove)
+	assert.Equal([]chess.Move{move}, info.PrincipalVariation)
+	assert.Greater(info.Time, time.Duration(0))
+}
+
+func TestFromChessBotCancelledBeforeThinking(t *testing.T) {
+	board, _ := chess.LoadFen(chess.StartingPositionFen)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	move, _ := bot.FromChessBot(&stoppableBot{}).Think(ctx, board, bot.Limits{})
+	assert.NotEqual(t, chess.Move{}, move)
+}
+
+func TestFromChessBotUsesContextBot(t *testing.T) {
+	assert := assert.New(t)
+
+	board, _ := chess.LoadFen(chess.StartingPositionFen)
+	contextBot := &contextBot{}
+	ctx := context.Background()
+	limits := bot.Limits{Depth: 7}
+
+	move, info := bot.FromChessBot(contextBot).Think(ctx, board, limits)
+
+	assert.Equal(chess.Move{Source: chess.E2, Destination: chess.E4}, move)
+	assert.Equal(7, info.Depth)
+	assert.Equal(limits, contextBot.limits)
+	assert.Equal(ctx, contextBot.ctx)
+}
@@ -0,0 +1,187 @@
+package botv1
+
+import (
+	"fmt"
+	"gogm/chess"
+	"strings"
+)
+
+// Set a function to be called with a comment on each move the bot chooses, or nil for no comments
+func (bot *BotV1) SetCommentCallback(callback chess.CommentCallback) {
+    bot.commentCallback = callback
+}
+
+// Describe the move chosen by the last search in a few words, e.g. "develops and threatens Nxe5"
+// The comment is derived from the scores of the root moves and simple motifs found by looking at
+// the position after the move
+func (bot *BotV1) comment(board *chess.Board, move chess.Move) string {
+    var parts []string
+
+    afterMove := board.MakeMoveCopy(move)
+    if afterMove.IsCheck() && len(afterMove.GetLegalMoves(false)) == 0 {
+        return "checkmate"
+    }
+
+    // What the search found
+    // Refuted root moves only have upper bounds on their scores, so whether they lose to mate is
+    // checked separately
+    mateIn, isMate := chess.MateIn(bot.rootMoves[0].Score)
+    switch {
+    case len(bot.rootMoves) == 1:
+        parts = append(parts, "only move")
+
+    case isMate && mateIn > 0:
+        parts = append(parts, fmt.Sprintf("forces mate in %v", mateIn))
+
+    case isMate:
+        parts = append(parts, "every move loses to mate")
+
+    case allOtherMovesAllowMateInOne(board, move):
+        parts = append(parts, "only move to avoid mate")
+    }
+
+    // What the move does
+    piece := board.GetPiece(move.Source)
+
+    switch {
+    case board.IsCastlingMove(move):
+        parts = append(parts, "castles")
+
+    case board.IsEnPassantMove(move):
+        parts = append(parts, "captures en passant")
+
+    case board.IsCaptureMove(move):
+        parts = append(parts, "captures a " + board.GetPiece(move.Destination).Kind.Name())
+
+    case isDevelopingMove(piece, move):
+        parts = append(parts, "develops")
+    }
+
+    if move.IsPromotion {
+        parts = append(parts, "promotes to a " + move.PromotedPiece.Name())
+    }
+
+    // What the move leads to
+    if checkKind := afterMove.GetCheckKind(move); checkKind != chess.NoCheck {
+        parts = append(parts, "gives " + checkKind.String())
+    } else if threat, ok := findThreat(&afterMove, move.Destination); ok {
+        parts = append(parts, "threatens " + threat)
+    }
+
+    if len(parts) == 0 {
+        return "quiet move"
+    }
+
+    return joinCommentParts(parts)
+}
+
+// Whether every legal move other than `move` lets the opponent checkmate immediately
+func allOtherMovesAllowMateInOne(board *chess.Board, move chess.Move) bool {
+    for _, other := range board.GetLegalMoves(false) {
+        if other == move {
+            continue
+        }
+
+        afterOther := board.MakeMoveCopy(other)
+        if !hasMateInOne(&afterOther) {
+            return false
+        }
+    }
+
+    return true
+}
+
+// Whether the side to move has a move that checkmates
+func hasMateInOne(board *chess.Board) bool {
+    for _, move := range board.GetLegalMoves(false) {
+        afterMove := board.MakeMoveCopy(move)
+        if afterMove.IsCheck() && len(afterMove.GetLegalMoves(false)) == 0 {
+            return true
+        }
+    }
+
+    return false
+}
+
+// Whether the move brings a knight or bishop off its back rank
+func isDevelopingMove(piece *chess.Piece, move chess.Move) bool {
+    if piece.Kind != chess.Knight && piece.Kind != chess.Bishop {
+        return false
+    }
+
+    backRank := chess.Rank1
+    if piece.IsBlack {
+        backRank = chess.Rank8
+    }
+
+    return move.Source.Rank() == backRank && move.Destination.Rank() != backRank
+}
+
+// Returns a capture the piece on `sq` could make next move (in SAN), if the side that just moved
+// were to move again, of a piece that is either worth more than it or undefended
+// The most valuable such piece is chosen
+func findThreat(board *chess.Board, sq chess.Square) (threat string, ok bool) {
+    unmove := board.MakeNullMove()
+    defer board.UnmakeNullMove(unmove)
+
+    attackerValue := pieceValueForThreats(board.GetPiece(sq).Kind)
+    bestValue := 0
+
+    for _, capture := range board.GetLegalMovesFromSquare(sq) {
+        if !board.IsCaptureMove(capture) {
+            continue
+        }
+
+        targetValue := pieceValueForThreats(board.GetPiece(capture.Destination).Kind)
+        if targetValue <= bestValue {
+            continue
+        }
+
+        afterCapture := board.MakeMoveCopy(capture)
+        if targetValue > attackerValue || !canCaptureOn(&afterCapture, capture.Destination) {
+            threat = board.San(capture)
+            bestValue = targetValue
+            ok = true
+        }
+    }
+
+    return
+}
+
+// Whether the side to move can capture the piece on `sq`
+func canCaptureOn(board *chess.Board, sq chess.Square) bool {
+    for _, capture := range board.GetLegalMoves(true) {
+        if capture.Destination == sq {
+            return true
+        }
+    }
+
+    return false
+}
+
+// Rough piece values for deciding what counts as a threat, independent of the evaluation
+// parameters
+func pieceValueForThreats(kind chess.PieceKind) int {
+    switch kind {
+    case chess.Pawn:
+        return 1
+    case chess.Knight, chess.Bishop:
+        return 3
+    case chess.Rook:
+        return 5
+    case chess.Queen:
+        return 9
+    }
+
+    return 100
+}
+
+// Join the parts of a comment into a phrase, e.g. "captures a knight, promotes to a queen and
+// gives check"
+func joinCommentParts(parts []string) string {
+    if len(parts) == 1 {
+        return parts[0]
+    }
+
+    return strings.Join(parts[:len(parts) - 1], ", ") + " and " + parts[len(parts) - 1]
+}
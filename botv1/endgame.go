@@ -0,0 +1,53 @@
+package botv1
+
+import (
+	"gogm/chess"
+)
+
+// Bonuses of the mop-up evaluation in pawns: for each step the losing king is from the centre, and
+// for each step the kings are closer together than the width of the board
+const (
+    mopUpEdgeBonus         float64 = 0.1
+    mopUpKingDistanceBonus float64 = 0.04
+)
+
+// Returns whether the position is a draw the evaluation knows of, which is king and pawn versus
+// king when the bitbase says the pawn cannot be forced through
+func isKnownDraw(board *chess.Board) bool {
+    return board.GetOccupiedBitboard().PopCount() == 3 &&
+        (board.PiecesBB(chess.Pawn, false) | board.PiecesBB(chess.Pawn, true)) != chess.EmptyBitboard &&
+        !probeKPK(board)
+}
+
+// Returns the mop-up bonus of the side if the enemy has only its king left and the side has the
+// material to checkmate it: the enemy king is driven towards the edge of the board and the side's
+// own king brought close to help, so that the search finds its way to checkmate even when the mate
+// is too deep to see, rather than shuffling its pieces around
+// https://www.chessprogramming.org/Mop-up_Evaluation
+func evaluateMopUp(board *chess.Board, black bool) float64 {
+    if board.GetPiecesBitboard(!black) != board.PiecesBB(chess.King, !black) || !hasMatingMaterial(board, black) {
+        return 0.0
+    }
+
+    king := board.GetKingSquare(black)
+    enemyKing := board.GetKingSquare(!black)
+
+    return float64(centreDistance(enemyKing)) * mopUpEdgeBonus + float64(14 - chess.ManhattanDistance(king, enemyKing)) * mopUpKingDistanceBonus
+}
+
+// Returns whether the side has the pieces to checkmate a lone king without the help of its pawns
+func hasMatingMaterial(board *chess.Board, black bool) bool {
+    bishops := board.PiecesBB(chess.Bishop, black).PopCount()
+    knights := board.PiecesBB(chess.Knight, black).PopCount()
+
+    return board.PiecesBB(chess.Queen, black) != chess.EmptyBitboard ||
+        board.PiecesBB(chess.Rook, black) != chess.EmptyBitboard ||
+        bishops >= 2 || bishops >= 1 && knights >= 1
+}
+
+// Returns the number of steps along files and ranks from the square to the nearest of the four
+// centre squares
+func centreDistance(sq chess.Square) int {
+    file, rank := int(sq.File()), int(sq.Rank())
+    return max(3 - file, file - 4) + max(3 - rank, rank - 4)
+}
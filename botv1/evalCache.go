@@ -0,0 +1,104 @@
+package botv1
+
+import (
+	"gogm/chess"
+)
+
+// Number of entries of the pawn hash table and of the evaluation cache, each a power of two so that
+// the index is the low bits of the key
+const (
+    pawnHashEntries  int = 1 << 14
+    evalCacheEntries int = 1 << 16
+)
+
+// Pawn structure score of a position, from white's perspective
+type pawnHashEntry struct {
+    key       uint64
+    structure float64
+    passed    float64
+    used      bool
+}
+
+type evalCacheEntry struct {
+    key   uint64
+    score float64
+    used  bool
+}
+
+// Results of evaluating recent positions, so that they need not be evaluated again
+// Pawn structure changes rarely between nodes of the search, as most moves are not pawn moves, so
+// its score is kept by the pawn-only hash of the position, and whole evaluations by the full hash,
+// as transpositions and the repeated evaluation of a node by different parts of the search lead to
+// the same position being evaluated more than once
+// https://www.chessprogramming.org/Pawn_Hash_Table
+// https://www.chessprogramming.org/Evaluation_Hash_Table
+type evalCaches struct {
+    pawns [pawnHashEntries]pawnHashEntry
+    evals [evalCacheEntries]evalCacheEntry
+
+    // Evaluation parameters the cached scores were evaluated with
+    params *EvalParams
+
+    // Number of lookups of each table since the counters were reset, and how many found an entry
+    pawnProbes uint64
+    pawnHits   uint64
+    evalProbes uint64
+    evalHits   uint64
+}
+
+// Forget the cached scores if they were evaluated with parameters other than `params`
+func (caches *evalCaches) useParams(params *EvalParams) {
+    if caches.params != params {
+        caches.clear()
+        caches.params = params
+    }
+}
+
+func (caches *evalCaches) clear() {
+    caches.pawns = [pawnHashEntries]pawnHashEntry{}
+    caches.evals = [evalCacheEntries]evalCacheEntry{}
+    caches.params = nil
+}
+
+func (caches *evalCaches) resetCounters() {
+    caches.pawnProbes, caches.pawnHits = 0, 0
+    caches.evalProbes, caches.evalHits = 0, 0
+}
+
+// Returns the pawn structure score and the passed pawn score of the position from white's
+// perspective, evaluating them if they are not in the pawn hash table
+func (caches *evalCaches) pawnStructure(board *chess.Board, params *EvalParams) (structure float64, passed float64) {
+    key := board.PawnKey()
+    entry := &caches.pawns[key & uint64(pawnHashEntries - 1)]
+
+    caches.pawnProbes++
+    if entry.used && entry.key == key {
+        caches.pawnHits++
+        return entry.structure, entry.passed
+    }
+
+    structure, passed = evaluatePawnStructure(board, params)
+    *entry = pawnHashEntry { key: key, structure: structure, passed: passed, used: true }
+
+    return structure, passed
+}
+
+// Returns the evaluation of the position stored in the cache, if there is one
+func (caches *evalCaches) probe(board *chess.Board) (float64, bool) {
+    key := board.Hash()
+    entry := &caches.evals[key & uint64(evalCacheEntries - 1)]
+
+    caches.evalProbes++
+    if entry.used && entry.key == key {
+        caches.evalHits++
+        return entry.score, true
+    }
+
+    return 0.0, false
+}
+
+// Store the complete evaluation of the position, replacing whichever position shared its entry
+func (caches *evalCaches) store(board *chess.Board, score float64) {
+    key := board.Hash()
+    caches.evals[key & uint64(evalCacheEntries - 1)] = evalCacheEntry { key: key, score: score, used: true }
+}
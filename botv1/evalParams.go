@@ -0,0 +1,266 @@
+package botv1
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "gogm/chess"
+    "math"
+    "os"
+)
+
+// Tunable parameters of the evaluation function
+// Piece values are measured in pawns and piece-square table entries in centipawns
+type EvalParams struct {
+    PawnValue           float64 `json:"pawnValue"`
+    KnightValue         float64 `json:"knightValue"`
+    BishopValue         float64 `json:"bishopValue"`
+    RookValue           float64 `json:"rookValue"`
+    QueenValue          float64 `json:"queenValue"`
+    CastlingRightsBonus float64 `json:"castlingRightsBonus"`
+
+    // Bonus for each square attacked by a knight, bishop, rook or queen that is not occupied by a
+    // piece of its own side
+    MobilityBonus float64 `json:"mobilityBonus"`
+
+    BishopPairBonus       float64 `json:"bishopPairBonus"`
+    RookOpenFileBonus     float64 `json:"rookOpenFileBonus"`
+    RookSemiOpenFileBonus float64 `json:"rookSemiOpenFileBonus"`
+    RookSeventhRankBonus  float64 `json:"rookSeventhRankBonus"`
+
+    // Bonus for a knight on the fourth to sixth rank that is defended by a pawn and can never be
+    // attacked by an enemy pawn
+    KnightOutpostBonus float64 `json:"knightOutpostBonus"`
+
+    // Penalty for each pawn on a file beyond the first, and for each pawn with no pawns of its own
+    // side on the neighbouring files
+    DoubledPawnPenalty  float64 `json:"doubledPawnPenalty"`
+    IsolatedPawnPenalty float64 `json:"isolatedPawnPenalty"`
+
+    // Bonus for a passed pawn on the seventh rank in the endgame, which is less for pawns further
+    // back and half as much in the middlegame
+    PassedPawnBonus float64 `json:"passedPawnBonus"`
+
+    PawnTables   PieceSquareTables `json:"pawnTables"`
+    KnightTables PieceSquareTables `json:"knightTables"`
+    BishopTables PieceSquareTables `json:"bishopTables"`
+    RookTables   PieceSquareTables `json:"rookTables"`
+    QueenTables  PieceSquareTables `json:"queenTables"`
+    KingTables   PieceSquareTables `json:"kingTables"`
+}
+
+// Middlegame and endgame piece-square tables for one kind of piece
+// Tables are written from white's perspective, starting from a8 and ending at h1
+type PieceSquareTables struct {
+    Middlegame [64]int `json:"middlegame"`
+    Endgame    [64]int `json:"endgame"`
+}
+
+// Returns the built-in evaluation parameters
+func DefaultEvalParams() *EvalParams {
+    return &EvalParams{
+        PawnValue:           1.0,
+        KnightValue:         3.0,
+        BishopValue:         3.2,
+        RookValue:           5.0,
+        QueenValue:          9.0,
+        CastlingRightsBonus: 0.5,
+
+        MobilityBonus: 0.03,
+
+        BishopPairBonus:       0.3,
+        RookOpenFileBonus:     0.25,
+        RookSemiOpenFileBonus: 0.1,
+        RookSeventhRankBonus:  0.2,
+        KnightOutpostBonus:    0.25,
+
+        DoubledPawnPenalty:  0.1,
+        IsolatedPawnPenalty: 0.1,
+        PassedPawnBonus:     0.5,
+
+        PawnTables:   PieceSquareTables{pieceSquareTablePawnMiddlegame, pieceSquareTablePawnEndgame},
+        KnightTables: PieceSquareTables{pieceSquareTableKnightMiddlegame, pieceSquareTableKnightEndgame},
+        BishopTables: PieceSquareTables{pieceSquareTableBishopMiddlegame, pieceSquareTableBishopEndgame},
+        RookTables:   PieceSquareTables{pieceSquareTableRookMiddlegame, pieceSquareTableRookEndgame},
+        QueenTables:  PieceSquareTables{pieceSquareTableQueenMiddlegame, pieceSquareTableQueenEndgame},
+        KingTables:   PieceSquareTables{pieceSquareTableKingMiddlegame, pieceSquareTableKingEndgame},
+    }
+}
+
+// Read evaluation parameters from a JSON file
+// Parameters missing from the file keep their default values
+func LoadEvalParams(path string) (*EvalParams, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+
+    params := DefaultEvalParams()
+    if err := json.Unmarshal(data, params); err != nil {
+        return nil, errors.New(fmt.Sprintf("failed to parse evaluation parameters %v: %v", path, err))
+    }
+
+    return params, nil
+}
+
+// Write the evaluation parameters to a JSON file
+func (params *EvalParams) Save(path string) error {
+    data, err := json.MarshalIndent(params, "", "  ")
+    if err != nil {
+        return err
+    }
+
+    return os.WriteFile(path, data, 0644)
+}
+
+// Names of the piece values and bonuses of EvalParams, in the order they appear in the vector
+var evalParamScalarNames = []string{
+    "pawnValue", "knightValue", "bishopValue", "rookValue", "queenValue", "castlingRightsBonus",
+    "mobilityBonus", "bishopPairBonus", "rookOpenFileBonus", "rookSemiOpenFileBonus",
+    "rookSeventhRankBonus", "knightOutpostBonus", "doubledPawnPenalty", "isolatedPawnPenalty",
+    "passedPawnBonus",
+}
+
+// Names of the piece-square tables of EvalParams, in the order they appear in the vector
+var evalParamTableNames = []string{
+    "pawnTables", "knightTables", "bishopTables", "rookTables", "queenTables", "kingTables",
+}
+
+func (params *EvalParams) scalars() []*float64 {
+    return []*float64{
+        &params.PawnValue, &params.KnightValue, &params.BishopValue, &params.RookValue, &params.QueenValue, &params.CastlingRightsBonus,
+        &params.MobilityBonus, &params.BishopPairBonus, &params.RookOpenFileBonus, &params.RookSemiOpenFileBonus,
+        &params.RookSeventhRankBonus, &params.KnightOutpostBonus, &params.DoubledPawnPenalty, &params.IsolatedPawnPenalty,
+        &params.PassedPawnBonus,
+    }
+}
+
+func (params *EvalParams) tables() []*PieceSquareTables {
+    return []*PieceSquareTables{
+        &params.PawnTables, &params.KnightTables, &params.BishopTables, &params.RookTables, &params.QueenTables, &params.KingTables,
+    }
+}
+
+// Returns the names of the entries of the vector returned by Vector, e.g. "knightValue" or
+// "pawnTables.endgame[8]"
+func EvalParamNames() []string {
+    names := append([]string{}, evalParamScalarNames...)
+
+    for _, table := range evalParamTableNames {
+        for _, phase := range []string{"middlegame", "endgame"} {
+            for sq := 0; sq < 64; sq++ {
+                names = append(names, fmt.Sprintf("%v.%v[%v]", table, phase, sq))
+            }
+        }
+    }
+
+    return names
+}
+
+// Returns the parameters as a vector of numbers for tuning, with the piece values and bonuses
+// followed by the middlegame and endgame entries of each piece-square table
+// Every entry is in centipawns, so that a step of 1 means as much for each of them
+func (params *EvalParams) Vector() []float64 {
+    var vector []float64
+
+    for _, scalar := range params.scalars() {
+        vector = append(vector, *scalar * 100.0)
+    }
+
+    for _, tables := range params.tables() {
+        for _, entry := range tables.Middlegame {
+            vector = append(vector, float64(entry))
+        }
+        for _, entry := range tables.Endgame {
+            vector = append(vector, float64(entry))
+        }
+    }
+
+    return vector
+}
+
+// Set the parameters from a vector laid out as returned by Vector
+// Piece-square table entries are rounded to whole centipawns
+func (params *EvalParams) SetVector(vector []float64) error {
+    scalars, tables := params.scalars(), params.tables()
+
+    if len(vector) != len(scalars) + len(tables) * 128 {
+        return errors.New(fmt.Sprintf("expected %v evaluation parameters, got %v", len(scalars) + len(tables) * 128, len(vector)))
+    }
+
+    for index, scalar := range scalars {
+        *scalar = vector[index] / 100.0
+    }
+
+    vector = vector[len(scalars):]
+    for _, tables := range tables {
+        for sq := 0; sq < 64; sq++ {
+            tables.Middlegame[sq] = int(math.Round(vector[sq]))
+            tables.Endgame[sq] = int(math.Round(vector[64 + sq]))
+        }
+
+        vector = vector[128:]
+    }
+
+    return nil
+}
+
+// Replace the evaluation parameters used by the bot
+// This is safe to call while the bot is thinking; the new parameters are used from the next search
+func (bot *BotV1) SetEvalParams(params *EvalParams) {
+    bot.evalParams.Store(params)
+}
+
+// Load evaluation parameters from a JSON file, remembering the path so that the parameters can be
+// reloaded with ReloadEvalParams
+func (bot *BotV1) LoadEvalParamsFile(path string) error {
+    bot.evalParamsPath = path
+    return bot.ReloadEvalParams()
+}
+
+// Reload the evaluation parameters from the file last passed to LoadEvalParamsFile, allowing
+// evaluation weights to be tweaked without restarting
+// If the file cannot be read, the current parameters are kept
+func (bot *BotV1) ReloadEvalParams() error {
+    if bot.evalParamsPath == "" {
+        return errors.New("no evaluation parameters file loaded")
+    }
+
+    params, err := LoadEvalParams(bot.evalParamsPath)
+    if err != nil {
+        return err
+    }
+
+    bot.SetEvalParams(params)
+    return nil
+}
+
+// Register the options of the evaluation: EvalFile, a JSON file of evaluation parameters to use
+// instead of the built-in ones, which are used again when it is set to nothing
+func (bot *BotV1) registerEvalOptions(registry *chess.OptionRegistry) {
+    registry.AddString("EvalFile", bot.evalParamsPath, func(path string) error {
+        // A file that cannot be read leaves the current parameters and path in place
+        params := (*EvalParams)(nil)
+        if path != "" {
+            var err error
+            if params, err = LoadEvalParams(path); err != nil {
+                return err
+            }
+        }
+
+        bot.evalParamsPath = path
+        bot.SetEvalParams(params)
+        return nil
+    })
+}
+
+// Returns the evaluation parameters currently in use
+func (bot *BotV1) currentEvalParams() *EvalParams {
+    if params := bot.evalParams.Load(); params != nil {
+        return params
+    }
+
+    return defaultEvalParams
+}
+
+var defaultEvalParams = DefaultEvalParams()
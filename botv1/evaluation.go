@@ -1,94 +1,354 @@
 package botv1
 
 import (
-	"math"
 	"gogm/chess"
 )
 
-func evaluate(board *chess.Board) float64 {
-    evaluation := float64(0.0)
+// Margin in pawns by which the material and piece-square table score must fall outside the
+// alpha-beta window for lazy evaluation to skip the remaining terms. This must be larger than the
+// most the remaining terms can add to or subtract from the score
+const lazyEvalMargin float64 = 2.0
+
+// Evaluate the position, looking it up in `caches` and storing it there unless `caches` is nil
+func evaluate(board *chess.Board, params *EvalParams, caches *evalCaches) float64 {
+    if isKnownDraw(board) {
+        return 0.0
+    }
+
+    if caches != nil {
+        if eval, ok := caches.probe(board); ok {
+            return eval
+        }
+    }
+
+    eval := evaluateRemaining(board, params, evaluateMaterial(board, params, caches))
+
+    if caches != nil {
+        caches.store(board, eval)
+    }
+
+    return eval
+}
+
+// Evaluate the position, returning only the cheap material and piece-square table score if it is
+// so far outside the window (alpha, beta) that the remaining terms cannot bring it back inside
+// Positions in check are always evaluated fully so that checkmate is not missed, but stalemate is
+// not detected when the evaluation is skipped. Only complete evaluations are stored in `caches`
+func evaluateLazy(board *chess.Board, params *EvalParams, caches *evalCaches, alpha float64, beta float64) float64 {
+    if isKnownDraw(board) {
+        return 0.0
+    }
+
+    if caches != nil {
+        if eval, ok := caches.probe(board); ok {
+            return eval
+        }
+    }
+
+    materialEval := evaluateMaterial(board, params, caches)
+
+    if !board.IsCheck() && (materialEval - lazyEvalMargin >= beta || materialEval + lazyEvalMargin <= alpha) {
+        return materialEval
+    }
+
+    eval := evaluateRemaining(board, params, materialEval)
+
+    if caches != nil {
+        caches.store(board, eval)
+    }
+
+    return eval
+}
+
+// Returns the material, piece-square table, piece placement, pawn structure and mop-up score from
+// the perspective of the side to move, looking the pawn structure up in `caches` unless it is nil
+func evaluateMaterial(board *chess.Board, params *EvalParams, caches *evalCaches) float64 {
+    black := board.IsBlackToMove()
+    endgameWeight := endgameWeight(board)
+
+    var structure, passed float64
+    if caches != nil {
+        structure, passed = caches.pawnStructure(board, params)
+    } else {
+        structure, passed = evaluatePawnStructure(board, params)
+    }
+
+    pawnEval := structure + scalePassedPawns(passed, endgameWeight)
+    if black {
+        pawnEval = -pawnEval
+    }
+
+    return evaluatePieces(board, black, params, endgameWeight) - evaluatePieces(board, !black, params, endgameWeight) + pawnEval
+}
+
+// Contribution of each kind of piece to the game phase, such that the pieces of the starting
+// position add up to openingPhase
+const (
+    knightPhase  int = 1
+    bishopPhase  int = 1
+    rookPhase    int = 2
+    queenPhase   int = 4
+    openingPhase int = 24
+)
+
+// Returns how far the position is into the endgame, from 0 with all the pieces of the starting
+// position on the board to 1 with only kings and pawns, used to blend the middlegame and endgame
+// piece-square tables
+// https://www.chessprogramming.org/Tapered_Eval
+func endgameWeight(board *chess.Board) float64 {
+    phase := 0
+    for _, black := range []bool{false, true} {
+        phase += board.PiecesBB(chess.Knight, black).PopCount() * knightPhase
+        phase += board.PiecesBB(chess.Bishop, black).PopCount() * bishopPhase
+        phase += board.PiecesBB(chess.Rook, black).PopCount() * rookPhase
+        phase += board.PiecesBB(chess.Queen, black).PopCount() * queenPhase
+    }
+
+    // Promotions can take the phase past that of the starting position
+    phase = min(phase, openingPhase)
+
+    return 1.0 - float64(phase) / float64(openingPhase)
+}
+
+// Complete the evaluation given the material and piece-square table score, detecting checkmate
+// and stalemate and adding the more expensive terms
+func evaluateRemaining(board *chess.Board, params *EvalParams, materialEval float64) float64 {
+    evaluation := materialEval
     black := board.IsBlackToMove()
     legalMoves := board.GetLegalMoves(false)
 
     if len(legalMoves) == 0 {
         if board.IsCheck() {
             // Checkmate
-            return math.Inf(-1)
+            return chess.MatedInPly(0)
         } else {
             // Stalemate
             return 0.0
         }
     }
 
-    evaluation += evaluatePieces(board, black)
-    evaluation -= evaluatePieces(board, !black)
+    evaluation += evaluateCastlingRights(board, black, params)
+    evaluation -= evaluateCastlingRights(board, !black, params)
 
-    evaluation += evaluateCastlingRights(board, black)
-    evaluation -= evaluateCastlingRights(board, !black)
+    evaluation += float64(board.MobilityCount(black) - board.MobilityCount(!black)) * params.MobilityBonus
 
     return evaluation
 }
 
-func evaluatePieces(board *chess.Board, black bool) (result float64) {
-    const (
-        pawnValue   float64 = 1.0
-        knightValue float64 = 3.0
-        bishopValue float64 = 3.2
-        rookValue   float64 = 5.0
-        queenValue  float64 = 9.0
-    )
+func evaluatePieces(board *chess.Board, black bool, params *EvalParams, endgameWeight float64) (result float64) {
+    result += evaluatePieceKind(board.PiecesBB(chess.Pawn, black), black, params.PawnValue, endgameWeight, &params.PawnTables)
+    result += evaluatePieceKind(board.PiecesBB(chess.Knight, black), black, params.KnightValue, endgameWeight, &params.KnightTables)
+    result += evaluatePieceKind(board.PiecesBB(chess.Bishop, black), black, params.BishopValue, endgameWeight, &params.BishopTables)
+    result += evaluatePieceKind(board.PiecesBB(chess.Rook, black), black, params.RookValue, endgameWeight, &params.RookTables)
+    result += evaluatePieceKind(board.PiecesBB(chess.Queen, black), black, params.QueenValue, endgameWeight, &params.QueenTables)
+    result += evaluatePieceKind(board.PiecesBB(chess.King, black), black, 0.0, endgameWeight, &params.KingTables)
+    result += evaluatePiecePlacement(board, black, params)
+    result += evaluateMopUp(board, black)
+
+    return
+}
+
+// Returns the bonuses for the bishop pair, rooks on open and semi-open files and on the seventh
+// rank, and knights on outposts
+func evaluatePiecePlacement(board *chess.Board, black bool, params *EvalParams) float64 {
+    rookFiles, rookSeventhRank := evaluateRooks(board, black, params)
+    return evaluateBishopPair(board, black, params) + rookFiles + rookSeventhRank + evaluateKnightOutposts(board, black, params)
+}
+
+func evaluateBishopPair(board *chess.Board, black bool, params *EvalParams) float64 {
+    if board.PiecesBB(chess.Bishop, black).PopCount() >= 2 {
+        return params.BishopPairBonus
+    }
+
+    return 0.0
+}
+
+// Returns the bonuses for rooks on open and semi-open files, and for rooks on the seventh rank
+func evaluateRooks(board *chess.Board, black bool, params *EvalParams) (files float64, seventhRank float64) {
+    friendlyPawns := board.PiecesBB(chess.Pawn, black)
+    enemyPawns := board.PiecesBB(chess.Pawn, !black)
+
+    for v := board.PiecesBB(chess.Rook, black); v != chess.EmptyBitboard; v = v.ClearLSB() {
+        sq := v.LSB()
+        file := chess.FileBitboard(sq.File())
+
+        if file & friendlyPawns == chess.EmptyBitboard {
+            if file & enemyPawns == chess.EmptyBitboard {
+                files += params.RookOpenFileBonus
+            } else {
+                files += params.RookSemiOpenFileBonus
+            }
+        }
+
+        if chess.RelativeSquare(sq, black).Rank() == chess.Rank7 {
+            seventhRank += params.RookSeventhRankBonus
+        }
+    }
+
+    return
+}
+
+func evaluateKnightOutposts(board *chess.Board, black bool, params *EvalParams) float64 {
+    outpostRanks := chess.RankBitboard(chess.Rank4) | chess.RankBitboard(chess.Rank5) | chess.RankBitboard(chess.Rank6)
+    if black {
+        outpostRanks = chess.RankBitboard(chess.Rank5) | chess.RankBitboard(chess.Rank4) | chess.RankBitboard(chess.Rank3)
+    }
+
+    friendlyPawns := board.PiecesBB(chess.Pawn, black)
+    enemyPawns := board.PiecesBB(chess.Pawn, !black)
+
+    outposts := outpostRanks & pawnAttacks(friendlyPawns, black) & ^pawnAttackSpan(enemyPawns, !black)
+    return float64((board.PiecesBB(chess.Knight, black) & outposts).PopCount()) * params.KnightOutpostBonus
+}
+
+// Returns the squares attacked by the pawns of the given side
+func pawnAttacks(pawns chess.Bitboard, black bool) chess.Bitboard {
+    if black {
+        return pawns.Shift(chess.SouthEast) | pawns.Shift(chess.SouthWest)
+    } else {
+        return pawns.Shift(chess.NorthEast) | pawns.Shift(chess.NorthWest)
+    }
+}
+
+// Returns the squares the pawns of the given side attack now or could attack after advancing
+func pawnAttackSpan(pawns chess.Bitboard, black bool) chess.Bitboard {
+    return pawnAttacks(pawns | pawnFrontSpan(pawns, black), black)
+}
+
+// Returns the squares in front of the pawns of the given side on their files
+func pawnFrontSpan(pawns chess.Bitboard, black bool) chess.Bitboard {
+    forward := chess.North
+    if black {
+        forward = chess.South
+    }
+
+    span := pawns.Shift(forward)
+    for step := 0; step < 5; step++ {
+        span |= span.Shift(forward)
+    }
+
+    return span
+}
+
+// Bonus for a passed pawn by its rank relative to its side, as a fraction of PassedPawnBonus, which
+// is the bonus on the seventh rank
+var passedPawnRankFactors = [8]float64 {
+    chess.Rank8: 0.0,
+    chess.Rank7: 1.0,
+    chess.Rank6: 0.6,
+    chess.Rank5: 0.35,
+    chess.Rank4: 0.2,
+    chess.Rank3: 0.1,
+    chess.Rank2: 0.05,
+    chess.Rank1: 0.0,
+}
 
-    endgameWeight := 0.0
+// Returns the pawn structure score and the passed pawn score of the position from white's
+// perspective. The passed pawn score is not yet scaled by game phase (see scalePassedPawns), as it
+// depends on the pawns alone and can then be kept in the pawn hash table
+func evaluatePawnStructure(board *chess.Board, params *EvalParams) (structure float64, passed float64) {
+    whiteDoubled, whiteIsolated, whitePassed := evaluatePawns(board, false, params)
+    blackDoubled, blackIsolated, blackPassed := evaluatePawns(board, true, params)
 
-    for _, piece := range board.GetPiecesForSide(black) {
-        switch piece.Kind {
-        case chess.Pawn:
-            result += pawnValue
-            result += evaluatePieceSquareTables(piece.Square, endgameWeight, &pieceSquareTablePawnMiddlegame, &pieceSquareTablePawnEndgame)
+    return whiteDoubled + whiteIsolated - blackDoubled - blackIsolated, whitePassed - blackPassed
+}
 
-        case chess.Knight:
-            result += knightValue
-            result += evaluatePieceSquareTables(piece.Square, endgameWeight, &pieceSquareTableKnightMiddlegame, &pieceSquareTableKnightEndgame)
+// Returns the penalties for doubled and isolated pawns of one side, as negative scores, and the
+// bonus for its passed pawns before scaling by game phase
+// Of several pawns on a file only the most advanced can be passed, and a pawn is passed if no enemy
+// pawn stands in front of it or can capture it on its way to promotion
+// https://www.chessprogramming.org/Passed_Pawn
+func evaluatePawns(board *chess.Board, black bool, params *EvalParams) (doubled float64, isolated float64, passed float64) {
+    friendlyPawns := board.PiecesBB(chess.Pawn, black)
+    enemyPawns := board.PiecesBB(chess.Pawn, !black)
 
-        case chess.Bishop:
-            result += bishopValue
-            result += evaluatePieceSquareTables(piece.Square, endgameWeight, &pieceSquareTableBishopMiddlegame, &pieceSquareTableBishopEndgame)
+    for file := chess.File(0); file < 8; file++ {
+        fileBitboard := chess.FileBitboard(file)
 
-        case chess.Rook:
-            result += rookValue
-            result += evaluatePieceSquareTables(piece.Square, endgameWeight, &pieceSquareTableRookMiddlegame, &pieceSquareTableRookEndgame)
+        pawnsOnFile := (friendlyPawns & fileBitboard).PopCount()
+        if pawnsOnFile == 0 {
+            continue
+        }
 
-        case chess.Queen:
-            result += queenValue
-            result += evaluatePieceSquareTables(piece.Square, endgameWeight, &pieceSquareTableQueenMiddlegame, &pieceSquareTableQueenEndgame)
+        doubled -= float64(pawnsOnFile - 1) * params.DoubledPawnPenalty
 
-        case chess.King:
-            result += evaluatePieceSquareTables(piece.Square, endgameWeight, &pieceSquareTableKingMiddlegame, &pieceSquareTableKingEndgame)
+        if friendlyPawns & (fileBitboard.Shift(chess.East) | fileBitboard.Shift(chess.West)) == chess.EmptyBitboard {
+            isolated -= float64(pawnsOnFile) * params.IsolatedPawnPenalty
         }
     }
 
+    stopped := pawnFrontSpan(enemyPawns, !black) | pawnAttackSpan(enemyPawns, !black) | pawnFrontSpan(friendlyPawns, black)
+
+    for v := friendlyPawns & ^stopped; v != chess.EmptyBitboard; v = v.ClearLSB() {
+        passed += passedPawnRankFactors[chess.RelativeSquare(v.LSB(), black).Rank()] * params.PassedPawnBonus
+    }
+
     return
 }
 
-func evaluateCastlingRights(board *chess.Board, black bool) float64 {
-    const castlingRightsBonus float64 = 0.5
+// Scale the passed pawn score by game phase: passed pawns count for half as much in the middlegame,
+// where there are more pieces to stop them
+func scalePassedPawns(passed float64, endgameWeight float64) float64 {
+    return passed * mix(0.5, 1.0, endgameWeight)
+}
+
+// Returns the material value and piece-square table score of the pieces of one kind
+func evaluatePieceKind(pieces chess.Bitboard, black bool, value float64, endgameWeight float64, tables *PieceSquareTables) float64 {
+    result := value * float64(pieces.PopCount())
 
-    canCastleKingside, canCastleQueenside := board.GetCastlingRights(black)
+    for v := pieces; v != chess.EmptyBitboard; v = v.ClearLSB() {
+        result += evaluatePieceSquareTables(chess.RelativeSquare(v.LSB(), black), endgameWeight, tables)
+    }
+
+    return result
+}
+
+func evaluateCastlingRights(board *chess.Board, black bool, params *EvalParams) float64 {
+    canCastleKingside, canCastleQueenside := board.GetCastlingRightsForColor(chess.ColorWithIsBlack(black))
 
     if canCastleKingside || canCastleQueenside {
-        return castlingRightsBonus
+        return params.CastlingRightsBonus
     } else {
         return 0.0
     }
 }
 
-func evaluatePieceSquareTables(sq chess.Square, endgameWeight float64, middlegameTable *[64]int, endgameTable *[64]int) float64 {
-    tableIndex := 63 - uint(sq)
-    middlegameSquareValue := float64(middlegameTable[tableIndex]) * 0.01
-    endgameSquareValue := float64(endgameTable[tableIndex]) * 0.01
+// Piece-square tables are written from white's perspective, so `relativeSquare` must be the square
+// of the piece relative to its side (see chess.RelativeSquare)
+func evaluatePieceSquareTables(relativeSquare chess.Square, endgameWeight float64, tables *PieceSquareTables) float64 {
+    tableIndex := uint(relativeSquare)
+    middlegameSquareValue := float64(tables.Middlegame[tableIndex]) * 0.01
+    endgameSquareValue := float64(tables.Endgame[tableIndex]) * 0.01
     return mix(middlegameSquareValue, endgameSquareValue, endgameWeight)
 }
 
 func mix(a float64, b float64, t float64) float64 {
     return a + (b - a) * t
 }
+
+// Returns the value in pawns of the piece captured by the move, or 0 if it is not a capture
+func (params *EvalParams) captureValue(board *chess.Board, move chess.Move) float64 {
+    if board.IsEnPassantMove(move) {
+        return params.PawnValue
+    }
+
+    victim := board.GetPiece(move.Destination)
+    if victim == nil {
+        return 0.0
+    }
+
+    switch victim.Kind {
+    case chess.Pawn:
+        return params.PawnValue
+    case chess.Knight:
+        return params.KnightValue
+    case chess.Bishop:
+        return params.BishopValue
+    case chess.Rook:
+        return params.RookValue
+    case chess.Queen:
+        return params.QueenValue
+    default:
+        return 0.0
+    }
+}
@@ -0,0 +1,94 @@
+package botv1
+
+import (
+	"gogm/chess"
+)
+
+// Scores positions for the search, so that a different evaluation, such as a neural network or the
+// built-in evaluation with experimental terms, can be used without changing the search
+// Scores must be in pawns, as the margins the search prunes by (futility, delta pruning, ProbCut and
+// the aspiration window) are
+type Evaluator interface {
+    // Returns the score of the position in pawns from the perspective of the side to move
+    // Checkmate is detected by the search, so the evaluator need not check for it
+    Evaluate(board *chess.Board) float64
+}
+
+// Implemented by evaluators that can save time on positions whose score is far outside the search
+// window, where only which side of the window the score is on matters
+type LazyEvaluator interface {
+    Evaluator
+
+    // Returns the score of the position, or if the score is outside the window (alpha, beta), any
+    // score on the same side of the window
+    EvaluateLazy(board *chess.Board, alpha float64, beta float64) float64
+}
+
+// Implemented by evaluators with their own piece values, which the search uses to decide which
+// captures could raise the score to alpha in the quiescence search (delta pruning). Captures by
+// other evaluators are valued with the piece values of static exchange evaluation
+type MaterialEvaluator interface {
+    Evaluator
+
+    // Returns the value in pawns of the piece captured by the move, or 0 if it is not a capture
+    CaptureValue(board *chess.Board, move chess.Move) float64
+}
+
+// The built-in evaluation: material, piece-square tables blended by game phase, piece placement,
+// pawn structure and mobility, weighted by the evaluation parameters
+type HandCraftedEvaluator struct {
+    Params *EvalParams
+
+    // Results of previous evaluations, kept by the bot for the evaluator it creates for a search, or
+    // nil for no caching
+    caches *evalCaches
+}
+
+func (evaluator HandCraftedEvaluator) Evaluate(board *chess.Board) float64 {
+    return evaluate(board, evaluator.Params, evaluator.caches)
+}
+
+func (evaluator HandCraftedEvaluator) EvaluateLazy(board *chess.Board, alpha float64, beta float64) float64 {
+    return evaluateLazy(board, evaluator.Params, evaluator.caches, alpha, beta)
+}
+
+func (evaluator HandCraftedEvaluator) CaptureValue(board *chess.Board, move chess.Move) float64 {
+    return evaluator.Params.captureValue(board, move)
+}
+
+// Use the evaluator in the search instead of the built-in evaluation, or nil to go back to the
+// built-in evaluation with the bot's evaluation parameters
+// This must not be called while the bot is thinking
+func (bot *BotV1) SetEvaluator(evaluator Evaluator) {
+    bot.evaluator = evaluator
+}
+
+// Returns the score of the position from the evaluator used by the current search, evaluated lazily
+// if the evaluator supports it
+func (bot *BotV1) staticEval(board *chess.Board, alpha float64, beta float64) float64 {
+    if bot.searchLazyEvaluator != nil {
+        return bot.searchLazyEvaluator.EvaluateLazy(board, alpha, beta)
+    }
+
+    return bot.searchEvaluator.Evaluate(board)
+}
+
+// Returns the value in pawns of the piece captured by the move, from the evaluator used by the
+// current search if it is a MaterialEvaluator and otherwise from the piece values of static exchange
+// evaluation
+func (bot *BotV1) captureValue(board *chess.Board, move chess.Move) float64 {
+    if bot.searchMaterialEvaluator != nil {
+        return bot.searchMaterialEvaluator.CaptureValue(board, move)
+    }
+
+    if board.IsEnPassantMove(move) {
+        return float64(chess.SeeValue(chess.Pawn)) * 0.01
+    }
+
+    victim := board.GetPiece(move.Destination)
+    if victim == nil {
+        return 0.0
+    }
+
+    return float64(chess.SeeValue(victim.Kind)) * 0.01
+}
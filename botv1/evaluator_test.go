@@ -0,0 +1,37 @@
+package botv1
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+// Evaluator with no piece values of its own
+type materialOnlyEvaluator struct{}
+
+func (materialOnlyEvaluator) Evaluate(board *chess.Board) float64 {
+	return 0.0
+}
+
+func TestCaptureValue(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("4k3/8/8/3n4/4P3/8/8/4K3 w - - 0 1")
+	assert.Nil(err)
+	capture := chess.Move{Source: chess.E4, Destination: chess.D5}
+	push := chess.Move{Source: chess.E4, Destination: chess.E5}
+
+	params := DefaultEvalParams()
+	params.KnightValue = 3.5
+
+	// The built-in evaluation values captures with its own piece values
+	bot := &BotV1{}
+	bot.searchMaterialEvaluator = HandCraftedEvaluator{Params: params}
+	assert.Equal(3.5, bot.captureValue(board, capture))
+	assert.Equal(0.0, bot.captureValue(board, push))
+
+	// Other evaluators fall back to the piece values of static exchange evaluation, in pawns
+	bot.searchMaterialEvaluator, _ = Evaluator(materialOnlyEvaluator{}).(MaterialEvaluator)
+	assert.Equal(3.0, bot.captureValue(board, capture))
+	assert.Equal(0.0, bot.captureValue(board, push))
+}
@@ -0,0 +1,159 @@
+package botv1
+
+import (
+	"gogm/chess"
+)
+
+// Greatest distance from the root at which the search keeps killer moves and the line being
+// searched (ply). Deeper nodes, only reached through long series of extensions, order their quiet
+// moves by history alone
+const maxHistoryPly int = 128
+
+// Bound on the history scores, which the updates approach but never pass, so that old results are
+// gradually outweighed by new ones
+const maxHistoryScore int = 16384
+
+// A move identified by the piece that made it and its destination, which says more about the move
+// than its source square when looking for replies to it
+type pieceMove struct {
+    piece       int
+    destination chess.Square
+}
+
+// Statistics on which quiet moves caused beta cutoffs, used to order the quiet moves of later nodes
+// best first. Captures are ordered by the material they win, but quiet moves have nothing so simple
+// to go on
+// https://www.chessprogramming.org/History_Heuristic
+type moveHistory struct {
+    // Move made at each ply of the line being searched
+    line [maxHistoryPly]pieceMove
+
+    // Two quiet moves that caused a cutoff at each ply, which often refute the other moves at the
+    // same ply too (killer moves)
+    // https://www.chessprogramming.org/Killer_Move
+    killers [maxHistoryPly][2]chess.Move
+
+    // How often each quiet move, by side, source and destination, caused a cutoff
+    butterfly [2][64][64]int
+
+    // Quiet move that last caused a cutoff in reply to each move, by piece and destination
+    // https://www.chessprogramming.org/Countermove_Heuristic
+    counterMoves [12][64]chess.Move
+
+    // How often each quiet move caused a cutoff in reply to each move, both by piece and
+    // destination (one-ply continuation history)
+    continuation [12][64][12][64]int
+}
+
+// Returns the index of the piece among both sides' pieces
+func pieceIndex(kind chess.PieceKind, black bool) int {
+    if black {
+        return 6 + int(kind)
+    }
+
+    return int(kind)
+}
+
+// Returns the move by piece and destination, for a move about to be made on the board
+func newPieceMove(board *chess.Board, move chess.Move) pieceMove {
+    return pieceMove { piece: pieceIndex(board.GetPiece(move.Source).Kind, board.IsBlackToMove()), destination: move.Destination }
+}
+
+// Forget the killer moves, which only apply to the position searched
+func (history *moveHistory) clearKillers() {
+    history.killers = [maxHistoryPly][2]chess.Move{}
+}
+
+// Record the move about to be made at the ply of the line being searched
+func (history *moveHistory) setLine(ply int, board *chess.Board, move chess.Move) {
+    if ply < maxHistoryPly {
+        history.line[ply] = newPieceMove(board, move)
+    }
+}
+
+// Returns the move made before the node at the ply, unless the node is the root or too deep for
+// the line to have been kept
+func (history *moveHistory) previousMove(ply int) (pieceMove, bool) {
+    if ply == 0 || ply > maxHistoryPly {
+        return pieceMove{}, false
+    }
+
+    return history.line[ply - 1], true
+}
+
+// Returns the ordering score of a quiet move at the ply: killers first, then the counter-move to
+// the previous move, then the rest by how often they caused cutoffs
+func (history *moveHistory) quietMoveScore(board *chess.Board, move chess.Move, ply int) int {
+    if ply < maxHistoryPly {
+        if move == history.killers[ply][0] {
+            return killerMoveScore + 1
+        }
+        if move == history.killers[ply][1] {
+            return killerMoveScore
+        }
+    }
+
+    black := board.IsBlackToMove()
+    score := history.butterfly[sideIndex(black)][move.Source][move.Destination]
+
+    if previous, ok := history.previousMove(ply); ok {
+        if move == history.counterMoves[previous.piece][previous.destination] {
+            return counterMoveScore
+        }
+
+        current := newPieceMove(board, move)
+        score += history.continuation[previous.piece][previous.destination][current.piece][current.destination]
+    }
+
+    return score
+}
+
+// Update the statistics for a quiet move that caused a cutoff at the ply, after the quiet moves in
+// `failed` were searched before it without causing one. The deeper the node, the more the cutoff
+// counts
+func (history *moveHistory) update(board *chess.Board, move chess.Move, failed []chess.Move, ply int, depth int) {
+    if ply < maxHistoryPly && history.killers[ply][0] != move {
+        history.killers[ply][1] = history.killers[ply][0]
+        history.killers[ply][0] = move
+    }
+
+    previous, hasPrevious := history.previousMove(ply)
+    if hasPrevious {
+        history.counterMoves[previous.piece][previous.destination] = move
+    }
+
+    bonus := min(depth * depth, maxHistoryBonus)
+
+    history.adjust(board, move, bonus, previous, hasPrevious)
+    for _, failedMove := range failed {
+        history.adjust(board, failedMove, -bonus, previous, hasPrevious)
+    }
+}
+
+// Largest change to a history score from a single cutoff
+const maxHistoryBonus int = 400
+
+func (history *moveHistory) adjust(board *chess.Board, move chess.Move, bonus int, previous pieceMove, hasPrevious bool) {
+    side := sideIndex(board.IsBlackToMove())
+    applyHistoryBonus(&history.butterfly[side][move.Source][move.Destination], bonus)
+
+    if hasPrevious {
+        current := newPieceMove(board, move)
+        applyHistoryBonus(&history.continuation[previous.piece][previous.destination][current.piece][current.destination], bonus)
+    }
+}
+
+// Move the score towards maxHistoryScore by the bonus, or towards -maxHistoryScore for a negative
+// bonus, by less the closer it already is
+// https://www.chessprogramming.org/History_Heuristic#History_Bonuses
+func applyHistoryBonus(score *int, bonus int) {
+    *score += bonus - *score * max(bonus, -bonus) / maxHistoryScore
+}
+
+func sideIndex(black bool) int {
+    if black {
+        return 1
+    }
+
+    return 0
+}
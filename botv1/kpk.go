@@ -0,0 +1,178 @@
+package botv1
+
+import (
+	"gogm/chess"
+	"sync"
+)
+
+// Bitbase of king and pawn versus king: whether each position is won for the side with the pawn,
+// or a draw. The evaluation cannot tell the two apart, as whether the pawn can be stopped depends
+// on the exact squares of the kings, so drawn positions are scored as draws and the search need not
+// see to the end of the ending to avoid them
+// The positions are indexed by the kings, the pawn and the side to move, with white having the pawn
+// on files a to d, and positions are mirrored to match. The bitbase is generated by retrograde
+// analysis when first needed, which takes a few milliseconds
+// https://www.chessprogramming.org/KPK
+const kpkPositions int = 2 * 24 * 64 * 64
+
+var (
+    kpkBitbase     [kpkPositions / 64]uint64
+    kpkBitbaseOnce sync.Once
+)
+
+// Returns the index of the position in the bitbase, with white having the pawn on files a to d and
+// ranks 2 to 7
+func kpkIndex(whiteKing chess.Square, blackKing chess.Square, pawn chess.Square, whiteToMove bool) int {
+    side := 0
+    if !whiteToMove {
+        side = 1
+    }
+
+    return int(whiteKing) | int(blackKing) << 6 | side << 12 | int(pawn.File()) << 13 | (int(pawn.Rank()) - int(chess.Rank7)) << 15
+}
+
+// Returns whether the side with the pawn wins the position with king and pawn versus king, or
+// draws it with best play
+func probeKPK(board *chess.Board) bool {
+    kpkBitbaseOnce.Do(generateKPKBitbase)
+
+    strongIsBlack := board.PiecesBB(chess.Pawn, true) != chess.EmptyBitboard
+
+    strongKing := board.GetKingSquare(strongIsBlack)
+    weakKing := board.GetKingSquare(!strongIsBlack)
+    pawn := board.PiecesBB(chess.Pawn, strongIsBlack).LSB()
+
+    // Mirror the board so that white has the pawn, on files a to d
+    if strongIsBlack {
+        strongKing, weakKing, pawn = strongKing ^ 56, weakKing ^ 56, pawn ^ 56
+    }
+    if pawn.File() >= 4 {
+        strongKing, weakKing, pawn = strongKing ^ 7, weakKing ^ 7, pawn ^ 7
+    }
+
+    index := kpkIndex(strongKing, weakKing, pawn, board.IsBlackToMove() == strongIsBlack)
+    return kpkBitbase[index / 64] & (1 << (index % 64)) != 0
+}
+
+// Results of positions during the generation of the bitbase, which can be combined as bit sets
+const (
+    kpkInvalid uint8 = 0
+    kpkUnknown uint8 = 1
+    kpkDraw    uint8 = 2
+    kpkWin     uint8 = 4
+)
+
+type kpkPosition struct {
+    whiteKing   chess.Square
+    blackKing   chess.Square
+    pawn        chess.Square
+    whiteToMove bool
+    result      uint8
+}
+
+// Generate the bitbase: positions won or drawn at once are classified first, then the rest are
+// classified from the positions they lead to until nothing changes. Any position still unknown
+// after that is a draw, as white could never force a win from it
+func generateKPKBitbase() {
+    positions := make([]kpkPosition, kpkPositions)
+
+    for index := range positions {
+        positions[index] = newKPKPosition(index)
+    }
+
+    for changed := true; changed; {
+        changed = false
+
+        for index := range positions {
+            if position := &positions[index]; position.result == kpkUnknown {
+                position.result = position.classify(positions)
+                changed = changed || position.result != kpkUnknown
+            }
+        }
+    }
+
+    for index, position := range positions {
+        if position.result == kpkWin {
+            kpkBitbase[index / 64] |= 1 << (index % 64)
+        }
+    }
+}
+
+// Returns the position at the index, classified as far as it can be on its own
+func newKPKPosition(index int) kpkPosition {
+    position := kpkPosition {
+        whiteKing:   chess.Square(index & 63),
+        blackKing:   chess.Square(index >> 6 & 63),
+        whiteToMove: index >> 12 & 1 == 0,
+        pawn:        chess.SquareAt(chess.File(index >> 13 & 3), chess.Rank(index >> 15) + chess.Rank7),
+    }
+
+    pawnAttacks := chess.PawnAttacks(position.pawn, false)
+    promotionSquare := position.pawn - 8
+
+    switch {
+    // Kings next to each other or on the pawn, or the black king in check with white to move
+    case chess.ChebyshevDistance(position.whiteKing, position.blackKing) <= 1 ||
+        position.whiteKing == position.pawn || position.blackKing == position.pawn ||
+        position.whiteToMove && pawnAttacks.Get(position.blackKing):
+        position.result = kpkInvalid
+
+    // The pawn promotes and the new queen cannot be captured
+    case position.whiteToMove && position.pawn.Rank() == chess.Rank7 && position.whiteKing != promotionSquare &&
+        (chess.ChebyshevDistance(position.blackKing, promotionSquare) > 1 || chess.ChebyshevDistance(position.whiteKing, promotionSquare) == 1):
+        position.result = kpkWin
+
+    // Stalemate, or the black king captures the undefended pawn
+    case !position.whiteToMove && (chess.KingAttacks(position.blackKing) & ^(chess.KingAttacks(position.whiteKing) | pawnAttacks) == chess.EmptyBitboard ||
+        chess.KingAttacks(position.blackKing).Get(position.pawn) && !chess.KingAttacks(position.whiteKing).Get(position.pawn)):
+        position.result = kpkDraw
+
+    default:
+        position.result = kpkUnknown
+    }
+
+    return position
+}
+
+// Returns the result of the position from the results of the positions its moves lead to: white
+// wins if any move wins, black draws if any move draws, and otherwise the result is the other one
+// once all the moves are known
+// Moves onto squares that are not allowed lead to invalid positions, which are ignored
+func (position *kpkPosition) classify(positions []kpkPosition) uint8 {
+    good, bad := kpkDraw, kpkWin
+    if position.whiteToMove {
+        good, bad = kpkWin, kpkDraw
+    }
+
+    results := kpkInvalid
+
+    if position.whiteToMove {
+        for v := chess.KingAttacks(position.whiteKing); v != chess.EmptyBitboard; v = v.ClearLSB() {
+            results |= positions[kpkIndex(v.LSB(), position.blackKing, position.pawn, false)].result
+        }
+
+        // Pawn pushes, promotions having been classified already
+        if position.pawn.Rank() != chess.Rank7 {
+            push := position.pawn - 8
+            results |= positions[kpkIndex(position.whiteKing, position.blackKing, push, false)].result
+
+            doublePush := push - 8
+            if position.pawn.Rank() == chess.Rank2 && push != position.whiteKing && push != position.blackKing {
+                results |= positions[kpkIndex(position.whiteKing, position.blackKing, doublePush, false)].result
+            }
+        }
+    } else {
+        for v := chess.KingAttacks(position.blackKing); v != chess.EmptyBitboard; v = v.ClearLSB() {
+            results |= positions[kpkIndex(position.whiteKing, v.LSB(), position.pawn, true)].result
+        }
+    }
+
+    switch {
+    case results & good != 0:
+        return good
+    case results & kpkUnknown != 0:
+        return kpkUnknown
+    default:
+        return bad
+    }
+}
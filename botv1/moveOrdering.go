@@ -0,0 +1,86 @@
+package botv1
+
+import (
+	"gogm/chess"
+)
+
+// Moves are searched in order of these scores, highest first, so that alpha-beta finds the best
+// move early and can prune the rest
+// Captures that win material or break even come first, ordered by most valuable victim then least
+// valuable attacker (MVV-LVA), then the killer moves and the counter-move, then the other quiet
+// moves by their history scores, which lie between -2 * maxHistoryScore and 2 * maxHistoryScore,
+// then captures that lose material
+// https://www.chessprogramming.org/MVV-LVA
+const (
+    hashMoveScore    int = 1 << 30
+    goodCaptureScore int = 1 << 20
+    killerMoveScore  int = 1 << 19
+    counterMoveScore int = 1 << 18
+    quietMoveScore   int = 0
+    badCaptureScore  int = -1 << 20
+)
+
+// Sort the moves best first, with `hashMove` first of all if it is one of them
+// Quiet moves are ordered by the history of the search at the ply if `history` is not nil, and
+// otherwise in generation order
+func orderMoves(board *chess.Board, moves []chess.Move, hashMove chess.Move, hasHashMove bool, history *moveHistory, ply int) {
+    var scoreBuffer [256]int
+    scores := scoreBuffer[:0]
+
+    for _, move := range moves {
+        if hasHashMove && move == hashMove {
+            scores = append(scores, hashMoveScore)
+        } else {
+            scores = append(scores, moveOrderingScore(board, move, history, ply))
+        }
+    }
+
+    // Insertion sort, as move lists are short and it keeps moves with equal scores in generation
+    // order, which keeps the search deterministic
+    for i := 1; i < len(moves); i++ {
+        move, score := moves[i], scores[i]
+
+        j := i
+        for ; j > 0 && scores[j - 1] < score; j-- {
+            moves[j], scores[j] = moves[j - 1], scores[j - 1]
+        }
+
+        moves[j], scores[j] = move, score
+    }
+}
+
+// Returns the score used to order the move
+// A capture of a piece at least as valuable as the capturing piece cannot lose material, so only
+// the close cases where the attacker is worth more than the victim need a static exchange
+// evaluation to tell whether they are good or bad
+func moveOrderingScore(board *chess.Board, move chess.Move, history *moveHistory, ply int) int {
+    attacker := board.GetPiece(move.Source).Kind
+
+    victimValue := 0
+    if board.IsEnPassantMove(move) {
+        victimValue = chess.SeeValue(chess.Pawn)
+    } else if victim := board.GetPiece(move.Destination); victim != nil {
+        victimValue = chess.SeeValue(victim.Kind)
+    }
+
+    // Promotions are ordered as if they captured the value they add
+    if move.IsPromotion {
+        victimValue += chess.SeeValue(move.PromotedPiece) - chess.SeeValue(chess.Pawn)
+    }
+
+    if victimValue == 0 {
+        if history != nil {
+            return history.quietMoveScore(board, move, ply)
+        }
+
+        return quietMoveScore
+    }
+
+    mvvLva := victimValue * 16 - chess.SeeValue(attacker) / 100
+
+    if victimValue < chess.SeeValue(attacker) && board.SEE(move) < 0 {
+        return badCaptureScore + mvvLva
+    }
+
+    return goodCaptureScore + mvvLva
+}
@@ -0,0 +1,251 @@
+package botv1
+
+import (
+	"errors"
+	"fmt"
+	"gogm/chess"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Configuration of a bot created by New, so that bots configured differently can play each other
+// in the same process. Zero values leave the defaults
+type Options struct {
+    // Size of the transposition table in megabytes
+    HashSizeMB int
+
+    // Maximum depth of iterative deepening (ply), and maximum number of nodes searched per move
+    MaxDepth int
+    MaxNodes uint64
+
+    // Time Think spends on each move, rather than searching to the maximum depth
+    MoveTime time.Duration
+
+    // Number of best moves to find exact scores for (see SetMultiPV)
+    MultiPV int
+
+    // How much worse than equal the bot considers a draw for itself (pawns, see SetContempt)
+    Contempt float64
+
+    // Strength from 1 to 20, or 0 for full strength (see SetSkillLevel)
+    SkillLevel int
+
+    // How far below the best move's score a move can be and still be chosen at random (pawns, see
+    // SetRandomMargin), and the seed for choosing, or 0 for a random seed
+    RandomMargin float64
+    RandomSeed   int64
+
+    // JSON file of evaluation parameters, which can be reloaded with ReloadEvalParams
+    EvalParamsPath string
+
+    // Whether NewGame keeps the search state (see SetKeepHashBetweenGames)
+    KeepHashBetweenGames bool
+
+    // Whether each search depends only on the position, the limits and RandomSeed (see
+    // SetDeterministic)
+    Deterministic bool
+}
+
+// Highest skill level, at which the bot plays at full strength
+const maxSkillLevel int = 20
+
+// Returns a bot configured by the options
+func New(options Options) (*BotV1, error) {
+    if options.HashSizeMB < 0 || options.MaxDepth < 0 || options.MoveTime < 0 || options.MultiPV < 0 || options.RandomMargin < 0 {
+        return nil, errors.New("hash size, depth, move time, MultiPV and random margin must not be negative")
+    }
+
+    if options.SkillLevel < 0 || options.SkillLevel > maxSkillLevel {
+        return nil, errors.New(fmt.Sprintf("skill level must be from 1 to %v, or 0 for full strength: %v", maxSkillLevel, options.SkillLevel))
+    }
+
+    bot := &BotV1{}
+    bot.SetHashSize(options.HashSizeMB)
+    bot.SetMaxDepth(options.MaxDepth)
+    bot.SetMaxNodes(options.MaxNodes)
+    bot.SetMoveTime(options.MoveTime)
+    bot.SetMultiPV(options.MultiPV)
+    bot.SetContempt(options.Contempt)
+    bot.SetSkillLevel(options.SkillLevel)
+    bot.SetRandomMargin(options.RandomMargin)
+    if options.RandomSeed != 0 {
+        bot.SetRandomSeed(options.RandomSeed)
+    }
+    bot.SetKeepHashBetweenGames(options.KeepHashBetweenGames)
+    bot.SetDeterministic(options.Deterministic)
+
+    if options.EvalParamsPath != "" {
+        if err := bot.LoadEvalParamsFile(options.EvalParamsPath); err != nil {
+            return nil, err
+        }
+    }
+
+    return bot, nil
+}
+
+// Set an option listed in Info, as by the UCI "setoption" command, returning an error if there is no
+// such option or the value is not valid for it (see chess.ConfigurableBot)
+// This must not be called while the bot is thinking
+func (bot *BotV1) SetOption(name string, value string) error {
+    return bot.optionRegistry().Set(name, value)
+}
+
+// Returns the options of the bot, with its current settings as their defaults. Each part of the bot
+// registers its own options, so a new option only needs registering where it is used
+func (bot *BotV1) optionRegistry() *chess.OptionRegistry {
+    registry := &chess.OptionRegistry{}
+    bot.registerSearchOptions(registry)
+    bot.registerStrengthOptions(registry)
+    bot.registerEvalOptions(registry)
+
+    return registry
+}
+
+// Register the options setting the strength and variety of play: the skill level, the random margin
+// (centipawns) and deterministic mode
+func (bot *BotV1) registerStrengthOptions(registry *chess.OptionRegistry) {
+    skillLevel := bot.skillLevel
+    if skillLevel == 0 {
+        skillLevel = maxSkillLevel
+    }
+
+    registry.AddSpin("Skill Level", skillLevel, 1, maxSkillLevel, bot.SetSkillLevel)
+    registry.AddSpin("Random Margin", int(math.Round(bot.randomMargin * 100.0)), 0, maxRandomMarginCentipawns, func(margin int) {
+        bot.SetRandomMargin(float64(margin) / 100.0)
+    })
+    registry.AddCheck("Deterministic", bot.deterministic, bot.SetDeterministic)
+}
+
+// Largest random margin that can be set through the Random Margin option (centipawns)
+const maxRandomMarginCentipawns int = 100
+
+// Set the strength of the bot, from 1 to 20 (full strength), or 0 for full strength
+// Below full strength, the search is shallower and the bot sometimes chooses one of the other best
+// moves over the best, more often and by more the lower the level
+func (bot *BotV1) SetSkillLevel(level int) {
+    bot.skillLevel = level
+}
+
+// Make the bot choose at random between the moves scoring within the margin of the best move
+// (pawns), so that games between bots from the same position do not all repeat the same moves, or
+// 0 to always choose the best move. Forced mates are always played. A small margin, such as 0.1,
+// varies the openings without costing much strength
+func (bot *BotV1) SetRandomMargin(margin float64) {
+    bot.randomMargin = margin
+}
+
+// Seed the choices made at random by a random margin or a skill level below full strength, so that
+// a bot created with the same seed makes the same choices in the same positions
+// Otherwise a random seed is used
+func (bot *BotV1) SetRandomSeed(seed int64) {
+    bot.random = rand.New(rand.NewSource(seed))
+    bot.randomSeed = seed
+}
+
+// Make each search depend only on the position, the limits and the random seed (0 unless set by
+// SetRandomSeed), so that repeating a search gives the same move, scores, principal variations and
+// node counts, down to the byte, for debugging and for checking that a change to the search
+// changes only what it should. Only the times reported differ
+// In this mode each search starts from a cleared transposition table and move ordering history,
+// the random choices are seeded afresh, and time limits are turned into node limits at
+// deterministicNodesPerSecond. Stopping the search with Stop still ends it early
+func (bot *BotV1) SetDeterministic(deterministic bool) {
+    bot.deterministic = deterministic
+}
+
+// Speed at which time limits are turned into node limits in deterministic mode, about the speed of
+// the search on a typical machine
+const deterministicNodesPerSecond uint64 = 500000
+
+// Returns the number of nodes searched in the time in deterministic mode
+func nodesForTime(duration time.Duration) uint64 {
+    return max(uint64(duration.Milliseconds()) * deterministicNodesPerSecond / 1000, 1)
+}
+
+// Returns the source of chance in choosing between moves, creating it with a random seed if it was
+// not seeded
+func (bot *BotV1) rng() *rand.Rand {
+    if bot.random == nil {
+        bot.random = rand.New(rand.NewSource(time.Now().UnixNano()))
+    }
+
+    return bot.random
+}
+
+// Returns one of the root moves scoring within the random margin of the best, chosen uniformly at
+// random
+func (bot *BotV1) randomMove() chess.Move {
+    best := bot.rootMoves[0]
+    if chess.IsMateScore(best.Score) {
+        return best.Move
+    }
+
+    candidates := 1
+    for _, rootMove := range bot.rootMoves[1:] {
+        if !rootMove.Exact || rootMove.Score < best.Score - bot.randomMargin {
+            break
+        }
+        candidates++
+    }
+
+    return bot.rootMoves[bot.rng().Intn(candidates)].Move
+}
+
+func (bot *BotV1) isWeakened() bool {
+    return bot.skillLevel > 0 && bot.skillLevel < maxSkillLevel
+}
+
+// Returns the number of best moves the search finds exact scores for. A weakened bot needs several
+// to choose between
+func (bot *BotV1) pvCount() int {
+    if bot.isWeakened() {
+        return max(bot.multiPV, weakenedPVCount)
+    }
+
+    return max(bot.multiPV, 1)
+}
+
+// Number of best moves a weakened bot chooses between
+const weakenedPVCount int = 4
+
+// Returns the depth a bot at the skill level searches to (ply)
+func skillDepth(level int) int {
+    return 1 + level / 2
+}
+
+// Returns a move chosen from the best root moves of the search, favouring the best move less the
+// lower the skill level. Each move's score is pushed up by a random amount and, at low levels, by
+// how much worse than the best move it is, and the move with the highest pushed score is chosen
+// This follows the skill levels of Stockfish
+func (bot *BotV1) weakenedMove() chess.Move {
+    candidates := bot.rootMoves[:min(len(bot.rootMoves), weakenedPVCount)]
+
+    // Mates are not thrown away
+    best := candidates[0]
+    if chess.IsMateScore(best.Score) {
+        return best.Move
+    }
+
+    // Scores in centipawns
+    weakness := 120 - 2 * (bot.skillLevel - 1)
+    delta := min(best.Score - candidates[len(candidates) - 1].Score, 1.0) * 100.0
+
+    chosen := best
+    highest := math.Inf(-1)
+
+    for _, candidate := range candidates {
+        if !candidate.Exact {
+            break
+        }
+
+        gap := (best.Score - candidate.Score) * 100.0
+        push := (float64(weakness) * gap + delta * float64(bot.rng().Intn(weakness))) / 128.0
+
+        if pushed := candidate.Score * 100.0 + push; pushed > highest {
+            chosen, highest = candidate, pushed
+        }
+    }
+
+    return chosen.Move
+}
@@ -0,0 +1,7 @@
+//go:build amd64
+
+package botv1
+
+// Hint to the CPU that the memory at `address` will be read soon
+// Implemented in prefetch_amd64.s
+func prefetch(address uintptr)
@@ -0,0 +1,6 @@
+//go:build !amd64
+
+package botv1
+
+// Prefetching is only implemented on amd64, elsewhere the hint is skipped
+func prefetch(address uintptr) {}
@@ -1,63 +1,948 @@
 package botv1
 
 import (
+	"context"
 	"gogm/chess"
 	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
-type BotV1 struct {}
+// The search is single-threaded and uses no random numbers, and its transposition table is only
+// shared between searches by the same bot, so searching the same position with a new bot (or after
+// ClearHash) always visits the same nodes in the same order and returns the same move. Only a skill
+// level below full strength or a random margin brings in chance, when choosing between the best
+// moves found, and time limits make the depth reached depend on the machine. SetDeterministic
+// removes both, and the state kept between searches
+// The zero value is a bot with the default configuration, and New creates a bot configured by
+// Options
+type BotV1 struct {
+    stats          SearchStats
+    evalParams     atomic.Pointer[EvalParams]
+    evalParamsPath string
+
+    // Evaluation set by SetEvaluator, or nil for the built-in evaluation
+    evaluator Evaluator
+
+    // Evaluation parameters and evaluator used by the current search, and the evaluator again if
+    // it is a LazyEvaluator or a MaterialEvaluator
+    searchParams            *EvalParams
+    searchEvaluator         Evaluator
+    searchLazyEvaluator     LazyEvaluator
+    searchMaterialEvaluator MaterialEvaluator
+
+    // Root moves of the current search, ordered by the scores from the previous iteration
+    rootMoves []RootMove
+
+    // Copy of the root moves after the last completed iteration, shared with other goroutines
+    publishedRootMovesLock sync.Mutex
+    publishedRootMoves     []RootMove
+
+    // Maximum depth of iterative deepening (ply), or 0 to use searchDepth
+    maxDepth int
+
+    // Depth of the current iteration of iterative deepening (ply)
+    rootDepth int
+
+    // Number of nodes after which the search is stopped, or 0 for no limit
+    maxNodes uint64
+
+    // Time after which no more iterations are started, or the zero time for no limit
+    softDeadline time.Time
+
+    // Number of nodes after which no more iterations are started, or 0 for no limit, standing in for
+    // the soft deadline in deterministic mode
+    softMaxNodes uint64
+
+    // Time Think spends on each move, or 0 to search to the maximum depth
+    moveTime time.Duration
+
+    // Number of best moves to find exact scores for, or 0 for just the best move
+    multiPV int
+
+    // Skill level from 1 to maxSkillLevel, or 0 for full strength
+    skillLevel int
+
+    // How far below the best move's score a move can be and still be chosen at random (pawns), or 0
+    // to always choose the best move
+    randomMargin float64
+
+    // Source of chance in choosing between moves, seeded by SetRandomSeed or otherwise created with
+    // a random seed when first needed, and the seed set by SetRandomSeed
+    random     *rand.Rand
+    randomSeed int64
+
+    // Whether each search depends only on the position, the limits and the random seed (see
+    // SetDeterministic)
+    deterministic bool
+
+    // Set by Stop to abandon the current search
+    stopRequested atomic.Bool
+
+    // Score of a draw for the bot's side (pawns below 0), and whether the bot is black in the
+    // current search
+    contempt    float64
+    rootIsBlack bool
+
+    // Called with a comment on each move chosen, if set
+    commentCallback chess.CommentCallback
+
+    // Called after each completed iteration of iterative deepening, if set
+    searchInfoCallback chess.SearchInfoCallback
+
+    // Progress of the current search after its last completed iteration
+    lastSearchInfo chess.SearchInfo
+
+    // Whether NewGame keeps the search state rather than clearing it
+    keepHashBetweenGames bool
+
+    // Results of previous searches, allocated by the first search
+    tt         *transpositionTable
+    hashSizeMB int
+
+    // Which quiet moves caused cutoffs in previous searches, allocated along with the transposition
+    // table
+    history *moveHistory
+
+    // Pawn hash table and evaluation cache of the built-in evaluation, allocated along with the
+    // transposition table
+    caches *evalCaches
+
+    // Whether to check that the move of each transposition table entry found is legal
+    verifyHash bool
+}
+
+// A legal move in the position being searched, with the results of searching it in the most
+// recent iteration of iterative deepening
+type RootMove struct {
+    Move chess.Move
+
+    // Evaluation of the move from the perspective of the side to move, in pawns or as a mate score
+    // (see chess.MateIn). If Exact is false, the move was refuted and the score is only an upper bound
+    Score float64
+    Exact bool
+
+    // Depth the move was searched to (ply, including the move itself)
+    Depth int
+
+    // Number of nodes (including quiescence nodes) searched below the move in the iteration
+    Nodes uint64
+}
+
+// Statistics collected during a search, useful for comparing searches between versions
+type SearchStats struct {
+    // Number of nodes visited by the main search
+    Nodes uint64
+
+    // Number of nodes visited by the quiescence search
+    QuiescenceNodes uint64
+
+    // Greatest distance from the root reached by any line, including extensions and the quiescence
+    // search (ply)
+    SelectiveDepth int
+
+    // Number of transposition table entries found whose move was not legal in the position, which
+    // means two positions had the same hash or the hash was not updated correctly. Only counted
+    // after SetVerifyHash(true)
+    HashMismatches uint64
+
+    // Number of lookups in the pawn hash table and the evaluation cache of the built-in evaluation,
+    // and how many found the position
+    PawnHashProbes  uint64
+    PawnHashHits    uint64
+    EvalCacheProbes uint64
+    EvalCacheHits   uint64
+}
+
+// Returns the fraction of lookups in the pawn hash table that found the pawn structure, or 0 if
+// there were none
+func (stats SearchStats) PawnHashHitRate() float64 {
+    return hitRate(stats.PawnHashHits, stats.PawnHashProbes)
+}
+
+// Returns the fraction of lookups in the evaluation cache that found the position, or 0 if there
+// were none
+func (stats SearchStats) EvalCacheHitRate() float64 {
+    return hitRate(stats.EvalCacheHits, stats.EvalCacheProbes)
+}
+
+func hitRate(hits uint64, probes uint64) float64 {
+    if probes == 0 {
+        return 0.0
+    }
+
+    return float64(hits) / float64(probes)
+}
+
+const (
+    botName    string = "gogm"
+    botVersion string = "1.0.0"
+    botAuthor  string = "sixthsurge"
+)
+
+// Role of a node in the alpha-beta tree, as expected before searching it
+// The first move of a PV node leads to another PV node and the rest to cut nodes. The moves of a cut
+// node lead to all nodes, where every move must be searched, and the moves of an all node lead to
+// cut nodes, where the first move is expected to fail high
+type nodeType uint8
+
+const (
+    pvNode nodeType = iota
+    cutNode
+    allNode
+)
+
+// Returns the type of the node reached by the move with the given index
+func (nodeType nodeType) child(index int) nodeType {
+    switch {
+    case nodeType == pvNode && index == 0:
+        return pvNode
+    case nodeType == cutNode:
+        return allNode
+    default:
+        return cutNode
+    }
+}
 
 // Depth to search all legal moves to (ply)
 const searchDepth int = 4
 
+// Depth iterative deepening stops at when searching until a time or node limit is reached (ply)
+const limitedSearchDepth int = 64
+
 // Depth to search captures only at the end of the main search (ply)
 const quiescenceSearchDepth int = 4
 
+// Margin in pawns for positional gains when deciding whether a capture in the quiescence search
+// could raise the score to alpha. This must be at least lazyEvalMargin, as the stand pat score may
+// leave out terms worth up to that much
+const deltaPruningMargin float64 = 2.0
+
+// Futility pruning: at the depths with a margin, a node whose static evaluation is more than the
+// margin below alpha only searches the moves that could win material or give check, as quiet moves
+// are unlikely to make up the difference. The margins for depth 1 (frontier nodes) and depth 2
+// (pre-frontier nodes) are indexed by depth (pawns)
+// https://www.chessprogramming.org/Futility_Pruning
+var futilityMargins = [...]float64{0.0, 2.0, 3.5}
+
+// Reverse futility pruning (static null move pruning): at depths up to reverseFutilityDepth, a node
+// whose static evaluation is more than the margin per ply of depth above beta is assumed to fail
+// high without being searched (pawns)
+// https://www.chessprogramming.org/Reverse_Futility_Pruning
+const reverseFutilityDepth int = 3
+const reverseFutilityMargin float64 = 1.2
+
+// Singular extensions: at depths from singularExtensionDepth, a hash move that scored at least beta
+// is searched a ply deeper if every other move, searched to half the depth, falls short of its score
+// by the margin per ply of depth (pawns), as the line then rests on that one move
+// https://www.chessprogramming.org/Singular_Extensions
+const singularExtensionDepth int = 6
+const singularMargin float64 = 0.02
+
+// ProbCut: at depths from probCutDepth, a capture that beats beta by probCutMargin (pawns) in a
+// search shallower by probCutReduction would very probably beat beta in the full search too, so the
+// node is assumed to fail high
+// https://www.chessprogramming.org/ProbCut
+const probCutDepth int = 5
+const probCutMargin float64 = 1.0
+const probCutReduction int = 4
+
+// Half the width of the first aspiration window around the previous iteration's score (pawns)
+// The window is doubled on the side the score falls outside of until it is wider than
+// maxAspirationWindow, after which that side is left open
+const aspirationWindow float64 = 0.25
+const maxAspirationWindow float64 = 4.0
+
+// Search the position with iterative deepening: the root moves are searched to depth 1, 2, ... up
+// to searchDepth, with the moves reordered after each iteration so that the best moves from the
+// previous iteration are searched first
+// If SetMoveTime was called, the search instead deepens until the time is up
 func (bot *BotV1) Think(board *chess.Board) chess.Move {
-    bestMove, _ := search(searchDepth, board, math.Inf(-1), math.Inf(1))
-    return bestMove
+    if bot.moveTime > 0 {
+        return bot.ThinkWithLimits(board, chess.SearchLimits{MoveTime: bot.moveTime})
+    }
+
+    bot.stopRequested.Store(false)
+    return bot.think(board)
+}
+
+// Search the position within the limits, with the time for the move allocated from the clocks by
+// chess.SearchLimits.TimeForMove. Unless a depth is given, the search deepens until it runs out of
+// time or nodes, and no new iteration is started once the time aimed for has passed
+// Limits that are not given fall back to those set by SetMaxDepth and SetMaxNodes, so a search with
+// no limits at all is the same as Think
+func (bot *BotV1) ThinkWithLimits(board *chess.Board, limits chess.SearchLimits) chess.Move {
+    // Stop requests from here on apply to this search, including the timer's
+    bot.stopRequested.Store(false)
+    return bot.thinkWithLimits(board, limits)
+}
+
+// Search the position within the limits as ThinkWithLimits does, until the context is cancelled,
+// and return the best move with the progress of the search after its last completed iteration
+// With no limits at all, the search is the same as Think's, including the time set by SetMoveTime
+func (bot *BotV1) ThinkContext(ctx context.Context, board *chess.Board, limits chess.SearchLimits) (chess.Move, chess.SearchInfo) {
+    if limits == (chess.SearchLimits{}) {
+        limits.MoveTime = bot.moveTime
+    }
+
+    bot.stopRequested.Store(false)
+
+    // Started after the stop request is cleared, so that a context cancelled before the search
+    // starts still stops it
+    stop := context.AfterFunc(ctx, bot.Stop)
+    defer stop()
+
+    move := bot.thinkWithLimits(board, limits)
+    return move, bot.lastSearchInfo
+}
+
+func (bot *BotV1) thinkWithLimits(board *chess.Board, limits chess.SearchLimits) chess.Move {
+    maxDepth, maxNodes := bot.maxDepth, bot.maxNodes
+    defer func() {
+        bot.maxDepth, bot.maxNodes, bot.softDeadline, bot.softMaxNodes = maxDepth, maxNodes, time.Time{}, 0
+    }()
+
+    optimum, maximum, hasTimeLimit := limits.TimeForMove(board.IsBlackToMove())
+
+    switch {
+    case limits.Infinite:
+        bot.maxDepth, bot.maxNodes = limitedSearchDepth, 0
+    case limits.Depth > 0:
+        bot.maxDepth = limits.Depth
+    case hasTimeLimit || limits.Nodes > 0:
+        bot.maxDepth = limitedSearchDepth
+    }
+
+    if limits.Nodes > 0 && !limits.Infinite {
+        bot.maxNodes = limits.Nodes
+    }
+
+    if hasTimeLimit && bot.deterministic {
+        // The clock would make the search depend on the speed of the machine, so the time is
+        // turned into nodes
+        bot.softMaxNodes = nodesForTime(optimum)
+        if timeNodes := nodesForTime(maximum); bot.maxNodes == 0 || timeNodes < bot.maxNodes {
+            bot.maxNodes = timeNodes
+        }
+    } else if hasTimeLimit {
+        bot.softDeadline = time.Now().Add(optimum)
+
+        timer := time.AfterFunc(maximum, bot.Stop)
+        defer timer.Stop()
+    }
+
+    return bot.think(board)
+}
+
+func (bot *BotV1) think(board *chess.Board) chess.Move {
+    start := time.Now()
+    bot.stats = SearchStats{}
+    bot.lastSearchInfo = chess.SearchInfo{}
+    bot.searchParams = bot.currentEvalParams()
+    bot.Init()
+    if bot.deterministic {
+        bot.ClearHash()
+        bot.random = rand.New(rand.NewSource(bot.randomSeed))
+    }
+    bot.caches.useParams(bot.searchParams)
+    bot.caches.resetCounters()
+    bot.searchEvaluator = bot.evaluator
+    if bot.searchEvaluator == nil {
+        bot.searchEvaluator = HandCraftedEvaluator{bot.searchParams, bot.caches}
+    }
+    bot.searchLazyEvaluator, _ = bot.searchEvaluator.(LazyEvaluator)
+    bot.searchMaterialEvaluator, _ = bot.searchEvaluator.(MaterialEvaluator)
+    bot.rootIsBlack = board.IsBlackToMove()
+    bot.history.clearKillers()
+
+    // The first iteration searches the root moves in the same order as any other node, and later
+    // iterations in the order of their scores from the previous one
+    moves := board.GetLegalMoves(false)
+    orderMoves(board, moves, chess.Move{}, false, bot.history, 0)
+
+    bot.rootMoves = bot.rootMoves[:0]
+    for _, move := range moves {
+        bot.rootMoves = append(bot.rootMoves, RootMove{Move: move, Score: math.Inf(-1)})
+    }
+    bot.publishRootMoves()
+
+    if len(bot.rootMoves) == 0 {
+        return chess.Move{}
+    }
+
+    maxDepth := searchDepth
+    if bot.maxDepth > 0 {
+        maxDepth = bot.maxDepth
+    }
+    if bot.isWeakened() {
+        maxDepth = min(maxDepth, skillDepth(bot.skillLevel))
+    }
+
+    for depth := 1; depth <= maxDepth; depth++ {
+        if !bot.searchIteration(depth, board) {
+            // The iteration was stopped part way through, so its scores cannot be compared with
+            // each other. Go back to the order from the last completed iteration
+            bot.rootMoves = append(bot.rootMoves[:0], bot.RootMoves()...)
+            break
+        }
+        bot.publishRootMoves()
+        bot.collectCacheStats()
+
+        bot.lastSearchInfo = chess.SearchInfo{
+            Depth:              depth,
+            SelectiveDepth:     bot.stats.SelectiveDepth,
+            Score:              bot.rootMoves[0].Score,
+            BestMove:           bot.rootMoves[0].Move,
+            PrincipalVariation: bot.principalVariation(board, depth),
+            Nodes:              bot.stats.Nodes + bot.stats.QuiescenceNodes,
+            Time:               time.Since(start),
+            HashFull:           bot.tt.hashFull(),
+        }
+
+        if bot.searchInfoCallback != nil {
+            bot.searchInfoCallback(bot.lastSearchInfo)
+        }
+
+        // No need to search deeper once a forced mate within the depth searched has been found, as
+        // any faster mate would have been found too
+        if ply, ok := chess.MatePly(bot.rootMoves[0].Score); ok && max(ply, -ply) <= depth {
+            break
+        }
+
+        // The next iteration would most likely take longer than all the iterations so far
+        if !bot.softDeadline.IsZero() && time.Now().After(bot.softDeadline) {
+            break
+        }
+        if bot.softMaxNodes > 0 && bot.stats.Nodes + bot.stats.QuiescenceNodes >= bot.softMaxNodes {
+            break
+        }
+    }
+
+    bot.collectCacheStats()
+
+    move := bot.rootMoves[0].Move
+    if bot.isWeakened() {
+        move = bot.weakenedMove()
+    } else if bot.randomMargin > 0 {
+        move = bot.randomMove()
+    }
+
+    if bot.commentCallback != nil {
+        bot.commentCallback(move, bot.comment(board, move))
+    }
+
+    return move
+}
+
+// Returns the best root move followed by the best moves stored in the transposition table for the
+// positions after it, up to `depth` moves. The line ends early at a position with no entry, or
+// whose entry's move is not legal because of a hash collision
+func (bot *BotV1) principalVariation(board *chess.Board, depth int) []chess.Move {
+    var line []chess.Move
+    var unmoves []chess.Unmove
+
+    move := bot.rootMoves[0].Move
+    for len(line) < depth {
+        line = append(line, move)
+        unmoves = append(unmoves, board.MakeMove(move))
+
+        entry, found := bot.tt.probe(board.Hash())
+        if !found || !board.IsLegalMove(entry.move) {
+            break
+        }
+        move = entry.move
+    }
+
+    for index := len(unmoves) - 1; index >= 0; index-- {
+        board.UnmakeMove(unmoves[index])
+    }
+
+    return line
+}
+
+// Returns the root moves of the current or most recent search, best first, with the scores and
+// node counts from the last completed iteration
+// This is safe to call from another goroutine while the bot is thinking, allowing a GUI to show
+// how the candidate moves change as the search deepens
+func (bot *BotV1) RootMoves() []RootMove {
+    bot.publishedRootMovesLock.Lock()
+    defer bot.publishedRootMovesLock.Unlock()
+
+    return append([]RootMove(nil), bot.publishedRootMoves...)
+}
+
+// Set the function called with the best move and its score after each completed iteration of
+// iterative deepening, or nil to stop reporting progress
+func (bot *BotV1) SetSearchInfoCallback(callback chess.SearchInfoCallback) {
+    bot.searchInfoCallback = callback
+}
+
+// Returns whether a stop has been requested and the current iteration can be abandoned. The first
+// iteration is always completed, as its scores are kept whether or not it was stopped
+func (bot *BotV1) stopped() bool {
+    return bot.rootDepth > 1 && bot.stopRequested.Load()
+}
+
+// Set the maximum depth of iterative deepening (ply), or 0 to use the default depth
+func (bot *BotV1) SetMaxDepth(depth int) {
+    bot.maxDepth = depth
+}
+
+// Limit the number of nodes (including quiescence nodes) searched for each move, or 0 for no limit
+// The search stops as if Stop had been called once the limit is reached, so the first iteration
+// is completed regardless. As the search is deterministic, a node limit makes the moves chosen
+// independent of the speed of the machine, unlike a time limit
+func (bot *BotV1) SetMaxNodes(nodes uint64) {
+    bot.maxNodes = nodes
+}
+
+// Ask the bot to stop thinking as soon as possible, making Think return the best move from the
+// last completed iteration. The first iteration is always completed so that Think can return a
+// sensible move
+// This is safe to call from another goroutine while the bot is thinking
+func (bot *BotV1) Stop() {
+    bot.stopRequested.Store(true)
+}
+
+// Make Think search each move for the given time, rather than to the maximum depth, or 0 to go back
+// to searching to the maximum depth. ThinkWithLimits is not affected
+func (bot *BotV1) SetMoveTime(moveTime time.Duration) {
+    bot.moveTime = moveTime
+}
+
+// Set the number of best moves the search finds exact scores for, shown by RootMoves, rather than
+// just the best move. Each move after the first costs about as much as searching another position
+func (bot *BotV1) SetMultiPV(multiPV int) {
+    bot.multiPV = multiPV
+}
+
+// Set how much worse than equal the bot considers a draw for itself (pawns), so that with a positive
+// contempt it avoids repetitions and other draws against weaker opponents and with a negative one
+// it seeks them against stronger ones. The default is 0
+func (bot *BotV1) SetContempt(contempt float64) {
+    bot.contempt = contempt
+}
+
+// Prepare for a new game, clearing the search state unless SetKeepHashBetweenGames(true) was called
+func (bot *BotV1) NewGame() {
+    if !bot.keepHashBetweenGames {
+        bot.ClearHash()
+    }
+}
+
+// Discard the search state kept from previous searches: the transposition table, the move ordering
+// history, the evaluation caches, and the root moves and statistics of the last search
+func (bot *BotV1) ClearHash() {
+    if bot.tt != nil {
+        bot.tt.clear()
+    }
+    if bot.history != nil {
+        *bot.history = moveHistory{}
+    }
+    if bot.caches != nil {
+        bot.caches.clear()
+    }
+
+    bot.stats = SearchStats{}
+    bot.rootMoves = bot.rootMoves[:0]
+    bot.publishRootMoves()
+}
+
+// Allocate the transposition table if it has not been allocated yet, rather than leaving it to the
+// first search
+func (bot *BotV1) Init() {
+    if bot.tt == nil {
+        if bot.hashSizeMB <= 0 {
+            bot.hashSizeMB = defaultHashSizeMB
+        }
+        bot.tt = newTranspositionTable(bot.hashSizeMB)
+    }
+
+    if bot.history == nil {
+        bot.history = &moveHistory{}
+    }
+
+    if bot.caches == nil {
+        bot.caches = &evalCaches{}
+    }
+}
+
+// Allocate the transposition table and clear it, which makes the operating system map in all of its
+// memory so that the first search does not pay for page faults
+// This must not be called while the bot is thinking
+func (bot *BotV1) Warmup() {
+    bot.Init()
+    bot.tt.clear()
+}
+
+// Set the size of the transposition table in megabytes, discarding its contents. The table is
+// allocated again by the next call to Init, Warmup or Think
+// This must not be called while the bot is thinking
+func (bot *BotV1) SetHashSize(sizeMB int) {
+    bot.hashSizeMB = sizeMB
+    bot.tt = nil
+}
+
+// Set whether the search checks that the move stored with each transposition table entry it finds is
+// legal in the position, counting entries that fail in LastSearchStats and ignoring them. This is
+// for debugging the hashing of positions and slows the search down considerably
+func (bot *BotV1) SetVerifyHash(verify bool) {
+    bot.verifyHash = verify
+}
+
+// Set whether NewGame keeps the search state. By default it is cleared so that games in a match do
+// not affect each other, while analysis of positions from the same game benefits from keeping it
+func (bot *BotV1) SetKeepHashBetweenGames(keep bool) {
+    bot.keepHashBetweenGames = keep
+}
+
+func (bot *BotV1) publishRootMoves() {
+    bot.publishedRootMovesLock.Lock()
+    defer bot.publishedRootMovesLock.Unlock()
+
+    bot.publishedRootMoves = append(bot.publishedRootMoves[:0], bot.rootMoves...)
+}
+
+// Search the root moves to the given depth with an aspiration window: a narrow window around the
+// score of the previous iteration, which lets more of the tree be pruned if the score does not
+// change much. If the score falls outside the window, the window is widened on that side and the
+// iteration is repeated
+// Returns false if the search was stopped
+func (bot *BotV1) searchIteration(depth int, board *chess.Board) bool {
+    alpha := math.Inf(-1)
+    beta := math.Inf(1)
+
+    // The first iteration has no previous score, and mate scores change by a ply at a time as the
+    // search deepens so are searched with the full window. With more than one PV or a random margin,
+    // the window would have to reach down to the worst move that could be chosen, so the full window
+    // is used too
+    previousScore := bot.rootMoves[0].Score
+    window := aspirationWindow
+    if !chess.IsMateScore(previousScore) && bot.pvCount() == 1 && bot.randomMargin == 0 {
+        alpha, beta = previousScore - window, previousScore + window
+    }
+
+    for {
+        completed, score := bot.searchRoot(depth, board, alpha, beta)
+        if !completed {
+            return false
+        }
+
+        window *= 2
+        openWindow := window > maxAspirationWindow
+
+        switch {
+        case score <= alpha && !math.IsInf(alpha, -1):
+            alpha = previousScore - window
+            if openWindow {
+                alpha = math.Inf(-1)
+            }
+        case score >= beta && !math.IsInf(beta, 1):
+            beta = previousScore + window
+            if openWindow {
+                beta = math.Inf(1)
+            }
+        default:
+            return true
+        }
+    }
+}
+
+// Search each root move to the given depth within the window (alpha, beta), then sort the root
+// moves best first. If a move scores at least beta, the iteration ends there with that move moved
+// to the front
+// With more than one PV, a move only needs to beat the worst of the best moves so far to be one of
+// them, so alpha is raised to that score rather than the best, and the first moves are all searched
+// as PV moves. Likewise, with a random margin, alpha is only raised to the margin below the best
+// score, so that every move that could be chosen has an exact score
+// Returns false if the search was stopped before every root move was searched, and otherwise the
+// best score found
+func (bot *BotV1) searchRoot(depth int, board *chess.Board, alpha float64, beta float64) (bool, float64) {
+    bestScore := math.Inf(-1)
+    pvCount := bot.pvCount()
+    bot.rootDepth = depth
+
+    // Best scores so far, best first, up to one for each PV
+    var bestScores []float64
+
+    for index := range bot.rootMoves {
+        rootMove := &bot.rootMoves[index]
+        nodesBefore := bot.stats.Nodes + bot.stats.QuiescenceNodes
+
+        if len(bestScores) == pvCount {
+            alpha = math.Max(alpha, math.Min(bestScores[pvCount - 1], bestScores[0] - bot.randomMargin))
+        }
+
+        bot.history.setLine(0, board, rootMove.Move)
+        unmove := board.MakeMove(rootMove.Move)
+        eval := bot.searchChild(depth - 1, 0, board, alpha, beta, pvNode, max(index - pvCount + 1, 0))
+        board.UnmakeMove(unmove)
+
+        if bot.stopped() {
+            return false, 0.0
+        }
+
+        // Scores are clamped to the window, so a score at either edge is only a bound. An open edge
+        // cannot be passed, so a score there is exact
+        rootMove.Score = eval
+        rootMove.Exact = (eval > alpha || math.IsInf(alpha, -1)) && (eval < beta || math.IsInf(beta, 1))
+        rootMove.Depth = depth
+        rootMove.Nodes = bot.stats.Nodes + bot.stats.QuiescenceNodes - nodesBefore
+
+        bestScore = math.Max(bestScore, eval)
+
+        if eval >= beta && !math.IsInf(beta, 1) {
+            failHighMove := *rootMove
+            copy(bot.rootMoves[1:index + 1], bot.rootMoves[:index])
+            bot.rootMoves[0] = failHighMove
+            return true, eval
+        }
+
+        position := sort.Search(len(bestScores), func(i int) bool { return bestScores[i] < eval })
+        if position < pvCount {
+            bestScores = append(bestScores, 0.0)
+            copy(bestScores[position + 1:], bestScores[position:])
+            bestScores[position] = eval
+            bestScores = bestScores[:min(len(bestScores), pvCount)]
+        }
+    }
+
+    // Moves with exact scores come first, and refuted moves that took more nodes to refute are
+    // likely to be better than those refuted quickly
+    sort.SliceStable(bot.rootMoves, func(i int, j int) bool {
+        a, b := &bot.rootMoves[i], &bot.rootMoves[j]
+
+        if a.Exact != b.Exact {
+            return a.Exact
+        }
+        if a.Score != b.Score {
+            return a.Score > b.Score
+        }
+        return a.Nodes > b.Nodes
+    })
+
+    return true, bestScore
+}
+
+// Copy the lookup counts of the evaluation caches into the search statistics
+func (bot *BotV1) collectCacheStats() {
+    bot.stats.PawnHashProbes, bot.stats.PawnHashHits = bot.caches.pawnProbes, bot.caches.pawnHits
+    bot.stats.EvalCacheProbes, bot.stats.EvalCacheHits = bot.caches.evalProbes, bot.caches.evalHits
+}
+
+// Returns the statistics collected during the most recent call to Think
+func (bot *BotV1) LastSearchStats() SearchStats {
+    return bot.stats
+}
+
+func (bot *BotV1) Info() chess.BotInfo {
+    return chess.BotInfo{
+        Name:    botName,
+        Version: botVersion,
+        Author:  botAuthor,
+        Options: bot.optionRegistry().BotOptions(),
+    }
+}
+
+// Register the options of the search: the size of the transposition table, clearing it, the number
+// of best moves and contempt (centipawns)
+func (bot *BotV1) registerSearchOptions(registry *chess.OptionRegistry) {
+    hashSizeMB := bot.hashSizeMB
+    if hashSizeMB <= 0 {
+        hashSizeMB = defaultHashSizeMB
+    }
+
+    registry.AddSpin("Hash", hashSizeMB, minHashSizeMB, maxHashSizeMB, bot.SetHashSize)
+    registry.AddButton("Clear Hash", bot.ClearHash)
+    registry.AddSpin("MultiPV", max(bot.multiPV, 1), 1, maxMultiPV, bot.SetMultiPV)
+    registry.AddSpin("Contempt", int(math.Round(bot.contempt * 100.0)), -maxContemptCentipawns, maxContemptCentipawns, func(contempt int) {
+        bot.SetContempt(float64(contempt) / 100.0)
+    })
 }
 
+// Largest number of best moves that can be asked for through the MultiPV option
+const maxMultiPV int = 256
+
+// Largest contempt that can be set through the Contempt option (centipawns)
+const maxContemptCentipawns int = 100
+
 // Negamax search with alpha-beta pruning
 // Alpha and beta are used to prune large portions of the game tree using the observation that
 // if we have already evaluated one option and are currently evaluating another, if any of the
 // opponent's options lead to an evaluation that is worse than that of the move already evaluated,
 // there is no need to continue exploring that option as the original option is guaranteed to be
 // better
+// Ply: distance of the node from the root
 // Alpha: the minimum evaluation that we can be assured of
 // Beta: the maximum evaluation that our opponent can be assured of
-func search(depth int, board *chess.Board, alpha float64, beta float64) (bestMove chess.Move, bestEval float64) {
+// Node type: whether the node is expected to be on the principal variation, fail high or fail low.
+// Anything that could make the search miss a tactic, such as pruning on the strength of a stored
+// score, is only done away from the principal variation
+func (bot *BotV1) search(depth int, ply int, board *chess.Board, alpha float64, beta float64, nodeType nodeType) (bestMove chess.Move, bestEval float64) {
+    // The root is never a draw, as there would be no move to return, so this is only checked below it
+    if isDrawInSearch(board) {
+        return chess.Move{}, bot.drawScore(board)
+    }
+
+    // Check extension: positions in check are searched a ply deeper, as there are few legal moves
+    // and the check often leads to something forcing. Only the side giving check loses a ply for
+    // each pair of moves, so a series of checks still ends
+    inCheck := board.IsCheck()
+    if inCheck {
+        depth++
+    }
+
     if depth <= 0 {
         // At the end of the main search, perform a quiescence search to avoid the horizon effect
-        eval := quiescenceSearch(quiescenceSearchDepth, board, alpha, beta)
+        eval := bot.quiescenceSearch(quiescenceSearchDepth, ply, board, alpha, beta)
         return chess.Move{}, eval
     }
 
+    bot.stats.Nodes++
+    bot.stats.SelectiveDepth = max(bot.stats.SelectiveDepth, ply)
+
+    if bot.maxNodes > 0 && bot.stats.Nodes + bot.stats.QuiescenceNodes >= bot.maxNodes {
+        bot.stopRequested.Store(true)
+    }
+
+    // The iteration is discarded when the search is stopped, so give up straight away
+    if bot.stopped() {
+        return chess.Move{}, 0.0
+    }
+
+    // Use the result of a previous search of the position if it was searched deep enough, and
+    // otherwise search its best move first
+    key := board.Hash()
+    entry, found := bot.tt.probe(key)
+
+    if found && bot.verifyHash && !board.IsLegalMove(entry.move) {
+        bot.stats.HashMismatches++
+        found = false
+    }
+
+    // A position that was on the principal variation of a previous search is likely to be on it
+    // again, so it is pruned no more than a PV node whatever type was expected. Its stored score is
+    // still used away from the principal variation, where the window is closed and the score only
+    // has to be on the right side of it
+    expectedType := nodeType
+    if found && entry.nodeType == pvNode {
+        nodeType = pvNode
+    }
+
+    if found && int(entry.depth) >= depth && expectedType != pvNode {
+        switch {
+        case entry.bound == exactBound:
+            return entry.move, math.Max(alpha, math.Min(beta, entry.score))
+        case entry.bound == lowerBound && entry.score >= beta:
+            return entry.move, beta
+        case entry.bound == upperBound && entry.score <= alpha:
+            return entry.move, alpha
+        }
+    }
+
     // Get legal moves from the current position
     moves := board.GetLegalMoves(false)
 
     if len(moves) == 0 {
-        // Checkmate or stalemate
-        return chess.Move{}, evaluate(board)
+        // Checkmate, scored a ply closer for each ply above so that a faster mate scores higher,
+        // or stalemate
+        score := bot.drawScore(board)
+        if inCheck {
+            score = chess.MatedInPly(0)
+        }
+
+        return chess.Move{}, math.Max(alpha, math.Min(beta, score))
+    }
+
+    futile := false
+    if bot.canPruneStatically(depth, board, alpha, beta, nodeType, inCheck) {
+        futilityMargin := 0.0
+        if depth < len(futilityMargins) {
+            futilityMargin = futilityMargins[depth]
+        }
+        reverseMargin := reverseFutilityMargin * float64(depth)
+
+        // Only which side of the margins the evaluation is on matters
+        staticEval := bot.staticEval(board, alpha - futilityMargin, beta + reverseMargin)
+
+        if depth <= reverseFutilityDepth && staticEval - reverseMargin >= beta && hasPiecesBesidesPawns(board) {
+            return chess.Move{}, beta
+        }
+
+        futile = depth < len(futilityMargins) && staticEval + futilityMargin <= alpha
+    }
+
+    // Search the best move from the transposition table first, then the likely good captures, then
+    // the quiet moves that caused cutoffs elsewhere
+    orderMoves(board, moves, entry.move, found, bot.history, ply)
+
+    if bot.canProbCut(depth, beta, nodeType, inCheck) && bot.probCut(depth, ply, board, moves, beta) {
+        return chess.Move{}, beta
+    }
+
+    // Extensions are limited to lines no longer than twice the depth of the iteration, so that they
+    // cannot compound without end
+    singular := false
+    if depth >= singularExtensionDepth && found && moves[0] == entry.move && entry.bound != upperBound &&
+        int(entry.depth) >= depth - 3 && !chess.IsMateScore(entry.score) && ply < 2 * bot.rootDepth {
+        singular = bot.isSingular(depth, ply, board, moves[1:], entry.score - singularMargin * float64(depth))
     }
 
     bestMove = moves[0]
+    scoreBound := upperBound
 
-    for _, move := range moves {
+    // Quiet moves searched without causing a cutoff, whose history scores are lowered if a later
+    // quiet move causes one
+    var failedQuietsBuffer [64]chess.Move
+    failedQuiets := failedQuietsBuffer[:0]
+
+    for index, move := range moves {
+        quiet := board.IsQuietMove(move)
+
+        bot.history.setLine(ply, board, move)
         unmove := board.MakeMove(move)
 
+        // Moves that give check are never futile, as they may lead to mate or win material
+        if futile && index > 0 && quiet && !board.IsCheck() {
+            board.UnmakeMove(unmove)
+            continue
+        }
+
+        bot.tt.prefetch(board.Hash())
+
+        childDepth := depth - 1
+        if singular && index == 0 {
+            childDepth++
+        }
+
         // Continue the search from the opponent's perspective
-        _, eval := search(depth - 1, board, -beta, -alpha)
-        eval = -eval
+        eval := bot.searchChild(childDepth, ply, board, alpha, beta, nodeType, index)
 
         board.UnmakeMove(unmove)
 
+        // The scores of a stopped search are meaningless, so must not be stored
+        if bot.stopped() {
+            return bestMove, alpha
+        }
+
         if eval >= beta {
             // "Fail hard": the evaluation of this node is greater than or equal to the maximum (worst)
             // evaluation the opponent is already assured of by another branch. This means that the
             // opponent will never play into this line so there is no point continuing to explore
             // it
-            return bestMove, beta // what move is returned here should not matter
+            if quiet {
+                bot.history.update(board, move, failedQuiets, ply, depth)
+            }
+
+            bot.tt.store(key, depth, beta, lowerBound, nodeType, move)
+            return move, beta
+        }
+
+        if quiet {
+            failedQuiets = append(failedQuiets, move)
         }
 
         if eval > alpha {
@@ -65,17 +950,191 @@ func search(depth int, board *chess.Board, alpha float64, beta float64) (bestMov
             // so far, meaning we have found a new best move
             alpha = eval
             bestMove = move
+            scoreBound = exactBound
         }
     }
 
+    bot.tt.store(key, depth, alpha, scoreBound, nodeType, bestMove)
     return bestMove, alpha
 }
 
+// Returns whether every one of the moves scores below `singularBeta` in a search to half the depth
+// The moves are searched with a zero window, so each only has to be proved worse
+func (bot *BotV1) isSingular(depth int, ply int, board *chess.Board, moves []chess.Move, singularBeta float64) bool {
+    alpha := math.Nextafter(singularBeta, math.Inf(-1))
+
+    for _, move := range moves {
+        bot.history.setLine(ply, board, move)
+        unmove := board.MakeMove(move)
+        eval := bot.searchChild(depth / 2 - 1, ply, board, alpha, singularBeta, cutNode, 1)
+        board.UnmakeMove(unmove)
+
+        if eval >= singularBeta || bot.stopped() {
+            return false
+        }
+    }
+
+    return true
+}
+
+// Returns whether the node is deep enough for ProbCut and expected to fail high, and not in check,
+// where captures are not the only moves worth searching
+func (bot *BotV1) canProbCut(depth int, beta float64, nodeType nodeType, inCheck bool) bool {
+    return depth >= probCutDepth && nodeType != pvNode && !inCheck && !chess.IsMateScore(beta)
+}
+
+// Returns whether a capture that does not lose material beats beta by probCutMargin in a shallower
+// search, which is first checked with a quiescence search to save searching captures that do not
+// come close. The moves are ordered, so the captures that do not lose material come first
+func (bot *BotV1) probCut(depth int, ply int, board *chess.Board, moves []chess.Move, beta float64) bool {
+    probCutBeta := beta + probCutMargin
+    probCutAlpha := math.Nextafter(probCutBeta, math.Inf(-1))
+
+    for _, move := range moves {
+        if board.IsQuietMove(move) {
+            continue
+        }
+        if board.SEE(move) < 0 {
+            break
+        }
+
+        bot.history.setLine(ply, board, move)
+        unmove := board.MakeMove(move)
+
+        eval := parentScore(bot.quiescenceSearch(quiescenceSearchDepth, ply + 1, board, childScore(probCutBeta), childScore(probCutAlpha)))
+        if eval >= probCutBeta {
+            eval = bot.searchChild(depth - probCutReduction - 1, ply, board, probCutAlpha, probCutBeta, cutNode, 1)
+        }
+
+        board.UnmakeMove(unmove)
+
+        if bot.stopped() {
+            return false
+        }
+        if eval >= probCutBeta {
+            return true
+        }
+    }
+
+    return false
+}
+
+// Returns whether the node can be pruned by its static evaluation (futility and reverse futility
+// pruning): it is shallow, it is not in check, where the evaluation says little, it is not on the
+// principal variation, whose score should be exact, and neither alpha nor beta is a mate score,
+// which the margins mean nothing next to
+func (bot *BotV1) canPruneStatically(depth int, board *chess.Board, alpha float64, beta float64, nodeType nodeType, inCheck bool) bool {
+    return !inCheck &&
+        nodeType != pvNode &&
+        depth <= max(reverseFutilityDepth, len(futilityMargins) - 1) &&
+        !chess.IsMateScore(alpha) &&
+        !chess.IsMateScore(beta)
+}
+
+// Returns whether the side to move has a piece other than its king and pawns. Without one, it is
+// likely to be in zugzwang, where the static evaluation overrates its position as it has to move
+func hasPiecesBesidesPawns(board *chess.Board) bool {
+    black := board.IsBlackToMove()
+    return board.PiecesBB(chess.Knight, black) | board.PiecesBB(chess.Bishop, black) | board.PiecesBB(chess.Rook, black) | board.PiecesBB(chess.Queen, black) != chess.EmptyBitboard
+}
+
+// Returns whether the search should score the position as a draw without searching it: it repeats
+// an earlier position, the fifty-move rule can be claimed, or neither side can mate
+// A single repetition is enough, as the side that allowed it could repeat the position again. This
+// also stops the search from shuffling pieces back and forth when it sees no way to make progress
+func isDrawInSearch(board *chess.Board) bool {
+    if board.RepetitionCount() >= 2 || board.IsDeadPosition() {
+        return true
+    }
+
+    // Checkmate on the move that reaches the fifty-move limit still counts
+    return board.HalfmoveClock() >= 100 && !(board.IsCheck() && len(board.GetLegalMoves(false)) == 0)
+}
+
+// Returns the score of a drawn position from the perspective of the side to move
+func (bot *BotV1) drawScore(board *chess.Board) float64 {
+    if board.IsBlackToMove() == bot.rootIsBlack {
+        return -bot.contempt
+    }
+
+    return bot.contempt
+}
+
+// Search the position reached by the move with the given index from a node of the given type with
+// the window (alpha, beta), returning the score from the perspective of the side that made the move
+// Principal variation search: once the first move has been searched, the rest are expected to be
+// worse, so they are searched with a zero window that only tells whether they are better than alpha,
+// which can be proved with far fewer nodes. A move that turns out to be better is searched again
+// with the full window to find its score
+// https://www.chessprogramming.org/Principal_Variation_Search
+func (bot *BotV1) searchChild(depth int, ply int, board *chess.Board, alpha float64, beta float64, nodeType nodeType, index int) float64 {
+    if index > 0 {
+        _, eval := bot.search(depth, ply + 1, board, childScore(math.Nextafter(alpha, beta)), childScore(alpha), nodeType.child(index))
+        eval = parentScore(eval)
+
+        if eval <= alpha || eval >= beta || bot.stopped() {
+            return eval
+        }
+    }
+
+    _, eval := bot.search(depth, ply + 1, board, childScore(beta), childScore(alpha), nodeType.child(0))
+    return parentScore(eval)
+}
+
+// Scores are from the perspective of the side to move in the node returning them, with mate scores
+// counting the ply to mate from that node, so that they can be stored in the transposition table
+// and used wherever the position is reached again. The root sees a mate in n ply from it as
+// chess.MateInPly(n)
+// Returns the score from the perspective of the parent node for a score returned by a child node,
+// with a mate score one ply further away
+func parentScore(score float64) float64 {
+    score = -score
+    if chess.IsMateScore(score) {
+        score -= math.Copysign(1, score)
+    }
+
+    return score
+}
+
+// Returns the bound to pass to a child node for the score `score` of the parent node, the inverse
+// of parentScore
+func childScore(score float64) float64 {
+    if chess.IsMateScore(score) {
+        score += math.Copysign(1, score)
+    }
+
+    return -score
+}
+
 // A second search performed at the end of the main search intended to only evaluate "quiet"
 // positions with no tension between pieces. This is needed to avoid the horizon effect
-func quiescenceSearch(depth int, board *chess.Board, alpha float64, beta float64) float64 {
+// Captures are searched, and at the first ply quiet moves that give check too, so that mating
+// attacks just past the horizon are seen. Captures that lose material are skipped, as are captures
+// that cannot bring the score up to alpha however much positional compensation they win (delta
+// pruning). In check, the side to move cannot stand pat, so every move is searched instead
+func (bot *BotV1) quiescenceSearch(depth int, ply int, board *chess.Board, alpha float64, beta float64) float64 {
+    bot.stats.QuiescenceNodes++
+    bot.stats.SelectiveDepth = max(bot.stats.SelectiveDepth, ply)
+
+    // Captures cannot repeat a position, but can leave too little material to mate
+    if board.IsDeadPosition() {
+        return bot.drawScore(board)
+    }
+
+    if board.IsCheck() {
+        if depth > 0 {
+            return bot.quiescenceEvasions(depth, ply, board, alpha, beta)
+        }
+
+        // At the last ply, only check for checkmate, as the evaluator is not expected to
+        if len(board.GetLegalMoves(false)) == 0 {
+            return math.Max(alpha, math.Min(beta, chess.MatedInPly(0)))
+        }
+    }
+
     // Current evaluation used to establish a lower bound for the score
-    standPat := evaluate(board)
+    // Only whether it falls outside the window matters when it does, so it can be evaluated lazily
+    standPat := bot.staticEval(board, alpha, beta)
 
     // If the evaluation of the current position is better than the maximum (worst) evaluation our
     // opponent is already assured of, we can fail hard here as the opponent will never play into this line
@@ -89,19 +1148,30 @@ func quiescenceSearch(depth int, board *chess.Board, alpha float64, beta float64
         alpha = standPat
     }
 
+    if depth <= 0 {
+        return alpha
+    }
+
     // Get all legal capturing moves
     legalCaptures := board.GetLegalMoves(true)
-
-    if len(legalCaptures) == 0 || depth <= 0 {
-        // Checkmate or stalemate
-        return evaluate(board)
-    }
+    orderMoves(board, legalCaptures, chess.Move{}, false, nil, ply)
 
     for _, move := range legalCaptures {
+        if !move.IsPromotion {
+            if standPat + bot.captureValue(board, move) + deltaPruningMargin <= alpha {
+                continue
+            }
+
+            // Moves are ordered with losing captures last, so once one is found the rest lose too
+            if board.SEE(move) < 0 {
+                break
+            }
+        }
+
         unmove := board.MakeMove(move)
 
         // Continue the search from the opponent's perspective
-        eval := -quiescenceSearch(depth - 1, board, -beta, -alpha)
+        eval := parentScore(bot.quiescenceSearch(depth - 1, ply + 1, board, childScore(beta), childScore(alpha)))
 
         board.UnmakeMove(unmove)
 
@@ -116,6 +1186,57 @@ func quiescenceSearch(depth int, board *chess.Board, alpha float64, beta float64
         }
     }
 
+    if depth < quiescenceSearchDepth {
+        return alpha
+    }
+
+    // Only the quiet moves that give check are made
+    for _, move := range board.GetLegalMoves(false) {
+        if !board.IsQuietMove(move) || !board.GivesCheck(move) {
+            continue
+        }
+
+        unmove := board.MakeMove(move)
+        eval := parentScore(bot.quiescenceSearch(depth - 1, ply + 1, board, childScore(beta), childScore(alpha)))
+        board.UnmakeMove(unmove)
+
+        if eval >= beta {
+            return beta
+        }
+
+        if eval > alpha {
+            alpha = eval
+        }
+    }
+
+    return alpha
+}
+
+// Quiescence search of a position in check, where every legal move is searched
+func (bot *BotV1) quiescenceEvasions(depth int, ply int, board *chess.Board, alpha float64, beta float64) float64 {
+    moves := board.GetLegalMoves(false)
+
+    if len(moves) == 0 {
+        // Checkmate
+        return math.Max(alpha, math.Min(beta, chess.MatedInPly(0)))
+    }
+
+    orderMoves(board, moves, chess.Move{}, false, nil, ply)
+
+    for _, move := range moves {
+        unmove := board.MakeMove(move)
+        eval := parentScore(bot.quiescenceSearch(depth - 1, ply + 1, board, childScore(beta), childScore(alpha)))
+        board.UnmakeMove(unmove)
+
+        if eval >= beta {
+            return beta
+        }
+
+        if eval > alpha {
+            alpha = eval
+        }
+    }
+
     return alpha
 }
 
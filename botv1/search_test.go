@@ -0,0 +1,107 @@
+package botv1_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/botV1"
+	"gogm/chess"
+	"testing"
+	"time"
+)
+
+// The result of a search that must be the same every time the search is repeated
+type searchResult struct {
+	move      chess.Move
+	stats     botv1.SearchStats
+	rootMoves []botv1.RootMove
+	info      chess.SearchInfo
+}
+
+func deterministicSearch(t *testing.T, bot *botv1.BotV1, fen string, limits chess.SearchLimits) searchResult {
+	board, err := chess.LoadFen(fen)
+	assert.Nil(t, err)
+
+	var info chess.SearchInfo
+	bot.SetSearchInfoCallback(func(latest chess.SearchInfo) {
+		info = latest
+	})
+
+	move := bot.ThinkWithLimits(board, limits)
+
+	// Only the time taken may differ
+	info.Time = 0
+
+	return searchResult{move, bot.LastSearchStats(), bot.RootMoves(), info}
+}
+
+func TestDeterministicSearch(t *testing.T) {
+	positions := []string{
+		chess.StartingPositionFen,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	}
+
+	limits := []struct {
+		description string
+		limits      chess.SearchLimits
+	}{
+		{"depth", chess.SearchLimits{Depth: 4}},
+		{"move time", chess.SearchLimits{MoveTime: 100 * time.Millisecond}},
+		{"clock", chess.SearchLimits{WhiteTime: 2 * time.Second, BlackTime: 2 * time.Second, WhiteIncrement: 100 * time.Millisecond}},
+	}
+
+	for _, fen := range positions {
+		for _, test := range limits {
+			description := test.description + " " + fen
+
+			first, err := botv1.New(botv1.Options{Deterministic: true})
+			assert.Nil(t, err)
+			expected := deterministicSearch(t, first, fen, test.limits)
+			assert.NotZero(t, expected.stats.Nodes, description)
+
+			// A fresh bot visits the same nodes
+			second, err := botv1.New(botv1.Options{Deterministic: true})
+			assert.Nil(t, err)
+			assert.Equal(t, expected, deterministicSearch(t, second, fen, test.limits), description)
+
+			// So does the same bot searching again, despite the state left by the first search
+			assert.Equal(t, expected, deterministicSearch(t, first, fen, test.limits), description)
+		}
+	}
+}
+
+func TestDeterministicSearchWithRandomMargin(t *testing.T) {
+	options := botv1.Options{Deterministic: true, RandomMargin: 0.5, RandomSeed: 42}
+	limits := chess.SearchLimits{Depth: 4}
+
+	first, err := botv1.New(options)
+	assert.Nil(t, err)
+	expected := deterministicSearch(t, first, chess.StartingPositionFen, limits)
+
+	// The random choice is seeded afresh for every search
+	for i := 0; i < 3; i++ {
+		bot, err := botv1.New(options)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, deterministicSearch(t, bot, chess.StartingPositionFen, limits))
+		assert.Equal(t, expected, deterministicSearch(t, first, chess.StartingPositionFen, limits))
+	}
+}
+
+func TestNodeLimitStillCompletesFirstIteration(t *testing.T) {
+	// Qd7+ loses the queen to Rxd7, which a search stopped part way through the first iteration
+	// would not have seen
+	board, err := chess.LoadFen("r2rk3/8/8/8/8/8/8/3QK3 w - - 0 1")
+	assert.Nil(t, err)
+
+	deep, err := botv1.New(botv1.Options{})
+	assert.Nil(t, err)
+	expected := deep.ThinkWithLimits(board, chess.SearchLimits{Depth: 6})
+
+	for _, nodes := range []uint64{1, 5, 20} {
+		bot, err := botv1.New(botv1.Options{})
+		assert.Nil(t, err)
+
+		move := bot.ThinkWithLimits(board, chess.SearchLimits{Nodes: nodes})
+		assert.Equal(t, expected, move, "nodes %d", nodes)
+		assert.NotEqual(t, chess.Move{Source: chess.D1, Destination: chess.D7}, move, "nodes %d", nodes)
+	}
+}
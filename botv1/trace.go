@@ -0,0 +1,97 @@
+package botv1
+
+import (
+	"gogm/chess"
+)
+
+// Break the evaluation of the position down into its terms, for finding out why the evaluation
+// likes or dislikes a position
+func (evaluator HandCraftedEvaluator) EvaluateTrace(board *chess.Board) chess.EvalTrace {
+    params := evaluator.Params
+    endgameWeight := endgameWeight(board)
+
+    trace := chess.EvalTrace {
+        Terms: []chess.EvalTerm {
+            {Name: "material"},
+            {Name: "piece-square tables"},
+            {Name: "bishop pair"},
+            {Name: "rook files"},
+            {Name: "rook seventh rank"},
+            {Name: "knight outposts"},
+            {Name: "doubled pawns"},
+            {Name: "isolated pawns"},
+            {Name: "passed pawns"},
+            {Name: "mop-up"},
+            {Name: "castling rights"},
+            {Name: "mobility"},
+        },
+    }
+
+    pieceKinds := []struct {
+        kind   chess.PieceKind
+        value  float64
+        tables *PieceSquareTables
+    } {
+        {chess.Pawn, params.PawnValue, &params.PawnTables},
+        {chess.Knight, params.KnightValue, &params.KnightTables},
+        {chess.Bishop, params.BishopValue, &params.BishopTables},
+        {chess.Rook, params.RookValue, &params.RookTables},
+        {chess.Queen, params.QueenValue, &params.QueenTables},
+        {chess.King, 0.0, &params.KingTables},
+    }
+
+    for _, black := range []bool{false, true} {
+        var material, pieceSquareTables float64
+        for _, pieceKind := range pieceKinds {
+            pieces := board.PiecesBB(pieceKind.kind, black)
+            material += pieceKind.value * float64(pieces.PopCount())
+
+            for v := pieces; v != chess.EmptyBitboard; v = v.ClearLSB() {
+                pieceSquareTables += evaluatePieceSquareTables(chess.RelativeSquare(v.LSB(), black), endgameWeight, pieceKind.tables)
+            }
+        }
+
+        rookFiles, rookSeventhRank := evaluateRooks(board, black, params)
+        doubledPawns, isolatedPawns, passedPawns := evaluatePawns(board, black, params)
+
+        scores := []float64 {
+            material,
+            pieceSquareTables,
+            evaluateBishopPair(board, black, params),
+            rookFiles,
+            rookSeventhRank,
+            evaluateKnightOutposts(board, black, params),
+            doubledPawns,
+            isolatedPawns,
+            scalePassedPawns(passedPawns, endgameWeight),
+            evaluateMopUp(board, black),
+            evaluateCastlingRights(board, black, params),
+            float64(board.MobilityCount(black)) * params.MobilityBonus,
+        }
+
+        for index, score := range scores {
+            if black {
+                trace.Terms[index].Black = score
+            } else {
+                trace.Terms[index].White = score
+            }
+        }
+    }
+
+    trace.Total = evaluate(board, params, nil)
+    if board.IsBlackToMove() {
+        trace.Total = -trace.Total
+    }
+
+    return trace
+}
+
+// Break the bot's evaluation of the position down into its terms, using the evaluator set by
+// SetEvaluator if it can explain itself, or otherwise the built-in evaluation
+func (bot *BotV1) EvaluateTrace(board *chess.Board) chess.EvalTrace {
+    if tracer, ok := bot.evaluator.(interface { EvaluateTrace(*chess.Board) chess.EvalTrace }); ok {
+        return tracer.EvaluateTrace(board)
+    }
+
+    return HandCraftedEvaluator{Params: bot.currentEvalParams()}.EvaluateTrace(board)
+}
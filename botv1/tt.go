@@ -0,0 +1,381 @@
+package botv1
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gogm/chess"
+	"hash/fnv"
+	"io"
+	"os"
+	"unsafe"
+)
+
+// Default size of the transposition table in megabytes, and the range of sizes offered by the Hash
+// option
+const (
+    defaultHashSizeMB int = 16
+    minHashSizeMB     int = 1
+    maxHashSizeMB     int = 4096
+)
+
+// What the score stored in a transposition table entry says about the true score of the position
+type bound uint8
+
+const (
+    // The score is exact
+    exactBound bound = iota
+
+    // The search failed high, so the true score is at least the stored score
+    lowerBound
+
+    // No move raised alpha, so the true score is at most the stored score
+    upperBound
+)
+
+// The result of searching a position, stored so that it need not be searched again when it is
+// reached by a different move order
+type ttEntry struct {
+    key   uint64
+    score float64
+    move  chess.Move
+    depth int8
+    bound bound
+
+    // Type of the node the position was searched as. Positions that have been on the principal
+    // variation keep the type pvNode, so they are not pruned as aggressively when reached again
+    nodeType nodeType
+
+    used bool
+}
+
+// Hash table of search results indexed by the Zobrist hash of the position
+// Entries are always replaced, which keeps the results of the most recent searches
+type transpositionTable struct {
+    entries []ttEntry
+    mask    uint64
+}
+
+// Returns a table using at most `sizeMB` megabytes, rounded down to a power of two number of entries
+func newTranspositionTable(sizeMB int) *transpositionTable {
+    count := uint64(1)
+    for (count * 2) * uint64(unsafe.Sizeof(ttEntry{})) <= uint64(sizeMB) << 20 {
+        count *= 2
+    }
+
+    return &transpositionTable{ entries: make([]ttEntry, count), mask: count - 1 }
+}
+
+// Returns the entry for the position with the given hash, if there is one
+func (tt *transpositionTable) probe(key uint64) (ttEntry, bool) {
+    entry := tt.entries[key & tt.mask]
+    return entry, entry.used && entry.key == key
+}
+
+func (tt *transpositionTable) store(key uint64, depth int, score float64, bound bound, nodeType nodeType, move chess.Move) {
+    tt.entries[key & tt.mask] = ttEntry{
+        key:      key,
+        score:    score,
+        move:     move,
+        depth:    int8(depth),
+        bound:    bound,
+        nodeType: nodeType,
+        used:     true,
+    }
+}
+
+// Start loading the entry for the position with the given hash into the CPU cache, so that it is
+// ready by the time the position is probed
+func (tt *transpositionTable) prefetch(key uint64) {
+    prefetch(uintptr(unsafe.Pointer(&tt.entries[key & tt.mask])))
+}
+
+// Returns the fraction of the table in use in permille, estimated from the first thousand entries as
+// the UCI hashfull field does
+func (tt *transpositionTable) hashFull() (used int) {
+    sample := tt.entries[:min(1000, len(tt.entries))]
+    for index := range sample {
+        if sample[index].used {
+            used++
+        }
+    }
+
+    return used * 1000 / len(sample)
+}
+
+func (tt *transpositionTable) clear() {
+    clear(tt.entries)
+}
+
+// Identifies a file of transposition table entries written by SaveHash, followed by a version that
+// is increased whenever the layout of the file changes
+// Version 2 adds the pawn hash table after the transposition table entries
+const ttFileMagic string = "gogm-tt"
+const ttFileVersion uint32 = 2
+
+// Layout of each transposition table entry in a file written by SaveHash, in little-endian order
+type ttFileEntry struct {
+    Key           uint64
+    Score         float64
+    Source        uint8
+    Destination   uint8
+    PromotedPiece uint8
+    IsPromotion   bool
+    Depth         int8
+    Bound         uint8
+    NodeType      uint8
+}
+
+// Layout of each pawn hash table entry in a file written by SaveHash, in little-endian order
+type pawnHashFileEntry struct {
+    Key       uint64
+    Structure float64
+    Passed    float64
+}
+
+// Returns an error if a field of the entry holds a value no entry written by save could have, so that
+// a corrupt file cannot put entries into the table that the search does not expect
+func (entry *ttFileEntry) validate() error {
+    switch {
+    case entry.Source > 63 || entry.Destination > 63:
+        return errors.New(fmt.Sprintf("move from square %v to square %v", entry.Source, entry.Destination))
+    case entry.PromotedPiece > uint8(chess.Pawn):
+        return errors.New(fmt.Sprintf("promoted piece %v", entry.PromotedPiece))
+    case entry.Bound > uint8(upperBound):
+        return errors.New(fmt.Sprintf("bound %v", entry.Bound))
+    case entry.NodeType > uint8(allNode):
+        return errors.New(fmt.Sprintf("node type %v", entry.NodeType))
+    }
+
+    return nil
+}
+
+// Write the header of a hash file, then the entries of the transposition table and of the pawn hash
+// table
+func saveHash(writer io.Writer, tt *transpositionTable, caches *evalCaches) error {
+    buffered := bufio.NewWriter(writer)
+    buffered.WriteString(ttFileMagic)
+    binary.Write(buffered, binary.LittleEndian, ttFileVersion)
+
+    if err := tt.save(buffered); err != nil {
+        return err
+    }
+    if err := caches.savePawns(buffered); err != nil {
+        return err
+    }
+
+    return buffered.Flush()
+}
+
+// Read a hash file written by saveHash into the tables. Pawn hash entries are only kept if they were
+// evaluated with the same parameters as `params`
+func loadHash(reader io.Reader, tt *transpositionTable, caches *evalCaches, params *EvalParams) error {
+    buffered := bufio.NewReader(reader)
+
+    magic := make([]byte, len(ttFileMagic))
+    if _, err := io.ReadFull(buffered, magic); err != nil || string(magic) != ttFileMagic {
+        return errors.New("not a transposition table file")
+    }
+
+    var version uint32
+    if err := binary.Read(buffered, binary.LittleEndian, &version); err != nil {
+        return err
+    }
+    if version != ttFileVersion {
+        return errors.New(fmt.Sprintf("unsupported transposition table file version %v (expected %v)", version, ttFileVersion))
+    }
+
+    if err := tt.load(buffered); err != nil {
+        return err
+    }
+
+    return caches.loadPawns(buffered, params)
+}
+
+// Write the entries in use to the writer, preceded by their number
+func (tt *transpositionTable) save(writer io.Writer) error {
+    count := uint64(0)
+    for index := range tt.entries {
+        if tt.entries[index].used {
+            count++
+        }
+    }
+
+    if err := binary.Write(writer, binary.LittleEndian, count); err != nil {
+        return err
+    }
+
+    for index := range tt.entries {
+        entry := &tt.entries[index]
+        if !entry.used {
+            continue
+        }
+
+        err := binary.Write(writer, binary.LittleEndian, ttFileEntry{
+            Key:           entry.key,
+            Score:         entry.score,
+            Source:        uint8(entry.move.Source),
+            Destination:   uint8(entry.move.Destination),
+            PromotedPiece: uint8(entry.move.PromotedPiece),
+            IsPromotion:   entry.move.IsPromotion,
+            Depth:         entry.depth,
+            Bound:         uint8(entry.bound),
+            NodeType:      uint8(entry.nodeType),
+        })
+        if err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// Read entries written by save into the table. The table need not be the same size as the one
+// saved; entries that map to the same slot replace each other as they would in a search
+func (tt *transpositionTable) load(reader io.Reader) error {
+    var count uint64
+    if err := binary.Read(reader, binary.LittleEndian, &count); err != nil {
+        return err
+    }
+
+    for i := uint64(0); i < count; i++ {
+        var entry ttFileEntry
+        if err := binary.Read(reader, binary.LittleEndian, &entry); err != nil {
+            return errors.New(fmt.Sprintf("transposition table file ends after %v of %v entries: %v", i, count, err))
+        }
+
+        if err := entry.validate(); err != nil {
+            return errors.New(fmt.Sprintf("entry %v of %v is invalid: %v", i, count, err))
+        }
+
+        move := chess.Move{
+            Source:        chess.Square(entry.Source),
+            Destination:   chess.Square(entry.Destination),
+            PromotedPiece: chess.PieceKind(entry.PromotedPiece),
+            IsPromotion:   entry.IsPromotion,
+        }
+        tt.store(entry.Key, int(entry.Depth), entry.Score, bound(entry.Bound), nodeType(entry.NodeType), move)
+    }
+
+    return nil
+}
+
+// Write the fingerprint of the parameters the pawn structure scores were evaluated with, followed by
+// the number of pawn hash entries in use and the entries
+func (caches *evalCaches) savePawns(writer io.Writer) error {
+    count := uint64(0)
+    for index := range caches.pawns {
+        if caches.pawns[index].used {
+            count++
+        }
+    }
+
+    binary.Write(writer, binary.LittleEndian, evalParamsFingerprint(caches.params))
+    if err := binary.Write(writer, binary.LittleEndian, count); err != nil {
+        return err
+    }
+
+    for index := range caches.pawns {
+        entry := &caches.pawns[index]
+        if !entry.used {
+            continue
+        }
+
+        err := binary.Write(writer, binary.LittleEndian, pawnHashFileEntry{
+            Key:       entry.key,
+            Structure: entry.structure,
+            Passed:    entry.passed,
+        })
+        if err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// Read pawn hash entries written by savePawns. The entries are skipped if they were evaluated with
+// parameters other than `params`, as their scores would not match the evaluation
+func (caches *evalCaches) loadPawns(reader io.Reader, params *EvalParams) error {
+    var fingerprint, count uint64
+    if err := binary.Read(reader, binary.LittleEndian, &fingerprint); err != nil {
+        return err
+    }
+    if err := binary.Read(reader, binary.LittleEndian, &count); err != nil {
+        return err
+    }
+
+    matches := fingerprint == evalParamsFingerprint(params)
+    if matches {
+        caches.useParams(params)
+    }
+
+    for i := uint64(0); i < count; i++ {
+        var entry pawnHashFileEntry
+        if err := binary.Read(reader, binary.LittleEndian, &entry); err != nil {
+            return errors.New(fmt.Sprintf("transposition table file ends after %v of %v pawn hash entries: %v", i, count, err))
+        }
+
+        if matches {
+            caches.pawns[entry.Key & uint64(pawnHashEntries - 1)] = pawnHashEntry{
+                key:       entry.Key,
+                structure: entry.Structure,
+                passed:    entry.Passed,
+                used:      true,
+            }
+        }
+    }
+
+    return nil
+}
+
+// Returns a hash of the evaluation parameters, identifying those the scores in a hash file were
+// evaluated with
+func evalParamsFingerprint(params *EvalParams) uint64 {
+    encoded, _ := json.Marshal(params)
+
+    hash := fnv.New64a()
+    hash.Write(encoded)
+    return hash.Sum64()
+}
+
+// Write the transposition table and the pawn hash table to a file, so that the results of a long
+// analysis can be loaded with LoadHash and built on later. Only the entries in use are written
+// This must not be called while the bot is thinking
+func (bot *BotV1) SaveHash(path string) error {
+    bot.Init()
+
+    file, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+
+    if err := saveHash(file, bot.tt, bot.caches); err != nil {
+        file.Close()
+        return err
+    }
+
+    return file.Close()
+}
+
+// Add the entries of a hash file written by SaveHash to the transposition table and the pawn hash
+// table, replacing those they collide with. The file may have been written by a bot with a different
+// hash size. Pawn hash entries evaluated with other evaluation parameters are left out
+// This must not be called while the bot is thinking
+func (bot *BotV1) LoadHash(path string) error {
+    bot.Init()
+
+    file, err := os.Open(path)
+    if err != nil {
+        return err
+    }
+    defer file.Close()
+
+    if err := loadHash(file, bot.tt, bot.caches, bot.currentEvalParams()); err != nil {
+        return errors.New(fmt.Sprintf("failed to load transposition table %v: %v", path, err))
+    }
+
+    return nil
+}
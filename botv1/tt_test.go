@@ -0,0 +1,101 @@
+package botv1
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func TestTranspositionTableSaveLoad(t *testing.T) {
+	assert := assert.New(t)
+
+	saved := newTranspositionTable(1)
+	promotion := chess.Move{Source: chess.B7, Destination: chess.A8, PromotedPiece: chess.Knight, IsPromotion: true}
+	saved.store(0x1234_5678_9abc_def0, 7, 1.25, lowerBound, cutNode, chess.Move{Source: chess.E2, Destination: chess.E4})
+	saved.store(0x0fed_cba9_8765_4321, 3, -0.5, upperBound, pvNode, promotion)
+
+	savedCaches := &evalCaches{}
+	savedCaches.useParams(defaultEvalParams)
+	board, _ := chess.LoadFen("4k3/pp3p2/8/3P4/8/8/P4PP1/4K3 w - - 0 1")
+	structure, passed := savedCaches.pawnStructure(board, defaultEvalParams)
+
+	var file bytes.Buffer
+	assert.Nil(saveHash(&file, saved, savedCaches))
+
+	// The entries are found in a table of a different size
+	loaded := newTranspositionTable(4)
+	loadedCaches := &evalCaches{}
+	assert.NotEqual(len(saved.entries), len(loaded.entries))
+	assert.Nil(loadHash(bytes.NewReader(file.Bytes()), loaded, loadedCaches, defaultEvalParams))
+
+	entry, found := loaded.probe(0x1234_5678_9abc_def0)
+	assert.True(found)
+	assert.Equal(int8(7), entry.depth)
+	assert.Equal(1.25, entry.score)
+	assert.Equal(lowerBound, entry.bound)
+	assert.Equal(cutNode, entry.nodeType)
+	assert.Equal(chess.Move{Source: chess.E2, Destination: chess.E4}, entry.move)
+
+	entry, found = loaded.probe(0x0fed_cba9_8765_4321)
+	assert.True(found)
+	assert.Equal(int8(3), entry.depth)
+	assert.Equal(-0.5, entry.score)
+	assert.Equal(upperBound, entry.bound)
+	assert.Equal(pvNode, entry.nodeType)
+	assert.Equal(promotion, entry.move)
+
+	_, found = loaded.probe(0x1111_1111_1111_1111)
+	assert.False(found)
+
+	// The pawn structure is not evaluated again
+	loadedCaches.resetCounters()
+	loadedStructure, loadedPassed := loadedCaches.pawnStructure(board, defaultEvalParams)
+	assert.Equal(uint64(1), loadedCaches.pawnHits)
+	assert.Equal(structure, loadedStructure)
+	assert.Equal(passed, loadedPassed)
+
+	// Pawn structure evaluated with other parameters is left out
+	otherParams := DefaultEvalParams()
+	otherParams.PawnValue += 0.1
+	otherCaches := &evalCaches{}
+	assert.Nil(loadHash(bytes.NewReader(file.Bytes()), newTranspositionTable(1), otherCaches, otherParams))
+	otherCaches.pawnStructure(board, otherParams)
+	assert.Equal(uint64(0), otherCaches.pawnHits)
+
+	// Files that are truncated or not transposition table files are rejected
+	truncated := bytes.NewReader(file.Bytes()[:file.Len() - 1])
+	assert.NotNil(loadHash(truncated, newTranspositionTable(1), &evalCaches{}, defaultEvalParams))
+	assert.NotNil(loadHash(bytes.NewReader([]byte("not a table")), newTranspositionTable(1), &evalCaches{}, defaultEvalParams))
+}
+
+func TestTranspositionTableLoadRejectsInvalidEntries(t *testing.T) {
+	valid := ttFileEntry{Key: 1, Source: uint8(chess.E2), Destination: uint8(chess.E4), Bound: uint8(exactBound), NodeType: uint8(pvNode)}
+
+	for _, test := range []struct {
+		description string
+		modify      func(entry *ttFileEntry)
+	}{
+		{"source off the board", func(entry *ttFileEntry) { entry.Source = 64 }},
+		{"destination off the board", func(entry *ttFileEntry) { entry.Destination = 200 }},
+		{"unknown promoted piece", func(entry *ttFileEntry) { entry.PromotedPiece = 6 }},
+		{"unknown bound", func(entry *ttFileEntry) { entry.Bound = uint8(upperBound) + 1 }},
+		{"unknown node type", func(entry *ttFileEntry) { entry.NodeType = uint8(allNode) + 1 }},
+	} {
+		entry := valid
+		test.modify(&entry)
+
+		var file bytes.Buffer
+		file.WriteString(ttFileMagic)
+		binary.Write(&file, binary.LittleEndian, ttFileVersion)
+		binary.Write(&file, binary.LittleEndian, uint64(1))
+		binary.Write(&file, binary.LittleEndian, entry)
+
+		tt := newTranspositionTable(1)
+		assert.NotNil(t, loadHash(&file, tt, &evalCaches{}, defaultEvalParams), test.description)
+
+		_, found := tt.probe(entry.Key)
+		assert.False(t, found, test.description)
+	}
+}
@@ -0,0 +1,133 @@
+package chess
+
+// The rule on which the members of the antichess family differ: how a game ends when the side to
+// move has no legal moves, whether because it has lost all its pieces or because they are blocked
+type AntichessStalemate uint8
+
+const (
+	// The side with no legal moves wins, as in giveaway and in antichess on Lichess
+	StalematedSideWins AntichessStalemate = iota
+
+	// The side with fewer pieces wins, and the game is drawn if both have as many, as in suicide
+	// chess on FICS
+	FewerPiecesWins
+)
+
+// Rules of the antichess family, where each side tries to lose all its pieces. Capturing is
+// compulsory when possible, the king is an ordinary piece that can be captured and promoted to,
+// and there is no castling
+type AntichessRules struct {
+	Stalemate AntichessStalemate
+}
+
+// Antichess as played on Lichess and in giveaway, where the side with no legal moves wins
+var Giveaway = AntichessRules{Stalemate: StalematedSideWins}
+
+// Antichess as played on FICS, where the side with fewer pieces wins when the side to move has no
+// legal moves
+var Suicide = AntichessRules{Stalemate: FewerPiecesWins}
+
+const antichessStartingFen = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w - - 0 1"
+
+func (rules AntichessRules) Name() string {
+	if rules.Stalemate == FewerPiecesWins {
+		return "suicide"
+	}
+
+	return "giveaway"
+}
+
+func (AntichessRules) StartingFen() string {
+	return antichessStartingFen
+}
+
+// The king is an ordinary piece, so it is never in check
+func (AntichessRules) HasCheck() bool {
+	return false
+}
+
+// Captures are generated first, and the other moves only if there are none
+func (AntichessRules) AppendLegalMoves(board *Board, moves []Move, capturesOnly bool) []Move {
+	start := len(moves)
+
+	enemyPiecesBitboard := board.sideBitboards[sideIndex(!board.blackToMove)]
+	moves = board.appendAntichessMoves(moves, enemyPiecesBitboard)
+
+	if board.hasEnPassantTarget {
+		capturingPawns := pawnAttackSet(board.enPassantTarget, !board.blackToMove) & board.pieceBitboards[sideIndex(board.blackToMove)][Pawn]
+
+		for v := capturingPawns; v != EmptyBitboard; v = v.ClearLSB() {
+			moves = append(moves, Move{Source: v.LSB(), Destination: board.enPassantTarget})
+		}
+	}
+
+	if capturesOnly || len(moves) > start {
+		return moves
+	}
+
+	return board.appendAntichessMoves(moves, ^board.GetOccupiedBitboard())
+}
+
+// Appends the moves of the side to move to squares in `targetMask`, other than en passant captures.
+// Pawns promote to a king as well as the usual pieces
+func (board *Board) appendAntichessMoves(moves []Move, targetMask Bitboard) []Move {
+	friendlySide := sideIndex(board.blackToMove)
+	enemyPiecesBitboard := board.sideBitboards[sideIndex(!board.blackToMove)]
+	allPiecesBitboard := board.GetOccupiedBitboard()
+
+	promotionRank := Rank8
+	if board.blackToMove {
+		promotionRank = Rank1
+	}
+
+	for kind := King; kind <= Pawn; kind++ {
+		for v := board.pieceBitboards[friendlySide][kind]; v != EmptyBitboard; v = v.ClearLSB() {
+			sq := v.LSB()
+
+			if kind != Pawn {
+				moves = appendMovesFromSquare(moves, sq, board.getCachedAttackSet(sq) & targetMask)
+				continue
+			}
+
+			moveSet := (pawnPushSet(sq, board.blackToMove, allPiecesBitboard) | pawnAttackSet(sq, board.blackToMove) & enemyPiecesBitboard) & targetMask
+			moves = appendPawnMovesFromSquare(moves, sq, moveSet, promotionRank)
+
+			for promotions := moveSet & RankBitboard(promotionRank); promotions != EmptyBitboard; promotions = promotions.ClearLSB() {
+				moves = append(moves, Move{Source: sq, Destination: promotions.LSB(), IsPromotion: true, PromotedPiece: King})
+			}
+		}
+	}
+
+	return moves
+}
+
+// The game ends when the side to move has no legal moves, with the winner decided by the rules'
+// Stalemate. Otherwise, it is drawn automatically by the 75-move rule or fivefold repetition
+func (rules AntichessRules) Outcome(board *Board) Outcome {
+	if len(rules.AppendLegalMoves(board, make([]Move, 0, 256), false)) > 0 {
+		return board.AutomaticDraw()
+	}
+
+	friendlyPieces := board.sideBitboards[sideIndex(board.blackToMove)].PopCount()
+	enemyPieces := board.sideBitboards[sideIndex(!board.blackToMove)].PopCount()
+
+	termination := Stalemate
+	if friendlyPieces == 0 {
+		termination = VariantEnd
+	}
+
+	sideToMoveWins := true
+	if rules.Stalemate == FewerPiecesWins {
+		if friendlyPieces == enemyPieces {
+			return Outcome{Result: Draw, Termination: termination}
+		}
+
+		sideToMoveWins = friendlyPieces < enemyPieces
+	}
+
+	if sideToMoveWins == board.blackToMove {
+		return Outcome{Result: BlackWins, Termination: termination}
+	} else {
+		return Outcome{Result: WhiteWins, Termination: termination}
+	}
+}
@@ -0,0 +1,116 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+// Giveaway and suicide only differ in who wins when the side to move has no legal moves, so their
+// perft results are the same
+var antichessPerftPositions = []struct {
+	fen   string
+	nodes []uint64
+}{
+	{chess.Giveaway.StartingFen(), []uint64{20, 400, 8067, 153299}},
+
+	// Promoting to a king
+	{"8/P7/8/8/8/8/8/k7 w - - 0 1", []uint64{5, 15}},
+
+	// Capturing en passant is compulsory
+	{"8/8/8/3pP3/8/8/8/7k w - d6 0 1", []uint64{1, 3}},
+}
+
+func TestAntichessPerft(t *testing.T) {
+	for _, rules := range []chess.AntichessRules{chess.Giveaway, chess.Suicide} {
+		for _, position := range antichessPerftPositions {
+			board, err := chess.LoadFenWithRules(position.fen, rules)
+			assert.Nil(t, err)
+
+			for depth, nodes := range position.nodes {
+				assert.Equal(t, nodes, chess.Perft(board, depth + 1), "%v %v depth %v", rules.Name(), position.fen, depth + 1)
+			}
+		}
+	}
+}
+
+func TestAntichessMoves(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFenWithRules("rnbqkbnr/p1pppppp/8/1p6/8/4P3/PPPP1PPP/RNBQKBNR w - b6 0 2", chess.Giveaway)
+	assert.Nil(err)
+	assert.Equal([]chess.Move{{Source: chess.F1, Destination: chess.B5}}, board.GetLegalMoves(false))
+
+	// Pawns can promote to a king
+	board, err = chess.LoadFenWithRules("8/P7/8/8/8/8/8/k7 w - - 0 1", chess.Giveaway)
+	assert.Nil(err)
+
+	move, err := board.MoveWithSan("a8=K")
+	assert.Nil(err)
+	assert.Equal(chess.Move{Source: chess.A7, Destination: chess.A8, IsPromotion: true, PromotedPiece: chess.King}, move)
+
+	// The king can be captured
+	board, err = chess.LoadFenWithRules("8/8/8/8/8/2k5/1P6/8 w - - 0 1", chess.Giveaway)
+	assert.Nil(err)
+	assert.Equal([]chess.Move{{Source: chess.B2, Destination: chess.C3}}, board.GetLegalMoves(false))
+}
+
+func TestAntichessOutcome(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		fen      string
+		giveaway chess.Outcome
+		suicide  chess.Outcome
+	}{
+		// White has lost all its pieces
+		{
+			"8/8/8/8/8/8/8/k7 w - - 0 1",
+			chess.Outcome{Result: chess.WhiteWins, Termination: chess.VariantEnd},
+			chess.Outcome{Result: chess.WhiteWins, Termination: chess.VariantEnd},
+		},
+		// White's pawns are blocked, and it has more pieces
+		{
+			"8/8/8/p7/P7/P7/8/8 w - - 0 1",
+			chess.Outcome{Result: chess.WhiteWins, Termination: chess.Stalemate},
+			chess.Outcome{Result: chess.BlackWins, Termination: chess.Stalemate},
+		},
+		// Black's pawn is blocked, and both sides have as many pieces
+		{
+			"8/8/8/p7/P7/8/8/8 b - - 0 1",
+			chess.Outcome{Result: chess.BlackWins, Termination: chess.Stalemate},
+			chess.Outcome{Result: chess.Draw, Termination: chess.Stalemate},
+		},
+		{
+			chess.Giveaway.StartingFen(),
+			chess.Outcome{},
+			chess.Outcome{},
+		},
+	}
+
+	for _, test := range tests {
+		board, err := chess.LoadFenWithRules(test.fen, chess.Giveaway)
+		assert.Nil(err)
+		assert.Equal(test.giveaway, board.Outcome(), test.fen)
+
+		board.SetRules(chess.Suicide)
+		assert.Equal(test.suicide, board.Outcome(), test.fen)
+	}
+}
+
+func TestVariantWithName(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, name := range []string{"standard", "giveaway", "suicide"} {
+		rules, err := chess.VariantWithName(name)
+		assert.Nil(err)
+		assert.Equal(name, rules.Name())
+	}
+
+	rules, err := chess.VariantWithName("antichess")
+	assert.Nil(err)
+	assert.Equal(chess.Giveaway, rules)
+
+	_, err = chess.VariantWithName("crazyhouse")
+	assert.NotNil(err)
+}
@@ -0,0 +1,76 @@
+package chess
+
+// Precomputed attack sets, for engines and analysis code built on gogm to reuse instead of
+// duplicating the tables
+// Each returns the squares attacked by a piece on `sq` whether or not they are occupied, so
+// friendly pieces must be masked out to get the squares the piece can move to
+
+// Returns the squares attacked by a knight on `sq`
+func KnightAttacks(sq Square) Bitboard {
+	return knightAttackSets[uint(sq)]
+}
+
+// Returns the squares attacked by a king on `sq`
+func KingAttacks(sq Square) Bitboard {
+	return kingAttackSets[uint(sq)]
+}
+
+// Returns the squares attacked by a pawn of the given side on `sq` (the squares it captures on,
+// not the squares it advances to)
+func PawnAttacks(sq Square, isBlack bool) Bitboard {
+	return pawnAttackSet(sq, isBlack)
+}
+
+// Returns the squares attacked by a bishop on `sq`, stopping at the first piece in `occupied` in
+// each direction
+func BishopAttacks(sq Square, occupied Bitboard) Bitboard {
+	return bishopAttackSet(sq, occupied)
+}
+
+// Returns the squares attacked by a rook on `sq`, stopping at the first piece in `occupied` in
+// each direction
+func RookAttacks(sq Square, occupied Bitboard) Bitboard {
+	return rookAttackSet(sq, occupied)
+}
+
+// Returns the squares attacked by a queen on `sq`, stopping at the first piece in `occupied` in
+// each direction
+func QueenAttacks(sq Square, occupied Bitboard) Bitboard {
+	return BishopAttacks(sq, occupied) | RookAttacks(sq, occupied)
+}
+
+// Returns the squares a bishop on `sq` would attack on an otherwise empty board
+func UnobstructedBishopAttacks(sq Square) Bitboard {
+	return BishopAttacks(sq, EmptyBitboard)
+}
+
+// Returns the squares a rook on `sq` would attack on an otherwise empty board
+func UnobstructedRookAttacks(sq Square) Bitboard {
+	return RookAttacks(sq, EmptyBitboard)
+}
+
+// Returns the squares a queen on `sq` would attack on an otherwise empty board
+func UnobstructedQueenAttacks(sq Square) Bitboard {
+	return QueenAttacks(sq, EmptyBitboard)
+}
+
+// Returns the squares attacked by a piece of the given kind and side on `sq`, with sliding
+// pieces stopping at the first piece in `occupied` in each direction
+func PieceAttacks(kind PieceKind, sq Square, isBlack bool, occupied Bitboard) Bitboard {
+	switch kind {
+	case Pawn:
+		return PawnAttacks(sq, isBlack)
+	case Knight:
+		return KnightAttacks(sq)
+	case Bishop:
+		return BishopAttacks(sq, occupied)
+	case Rook:
+		return RookAttacks(sq, occupied)
+	case Queen:
+		return QueenAttacks(sq, occupied)
+	case King:
+		return KingAttacks(sq)
+	}
+
+	return EmptyBitboard
+}
@@ -0,0 +1,37 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func TestLeaperAttacks(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(chess.BitboardFromSquares(chess.B3, chess.C2), chess.KnightAttacks(chess.A1))
+	assert.Equal(8, chess.KnightAttacks(chess.E4).PopCount())
+	assert.Equal(chess.BitboardFromSquares(chess.D1, chess.D2, chess.E2, chess.F2, chess.F1), chess.KingAttacks(chess.E1))
+	assert.Equal(chess.BitboardFromSquares(chess.D5, chess.F5), chess.PawnAttacks(chess.E4, false))
+	assert.Equal(chess.BitboardFromSquares(chess.D3, chess.F3), chess.PawnAttacks(chess.E4, true))
+	assert.Equal(chess.BitboardFromSquares(chess.B6), chess.PawnAttacks(chess.A7, true))
+}
+
+func TestSliderAttacks(t *testing.T) {
+	assert := assert.New(t)
+
+	occupied := chess.BitboardFromSquares(chess.D4, chess.D7, chess.G4, chess.F6, chess.B2)
+
+	assert.Equal(chess.BitboardFromSquares(chess.D5, chess.D6, chess.D7, chess.D3, chess.D2, chess.D1, chess.C4, chess.B4, chess.A4, chess.E4, chess.F4, chess.G4), chess.RookAttacks(chess.D4, occupied))
+	assert.Equal(chess.BitboardFromSquares(chess.E5, chess.F6, chess.C5, chess.B6, chess.A7, chess.C3, chess.B2, chess.E3, chess.F2, chess.G1), chess.BishopAttacks(chess.D4, occupied))
+	assert.Equal(chess.RookAttacks(chess.D4, occupied) | chess.BishopAttacks(chess.D4, occupied), chess.QueenAttacks(chess.D4, occupied))
+
+	assert.Equal((chess.FileBitboard(chess.FileD) | chess.RankBitboard(chess.Rank4)).Unset(chess.D4), chess.UnobstructedRookAttacks(chess.D4))
+	assert.Equal((chess.DiagonalBitboard(chess.D4) | chess.AntiDiagonalBitboard(chess.D4)).Unset(chess.D4), chess.UnobstructedBishopAttacks(chess.D4))
+	assert.Equal(27, chess.UnobstructedQueenAttacks(chess.D4).PopCount())
+
+	for sq := chess.Square(0); sq < 64; sq++ {
+		assert.Equal(chess.QueenAttacks(sq, occupied), chess.PieceAttacks(chess.Queen, sq, false, occupied))
+		assert.Equal(chess.PawnAttacks(sq, true), chess.PieceAttacks(chess.Pawn, sq, true, occupied))
+	}
+}
@@ -1,9 +1,38 @@
 package chess
 
+import (
+	"math/bits"
+	"strings"
+)
+
+// A set of squares, with bit n set if square n is in the set
 type Bitboard uint64
 
 const EmptyBitboard Bitboard = Bitboard(0)
 
+// A direction on the board, from white's perspective
+type Direction uint8
+
+const (
+	North Direction = iota
+	South
+	East
+	West
+	NorthEast
+	NorthWest
+	SouthEast
+	SouthWest
+)
+
+// Returns the bitboard containing exactly the given squares
+func BitboardFromSquares(squares ...Square) (result Bitboard) {
+	for _, sq := range squares {
+		result = result.Set(sq)
+	}
+
+	return
+}
+
 func (bitboard Bitboard) Get(sq Square) bool {
 	return bitboard & (1 << Bitboard(sq)) != EmptyBitboard
 }
@@ -19,3 +48,102 @@ func (bitboard Bitboard) Unset(sq Square) Bitboard {
 func (bitboard Bitboard) IntersectsSquare(sq Square) bool {
 	return bitboard & EmptyBitboard.Set(sq) != EmptyBitboard
 }
+
+// Returns the number of squares in the set
+func (bitboard Bitboard) PopCount() int {
+	return bits.OnesCount64(uint64(bitboard))
+}
+
+// Returns the lowest-numbered square in the set (the square closest to a8), which must not be empty
+func (bitboard Bitboard) LSB() Square {
+	return Square(bits.TrailingZeros64(uint64(bitboard)))
+}
+
+// Returns the highest-numbered square in the set (the square closest to h1), which must not be
+// empty
+func (bitboard Bitboard) MSB() Square {
+	return Square(63 - bits.LeadingZeros64(uint64(bitboard)))
+}
+
+// Returns the set without its lowest-numbered square
+// Used with LSB to iterate over the squares in a bitboard:
+//
+//	for v := bitboard; v != EmptyBitboard; v = v.ClearLSB() {
+//		sq := v.LSB()
+//	}
+func (bitboard Bitboard) ClearLSB() Bitboard {
+	return bitboard & (bitboard - 1)
+}
+
+// Removes the lowest-numbered square from the set and returns it. The set must not be empty
+func (bitboard *Bitboard) PopLSB() Square {
+	sq := bitboard.LSB()
+	*bitboard = bitboard.ClearLSB()
+	return sq
+}
+
+// Call `f` with each square in the set, in increasing order
+func (bitboard Bitboard) ForEachSquare(f func(sq Square)) {
+	for v := bitboard; v != EmptyBitboard; v = v.ClearLSB() {
+		f(v.LSB())
+	}
+}
+
+// Returns the squares in the set, in increasing order
+func (bitboard Bitboard) Squares() []Square {
+	squares := make([]Square, 0, bitboard.PopCount())
+
+	for v := bitboard; v != EmptyBitboard; v = v.ClearLSB() {
+		squares = append(squares, v.LSB())
+	}
+
+	return squares
+}
+
+// Returns the set with every square moved one step in the given direction
+// Squares moved off the board are discarded rather than wrapping around to the other side
+func (bitboard Bitboard) Shift(direction Direction) Bitboard {
+	switch direction {
+	case North:
+		return bitboard >> 8
+	case South:
+		return bitboard << 8
+	case East:
+		return (bitboard & ^FileHBitboard) << 1
+	case West:
+		return (bitboard & ^FileABitboard) >> 1
+	case NorthEast:
+		return (bitboard & ^FileHBitboard) >> 7
+	case NorthWest:
+		return (bitboard & ^FileABitboard) >> 9
+	case SouthEast:
+		return (bitboard & ^FileHBitboard) << 9
+	case SouthWest:
+		return (bitboard & ^FileABitboard) << 7
+	}
+
+	return bitboard
+}
+
+// Returns a grid showing the squares in the set, with the 8th rank at the top
+func (bitboard Bitboard) String() string {
+	var sb strings.Builder
+
+	for rankIndex := 0; rankIndex < 8; rankIndex++ {
+		for fileIndex := 0; fileIndex < 8; fileIndex++ {
+			if fileIndex > 0 {
+				sb.WriteRune(' ')
+			}
+
+			if bitboard.Get(SquareAt(File(fileIndex), Rank(rankIndex))) {
+				sb.WriteRune('x')
+			} else {
+				sb.WriteRune('.')
+			}
+		}
+
+		sb.WriteRune('\n')
+	}
+
+	return sb.String()
+}
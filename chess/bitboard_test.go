@@ -0,0 +1,65 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func TestBitboardSquares(t *testing.T) {
+	assert := assert.New(t)
+
+	bitboard := chess.BitboardFromSquares(chess.H1, chess.A8, chess.E4)
+
+	assert.Equal(3, bitboard.PopCount())
+	assert.Equal(chess.A8, bitboard.LSB())
+	assert.Equal(chess.H1, bitboard.MSB())
+	assert.Equal([]chess.Square{chess.A8, chess.E4, chess.H1}, bitboard.Squares())
+
+	var visited []chess.Square
+	bitboard.ForEachSquare(func(sq chess.Square) {
+		visited = append(visited, sq)
+	})
+	assert.Equal(bitboard.Squares(), visited)
+
+	assert.Equal(chess.BitboardFromSquares(chess.E4, chess.H1), bitboard.ClearLSB())
+	assert.Equal(chess.A8, bitboard.PopLSB())
+	assert.Equal(chess.BitboardFromSquares(chess.E4, chess.H1), bitboard)
+}
+
+func TestBitboardShift(t *testing.T) {
+	assert := assert.New(t)
+
+	e4 := chess.BitboardFromSquares(chess.E4)
+	assert.Equal(chess.BitboardFromSquares(chess.E5), e4.Shift(chess.North))
+	assert.Equal(chess.BitboardFromSquares(chess.E3), e4.Shift(chess.South))
+	assert.Equal(chess.BitboardFromSquares(chess.F4), e4.Shift(chess.East))
+	assert.Equal(chess.BitboardFromSquares(chess.D4), e4.Shift(chess.West))
+	assert.Equal(chess.BitboardFromSquares(chess.F5), e4.Shift(chess.NorthEast))
+	assert.Equal(chess.BitboardFromSquares(chess.D5), e4.Shift(chess.NorthWest))
+	assert.Equal(chess.BitboardFromSquares(chess.F3), e4.Shift(chess.SouthEast))
+	assert.Equal(chess.BitboardFromSquares(chess.D3), e4.Shift(chess.SouthWest))
+
+	// Shifts do not wrap around the edges of the board
+	corners := chess.BitboardFromSquares(chess.A1, chess.H8)
+	assert.Equal(chess.BitboardFromSquares(chess.B1), corners.Shift(chess.East))
+	assert.Equal(chess.BitboardFromSquares(chess.G8), corners.Shift(chess.West))
+	assert.Equal(chess.BitboardFromSquares(chess.A2), corners.Shift(chess.North))
+	assert.Equal(chess.BitboardFromSquares(chess.C3), corners.Shift(chess.NorthEast).Shift(chess.NorthEast))
+}
+
+func TestBitboardString(t *testing.T) {
+	assert := assert.New(t)
+
+	expected := "" +
+		"x . . . . . . .\n" +
+		". . . . . . . .\n" +
+		". . . . . . . .\n" +
+		". . . . . . . .\n" +
+		". . . . x . . .\n" +
+		". . . . . . . .\n" +
+		". . . . . . . .\n" +
+		". . . . . . . x\n"
+
+	assert.Equal(expected, chess.BitboardFromSquares(chess.A8, chess.E4, chess.H1).String())
+}
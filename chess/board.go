@@ -3,16 +3,32 @@ package chess
 import "log"
 
 // Information about the state of the board
+// Pieces are stored in per-side, per-kind bitboards which are updated incrementally, alongside a
+// mailbox of the piece on each square for fast lookup by square
 type Board struct {
-	whitePieces        []Piece
-	blackPieces        []Piece
-	squareContents     [64]*Piece
+	pieceBitboards     [2][6]Bitboard
+	sideBitboards      [2]Bitboard
+	squareContents     [64]Piece
 	enPassantTarget    Square
 	hasEnPassantTarget bool
 	blackToMove        bool
 	castlingRights     CastlingRights
-	bishopAttackTable  SlidingAttackTable
-	rookAttackTable    SlidingAttackTable
+
+	// Number of halfmoves since the last capture or pawn move
+	halfmoveClock int
+
+	// Repetition keys of the positions before the current one, oldest first
+	history []uint64
+
+	// Zobrist hash of the position and sub-keys of groups of pieces, updated incrementally
+	hash          uint64
+	pawnKey       uint64
+	minorPieceKey uint64
+	majorPieceKey uint64
+	kingKey       uint64
+
+	// Rules the game is played by, or nil for standard chess
+	rules Rules
 }
 
 // Information about a piece on the board
@@ -20,6 +36,9 @@ type Piece struct {
 	Kind         PieceKind
 	Square       Square
 	IsBlack      bool
+
+	// Squares attacked by the piece, cached by move generation and only valid if HasAttackSet is
+	// true. The cache is invalidated when a piece moves onto or away from a square in the attack set
 	HasAttackSet bool
 	AttackSet    Bitboard
 }
@@ -31,9 +50,28 @@ type CastlingRights struct {
 	BlackQueenside bool
 }
 
+// Remove the castling rights that need the king or rook starting on the given square
+func (rights *CastlingRights) removeForSquare(sq Square) {
+	switch sq {
+	case E1:
+		rights.WhiteKingside = false
+		rights.WhiteQueenside = false
+	case H1:
+		rights.WhiteKingside = false
+	case A1:
+		rights.WhiteQueenside = false
+	case E8:
+		rights.BlackKingside = false
+		rights.BlackQueenside = false
+	case H8:
+		rights.BlackKingside = false
+	case A8:
+		rights.BlackQueenside = false
+	}
+}
+
+// Returns an empty board
 func NewBoard() (board Board) {
-	board.bishopAttackTable = CreateBishopAttackTable()
-	board.rookAttackTable = CreateRookAttackTable()
 	return
 }
 
@@ -41,89 +79,178 @@ func (board *Board) IsBlackToMove() bool {
 	return board.blackToMove
 }
 
+// Returns whether the given side may still castle kingside and queenside
+//
+// Deprecated: use GetCastlingRightsForColor
 func (board *Board) GetCastlingRights(isBlack bool) (kingside bool, queenside bool) {
-	if isBlack {
-		kingside = board.castlingRights.BlackKingside
-		queenside = board.castlingRights.BlackQueenside
-	} else {
-		kingside = board.castlingRights.WhiteKingside
-		queenside = board.castlingRights.WhiteQueenside
-	}
+	return board.GetCastlingRightsForColor(ColorWithIsBlack(isBlack))
+}
 
-	return
+// Returns the square a pawn can capture en passant on, and whether there is one
+func (board *Board) GetEnPassantTarget() (Square, bool) {
+	return board.enPassantTarget, board.hasEnPassantTarget
+}
+
+// Returns the number of halfmoves since the last capture or pawn move
+func (board *Board) HalfmoveClock() int {
+	return board.halfmoveClock
 }
 
+// Returns a list of the pieces belonging to the given side, ordered by square
+//
+// Deprecated: use GetPiecesForColor
 func (board *Board) GetPiecesForSide(isBlack bool) []Piece {
-	if isBlack {
-		return board.blackPieces
-	} else {
-		return board.whitePieces
-	}
+	return board.GetPiecesForColor(ColorWithIsBlack(isBlack))
 }
 
+// Returns the piece on the given square, or nil if the square is empty
+// The returned piece belongs to the board and is only valid until the board is next modified
 func (board *Board) GetPiece(sq Square) *Piece {
-	return board.squareContents[uint32(sq)]
+	if !board.HasPiece(sq) {
+		return nil
+	}
+
+	return &board.squareContents[uint32(sq)]
 }
 
 func (board *Board) HasPiece(sq Square) bool {
-	return board.squareContents[uint32(sq)] != nil
+	return (board.sideBitboards[0] | board.sideBitboards[1]).Get(sq)
 }
 
 func (board *Board) SetPiece(sq Square, kind PieceKind, isBlack bool) {
 	// Remove existing piece
 	board.SetEmpty(sq)
 
-	// Set square content
-	board.squareContents[uint32(sq)] = &Piece{Square: sq, Kind: kind, IsBlack: isBlack}
-
-	// Add piece to piece list
-	if isBlack {
-		board.blackPieces = append(board.blackPieces, *board.squareContents[uint32(sq)])
-	} else {
-		board.whitePieces = append(board.whitePieces, *board.squareContents[uint32(sq)])
-	}
+	board.putPiece(sq, kind, isBlack)
 }
 
 func (board *Board) SetEmpty(sq Square) {
-	existingPiece := board.squareContents[uint32(sq)]
-	if existingPiece != nil {
-		if existingPiece.IsBlack {
-			updatedBlackPieces, removed := removeElement(board.blackPieces, *existingPiece)
-			if !removed {
-				panic("failed to remove piece")
-			}
-			board.blackPieces = updatedBlackPieces
-		} else {
-			updatedWhitePieces, removed := removeElement(board.whitePieces, *existingPiece)
-			if !removed {
-				panic("failed to remove piece")
-			}
-			board.whitePieces = updatedWhitePieces
-		}
+	if board.HasPiece(sq) {
+		board.removePiece(sq)
 	}
+}
 
-	board.squareContents[uint32(sq)] = nil
+func (board *Board) GetPiecesBitboard(isBlack bool) Bitboard {
+	return board.sideBitboards[sideIndex(isBlack)]
 }
 
-func (board *Board) GetPiecesBitboard(isBlack bool) (result Bitboard) {
-	var pieces []Piece
-	if isBlack {
-		pieces = board.blackPieces
-	} else {
-		pieces = board.whitePieces
+// Returns the bitboard of squares occupied by pieces of the given kind and side
+func (board *Board) PiecesBB(kind PieceKind, isBlack bool) Bitboard {
+	return board.pieceBitboards[sideIndex(isBlack)][kind]
+}
+
+// Returns the bitboard of squares occupied by pieces of either side
+func (board *Board) GetOccupiedBitboard() Bitboard {
+	return board.sideBitboards[0] | board.sideBitboards[1]
+}
+
+// Place a piece on an empty square
+func (board *Board) putPiece(sq Square, kind PieceKind, isBlack bool) {
+	side := sideIndex(isBlack)
+
+	board.invalidateAttackSets(sq)
+	board.togglePieceKey(sq, kind, isBlack)
+
+	board.pieceBitboards[side][kind] = board.pieceBitboards[side][kind].Set(sq)
+	board.sideBitboards[side] = board.sideBitboards[side].Set(sq)
+	board.squareContents[uint32(sq)] = Piece{Square: sq, Kind: kind, IsBlack: isBlack}
+}
+
+// Remove the piece from an occupied square
+func (board *Board) removePiece(sq Square) {
+	piece := &board.squareContents[uint32(sq)]
+	side := sideIndex(piece.IsBlack)
+
+	board.pieceBitboards[side][piece.Kind] = board.pieceBitboards[side][piece.Kind].Unset(sq)
+	board.sideBitboards[side] = board.sideBitboards[side].Unset(sq)
+
+	board.invalidateAttackSets(sq)
+	board.togglePieceKey(sq, piece.Kind, piece.IsBlack)
+}
+
+// Discard the cached attack sets of the sliding pieces attacking `sq`, as a piece has been placed
+// on or removed from the square, changing how far they can see
+// Other pieces attack the same squares wherever the other pieces on the board are, so their cached
+// attack sets stay valid until they move
+func (board *Board) invalidateAttackSets(sq Square) {
+	allPiecesBitboard := board.GetOccupiedBitboard()
+
+	diagonalSliders := board.pieceBitboards[0][Bishop] | board.pieceBitboards[0][Queen] |
+		board.pieceBitboards[1][Bishop] | board.pieceBitboards[1][Queen]
+	orthogonalSliders := board.pieceBitboards[0][Rook] | board.pieceBitboards[0][Queen] |
+		board.pieceBitboards[1][Rook] | board.pieceBitboards[1][Queen]
+
+	// A slider attacks `sq` exactly when `sq` is attacked by a slider of the same kind placed on
+	// `sq`, regardless of whether `sq` itself is occupied
+	affectedSliders := bishopAttackSet(sq, allPiecesBitboard) & diagonalSliders |
+		rookAttackSet(sq, allPiecesBitboard) & orthogonalSliders
+
+	for v := affectedSliders; v != EmptyBitboard; v = v.ClearLSB() {
+		board.squareContents[v.LSB()].HasAttackSet = false
 	}
+}
+
+// Returns the squares attacked by the piece on the occupied square `sq`, computing and caching
+// the attack set if it is not already cached
+func (board *Board) getCachedAttackSet(sq Square) Bitboard {
+	piece := &board.squareContents[uint32(sq)]
 
-	for _, piece := range pieces {
-		result = result.Set(piece.Square)
+	if !piece.HasAttackSet {
+		piece.AttackSet = board.getAttackSet(piece.Kind, sq, piece.IsBlack, board.GetOccupiedBitboard())
+		piece.HasAttackSet = true
 	}
 
-	return
+	return piece.AttackSet
+}
+
+// Move a piece from an occupied square to an empty square
+func (board *Board) movePiece(source Square, destination Square) {
+	piece := board.squareContents[uint32(source)]
+
+	board.removePiece(source)
+	board.putPiece(destination, piece.Kind, piece.IsBlack)
+}
+
+// True if the move captures an enemy piece, including by capturing en passant
+// Like the other move predicates, this can be used for moves not produced by the move generator
+// (e.g. moves parsed from UCI notation) as long as a piece of the side to move is on the source
+// square
+func (board *Board) IsCaptureMove(move Move) bool {
+	return board.sideBitboards[sideIndex(!board.blackToMove)].Get(move.Destination) || board.IsEnPassantMove(move)
+}
+
+// True if the move is a pawn capturing en passant
+func (board *Board) IsEnPassantMove(move Move) bool {
+	return board.hasEnPassantTarget &&
+		move.Destination == board.enPassantTarget &&
+		move.Source.File() != move.Destination.File() &&
+		board.pieceBitboards[sideIndex(board.blackToMove)][Pawn].Get(move.Source)
+}
+
+// True if the move is the king castling, which is written as the king moving two squares towards
+// the rook
+func (board *Board) IsCastlingMove(move Move) bool {
+	if !board.pieceBitboards[sideIndex(board.blackToMove)][King].Get(move.Source) {
+		return false
+	}
+
+	return move.Source.File() == FileE &&
+		move.Source.Rank() == move.Destination.Rank() &&
+		(move.Destination.File() == FileC || move.Destination.File() == FileG)
+}
+
+// True if the move neither captures nor promotes
+func (board *Board) IsQuietMove(move Move) bool {
+	return !move.IsPromotion && !board.IsCaptureMove(move)
 }
 
 // Update the board state by making the given move
 func (board *Board) MakeMove(move Move) (unmove Unmove) {
-	pieceMoved := board.GetPiece(move.Source).Kind
-	isCapture := board.GetPiece(move.Destination) != nil
+	pieceMoved := board.squareContents[uint32(move.Source)].Kind
+	isPawnMove := pieceMoved == Pawn
+	isCapture := board.HasPiece(move.Destination)
+	isEnPassantCapture := board.IsEnPassantMove(move)
+	isCastling := board.IsCastlingMove(move)
 
 	// Setup information to unmake move
 	unmove.source             = move.Source
@@ -133,9 +260,22 @@ func (board *Board) MakeMove(move Move) (unmove Unmove) {
 	unmove.isPromotion        = move.IsPromotion
 	unmove.hadEnPassantTarget = board.hasEnPassantTarget
 	unmove.oldCastlingRights  = board.castlingRights
+	unmove.oldHalfmoveClock   = board.halfmoveClock
+
+	board.history = append(board.history, board.repetitionKey())
+
+	if isPawnMove || isCapture {
+		board.halfmoveClock = 0
+	} else {
+		board.halfmoveClock += 1
+	}
+
+	// The side to move, castling rights and en passant target are hashed again once updated
+	board.hash ^= board.stateKey()
 
 	if isCapture {
-		unmove.capturedPiece = board.GetPiece(move.Destination).Kind
+		unmove.capturedPiece = board.squareContents[uint32(move.Destination)].Kind
+		board.removePiece(move.Destination)
 	}
 
 	// In the case of promotion, change the piece moved to the promoted piece
@@ -143,33 +283,22 @@ func (board *Board) MakeMove(move Move) (unmove Unmove) {
 		pieceMoved = move.PromotedPiece
 	}
 
-	board.SetEmpty(move.Source)
-	board.SetPiece(move.Destination, pieceMoved, board.blackToMove)
+	board.removePiece(move.Source)
+	board.putPiece(move.Destination, pieceMoved, board.blackToMove)
 
 	// In the case of en passant, remove the captured pawn
-	isEnPassantCapture := pieceMoved == Pawn && !isCapture && move.Source.File() != move.Destination.File()
 	if isEnPassantCapture {
-		board.SetEmpty(SquareAt(move.Destination.File(), move.Source.Rank()))
+		board.removePiece(SquareAt(move.Destination.File(), move.Source.Rank()))
 	}
 
 	// In case of castling, move the rook
-	if pieceMoved == King {
-		var backRank Rank
-		if board.blackToMove {
-			backRank = Rank8
-		} else {
-			backRank = Rank1
-		}
-
-		sourceFile := move.Source.File()
-		destinationFile := move.Destination.File()
+	if isCastling {
+		backRank := move.Source.Rank()
 
-		if sourceFile == FileE && destinationFile == FileC { // Queenside castle
-			board.SetEmpty(SquareAt(FileA, backRank))
-			board.SetPiece(SquareAt(FileD, backRank), Rook, board.blackToMove)
-		} else if sourceFile == FileE && destinationFile == FileG {
-			board.SetEmpty(SquareAt(FileH, backRank))
-			board.SetPiece(SquareAt(FileF, backRank), Rook, board.blackToMove)
+		if move.Destination.File() == FileC { // Queenside castle
+			board.movePiece(SquareAt(FileA, backRank), SquareAt(FileD, backRank))
+		} else { // Kingside castle
+			board.movePiece(SquareAt(FileH, backRank), SquareAt(FileF, backRank))
 		}
 	}
 
@@ -197,35 +326,44 @@ func (board *Board) MakeMove(move Move) (unmove Unmove) {
 		}
 	}
 
-	// Update castling rights
-	var kingsideCastlingRight *bool
-	var queensideCastlingRight *bool
-
-	if board.blackToMove {
-		kingsideCastlingRight = &board.castlingRights.BlackKingside
-		queensideCastlingRight = &board.castlingRights.BlackQueenside
-	} else {
-		kingsideCastlingRight = &board.castlingRights.WhiteKingside
-		queensideCastlingRight = &board.castlingRights.WhiteQueenside
-	}
-
-	if pieceMoved == King {
-		*kingsideCastlingRight = false
-		*queensideCastlingRight = false
-	} else if pieceMoved == Rook && move.Source.File() == FileA {
-		*queensideCastlingRight = false
-	} else if pieceMoved == Rook && move.Source.File() == FileH {
-		*kingsideCastlingRight = false
-	}
+	// Update castling rights: moving the king or a rook away from its starting square, or capturing
+	// a rook on its starting square, loses the right to castle with it
+	board.castlingRights.removeForSquare(move.Source)
+	board.castlingRights.removeForSquare(move.Destination)
 
 	// Update side to move
 	board.blackToMove = !board.blackToMove
 
+	board.hash ^= board.stateKey()
+
 	return
 }
 
+// Returns a copy of the board with the given move made, leaving the board itself unchanged
+// Boards hold no references to shared state, so the copy can be explored independently of the
+// original (e.g. by another goroutine) without keeping a stack of Unmoves
+func (board *Board) MakeMoveCopy(move Move) Board {
+	result := board.Copy()
+	result.MakeMove(move)
+	return result
+}
+
+// Returns a copy of the board that can be changed without affecting the original, e.g. to let a bot
+// think about the position in another goroutine
+func (board *Board) Copy() Board {
+	result := *board
+
+	// Limit the capacity so that appending to the history of the copy does not overwrite the
+	// history of the original
+	result.history = board.history[:len(board.history):len(board.history)]
+
+	return result
+}
+
 // Update the board state by unmaking the given move
 func (board *Board) UnmakeMove(unmove Unmove) {
+	board.hash ^= board.stateKey()
+
 	// Update side to move
 	board.blackToMove = !board.blackToMove
 
@@ -233,27 +371,26 @@ func (board *Board) UnmakeMove(unmove Unmove) {
 	if unmove.isPromotion {
 		pieceMoved = Pawn
 	} else {
-		if destinationPiece := board.GetPiece(unmove.destination); destinationPiece != nil {
-			pieceMoved = destinationPiece.Kind
+		if board.HasPiece(unmove.destination) {
+			pieceMoved = board.squareContents[uint32(unmove.destination)].Kind
 		} else {
 			log.Fatalf("UnmakeMove - Piece on destination square %v is nil\n", unmove.destination)
 		}
 	}
 
 	// Replace piece on old square
-	board.SetPiece(unmove.source, pieceMoved, board.blackToMove)
+	board.removePiece(unmove.destination)
+	board.putPiece(unmove.source, pieceMoved, board.blackToMove)
 
 	if unmove.isCapture {
 		// Restore captured piece
-		board.SetPiece(unmove.destination, unmove.capturedPiece, !board.blackToMove)
-	} else {
-		board.SetEmpty(unmove.destination)
+		board.putPiece(unmove.destination, unmove.capturedPiece, !board.blackToMove)
 	}
 
 	// In the case of en passant, restore the captured pawn
 	isEnPassantCapture := pieceMoved == Pawn && !unmove.isCapture && unmove.source.File() != unmove.destination.File()
 	if isEnPassantCapture {
-		board.SetPiece(SquareAt(unmove.destination.File(), unmove.source.Rank()), Pawn, !board.blackToMove)
+		board.putPiece(SquareAt(unmove.destination.File(), unmove.source.Rank()), Pawn, !board.blackToMove)
 	}
 
 	// In the case of castling, move the rook back
@@ -269,11 +406,9 @@ func (board *Board) UnmakeMove(unmove Unmove) {
 		destinationFile := unmove.destination.File()
 
 		if sourceFile == FileE && destinationFile == FileC { // Queenside castle
-			board.SetEmpty(SquareAt(FileD, backRank))
-			board.SetPiece(SquareAt(FileA, backRank), Rook, board.blackToMove)
+			board.movePiece(SquareAt(FileD, backRank), SquareAt(FileA, backRank))
 		} else if sourceFile == FileE && destinationFile == FileG { // Kingside castle
-			board.SetEmpty(SquareAt(FileF, backRank))
-			board.SetPiece(SquareAt(FileH, backRank), Rook, board.blackToMove)
+			board.movePiece(SquareAt(FileF, backRank), SquareAt(FileH, backRank))
 		}
 	}
 
@@ -283,17 +418,54 @@ func (board *Board) UnmakeMove(unmove Unmove) {
 
 	// Restore castling rights
 	board.castlingRights = unmove.oldCastlingRights
+
+	board.halfmoveClock = unmove.oldHalfmoveClock
+	board.history = board.history[:len(board.history) - 1]
+
+	board.hash ^= board.stateKey()
+}
+
+// Pass the turn to the opponent without moving a piece, as used by null move pruning
+// This must not be done when the side to move is in check, as the opponent could then capture the
+// king
+func (board *Board) MakeNullMove() (unmove NullUnmove) {
+	unmove.oldEnPassantTarget = board.enPassantTarget
+	unmove.hadEnPassantTarget = board.hasEnPassantTarget
+	unmove.oldHalfmoveClock = board.halfmoveClock
+
+	// Positions on either side of a null move are not repetitions of each other, so the halfmove
+	// clock is reset to stop repetition detection looking back past it
+	board.history = append(board.history, board.repetitionKey())
+	board.halfmoveClock = 0
+
+	board.hash ^= board.stateKey()
+	board.hasEnPassantTarget = false
+	board.blackToMove = !board.blackToMove
+	board.hash ^= board.stateKey()
+
+	return
+}
+
+// Update the board state by unmaking the given null move
+func (board *Board) UnmakeNullMove(unmove NullUnmove) {
+	board.hash ^= board.stateKey()
+	board.blackToMove = !board.blackToMove
+	board.hasEnPassantTarget = unmove.hadEnPassantTarget
+	board.enPassantTarget = unmove.oldEnPassantTarget
+	board.hash ^= board.stateKey()
+
+	board.halfmoveClock = unmove.oldHalfmoveClock
+	board.history = board.history[:len(board.history) - 1]
 }
 
 // Returns the square containing the king
 func (board *Board) GetKingSquare(isBlack bool) Square {
-	for _, piece := range board.GetPiecesForSide(isBlack) {
-		if piece.Kind == King {
-			return piece.Square
-		}
+	kingBitboard := board.pieceBitboards[sideIndex(isBlack)][King]
+	if kingBitboard == EmptyBitboard {
+		return A1
 	}
 
-	return A1
+	return kingBitboard.LSB()
 }
 
 // True if the previous move left the king in check
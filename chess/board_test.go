@@ -0,0 +1,268 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func TestMakeUnmakeMove(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	assert.Nil(err)
+
+	fen := board.Fen()
+
+	for _, move := range board.GetLegalMoves(false) {
+		unmove := board.MakeMove(move)
+		board.UnmakeMove(unmove)
+		assert.Equal(fen, board.Fen(), "after unmaking %v", move)
+	}
+}
+
+func TestEnPassantCaptureOfCheckingPawn(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("8/8/8/2k5/4p3/8/3P4/4K3 w - - 0 1")
+	assert.Nil(err)
+
+	board.MakeMove(chess.Move{Source: chess.D2, Destination: chess.D4})
+
+	assert.True(board.IsCheck())
+	assert.Contains(board.GetLegalMoves(false), chess.Move{Source: chess.E4, Destination: chess.D3})
+	assert.Contains(board.GetLegalMoves(true), chess.Move{Source: chess.E4, Destination: chess.D3})
+}
+
+func TestGetPiecesForSide(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen(chess.StartingPositionFen)
+	assert.Nil(err)
+
+	assert.Len(board.GetPiecesForSide(false), 16)
+	assert.Len(board.GetPiecesForSide(true), 16)
+	assert.Equal(chess.E1, board.GetKingSquare(false))
+	assert.Equal(chess.E8, board.GetKingSquare(true))
+}
+
+func TestGetLegalMoveInfoFromSquare(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("r3k2r/1P6/8/8/8/8/8/R3K2R w KQkq - 0 1")
+	assert.Nil(err)
+
+	kingMoves := board.GetLegalMoveInfoFromSquare(chess.E1)
+	assert.Contains(kingMoves, chess.MoveInfo{Move: chess.Move{Source: chess.E1, Destination: chess.G1}, IsCastle: true})
+	assert.Contains(kingMoves, chess.MoveInfo{Move: chess.Move{Source: chess.E1, Destination: chess.C1}, IsCastle: true})
+	assert.Contains(kingMoves, chess.MoveInfo{Move: chess.Move{Source: chess.E1, Destination: chess.F1}})
+
+	pawnMoves := board.GetLegalMoveInfoFromSquare(chess.B7)
+	assert.Len(pawnMoves, 8)
+	assert.Contains(pawnMoves, chess.MoveInfo{
+		Move: chess.Move{Source: chess.B7, Destination: chess.A8, IsPromotion: true, PromotedPiece: chess.Knight},
+		IsCapture: true,
+	})
+}
+
+// Walks the game tree from kiwipete, checking that the attack sets cached by move generation
+// always match freshly computed attack sets
+func TestCachedAttackSets(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	assert.Nil(err)
+
+	var walk func(depth int)
+	walk = func(depth int) {
+		moves := board.GetLegalMoves(false)
+
+		for sq := chess.Square(0); sq < 64; sq++ {
+			piece := board.GetPiece(sq)
+			if piece != nil && piece.HasAttackSet {
+				expected := chess.GetPieceAttackSet(*piece, board.GetOccupiedBitboard(), board)
+				if !assert.Equal(expected, piece.AttackSet, "attack set of %v in %v", sq, board.Fen()) {
+					return
+				}
+			}
+		}
+
+		if depth == 0 {
+			return
+		}
+
+		for _, move := range moves {
+			unmove := board.MakeMove(move)
+			walk(depth - 1)
+			board.UnmakeMove(unmove)
+		}
+	}
+
+	walk(2)
+}
+
+func TestMovePredicates(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("r3k2r/3p4/8/4P3/8/8/8/R3K2R b KQkq - 0 1")
+	assert.Nil(err)
+
+	board.MakeMove(chess.Move{Source: chess.D7, Destination: chess.D5})
+
+	enPassant := chess.Move{Source: chess.E5, Destination: chess.D6}
+	assert.True(board.IsEnPassantMove(enPassant))
+	assert.True(board.IsCaptureMove(enPassant))
+	assert.False(board.IsQuietMove(enPassant))
+
+	push := chess.Move{Source: chess.E5, Destination: chess.E6}
+	assert.False(board.IsEnPassantMove(push))
+	assert.False(board.IsCaptureMove(push))
+	assert.True(board.IsQuietMove(push))
+
+	rookCapture := chess.Move{Source: chess.A1, Destination: chess.A8}
+	assert.True(board.IsCaptureMove(rookCapture))
+	assert.False(board.IsCastlingMove(rookCapture))
+
+	assert.True(board.IsCastlingMove(chess.Move{Source: chess.E1, Destination: chess.G1}))
+	assert.True(board.IsCastlingMove(chess.Move{Source: chess.E1, Destination: chess.C1}))
+	assert.False(board.IsCastlingMove(chess.Move{Source: chess.E1, Destination: chess.F1}))
+
+	// Black's king cannot castle when it is white to move
+	assert.False(board.IsCastlingMove(chess.Move{Source: chess.E8, Destination: chess.G8}))
+}
+
+func TestPiecesBB(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen(chess.StartingPositionFen)
+	assert.Nil(err)
+
+	assert.Equal(chess.BitboardFromSquares(chess.B1, chess.G1), board.PiecesBB(chess.Knight, false))
+	assert.Equal(8, board.PiecesBB(chess.Pawn, true).PopCount())
+
+	board.MakeMove(chess.Move{Source: chess.G1, Destination: chess.F3})
+	assert.Equal(chess.BitboardFromSquares(chess.B1, chess.F3), board.PiecesBB(chess.Knight, false))
+}
+
+func TestMakeMoveCopy(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen(chess.StartingPositionFen)
+	assert.Nil(err)
+
+	child := board.MakeMoveCopy(chess.Move{Source: chess.E2, Destination: chess.E4})
+	grandchild := child.MakeMoveCopy(chess.Move{Source: chess.E7, Destination: chess.E5})
+
+	assert.Equal("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq", board.Fen())
+	assert.Equal("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq", child.Fen())
+	assert.Equal("rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq", grandchild.Fen())
+	assert.Len(grandchild.GetLegalMoves(false), 29)
+}
+
+func TestCopy(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen(chess.StartingPositionFen)
+	assert.Nil(err)
+	playUciMoves(t, board, "g1f3", "g8f6", "f3g1")
+
+	// Moves made on the copy must not be seen by the original, including through its history
+	copied := board.Copy()
+	copied.MakeMove(chess.Move{Source: chess.F6, Destination: chess.G8})
+	assert.Equal(2, copied.RepetitionCount())
+
+	board.MakeMove(chess.Move{Source: chess.E7, Destination: chess.E5})
+	assert.Equal(1, board.RepetitionCount())
+	assert.Equal(2, copied.RepetitionCount())
+	assert.Equal("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq", copied.Fen())
+}
+
+func TestMakeNullMove(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen(chess.StartingPositionFen)
+	assert.Nil(err)
+
+	board.MakeMove(chess.Move{Source: chess.E2, Destination: chess.E4})
+	board.MakeMove(chess.Move{Source: chess.A7, Destination: chess.A6})
+	board.MakeMove(chess.Move{Source: chess.E4, Destination: chess.E5})
+	board.MakeMove(chess.Move{Source: chess.D7, Destination: chess.D5})
+	fen := board.Fen()
+	assert.Contains(board.GetLegalMovesFromSquare(chess.E5), chess.Move{Source: chess.E5, Destination: chess.D6})
+
+	unmove := board.MakeNullMove()
+	assert.True(board.IsBlackToMove())
+
+	// The en passant capture is no longer possible after a pair of null moves
+	secondUnmove := board.MakeNullMove()
+	assert.False(board.IsBlackToMove())
+	assert.NotContains(board.GetLegalMovesFromSquare(chess.E5), chess.Move{Source: chess.E5, Destination: chess.D6})
+
+	board.UnmakeNullMove(secondUnmove)
+	board.UnmakeNullMove(unmove)
+	assert.Equal(fen, board.Fen())
+	assert.Contains(board.GetLegalMovesFromSquare(chess.E5), chess.Move{Source: chess.E5, Destination: chess.D6})
+}
+
+func TestMakeLegalMove(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("4k3/1P6/8/8/8/8/8/4K3 w - - 0 1")
+	assert.Nil(err)
+
+	fen := board.Fen()
+
+	// Kings move one square, pawns cannot reach the back rank without promoting and it is not black's turn
+	for _, move := range []chess.Move{
+		{Source: chess.E1, Destination: chess.E3},
+		{Source: chess.B7, Destination: chess.B8},
+		{Source: chess.E8, Destination: chess.E7},
+	} {
+		_, err := board.MakeLegalMove(move)
+		assert.NotNil(err, "%v", move)
+		assert.Equal(fen, board.Fen(), "after failing to make %v", move)
+	}
+
+	// The promoted piece is ignored for moves that are not promotions
+	unmove, err := board.MakeLegalMove(chess.Move{Source: chess.E1, Destination: chess.E2, PromotedPiece: chess.Queen})
+	assert.Nil(err)
+	board.UnmakeMove(unmove)
+	assert.Equal(fen, board.Fen())
+
+	_, err = board.MakeLegalMove(chess.Move{Source: chess.B7, Destination: chess.B8, IsPromotion: true, PromotedPiece: chess.Queen})
+	assert.Nil(err)
+	assert.Equal("1Q2k3/8/8/8/8/8/8/4K3 b -", board.Fen())
+}
+
+func TestCastlingRightsAfterRookCapture(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+	assert.Nil(err)
+
+	// Capturing a rook on its starting square removes its side's right to castle with it
+	unmove := board.MakeMove(chess.Move{Source: chess.A1, Destination: chess.A8})
+	assert.Equal("R3k2r/8/8/8/8/8/8/4K2R b Kk", board.Fen())
+
+	loaded, err := chess.LoadFen(board.Fen())
+	assert.Nil(err)
+	assert.Equal(loaded.Hash(), board.Hash())
+
+	board.UnmakeMove(unmove)
+	assert.Equal("r3k2r/8/8/8/8/8/8/R3K2R w KQkq", board.Fen())
+
+	board.MakeMove(chess.Move{Source: chess.H1, Destination: chess.H8})
+	assert.Equal("r3k2R/8/8/8/8/8/8/R3K3 b Qq", board.Fen())
+}
+
+func TestCastlingRightsAfterRookMovesOffStartingSquare(t *testing.T) {
+	assert := assert.New(t)
+
+	// Moving a rook that is not on its starting square keeps the right to castle with the other rook
+	// on the same file
+	board, err := chess.LoadFen("r3k2r/8/8/8/R7/8/8/R3K2R w KQkq - 0 1")
+	assert.Nil(err)
+
+	board.MakeMove(chess.Move{Source: chess.A4, Destination: chess.A5})
+	assert.Equal("r3k2r/8/8/R7/8/8/8/R3K2R b KQkq", board.Fen())
+}
@@ -1,5 +1,248 @@
 package chess
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 type Bot interface {
 	Think(*Board) Move
 }
+
+// Implemented by bots that can report their name, version, author and options
+type IdentifiableBot interface {
+	Bot
+	Info() BotInfo
+}
+
+// Implemented by bots that can be asked to stop thinking early, for example when a time budget runs
+// out. Stop may be called from another goroutine while Think is running, and Think should then
+// return the best move found so far
+type StoppableBot interface {
+	Bot
+	Stop()
+}
+
+// Called by a bot with a short comment on the move it has chosen, e.g. "only move to avoid mate",
+// before Think returns the move
+type CommentCallback func(move Move, comment string)
+
+// Implemented by bots that can comment on their moves
+type CommentingBot interface {
+	Bot
+	SetCommentCallback(callback CommentCallback)
+}
+
+// Progress of a bot's search, reported as it thinks
+type SearchInfo struct {
+	// Depth searched (ply)
+	Depth int
+
+	// Greatest depth reached by any line, including extensions and captures searched past Depth
+	// (ply), or 0 if the bot does not say
+	SelectiveDepth int
+
+	// Evaluation of the best move in pawns from the perspective of the side to move, or a mate score
+	// (see MateIn) for a forced mate
+	Score float64
+
+	BestMove Move
+
+	// Moves the bot expects to be played from the position, starting with BestMove, as far as it
+	// knows them
+	PrincipalVariation []Move
+
+	// Number of positions searched since the bot started thinking, and how long it has been
+	// thinking, or 0 if the bot does not say
+	Nodes uint64
+	Time  time.Duration
+
+	// How full the bot's transposition table is in permille, or 0 if it has none
+	HashFull int
+}
+
+// Returns the number of positions searched per second, or 0 if the time is not known
+func (info SearchInfo) NodesPerSecond() uint64 {
+	if info.Time <= 0 {
+		return 0
+	}
+
+	return uint64(float64(info.Nodes) / info.Time.Seconds())
+}
+
+// Called by a bot with the progress of its search, e.g. after each iteration of iterative
+// deepening. It may be called from the goroutine running Think, so must not block for long
+type SearchInfoCallback func(info SearchInfo)
+
+// Implemented by bots that can report the progress of their search while thinking
+type AnalysingBot interface {
+	Bot
+	SetSearchInfoCallback(callback SearchInfoCallback)
+}
+
+// Contribution of one term of a bot's evaluation, such as material or mobility, to each side's
+// score (pawns)
+type EvalTerm struct {
+	Name  string
+	White float64
+	Black float64
+}
+
+// Breakdown of a bot's evaluation of a position into its terms
+type EvalTrace struct {
+	Terms []EvalTerm
+
+	// Evaluation in pawns from white's perspective, or a mate score (see MateIn) for checkmate
+	// Unless the position is over, this is the sum of the terms' white scores less their black ones
+	Total float64
+}
+
+// Returns the trace as a table with a row for each term, giving each side's score and white's
+// advantage
+func (trace EvalTrace) String() string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "%-20v %7v %7v %7v\n", "term", "white", "black", "total")
+	for _, term := range trace.Terms {
+		fmt.Fprintf(&builder, "%-20v %7.2f %7.2f %+7.2f\n", term.Name, term.White, term.Black, term.White - term.Black)
+	}
+
+	if mateIn, ok := MateIn(trace.Total); ok {
+		fmt.Fprintf(&builder, "%-36v %7v", "total", fmt.Sprintf("#%v", mateIn))
+	} else {
+		fmt.Fprintf(&builder, "%-36v %+7.2f", "total", trace.Total)
+	}
+
+	return builder.String()
+}
+
+// Implemented by bots that can explain their evaluation of a position, term by term
+type EvalTracingBot interface {
+	Bot
+	EvaluateTrace(board *Board) EvalTrace
+}
+
+// Implemented by bots whose search depth can be limited (ply)
+type DepthLimitedBot interface {
+	Bot
+	SetMaxDepth(depth int)
+}
+
+// Implemented by bots whose search can be limited to a number of nodes per move, which makes games
+// between them reproducible on any machine
+type NodeLimitedBot interface {
+	Bot
+	SetMaxNodes(nodes uint64)
+}
+
+// Implemented by bots that keep search state, such as a transposition table or move ordering
+// history, from one move to the next
+type NewGameBot interface {
+	Bot
+
+	// Called before the first move of each game. Bots clear their search state here by default, so
+	// that games in a match are independent, but may be configured to keep it, which suits analysis
+	// of related positions
+	NewGame()
+
+	// Discard all search state
+	ClearHash()
+}
+
+// Tell the bot that a new game is starting, if it keeps search state between moves
+func StartNewGame(bot Bot) {
+	if newGameBot, ok := bot.(NewGameBot); ok {
+		newGameBot.NewGame()
+	}
+}
+
+// Implemented by bots that allocate large tables, such as a transposition table, for their search
+// Otherwise the tables are allocated by the first search, which under a fast time control can cost
+// a noticeable part of the time for the first move
+type WarmupBot interface {
+	Bot
+
+	// Allocate the tables at their configured sizes, if they have not been allocated already
+	Init()
+
+	// Allocate the tables and write to all of their memory, so that the operating system has
+	// mapped it in before the search needs it. This may discard the contents of the tables
+	Warmup()
+}
+
+// Prepare the bot's tables before the game starts, if it has any
+// The attack tables of the chess package are built when the program starts, so need no warm-up
+func WarmUp(bot Bot) {
+	if warmupBot, ok := bot.(WarmupBot); ok {
+		warmupBot.Warmup()
+	}
+}
+
+// Information identifying a bot, used by protocol front ends, game records and the GUI
+type BotInfo struct {
+	Name    string
+	Version string
+	Author  string
+	Options []BotOption
+}
+
+// Kinds of option a bot can expose, mirroring the UCI option types
+type BotOptionType uint8
+
+const (
+	CheckOption BotOptionType = iota
+	SpinOption
+	ComboOption
+	StringOption
+	ButtonOption
+)
+
+// Description of a configurable option exposed by a bot
+// Min and Max are only meaningful for spin options and Choices only for combo options
+type BotOption struct {
+	Name    string
+	Type    BotOptionType
+	Default string
+	Min     int
+	Max     int
+	Choices []string
+}
+
+// Returns the information identifying the bot
+// Bots that do not implement IdentifiableBot are named after their type
+func GetBotInfo(bot Bot) BotInfo {
+	if identifiableBot, ok := bot.(IdentifiableBot); ok {
+		return identifiableBot.Info()
+	}
+
+	typeName := strings.TrimPrefix(fmt.Sprintf("%T", bot), "*")
+	return BotInfo{Name: typeName}
+}
+
+// Returns the name of the bot followed by its version, if it has one
+func (info BotInfo) FullName() string {
+	if info.Version == "" {
+		return info.Name
+	} else {
+		return fmt.Sprintf("%v %v", info.Name, info.Version)
+	}
+}
+
+// Returns the UCI name of the option type
+func (optionType BotOptionType) String() string {
+	switch optionType {
+	case CheckOption:
+		return "check"
+	case SpinOption:
+		return "spin"
+	case ComboOption:
+		return "combo"
+	case StringOption:
+		return "string"
+	case ButtonOption:
+		return "button"
+	}
+
+	return "unknown"
+}
@@ -0,0 +1,39 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNodesPerSecond(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(uint64(40000), chess.SearchInfo{Nodes: 20000, Time: 500 * time.Millisecond}.NodesPerSecond())
+	assert.Equal(uint64(0), chess.SearchInfo{Nodes: 20000}.NodesPerSecond())
+}
+
+func TestEvalTraceString(t *testing.T) {
+	assert := assert.New(t)
+
+	trace := chess.EvalTrace{
+		Terms: []chess.EvalTerm{
+			{Name: "material", White: 39, Black: 38},
+			{Name: "mobility", White: 0.5, Black: 0.75},
+		},
+		Total: 0.75,
+	}
+
+	assert.Equal(
+		"term                   white   black   total\n" +
+		"material               39.00   38.00   +1.00\n" +
+		"mobility                0.50    0.75   -0.25\n" +
+		"total                                  +0.75",
+		trace.String(),
+	)
+
+	trace.Total = chess.MateInPly(3)
+	assert.True(strings.HasSuffix(trace.String(), "total                                     #2"))
+}
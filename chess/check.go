@@ -0,0 +1,148 @@
+package chess
+
+// How the side to move is in check, from the perspective of the last move made
+type CheckKind uint8
+
+const (
+	NoCheck CheckKind = iota
+
+	// The piece that moved gives check (including the rook after castling and the piece promoted to)
+	DirectCheck
+
+	// Moving the piece uncovered a check by a sliding piece behind it
+	DiscoveredCheck
+
+	// Two pieces give check, so the king must move
+	DoubleCheck
+)
+
+func (kind CheckKind) String() string {
+	switch kind {
+	case DirectCheck:
+		return "check"
+	case DiscoveredCheck:
+		return "discovered check"
+	case DoubleCheck:
+		return "double check"
+	default:
+		return "no check"
+	}
+}
+
+// Returns the bitboard of enemy pieces giving check to the king of the side to move
+func (board *Board) GetCheckers() Bitboard {
+	kingSquare := board.GetKingSquare(board.blackToMove)
+	return board.getCheckers(kingSquare, board.GetOccupiedBitboard())
+}
+
+// Returns the kind of check delivered by `move`, which must be the last move made on the board
+func (board *Board) GetCheckKind(move Move) CheckKind {
+	checkers := board.GetCheckers()
+
+	switch {
+	case checkers == EmptyBitboard:
+		return NoCheck
+
+	case checkers.PopCount() > 1:
+		return DoubleCheck
+
+	case checkers.LSB() == move.Destination:
+		return DirectCheck
+
+	case isCastlingRookCheck(move, checkers.LSB(), board.squareContents[uint32(move.Destination)].Kind):
+		return DirectCheck
+
+	default:
+		return DiscoveredCheck
+	}
+}
+
+// Returns whether `move`, which must be legal in the current position, gives check, without making
+// it. Castling and en passant captures, which move more than one piece, are made on a copy instead
+func (board *Board) GivesCheck(move Move) bool {
+	if board.IsCastlingMove(move) || board.IsEnPassantMove(move) {
+		afterMove := board.MakeMoveCopy(move)
+		return afterMove.IsCheck()
+	}
+
+	isBlack := board.blackToMove
+	enemyKings := board.pieceBitboards[sideIndex(!isBlack)][King]
+	if enemyKings == EmptyBitboard {
+		return false
+	}
+	kingSquare := enemyKings.LSB()
+
+	kind := board.squareContents[uint32(move.Source)].Kind
+	if move.IsPromotion {
+		kind = move.PromotedPiece
+	}
+
+	occupied := board.GetOccupiedBitboard().Unset(move.Source).Set(move.Destination)
+
+	// Direct check by the piece moved
+	if PieceAttacks(kind, move.Destination, isBlack, occupied).Get(kingSquare) {
+		return true
+	}
+
+	// Discovered check by a sliding piece the move uncovered
+	pieces := board.pieceBitboards[sideIndex(isBlack)]
+	diagonalSliders := (pieces[Bishop] | pieces[Queen]).Unset(move.Source)
+	orthogonalSliders := (pieces[Rook] | pieces[Queen]).Unset(move.Source)
+
+	return BishopAttacks(kingSquare, occupied) & diagonalSliders != EmptyBitboard ||
+		RookAttacks(kingSquare, occupied) & orthogonalSliders != EmptyBitboard
+}
+
+// Whether the checking piece is the rook that moved if `move` was castling
+func isCastlingRookCheck(move Move, checker Square, pieceMoved PieceKind) bool {
+	if pieceMoved != King || move.Source.File() != FileE || move.Source.Rank() != move.Destination.Rank() {
+		return false
+	}
+
+	switch move.Destination.File() {
+	case FileG:
+		return checker == SquareAt(FileF, move.Source.Rank())
+	case FileC:
+		return checker == SquareAt(FileD, move.Source.Rank())
+	default:
+		return false
+	}
+}
+
+// Information about the checks and pins against the king of the side to move, as used by legal
+// move generation
+type CheckInfo struct {
+	// Enemy pieces giving check
+	Checkers Bitboard
+
+	// Number of pieces giving check
+	CheckCount int
+
+	// Squares a piece could move to in order to block the check, which is empty unless there is a
+	// single check by a sliding piece that is not adjacent to the king
+	BlockSquares Bitboard
+
+	// Pieces of the side to move that are pinned to their king
+	Pinned Bitboard
+}
+
+// True if the side to move is in check
+func (info CheckInfo) IsCheck() bool {
+	return info.CheckCount > 0
+}
+
+// Returns information about the checks and pins against the king of the side to move
+func (board *Board) CheckInfo() (info CheckInfo) {
+	kingSquare := board.GetKingSquare(board.blackToMove)
+	allPiecesBitboard := board.GetOccupiedBitboard()
+
+	info.Checkers = board.getCheckers(kingSquare, allPiecesBitboard)
+	info.CheckCount = info.Checkers.PopCount()
+	info.Pinned = board.getPinMask(kingSquare, allPiecesBitboard) & board.sideBitboards[sideIndex(board.blackToMove)]
+
+	if info.CheckCount == 1 {
+		info.BlockSquares = BetweenBB(kingSquare, info.Checkers.LSB())
+	}
+
+	return
+}
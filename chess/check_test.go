@@ -0,0 +1,109 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func checkKindAfterMove(t *testing.T, fen string, uci string) chess.CheckKind {
+	board, err := chess.LoadFen(fen)
+	assert.Nil(t, err)
+
+	move, err := chess.MoveWithUciNotation(uci)
+	assert.Nil(t, err)
+
+	board.MakeMove(move)
+	return board.GetCheckKind(move)
+}
+
+func TestCheckKind(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(chess.NoCheck, checkKindAfterMove(t, chess.StartingPositionFen, "e2e4"))
+
+	// Rook gives check directly
+	assert.Equal(chess.DirectCheck, checkKindAfterMove(t, "4k3/8/8/8/8/8/8/R3K3 w Q - 0 1", "a1a8"))
+
+	// Bishop moves off the file of the rook
+	assert.Equal(chess.DiscoveredCheck, checkKindAfterMove(t, "4k3/8/8/8/4B3/8/8/4RK2 w - - 0 1", "e4b1"))
+
+	// Knight moves off the file of the rook and gives check itself
+	assert.Equal(chess.DoubleCheck, checkKindAfterMove(t, "4k3/8/8/8/4N3/8/8/4RK2 w - - 0 1", "e4d6"))
+
+	// Rook gives check after castling
+	assert.Equal(chess.DirectCheck, checkKindAfterMove(t, "5k2/8/8/8/8/8/8/4K2R w K - 0 1", "e1g1"))
+
+	// Promoted piece gives check
+	assert.Equal(chess.DirectCheck, checkKindAfterMove(t, "7k/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8q"))
+
+	// En passant capture uncovers the rook
+	assert.Equal(chess.DiscoveredCheck, checkKindAfterMove(t, "1k6/8/8/1Pp5/8/8/8/1R2K3 w - c6 0 1", "b5c6"))
+}
+
+func TestGivesCheck(t *testing.T) {
+	positions := []string{
+		chess.StartingPositionFen,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"4k3/8/8/8/4N3/8/8/4RK2 w - - 0 1",
+		"5k2/8/8/8/8/8/8/4K2R w K - 0 1",
+		"7k/1P6/8/8/8/8/8/4K3 w - - 0 1",
+		"1k6/8/8/1Pp5/8/8/8/1R2K3 w - c6 0 1",
+		"r3k2r/8/8/3Pp3/8/8/2B5/R3K2R w KQkq e6 0 1",
+	}
+
+	// Agrees with making each move and looking for check
+	for _, fen := range positions {
+		board, err := chess.LoadFen(fen)
+		assert.Nil(t, err)
+
+		for _, move := range board.GetLegalMoves(false) {
+			afterMove := board.MakeMoveCopy(move)
+			assert.Equal(t, afterMove.IsCheck(), board.GivesCheck(move), "%v in %v", move, fen)
+		}
+	}
+}
+
+func TestGetCheckers(t *testing.T) {
+	board, err := chess.LoadFen("4k3/8/3N4/8/8/8/8/4RK2 b - - 0 1")
+	assert.Nil(t, err)
+
+	assert.Equal(t, chess.BitboardFromSquares(chess.D6, chess.E1), board.GetCheckers())
+	assert.Equal(t, "double check", chess.DoubleCheck.String())
+}
+
+func TestCheckInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	// Single check by a rook, with the knight on d7 pinned by the bishop
+	board, err := chess.LoadFen("4k3/3n4/8/1B6/8/8/8/4RK2 b - - 0 1")
+	assert.Nil(err)
+
+	info := board.CheckInfo()
+	assert.True(info.IsCheck())
+	assert.Equal(1, info.CheckCount)
+	assert.Equal(chess.BitboardFromSquares(chess.E1), info.Checkers)
+	assert.Equal(chess.BitboardFromSquares(chess.E7, chess.E6, chess.E5, chess.E4, chess.E3, chess.E2), info.BlockSquares)
+	assert.Equal(chess.BitboardFromSquares(chess.D7), info.Pinned)
+
+	// A knight's check cannot be blocked
+	board, err = chess.LoadFen("4k3/8/3N4/8/8/8/8/5K2 b - - 0 1")
+	assert.Nil(err)
+
+	info = board.CheckInfo()
+	assert.Equal(1, info.CheckCount)
+	assert.Equal(chess.EmptyBitboard, info.BlockSquares)
+
+	// Double check
+	board, err = chess.LoadFen("4k3/8/3N4/8/8/8/8/4RK2 b - - 0 1")
+	assert.Nil(err)
+
+	info = board.CheckInfo()
+	assert.Equal(2, info.CheckCount)
+	assert.Equal(chess.EmptyBitboard, info.BlockSquares)
+
+	// No check
+	board, err = chess.LoadFen(chess.StartingPositionFen)
+	assert.Nil(err)
+	assert.Equal(chess.CheckInfo{}, board.CheckInfo())
+}
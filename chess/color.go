@@ -0,0 +1,62 @@
+package chess
+
+// Colour of a player's pieces
+type Color uint8
+
+const (
+	White Color = iota
+	Black
+)
+
+// Returns Black if `isBlack` is true and White otherwise, for converting from the bool-based API
+func ColorWithIsBlack(isBlack bool) Color {
+	return Color(sideIndex(isBlack))
+}
+
+// Returns the opponent's colour
+func (color Color) Other() Color {
+	return color ^ 1
+}
+
+func (color Color) IsBlack() bool {
+	return color == Black
+}
+
+func (color Color) String() string {
+	if color == Black {
+		return "black"
+	} else {
+		return "white"
+	}
+}
+
+// Returns the colour of the piece
+func (piece Piece) Color() Color {
+	return ColorWithIsBlack(piece.IsBlack)
+}
+
+// Returns the colour of the side to move
+func (board *Board) SideToMove() Color {
+	return ColorWithIsBlack(board.blackToMove)
+}
+
+// Returns a list of the pieces of the given colour, ordered by square
+func (board *Board) GetPiecesForColor(color Color) []Piece {
+	sideBitboard := board.sideBitboards[color]
+	pieces := make([]Piece, 0, sideBitboard.PopCount())
+
+	for v := sideBitboard; v != EmptyBitboard; v = v.ClearLSB() {
+		pieces = append(pieces, board.squareContents[v.LSB()])
+	}
+
+	return pieces
+}
+
+// Returns whether the side with the given colour may still castle kingside and queenside
+func (board *Board) GetCastlingRightsForColor(color Color) (kingside bool, queenside bool) {
+	if color == Black {
+		return board.castlingRights.BlackKingside, board.castlingRights.BlackQueenside
+	} else {
+		return board.castlingRights.WhiteKingside, board.castlingRights.WhiteQueenside
+	}
+}
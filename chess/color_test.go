@@ -0,0 +1,36 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func TestColor(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(chess.Black, chess.White.Other())
+	assert.Equal(chess.White, chess.Black.Other())
+	assert.Equal(chess.Black, chess.ColorWithIsBlack(true))
+	assert.True(chess.Black.IsBlack())
+	assert.Equal("white", chess.White.String())
+
+	board, err := chess.LoadFen("r3k3/8/8/8/8/8/8/4K2R b Kq - 0 1")
+	assert.Nil(err)
+	assert.Equal(chess.Black, board.SideToMove())
+
+	kingside, queenside := board.GetCastlingRightsForColor(chess.White)
+	assert.True(kingside)
+	assert.False(queenside)
+
+	kingside, queenside = board.GetCastlingRightsForColor(chess.Black)
+	assert.False(kingside)
+	assert.True(queenside)
+
+	pieces := board.GetPiecesForColor(chess.Black)
+	assert.Len(pieces, 2)
+	for _, piece := range pieces {
+		assert.Equal(chess.Black, piece.Color())
+	}
+	assert.Equal(board.GetPiecesForSide(false), board.GetPiecesForColor(chess.White))
+}
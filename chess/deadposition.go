@@ -0,0 +1,87 @@
+package chess
+
+// True if neither side can checkmate the other by any sequence of legal moves, so the game is
+// drawn. This recognises insufficient material (lone kings, a single minor piece, or bishops that
+// are all on squares of the same colour) and fully locked pawn structures that neither king can
+// break into. Other dead positions are not detected
+func (board *Board) IsDeadPosition() bool {
+	return board.hasInsufficientMaterial() || board.isLockedPawnPosition()
+}
+
+func (board *Board) hasInsufficientMaterial() bool {
+	var minorPieces, bishops Bitboard
+	for side := range board.pieceBitboards {
+		pieces := &board.pieceBitboards[side]
+
+		if pieces[Queen] | pieces[Rook] | pieces[Pawn] != EmptyBitboard {
+			return false
+		}
+
+		minorPieces |= pieces[Knight] | pieces[Bishop]
+		bishops |= pieces[Bishop]
+	}
+
+	// King against king and at most one minor piece
+	if minorPieces.PopCount() <= 1 {
+		return true
+	}
+
+	// Bishops on one colour can never attack a king on the other
+	return minorPieces == bishops &&
+		(bishops & LightSquaresBitboard == EmptyBitboard || bishops & DarkSquaresBitboard == EmptyBitboard)
+}
+
+// True if there are only kings and pawns, every pawn is blocked by an enemy pawn with nothing to
+// capture, and neither king can reach an enemy pawn that is not defended by another pawn. Then no
+// pawn can ever move, and as pawns give the only checks, no king can be checkmated
+func (board *Board) isLockedPawnPosition() bool {
+	whitePawns := board.pieceBitboards[0][Pawn]
+	blackPawns := board.pieceBitboards[1][Pawn]
+
+	if whitePawns == EmptyBitboard {
+		return false
+	}
+
+	for side := range board.pieceBitboards {
+		for kind, pieces := range board.pieceBitboards[side] {
+			if PieceKind(kind) != King && PieceKind(kind) != Pawn && pieces != EmptyBitboard {
+				return false
+			}
+		}
+	}
+
+	// Every pawn must be blocked by an enemy pawn
+	if whitePawns.Shift(North) != blackPawns {
+		return false
+	}
+
+	whitePawnAttacks := whitePawns.Shift(NorthEast) | whitePawns.Shift(NorthWest)
+	blackPawnAttacks := blackPawns.Shift(SouthEast) | blackPawns.Shift(SouthWest)
+
+	if whitePawnAttacks & blackPawns != EmptyBitboard || blackPawnAttacks & whitePawns != EmptyBitboard {
+		return false
+	}
+
+	whiteRegion := kingRegion(board.GetKingSquare(false), ^whitePawns & ^blackPawnAttacks)
+	blackRegion := kingRegion(board.GetKingSquare(true), ^blackPawns & ^whitePawnAttacks)
+
+	return whiteRegion & blackPawns == EmptyBitboard && blackRegion & whitePawns == EmptyBitboard
+}
+
+// Returns the squares a king starting on `sq` can walk to while only stepping on `passable` squares
+func kingRegion(sq Square, passable Bitboard) Bitboard {
+	region := EmptyBitboard.Set(sq)
+
+	for {
+		expanded := region
+		for direction := North; direction <= SouthWest; direction++ {
+			expanded |= region.Shift(direction) & passable
+		}
+
+		if expanded == region {
+			return region
+		}
+
+		region = expanded
+	}
+}
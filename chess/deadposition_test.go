@@ -0,0 +1,50 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func TestDeadPosition(t *testing.T) {
+	assert := assert.New(t)
+
+	dead := []string{
+		"4k3/8/8/8/8/8/8/4K3 w - - 0 1",
+		"4k3/8/8/8/8/8/8/4KN2 w - - 0 1",
+		"4kb2/8/8/8/8/8/8/2B1K3 w - - 0 1",
+
+		// Locked pawn chain with the kings on either side
+		"8/8/1k6/p1p1p1p1/P1P1P1P1/8/4K3/8 w - - 0 1",
+		"4k3/8/8/1p1p1p1p/pPpPpPpP/P1P1P1P1/8/4K3 b - - 0 1",
+	}
+
+	alive := []string{
+		chess.StartingPositionFen,
+		"4k3/8/8/8/8/8/8/3BKN2 w - - 0 1",
+		"4kb2/8/8/8/8/8/8/3BK3 w - - 0 1",
+		"4k3/8/8/8/8/8/4P3/4K3 w - - 0 1",
+
+		// The white king is on the same side of the chain as the black pawns and can capture them
+		"7K/8/1k6/p1p1p1p1/P1P1P1P1/8/8/8 w - - 0 1",
+
+		// A pawn can capture
+		"4k3/8/8/p2p4/P1pP4/2P5/8/4K3 w - - 0 1",
+
+		// A pawn is free to advance
+		"8/8/1k6/p1p1p1p1/P1P1P1P1/8/7P/4K3 w - - 0 1",
+	}
+
+	for _, fen := range dead {
+		board, err := chess.LoadFen(fen)
+		assert.Nil(err)
+		assert.True(board.IsDeadPosition(), fen)
+		assert.Equal(chess.Outcome{Result: chess.Draw, Termination: chess.DeadPosition}, board.Outcome(), fen)
+	}
+
+	for _, fen := range alive {
+		board, err := chess.LoadFen(fen)
+		assert.Nil(err)
+		assert.False(board.IsDeadPosition(), fen)
+	}
+}
@@ -0,0 +1,104 @@
+package chess
+
+import (
+	"gogm/chess/internal/slider"
+)
+
+// Shims for the low-level move generation helpers that were exported before the sliding attack
+// tables moved to internal/slider. They are kept so that code written against earlier versions
+// still builds
+
+// Attack sets of one kind of sliding piece, indexed by square and occupancy
+//
+// Deprecated: use BishopAttacks and RookAttacks
+type SlidingAttackTable struct {
+	table slider.Table
+}
+
+// Returns the attack set of a piece on `sq` when the squares in `allPiecesBitboard` are occupied
+//
+// Deprecated: use BishopAttacks and RookAttacks
+func (table SlidingAttackTable) GetAttackSet(sq Square, allPiecesBitboard Bitboard) Bitboard {
+	return Bitboard(table.table.Attacks(int(sq), uint64(allPiecesBitboard)))
+}
+
+// Deprecated: use BishopAttacks and RookAttacks, or the magic package to build tables from other
+// magic numbers
+func CreateSlidingAttackTable(
+	magics                 [64]Bitboard,
+	relevantBits           [64]uint,
+	relevantOccupancyMasks [64]Bitboard,
+	attackSetGenerator     func(Square, Bitboard) Bitboard,
+) SlidingAttackTable {
+	var rawMagics, rawMasks [64]uint64
+	for sq := 0; sq < 64; sq++ {
+		rawMagics[sq] = uint64(magics[sq])
+		rawMasks[sq] = uint64(relevantOccupancyMasks[sq])
+	}
+
+	return SlidingAttackTable{slider.NewTable(rawMagics, relevantBits, rawMasks, rawAttacks(attackSetGenerator))}
+}
+
+// Deprecated: use BishopAttacks
+func CreateBishopAttackTable() SlidingAttackTable {
+	return SlidingAttackTable{bishopAttackTable}
+}
+
+// Deprecated: use RookAttacks
+func CreateRookAttackTable() SlidingAttackTable {
+	return SlidingAttackTable{rookAttackTable}
+}
+
+// Returns the magic numbers, relevant bit counts and relevant occupancy masks used to index the
+// attack tables of the given kind of sliding piece, which must be Bishop or Rook
+//
+// Deprecated: use magic.BuiltinTable
+func SlidingPieceMagics(kind PieceKind) (magics [64]Bitboard, relevantBits [64]uint, relevantOccupancyMasks [64]Bitboard) {
+	rawMagics, relevantBits, rawMasks := slider.BishopMagics, slider.BishopRelevantBits, slider.BishopRelevantOccupancyMasks
+	if kind == Rook {
+		rawMagics, relevantBits, rawMasks = slider.RookMagics, slider.RookRelevantBits, slider.RookRelevantOccupancyMasks
+	}
+
+	for sq := 0; sq < 64; sq++ {
+		magics[sq] = Bitboard(rawMagics[sq])
+		relevantOccupancyMasks[sq] = Bitboard(rawMasks[sq])
+	}
+
+	return
+}
+
+// Returns the squares a pawn on `sq` can move to, given the king square of its side for en passant
+// pins. Other pins are not taken into account
+//
+// Deprecated: use GetLegalMovesFromSquare
+func PawnMoveSet(
+	sq                     Square,
+	isBlack                bool,
+	friendlyPiecesBitboard Bitboard,
+	enemyPiecesBitboard    Bitboard,
+	kingSquare             Square,
+	board                  *Board,
+) (result Bitboard) {
+	allPiecesBitboard := friendlyPiecesBitboard | enemyPiecesBitboard
+
+	result = pawnPushSet(sq, isBlack, allPiecesBitboard)
+
+	// Captures
+	attackSetBitboard := pawnAttackSet(sq, isBlack)
+	result |= attackSetBitboard & enemyPiecesBitboard
+
+	if board.hasEnPassantTarget && attackSetBitboard.Get(board.enPassantTarget) {
+		// Handle the annoying en passant pin, when the capturing pawn and the captured pawn
+		// are the only two pieces blocking an attack against the king
+		if board.isEnPassantLegal(sq, kingSquare) {
+			result = result.Set(board.enPassantTarget)
+		}
+	}
+
+	return
+}
+
+// Deprecated: use PieceAttacks
+func GetPieceAttackSet(piece Piece, allPiecesBitboard Bitboard, board *Board) Bitboard {
+	return board.getAttackSet(piece.Kind, piece.Square, piece.IsBlack, allPiecesBitboard)
+}
@@ -0,0 +1,42 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func TestDeprecatedAttackTables(t *testing.T) {
+	assert := assert.New(t)
+
+	bishopTable := chess.CreateBishopAttackTable()
+	rookTable := chess.CreateRookAttackTable()
+
+	magics, relevantBits, relevantOccupancyMasks := chess.SlidingPieceMagics(chess.Rook)
+	rebuiltRookTable := chess.CreateSlidingAttackTable(magics, relevantBits, relevantOccupancyMasks, chess.RookAttacks)
+
+	occupied := chess.BitboardFromSquares(chess.D4, chess.D7, chess.G4, chess.F6, chess.B2, chess.H8)
+
+	for sq := chess.Square(0); sq < 64; sq++ {
+		assert.Equal(chess.BishopAttacks(sq, occupied), bishopTable.GetAttackSet(sq, occupied))
+		assert.Equal(chess.RookAttacks(sq, occupied), rookTable.GetAttackSet(sq, occupied))
+		assert.Equal(chess.RookAttacks(sq, occupied), rebuiltRookTable.GetAttackSet(sq, occupied))
+	}
+
+	// As before the tables were deprecated, attack sets can be looked up in a table that is not
+	// addressable
+	assert.Equal(chess.BishopAttacks(chess.E4, occupied), chess.CreateBishopAttackTable().GetAttackSet(chess.E4, occupied))
+}
+
+func TestDeprecatedPawnMoveSet(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1")
+	assert.NoError(err)
+
+	friendly := chess.BitboardFromSquares(chess.E5, chess.E1)
+	enemy := chess.BitboardFromSquares(chess.D5, chess.E8)
+
+	assert.Equal(chess.BitboardFromSquares(chess.E6, chess.D6), chess.PawnMoveSet(chess.E5, false, friendly, enemy, chess.E1, board))
+	assert.Equal(chess.PawnAttacks(chess.D5, true), chess.GetPieceAttackSet(chess.Piece{Kind: chess.Pawn, Square: chess.D5, IsBlack: true}, friendly | enemy, board))
+}
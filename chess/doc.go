@@ -0,0 +1,18 @@
+// Package chess implements the rules of chess and its variants: board representation, legal move
+// generation, game outcomes, notation and perft
+//
+// # Stable API
+//
+// The following exported API is only added to, so that code using it keeps building as the package
+// changes:
+//   - Board, Square, Bitboard, Piece and Move, with loading and saving positions as FEN and EPD
+//   - legal move generation and the precomputed attack sets (attacks.go)
+//   - Game, with PGN reading and writing
+//   - notation: SAN, with localized piece letters, and UCI moves
+//   - Rules, Outcome and the variants
+//   - Perft and Divide
+//
+// Names marked Deprecated keep working for code written against them. The implementation of move
+// generation, such as the layout of the sliding attack tables, lives under internal/ and may change
+// at any time
+package chess
@@ -0,0 +1,256 @@
+package chess
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A position from an Extended Position Description record along with its operations, such as
+// bm (best moves), am (avoid moves), id (identifier) and dm (direct mate in n moves)
+// https://www.chessprogramming.org/Extended_Position_Description
+type EpdRecord struct {
+	Board      *Board
+	Operations map[string][]string
+}
+
+// Parse a single EPD record, e.g.
+// 2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001";
+// Quoted operands have their quotes removed
+func ParseEpd(line string) (record EpdRecord, err error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return record, errors.New(fmt.Sprintf("EPD record must start with four position fields: %v", line))
+	}
+
+	record.Board, err = LoadFen(strings.Join(fields[:4], " "))
+	if err != nil {
+		return record, err
+	}
+
+	// Skip past the position fields, which contain no spaces
+	rest := strings.TrimSpace(line)
+	for i := 0; i < 4; i++ {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		rest = rest[strings.IndexFunc(rest + " ", unicode.IsSpace):]
+	}
+
+	record.Operations, err = parseEpdOperations(rest)
+	return record, err
+}
+
+// Read the EPD records of a file, one per line, ignoring blank lines and lines starting with #
+func ReadEpdFile(path string) ([]EpdRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var records []EpdRecord
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		record, err := ParseEpd(line)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("%v:%v: %v", path, lineNumber, err))
+		}
+
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+// Parse the operations of an EPD record, each an opcode followed by operands and terminated by
+// a semicolon. Operands are separated by spaces, and strings containing spaces or semicolons are
+// enclosed in double quotes
+func parseEpdOperations(text string) (map[string][]string, error) {
+	operations := make(map[string][]string)
+
+	var tokens []string
+	var token strings.Builder
+	hasToken := false
+	inQuotes := false
+
+	endToken := func() {
+		if hasToken {
+			tokens = append(tokens, token.String())
+			token.Reset()
+			hasToken = false
+		}
+	}
+
+	for _, char := range text {
+		switch {
+		case inQuotes && char == '"':
+			inQuotes = false
+
+		case inQuotes:
+			token.WriteRune(char)
+
+		case char == '"':
+			inQuotes = true
+			hasToken = true
+
+		case char == ';':
+			endToken()
+			if len(tokens) > 0 {
+				operations[tokens[0]] = tokens[1:]
+			}
+			tokens = nil
+
+		case unicode.IsSpace(char):
+			endToken()
+
+		default:
+			token.WriteRune(char)
+			hasToken = true
+		}
+	}
+
+	if inQuotes {
+		return nil, errors.New("unterminated string in EPD operations")
+	}
+
+	endToken()
+	if len(tokens) > 0 {
+		return nil, errors.New(fmt.Sprintf("EPD operation %v is not terminated by a semicolon", tokens[0]))
+	}
+
+	return operations, nil
+}
+
+// Returns the record in EPD format, with the operations sorted by opcode
+func (record EpdRecord) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(record.Board.epdPosition())
+
+	opcodes := make([]string, 0, len(record.Operations))
+	for opcode := range record.Operations {
+		opcodes = append(opcodes, opcode)
+	}
+	sort.Strings(opcodes)
+
+	for _, opcode := range opcodes {
+		sb.WriteRune(' ')
+		sb.WriteString(opcode)
+
+		for _, operand := range record.Operations[opcode] {
+			sb.WriteRune(' ')
+
+			if operand == "" || strings.ContainsAny(operand, " \t;\"") {
+				sb.WriteString(strconv.Quote(operand))
+			} else {
+				sb.WriteString(operand)
+			}
+		}
+
+		sb.WriteRune(';')
+	}
+
+	return sb.String()
+}
+
+// Returns the identifier given by the id operation, if there is one
+func (record EpdRecord) ID() string {
+	if operands := record.Operations["id"]; len(operands) > 0 {
+		return operands[0]
+	}
+
+	return ""
+}
+
+// Returns the best moves given by the bm operation
+func (record EpdRecord) BestMoves() ([]Move, error) {
+	return record.moveOperands("bm")
+}
+
+// Returns the moves to avoid given by the am operation
+func (record EpdRecord) AvoidMoves() ([]Move, error) {
+	return record.moveOperands("am")
+}
+
+// Returns the number of moves to mate given by the dm operation, and whether there is one
+func (record EpdRecord) DirectMate() (int, bool) {
+	operands := record.Operations["dm"]
+	if len(operands) == 0 {
+		return 0, false
+	}
+
+	moves, err := strconv.Atoi(operands[0])
+	return moves, err == nil
+}
+
+// Returns whether the move is one of the record's best moves, if it has any, and none of its moves
+// to avoid. Records with neither a bm nor an am operation, or whose moves cannot be parsed, are never
+// solved
+func (record EpdRecord) IsSolution(move Move) bool {
+	bestMoves, err := record.BestMoves()
+	if err != nil {
+		return false
+	}
+
+	avoidMoves, err := record.AvoidMoves()
+	if err != nil {
+		return false
+	}
+
+	for _, avoidMove := range avoidMoves {
+		if move == avoidMove {
+			return false
+		}
+	}
+
+	if len(bestMoves) == 0 {
+		return len(avoidMoves) > 0
+	}
+
+	for _, bestMove := range bestMoves {
+		if move == bestMove {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the operands of the operation parsed as moves in SAN
+func (record EpdRecord) moveOperands(opcode string) ([]Move, error) {
+	var moves []Move
+
+	for _, operand := range record.Operations[opcode] {
+		move, err := record.Board.MoveWithSan(operand)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("bad %v operand in EPD record: %v", opcode, err))
+		}
+
+		moves = append(moves, move)
+	}
+
+	return moves, nil
+}
+
+// Returns the four position fields of an EPD record: piece placement, side to move, castling
+// rights and en passant target
+func (board *Board) epdPosition() string {
+	if board.hasEnPassantTarget {
+		return board.Fen() + " " + board.enPassantTarget.String()
+	} else {
+		return board.Fen() + " -"
+	}
+}
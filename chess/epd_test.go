@@ -0,0 +1,103 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseEpd(t *testing.T) {
+	assert := assert.New(t)
+
+	record, err := chess.ParseEpd(`2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001";`)
+	assert.Nil(err)
+
+	assert.Equal("WAC.001", record.ID())
+	assert.Equal("2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w -", record.Board.Fen())
+
+	bestMoves, err := record.BestMoves()
+	assert.Nil(err)
+	assert.Equal([]chess.Move{{Source: chess.G3, Destination: chess.G6}}, bestMoves)
+
+	avoidMoves, err := record.AvoidMoves()
+	assert.Nil(err)
+	assert.Empty(avoidMoves)
+
+	_, hasDirectMate := record.DirectMate()
+	assert.False(hasDirectMate)
+
+	assert.Equal(`2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id WAC.001;`, record.String())
+}
+
+func TestEpdOperations(t *testing.T) {
+	assert := assert.New(t)
+
+	record, err := chess.ParseEpd(`rnbqkbnr/ppp1pppp/8/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq d6 am Qh5 Ke2; dm 12; c0 "a; quoted comment";`)
+	assert.Nil(err)
+
+	assert.Equal([]string{"Qh5", "Ke2"}, record.Operations["am"])
+	assert.Equal([]string{"a; quoted comment"}, record.Operations["c0"])
+
+	mateIn, hasDirectMate := record.DirectMate()
+	assert.True(hasDirectMate)
+	assert.Equal(12, mateIn)
+
+	// The en passant target is parsed
+	enPassant, err := record.Board.MoveWithSan("exd6")
+	assert.Nil(err)
+	assert.Equal(chess.Move{Source: chess.E5, Destination: chess.D6}, enPassant)
+
+	reparsed, err := chess.ParseEpd(record.String())
+	assert.Nil(err)
+	assert.Equal(record.Operations, reparsed.Operations)
+	assert.Equal(record.String(), reparsed.String())
+
+	_, err = chess.ParseEpd(`8/8/8/8/8/8/8/8 w - - bm Qg6`)
+	assert.NotNil(err)
+}
+
+func TestEpdIsSolution(t *testing.T) {
+	assert := assert.New(t)
+
+	record, _ := chess.ParseEpd(`2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6;`)
+	assert.True(record.IsSolution(chess.Move{Source: chess.G3, Destination: chess.G6}))
+	assert.False(record.IsSolution(chess.Move{Source: chess.G3, Destination: chess.H3}))
+
+	record, _ = chess.ParseEpd(`rnbqkbnr/ppp1pppp/8/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq d6 am Qh5 Ke2;`)
+	assert.False(record.IsSolution(chess.Move{Source: chess.D1, Destination: chess.H5}))
+	assert.True(record.IsSolution(chess.Move{Source: chess.E5, Destination: chess.D6}))
+
+	// Neither bm nor am
+	record, _ = chess.ParseEpd(`rnbqkbnr/ppp1pppp/8/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq d6 id "none";`)
+	assert.False(record.IsSolution(chess.Move{Source: chess.E5, Destination: chess.D6}))
+
+	// Unparseable best move
+	record, _ = chess.ParseEpd(`rnbqkbnr/ppp1pppp/8/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq d6 bm Qh6;`)
+	assert.False(record.IsSolution(chess.Move{Source: chess.E5, Destination: chess.D6}))
+}
+
+func TestReadEpdFile(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "suite.epd")
+	contents := "# comment\n\n" +
+		`2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001";` + "\n" +
+		`rnbqkbnr/ppp1pppp/8/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq d6 am Qh5; id "second";` + "\n"
+	assert.Nil(os.WriteFile(path, []byte(contents), 0644))
+
+	records, err := chess.ReadEpdFile(path)
+	assert.Nil(err)
+	assert.Len(records, 2)
+	assert.Equal("WAC.001", records[0].ID())
+	assert.Equal("second", records[1].ID())
+
+	// Errors give the line number
+	assert.Nil(os.WriteFile(path, []byte("# comment\n8/8/8/8/8/8/8/8 w - - bm Qg6\n"), 0644))
+	_, err = chess.ReadEpdFile(path)
+	assert.ErrorContains(err, "suite.epd:2:")
+
+	_, err = chess.ReadEpdFile(filepath.Join(t.TempDir(), "missing.epd"))
+	assert.NotNil(err)
+}
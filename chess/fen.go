@@ -3,6 +3,7 @@ package chess
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"unicode"
 )
@@ -14,6 +15,9 @@ func LoadFen(fen string) (*Board, error) {
 	board := NewBoard()
 
 	fields := strings.Split(fen, " ")
+	if len(fields) < 3 {
+		return nil, errors.New(fmt.Sprintf("FEN must have at least three fields: %v", fen))
+	}
 
 	// Piece placement
 	rankIndex := 0
@@ -61,11 +65,47 @@ func LoadFen(fen string) (*Board, error) {
 	board.castlingRights.WhiteQueenside = strings.ContainsRune(fields[2], 'Q')
 	board.castlingRights.BlackQueenside = strings.ContainsRune(fields[2], 'q')
 
-	// TODO: en passant target, halfmove clock, fullmove number
+	// En passant target
+	if len(fields) > 3 && fields[3] != "-" {
+		enPassantTarget, err := SquareWithAlgebraicName(fields[3])
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("bad en passant target: %v", fields[3]))
+		}
+
+		board.hasEnPassantTarget = true
+		board.enPassantTarget = enPassantTarget
+	}
+
+	// Halfmove clock
+	if len(fields) > 4 {
+		halfmoveClock, err := strconv.Atoi(fields[4])
+		if err != nil || halfmoveClock < 0 {
+			return nil, errors.New(fmt.Sprintf("bad halfmove clock: %v", fields[4]))
+		}
+
+		board.halfmoveClock = halfmoveClock
+	}
+
+	// TODO: fullmove number
+
+	// The pieces were hashed as they were placed
+	board.hash ^= board.stateKey()
 
 	return &board, nil
 }
 
+// Returns the FEN of the position with all six fields, including the en passant target and the
+// halfmove clock that Fen leaves out. The board does not count moves, so the fullmove number is
+// given
+func (board *Board) FullFen(fullmoveNumber int) string {
+	enPassantTarget := "-"
+	if board.hasEnPassantTarget {
+		enPassantTarget = board.enPassantTarget.String()
+	}
+
+	return fmt.Sprintf("%v %v %v %v", board.Fen(), enPassantTarget, board.halfmoveClock, fullmoveNumber)
+}
+
 func (board *Board) Fen() string {
 	// Piece placement
 	var sb strings.Builder
@@ -0,0 +1,40 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func TestLoadFenErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, fen := range []string{
+		"",
+		"foo",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNX w KQkq - 0 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq z9 0 1",
+	} {
+		_, err := chess.LoadFen(fen)
+		assert.NotNil(err, fen)
+	}
+}
+
+func TestFullFen(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, test := range []struct {
+		fen            string
+		fullmoveNumber int
+	}{
+		{chess.StartingPositionFen, 1},
+		{"rnbqkbnr/ppp1pppp/8/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 3", 3},
+		{"8/8/4k3/8/8/4K3/4P3/8 b - - 12 60", 60},
+	} {
+		board, err := chess.LoadFen(test.fen)
+		assert.Nil(err)
+		assert.Equal(test.fen, board.FullFen(test.fullmoveNumber))
+	}
+}
@@ -0,0 +1,216 @@
+package chess
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A game of chess: the tags describing it, the moves played and the alternatives to them
+type Game struct {
+	// Tags in the order they are written in PGN, e.g. Event, White, Black
+	Tags []GameTag
+
+	// Comment before the first move
+	Comment string
+
+	// Moves of the main line, starting from the position given by StartingBoard
+	Moves []GameMove
+
+	Result Result
+}
+
+// A name and value describing a game, written in PGN as [Name "Value"]
+type GameTag struct {
+	Name  string
+	Value string
+}
+
+// A move of a game, with its annotations and the lines that could have been played instead
+type GameMove struct {
+	Move Move
+
+	// Comment after the move
+	Comment string
+
+	// Numeric annotation glyphs, e.g. 2 for a mistake (?) or 4 for a blunder (??)
+	Nags []int
+
+	// Alternatives to the move, each a line starting from the position before it
+	Variations [][]GameMove
+}
+
+// Numeric annotation glyphs for the traditional move annotations
+const (
+	NagGoodMove        int = 1 // !
+	NagMistake         int = 2 // ?
+	NagBrilliantMove   int = 3 // !!
+	NagBlunder         int = 4 // ??
+	NagInterestingMove int = 5 // !?
+	NagDubiousMove     int = 6 // ?!
+)
+
+// Tags every PGN game must have, in the order they must come first, and the values written when
+// they are not known
+var sevenTagRoster = []GameTag{
+	{Name: "Event", Value: "?"},
+	{Name: "Site", Value: "?"},
+	{Name: "Date", Value: "????.??.??"},
+	{Name: "Round", Value: "?"},
+	{Name: "White", Value: "?"},
+	{Name: "Black", Value: "?"},
+	{Name: "Result", Value: "*"},
+}
+
+// Returns a game with no moves starting from the position on the board, with the SetUp and FEN tags
+// set if it is not the standard starting position
+func NewGame(board *Board) *Game {
+	game := &Game{}
+
+	startingBoard, _ := LoadFen(StartingPositionFen)
+	if board.Hash() != startingBoard.Hash() {
+		game.SetTag("SetUp", "1")
+		game.SetTag("FEN", pgnFen(board))
+	}
+
+	return game
+}
+
+// Returns the value of the tag with the given name, or "" if the game does not have the tag
+func (game *Game) Tag(name string) string {
+	for _, tag := range game.Tags {
+		if tag.Name == name {
+			return tag.Value
+		}
+	}
+
+	return ""
+}
+
+// Set the value of the tag with the given name, adding it after the existing tags if the game does
+// not have it
+func (game *Game) SetTag(name string, value string) {
+	for index := range game.Tags {
+		if game.Tags[index].Name == name {
+			game.Tags[index].Value = value
+			return
+		}
+	}
+
+	game.Tags = append(game.Tags, GameTag{Name: name, Value: value})
+}
+
+// Set the White and Black tags to the names of the bots, and the WhiteType and BlackType tags to
+// whether each side was played by a bot ("program") or by a person ("human"). A nil bot is a person,
+// whose name is left as it is
+func (game *Game) SetPlayers(whiteBot Bot, blackBot Bot) {
+	for _, player := range []struct {
+		color string
+		bot   Bot
+	}{{"White", whiteBot}, {"Black", blackBot}} {
+		if player.bot == nil {
+			game.SetTag(player.color + "Type", "human")
+			continue
+		}
+
+		game.SetTag(player.color, GetBotInfo(player.bot).FullName())
+		game.SetTag(player.color + "Type", "program")
+	}
+}
+
+// Fill in the tags that can be worked out from the game itself, so that databases accept it as it
+// is: the result and how the game ended are taken from the position the main line ends in, or from
+// the Result field if the game ended some other way (e.g. by resignation), and the Seven Tag Roster
+// is moved before the other tags with "?" for those that are not known
+func (game *Game) CompleteTags() error {
+	board, err := game.FinalBoard()
+	if err != nil {
+		return err
+	}
+
+	if outcome := board.Outcome(); outcome.IsOver() {
+		game.Result = outcome.Result
+		game.SetTag("Termination", "normal")
+	} else if game.Result == NoResult {
+		game.SetTag("Termination", "unterminated")
+	} else if termination := game.Tag("Termination"); termination == "" || termination == "unterminated" {
+		game.SetTag("Termination", "normal")
+	}
+
+	game.SetTag("Result", game.Result.String())
+
+	if game.Tag("FEN") != "" {
+		game.SetTag("SetUp", "1")
+	}
+
+	tags := make([]GameTag, 0, len(game.Tags) + len(sevenTagRoster))
+	for _, tag := range sevenTagRoster {
+		if value := game.Tag(tag.Name); value != "" {
+			tag.Value = value
+		}
+		tags = append(tags, tag)
+	}
+
+	for _, tag := range game.Tags {
+		if !isSevenTagRosterTag(tag.Name) {
+			tags = append(tags, tag)
+		}
+	}
+
+	game.Tags = tags
+	return nil
+}
+
+func isSevenTagRosterTag(name string) bool {
+	for _, tag := range sevenTagRoster {
+		if tag.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the FEN of the position with all six fields, as the FEN tag of PGN requires. The move
+// number is not tracked by the board, so is always 1
+func pgnFen(board *Board) string {
+	return fmt.Sprintf("%v %v 1", board.epdPosition(), board.HalfmoveClock())
+}
+
+// Returns the position the game started from, given by the FEN tag if it has one
+func (game *Game) StartingBoard() (*Board, error) {
+	if fen := game.Tag("FEN"); fen != "" {
+		return LoadFen(fen)
+	}
+
+	return LoadFen(StartingPositionFen)
+}
+
+// Returns the number of the first move of the game, given by the FEN tag if it has one
+func (game *Game) startingMoveNumber() int {
+	fields := strings.Fields(game.Tag("FEN"))
+	if len(fields) < 6 {
+		return 1
+	}
+
+	number, err := strconv.Atoi(fields[5])
+	if err != nil || number < 1 {
+		return 1
+	}
+
+	return number
+}
+
+// Returns the board after the moves of the main line
+func (game *Game) FinalBoard() (*Board, error) {
+	board, err := game.StartingBoard()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, move := range game.Moves {
+		board.MakeMove(move.Move)
+	}
+
+	return board, nil
+}
@@ -0,0 +1,26 @@
+//go:build amd64 && pext
+
+package slider
+
+// Sliding attack tables are indexed with the BMI2 PEXT instruction when the CPU supports it,
+// which replaces the magic multiplication and shift with a single instruction. Only enabled with
+// the pext build tag as PEXT is microcoded and slow on AMD CPUs before Zen 3
+var usePext = hasBMI2()
+
+// Returns the bits of `x` selected by `mask`, packed into the low bits of the result
+// Implemented in pext_amd64.s
+func pext(x uint64, mask uint64) uint64
+
+// Returns whether the CPU supports the BMI2 instruction set extension
+func hasBMI2() bool {
+	maxLeaf, _, _, _ := cpuid(0, 0)
+	if maxLeaf < 7 {
+		return false
+	}
+
+	_, ebx, _, _ := cpuid(7, 0)
+	return ebx & (1 << 8) != 0
+}
+
+// Implemented in pext_amd64.s
+func cpuid(eaxArg uint32, ecxArg uint32) (eax uint32, ebx uint32, ecx uint32, edx uint32)
@@ -0,0 +1,10 @@
+//go:build !amd64 || !pext
+
+package slider
+
+// PEXT indexing is only available on amd64 with the pext build tag
+const usePext = false
+
+func pext(x uint64, mask uint64) uint64 {
+	panic("pext is not supported on this platform")
+}
@@ -0,0 +1,87 @@
+// Package slider implements the "magic bitboards" approach to sliding piece move generation, as used
+// by the chess package. It works on raw uint64 bitboards and square indices so that the chess
+// package can depend on it, and is internal so that its layout can change without breaking users
+// of the chess package
+// https://www.chessprogramming.org/Magic_Bitboards
+package slider
+
+import (
+	"math/bits"
+)
+
+// Attack sets of one kind of sliding piece for every square and every relevant occupancy
+// When PEXT is available, the relevant occupancy bits are extracted directly instead of being
+// hashed with the magic numbers (see pext_amd64.go)
+type Table struct {
+	attackSets             [64][]uint64
+	magics                 [64]uint64
+	relevantBits           [64]uint
+	relevantOccupancyMasks [64]uint64
+}
+
+// Returns the attack set of a piece on square `sq` when the squares in `occupied` are occupied
+func (table *Table) Attacks(sq int, occupied uint64) uint64 {
+	if usePext {
+		return table.attackSets[sq][pext(occupied, table.relevantOccupancyMasks[sq])]
+	}
+
+	key := ((occupied & table.relevantOccupancyMasks[sq]) * table.magics[sq]) >> (64 - table.relevantBits[sq])
+
+	return table.attackSets[sq][key]
+}
+
+// Build the table, filling in each entry with `attacks`, which returns the attack set of a piece on
+// the square when the given squares are occupied
+func NewTable(
+	magics                 [64]uint64,
+	relevantBits           [64]uint,
+	relevantOccupancyMasks [64]uint64,
+	attacks                func(sq int, occupied uint64) uint64,
+) Table {
+	var attackSets [64][]uint64
+
+	for sq := 0; sq < 64; sq++ {
+		tableSize := 1 << relevantBits[sq]
+		if usePext {
+			tableSize = 1 << bits.OnesCount64(relevantOccupancyMasks[sq])
+		}
+		attackSets[sq] = make([]uint64, tableSize, tableSize)
+
+		for _, occupied := range subsets(relevantOccupancyMasks[sq]) {
+			var key uint64
+			if usePext {
+				key = pext(occupied, relevantOccupancyMasks[sq])
+			} else {
+				key = (occupied * magics[sq]) >> (64 - relevantBits[sq])
+			}
+			attackSets[sq][key] = attacks(sq, occupied)
+		}
+	}
+
+	return Table{attackSets, magics, relevantBits, relevantOccupancyMasks}
+}
+
+// Returns the name of the indexing scheme used by the tables, either "pext" or "magic"
+func Backend() string {
+	if usePext {
+		return "pext"
+	} else {
+		return "magic"
+	}
+}
+
+// Returns every combination of the bits set in `mask`
+func subsets(mask uint64) []uint64 {
+	result := make([]uint64, 0, 1 << bits.OnesCount64(mask))
+
+	// Carry-Rippler trick: enumerates the subsets in increasing order, ending at the empty set
+	subset := uint64(0)
+	for {
+		result = append(result, subset)
+
+		subset = (subset - mask) & mask
+		if subset == 0 {
+			return result
+		}
+	}
+}
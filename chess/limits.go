@@ -0,0 +1,106 @@
+package chess
+
+import (
+	"time"
+)
+
+// Limits on a bot's search for a move, as set by a time control or the UCI go command
+// Zero values mean no limit. A bot given no limits at all searches as it would in Think
+type SearchLimits struct {
+	// Maximum depth to search to (ply)
+	Depth int
+
+	// Maximum number of nodes to search
+	Nodes uint64
+
+	// Time to spend on the move exactly, rather than allocating time from the clocks
+	MoveTime time.Duration
+
+	// Time left on each side's clock, and the time added to it after each of its moves
+	WhiteTime      time.Duration
+	BlackTime      time.Duration
+	WhiteIncrement time.Duration
+	BlackIncrement time.Duration
+
+	// Number of moves until the clocks are next topped up, or 0 if the rest of the game must be
+	// played in the time left
+	MovesToGo int
+
+	// Search until stopped, ignoring the other limits
+	Infinite bool
+}
+
+// Implemented by bots that can search within limits
+type LimitedBot interface {
+	Bot
+	ThinkWithLimits(board *Board, limits SearchLimits) Move
+}
+
+// Time kept back from each move for the delay between the bot choosing a move and the clock
+// stopping, e.g. while the move is sent to a GUI
+const moveOverhead = 30 * time.Millisecond
+
+// Number of moves the rest of the game is assumed to last when the time control does not say, so
+// that the time left is spread over them
+const expectedMovesToGo = 30
+
+// Returns the time the side to move should aim to spend on its move, and the time after which the
+// search must be stopped, and whether there is a time limit at all
+// With clocks, the time left is divided between the moves expected until the next time control,
+// plus most of the increment. The search may run past the aim to finish an iteration, up to a few
+// times as long, but never uses more than a fraction of the time left
+func (limits SearchLimits) TimeForMove(isBlack bool) (optimum time.Duration, maximum time.Duration, ok bool) {
+	if limits.Infinite {
+		return 0, 0, false
+	}
+
+	if limits.MoveTime > 0 {
+		moveTime := max(limits.MoveTime - moveOverhead, time.Millisecond)
+		return moveTime, moveTime, true
+	}
+
+	timeLeft, increment := limits.WhiteTime, limits.WhiteIncrement
+	if isBlack {
+		timeLeft, increment = limits.BlackTime, limits.BlackIncrement
+	}
+
+	if timeLeft <= 0 {
+		return 0, 0, false
+	}
+
+	movesToGo := expectedMovesToGo
+	if limits.MovesToGo > 0 {
+		movesToGo = min(limits.MovesToGo, expectedMovesToGo)
+	}
+
+	usable := max(timeLeft - moveOverhead, time.Millisecond)
+
+	optimum = usable / time.Duration(movesToGo) + increment * 3 / 4
+	maximum = optimum * 4
+
+	// With one move to go, the time left can be used up, but otherwise some is kept for the moves
+	// after this one
+	limit := usable
+	if movesToGo > 1 {
+		limit = usable / 2
+	}
+
+	return min(optimum, limit), min(maximum, limit), true
+}
+
+// Let the bot choose a move within the limits. Bots that are not LimitedBots are stopped once the
+// time allocated to the move runs out if they are StoppableBots, and otherwise think as usual
+func ThinkWithLimits(bot Bot, board *Board, limits SearchLimits) Move {
+	if limitedBot, ok := bot.(LimitedBot); ok {
+		return limitedBot.ThinkWithLimits(board, limits)
+	}
+
+	if stoppableBot, ok := bot.(StoppableBot); ok {
+		if _, maximum, ok := limits.TimeForMove(board.IsBlackToMove()); ok {
+			timer := time.AfterFunc(maximum, stoppableBot.Stop)
+			defer timer.Stop()
+		}
+	}
+
+	return bot.Think(board)
+}
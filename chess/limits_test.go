@@ -0,0 +1,46 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+	"time"
+)
+
+func TestTimeForMove(t *testing.T) {
+	assert := assert.New(t)
+
+	_, _, ok := chess.SearchLimits{Depth: 5}.TimeForMove(false)
+	assert.False(ok)
+
+	_, _, ok = chess.SearchLimits{MoveTime: time.Second, Infinite: true}.TimeForMove(false)
+	assert.False(ok)
+
+	// A fixed time per move is used up, less the overhead
+	optimum, maximum, ok := chess.SearchLimits{MoveTime: time.Second}.TimeForMove(false)
+	assert.True(ok)
+	assert.Equal(970 * time.Millisecond, optimum)
+	assert.Equal(optimum, maximum)
+
+	// The time left is spread over the moves expected, using the side to move's clock
+	limits := chess.SearchLimits{WhiteTime: time.Minute, BlackTime: time.Second}
+	optimum, maximum, ok = limits.TimeForMove(false)
+	assert.True(ok)
+	assert.Equal(1999 * time.Millisecond, optimum)
+	assert.Equal(4 * optimum, maximum)
+
+	blackOptimum, _, _ := limits.TimeForMove(true)
+	assert.Less(blackOptimum, optimum)
+
+	// Most of the increment is spent, but half of the time left is kept back
+	optimum, _, _ = chess.SearchLimits{WhiteTime: time.Minute, WhiteIncrement: 4 * time.Second}.TimeForMove(false)
+	assert.Equal(4999 * time.Millisecond, optimum)
+
+	optimum, maximum, _ = chess.SearchLimits{WhiteTime: time.Second, WhiteIncrement: 2 * time.Second}.TimeForMove(false)
+	assert.Equal(485 * time.Millisecond, optimum)
+	assert.Equal(485 * time.Millisecond, maximum)
+
+	// With one move to go, the time left can all be used
+	_, maximum, _ = chess.SearchLimits{BlackTime: 10 * time.Second, MovesToGo: 1}.TimeForMove(true)
+	assert.Equal(9970 * time.Millisecond, maximum)
+}
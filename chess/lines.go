@@ -0,0 +1,41 @@
+package chess
+
+// Lookup tables of the squares between and on the lines through pairs of squares, used to find
+// interpositions when in check and the squares a pinned piece can move to
+var (
+	betweenBitboards [64][64]Bitboard
+	lineBitboards    [64][64]Bitboard
+)
+
+// Directions a queen can move in, as horizontal and vertical offsets
+var queenDirections = [8][2]int{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}}
+
+func init() {
+	for a := Square(0); a < 64; a++ {
+		for _, direction := range queenDirections {
+			// The whole line through `a` in this direction and the opposite direction
+			line := rayBitboard(a, EmptyBitboard, direction[0], direction[1]) |
+				rayBitboard(a, EmptyBitboard, -direction[0], -direction[1])
+			line = line.Set(a)
+
+			between := EmptyBitboard
+			for b, ok := a.Offset(direction[0], direction[1]); ok; b, ok = b.Offset(direction[0], direction[1]) {
+				betweenBitboards[a][b] = between
+				lineBitboards[a][b] = line
+				between = between.Set(b)
+			}
+		}
+	}
+}
+
+// Returns the bitboard of squares strictly between `a` and `b`, or an empty bitboard if the
+// squares do not share a rank, file or diagonal
+func BetweenBB(a Square, b Square) Bitboard {
+	return betweenBitboards[a][b]
+}
+
+// Returns the bitboard of squares on the rank, file or diagonal through both `a` and `b`, from one
+// edge of the board to the other, or an empty bitboard if the squares do not share a line
+func LineBB(a Square, b Square) Bitboard {
+	return lineBitboards[a][b]
+}
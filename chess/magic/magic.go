@@ -0,0 +1,277 @@
+// Package magic finds and verifies the magic numbers used to index the sliding piece attack tables
+// https://www.chessprogramming.org/Magic_Bitboards
+package magic
+
+import (
+	"errors"
+	"fmt"
+	"gogm/chess"
+	"gogm/chess/internal/slider"
+	"io"
+	"math/bits"
+	"math/rand"
+)
+
+// Magic numbers, relevant bit counts and relevant occupancy masks for every square, for one kind
+// of sliding piece
+type Table struct {
+	Kind                   chess.PieceKind
+	Magics                 [64]chess.Bitboard
+	RelevantBits           [64]uint
+	RelevantOccupancyMasks [64]chess.Bitboard
+}
+
+// Returns the table of magic numbers built into the chess package for the given kind of piece
+func BuiltinTable(kind chess.PieceKind) Table {
+	magics, relevantBits, relevantOccupancyMasks := slider.BishopMagics, slider.BishopRelevantBits, slider.BishopRelevantOccupancyMasks
+	if kind == chess.Rook {
+		magics, relevantBits, relevantOccupancyMasks = slider.RookMagics, slider.RookRelevantBits, slider.RookRelevantOccupancyMasks
+	}
+
+	table := Table{Kind: kind, RelevantBits: relevantBits}
+	for squareIndex := 0; squareIndex < 64; squareIndex++ {
+		table.Magics[squareIndex] = chess.Bitboard(magics[squareIndex])
+		table.RelevantOccupancyMasks[squareIndex] = chess.Bitboard(relevantOccupancyMasks[squareIndex])
+	}
+
+	return table
+}
+
+// Search for magic numbers for every square, using random sparse candidates
+// Squares are first tried with `reduceBits` fewer index bits than relevant occupancy squares,
+// giving a denser table; the bit count is increased again for squares where no magic is found
+// within `maxAttempts` candidates
+func FindTable(kind chess.PieceKind, reduceBits uint, maxAttempts int, rng *rand.Rand) (table Table, err error) {
+	if kind != chess.Rook && kind != chess.Bishop {
+		return table, errors.New(fmt.Sprintf("not a sliding piece kind: %v", kind))
+	}
+
+	table.Kind = kind
+
+	for squareIndex := 0; squareIndex < 64; squareIndex++ {
+		sq := chess.Square(squareIndex)
+		mask := RelevantOccupancyMask(sq, kind)
+		maskBits := uint(mask.PopCount())
+
+		relevantBits := maskBits - min(reduceBits, maskBits)
+		for {
+			magic, found := Find(sq, kind, relevantBits, maxAttempts, rng)
+			if found {
+				table.Magics[squareIndex] = magic
+				table.RelevantBits[squareIndex] = relevantBits
+				table.RelevantOccupancyMasks[squareIndex] = mask
+				break
+			}
+
+			if relevantBits >= maskBits {
+				return table, errors.New(fmt.Sprintf("no magic found for %v", sq))
+			}
+			relevantBits++
+		}
+	}
+
+	return
+}
+
+// Search for a magic number for one square that indexes the attack sets with `relevantBits` bits
+// The second return value is false if no magic was found within `maxAttempts` candidates
+func Find(sq chess.Square, kind chess.PieceKind, relevantBits uint, maxAttempts int, rng *rand.Rand) (chess.Bitboard, bool) {
+	mask := RelevantOccupancyMask(sq, kind)
+	occupancies := subsets(mask)
+
+	attackSets := make([]chess.Bitboard, len(occupancies))
+	for index, occupancy := range occupancies {
+		attackSets[index] = Attacks(sq, kind, occupancy)
+	}
+
+	used := make([]chess.Bitboard, 1<<relevantBits)
+	usedValid := make([]bool, 1<<relevantBits)
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		// Candidates with few set bits are much more likely to be magic
+		candidate := chess.Bitboard(rng.Uint64() & rng.Uint64() & rng.Uint64())
+
+		// Quickly reject candidates which don't spread the mask over the high bits of the product
+		if bits.OnesCount64(uint64((mask*candidate)>>56)) < 6 {
+			continue
+		}
+
+		clear(usedValid)
+		if fillTable(candidate, relevantBits, occupancies, attackSets, used, usedValid) {
+			return candidate, true
+		}
+	}
+
+	return chess.EmptyBitboard, false
+}
+
+// Check that the magic number maps every relevant occupancy of the square to an index where there
+// is no collision with a different attack set
+func Verify(sq chess.Square, kind chess.PieceKind, magic chess.Bitboard, relevantBits uint) bool {
+	mask := RelevantOccupancyMask(sq, kind)
+	occupancies := subsets(mask)
+
+	attackSets := make([]chess.Bitboard, len(occupancies))
+	for index, occupancy := range occupancies {
+		attackSets[index] = Attacks(sq, kind, occupancy)
+	}
+
+	used := make([]chess.Bitboard, 1<<relevantBits)
+	usedValid := make([]bool, 1<<relevantBits)
+
+	return fillTable(magic, relevantBits, occupancies, attackSets, used, usedValid)
+}
+
+// Check every square of the table, returning an error naming the first square that fails
+func (table *Table) Verify() error {
+	for squareIndex := 0; squareIndex < 64; squareIndex++ {
+		sq := chess.Square(squareIndex)
+
+		if table.RelevantOccupancyMasks[squareIndex] != RelevantOccupancyMask(sq, table.Kind) {
+			return errors.New(fmt.Sprintf("wrong relevant occupancy mask for %v", sq))
+		}
+
+		if !Verify(sq, table.Kind, table.Magics[squareIndex], table.RelevantBits[squareIndex]) {
+			return errors.New(fmt.Sprintf("magic for %v has a destructive collision", sq))
+		}
+	}
+
+	return nil
+}
+
+// Returns the total number of attack set entries needed to store the table
+func (table *Table) Size() (size int) {
+	for _, relevantBits := range table.RelevantBits {
+		size += 1 << relevantBits
+	}
+
+	return
+}
+
+// Write the table as Go variable declarations in the format used by chess/internal/slider/magics.go
+func (table *Table) WriteGo(w io.Writer) error {
+	var prefix string
+	if table.Kind == chess.Rook {
+		prefix = "Rook"
+	} else {
+		prefix = "Bishop"
+	}
+
+	_, err := fmt.Fprintf(w,
+		"var %vMagics [64]uint64 = [64]uint64 %v\n"+
+			"var %vRelevantBits [64]uint = [64]uint %v\n"+
+			"var %vRelevantOccupancyMasks [64]uint64 = [64]uint64 %v\n",
+		prefix, formatArray(table.Magics[:]),
+		prefix, formatArray(table.RelevantBits[:]),
+		prefix, formatArray(table.RelevantOccupancyMasks[:]),
+	)
+
+	return err
+}
+
+// Returns the squares whose occupancy affects the attack set of a piece on the given square
+// The last square of each ray is excluded, as a piece there blocks nothing further
+func RelevantOccupancyMask(sq chess.Square, kind chess.PieceKind) (result chess.Bitboard) {
+	for _, direction := range directions(kind) {
+		current := sq
+		for {
+			next, nextValid := current.Offset(direction[0], direction[1])
+			if !nextValid {
+				break
+			}
+
+			if _, afterNextValid := next.Offset(direction[0], direction[1]); !afterNextValid {
+				break
+			}
+
+			result = result.Set(next)
+			current = next
+		}
+	}
+
+	return
+}
+
+// Returns the attack set of a piece on the given square by walking each ray until it is blocked
+func Attacks(sq chess.Square, kind chess.PieceKind, occupancy chess.Bitboard) (result chess.Bitboard) {
+	for _, direction := range directions(kind) {
+		current := sq
+		for {
+			next, nextValid := current.Offset(direction[0], direction[1])
+			if !nextValid {
+				break
+			}
+
+			result = result.Set(next)
+			if occupancy.Get(next) {
+				break
+			}
+
+			current = next
+		}
+	}
+
+	return
+}
+
+func directions(kind chess.PieceKind) [][2]int {
+	if kind == chess.Rook {
+		return [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	} else {
+		return [][2]int{{1, 1}, {-1, 1}, {1, -1}, {-1, -1}}
+	}
+}
+
+// Try to place every attack set in the table using the magic, returning false on a collision
+// between two different attack sets
+func fillTable(
+	magic        chess.Bitboard,
+	relevantBits uint,
+	occupancies  []chess.Bitboard,
+	attackSets   []chess.Bitboard,
+	used         []chess.Bitboard,
+	usedValid    []bool,
+) bool {
+	for index, occupancy := range occupancies {
+		key := (occupancy * magic) >> (64 - relevantBits)
+
+		if !usedValid[key] {
+			used[key] = attackSets[index]
+			usedValid[key] = true
+		} else if used[key] != attackSets[index] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Returns every subset of the set bits of the bitboard
+func subsets(bitboard chess.Bitboard) []chess.Bitboard {
+	result := make([]chess.Bitboard, 0, 1<<bitboard.PopCount())
+
+	// Carry-rippler trick: https://www.chessprogramming.org/Traversing_Subsets_of_a_Set
+	subset := chess.EmptyBitboard
+	for {
+		result = append(result, subset)
+		subset = (subset - bitboard) & bitboard
+		if subset == chess.EmptyBitboard {
+			break
+		}
+	}
+
+	return result
+}
+
+// Bitboards are formatted as integers rather than with their String method
+func formatArray[T ~uint64 | ~uint](values []T) string {
+	result := "{"
+	for index, value := range values {
+		if index > 0 {
+			result += ", "
+		}
+		result += fmt.Sprint(uint64(value))
+	}
+
+	return result + "}"
+}
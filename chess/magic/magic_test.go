@@ -0,0 +1,42 @@
+package magic_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"gogm/chess/magic"
+	"math/rand"
+	"testing"
+)
+
+func TestBuiltinTables(t *testing.T) {
+	assert := assert.New(t)
+
+	rookTable := magic.BuiltinTable(chess.Rook)
+	assert.Nil(rookTable.Verify())
+
+	bishopTable := magic.BuiltinTable(chess.Bishop)
+	assert.Nil(bishopTable.Verify())
+}
+
+func TestFindTable(t *testing.T) {
+	assert := assert.New(t)
+	rng := rand.New(rand.NewSource(1))
+
+	table, err := magic.FindTable(chess.Bishop, 0, 1_000_000, rng)
+	assert.Nil(err)
+	assert.Nil(table.Verify())
+	assert.Equal(magic.BuiltinTable(chess.Bishop).RelevantOccupancyMasks, table.RelevantOccupancyMasks)
+}
+
+func TestAttacks(t *testing.T) {
+	assert := assert.New(t)
+
+	occupancy := chess.EmptyBitboard.Set(chess.D6)
+	attacks := magic.Attacks(chess.D4, chess.Rook, occupancy)
+
+	assert.True(attacks.Get(chess.D5))
+	assert.True(attacks.Get(chess.D6))
+	assert.False(attacks.Get(chess.D7))
+	assert.True(attacks.Get(chess.A4))
+	assert.True(attacks.Get(chess.D1))
+}
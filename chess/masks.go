@@ -0,0 +1,75 @@
+package chess
+
+// Bitboards of each file
+const (
+	FileABitboard Bitboard = 0x0101010101010101
+	FileBBitboard Bitboard = FileABitboard << 1
+	FileCBitboard Bitboard = FileABitboard << 2
+	FileDBitboard Bitboard = FileABitboard << 3
+	FileEBitboard Bitboard = FileABitboard << 4
+	FileFBitboard Bitboard = FileABitboard << 5
+	FileGBitboard Bitboard = FileABitboard << 6
+	FileHBitboard Bitboard = FileABitboard << 7
+)
+
+// Bitboards of each rank
+const (
+	Rank8Bitboard Bitboard = 0xff
+	Rank7Bitboard Bitboard = Rank8Bitboard << 8
+	Rank6Bitboard Bitboard = Rank8Bitboard << 16
+	Rank5Bitboard Bitboard = Rank8Bitboard << 24
+	Rank4Bitboard Bitboard = Rank8Bitboard << 32
+	Rank3Bitboard Bitboard = Rank8Bitboard << 40
+	Rank2Bitboard Bitboard = Rank8Bitboard << 48
+	Rank1Bitboard Bitboard = Rank8Bitboard << 56
+)
+
+// Bitboards of commonly used regions of the board
+const (
+	// d4, e4, d5 and e5
+	CenterBitboard Bitboard = (FileDBitboard | FileEBitboard) & (Rank4Bitboard | Rank5Bitboard)
+
+	// The 16 squares from c3 to f6
+	ExtendedCenterBitboard Bitboard = (FileCBitboard | FileDBitboard | FileEBitboard | FileFBitboard) &
+		(Rank3Bitboard | Rank4Bitboard | Rank5Bitboard | Rank6Bitboard)
+
+	// Files a to d
+	QueensideBitboard Bitboard = FileABitboard | FileBBitboard | FileCBitboard | FileDBitboard
+
+	// Files e to h
+	KingsideBitboard Bitboard = ^QueensideBitboard
+
+	// Squares of the same colour as h1 and a8
+	LightSquaresBitboard Bitboard = 0xaa55aa55aa55aa55
+
+	// Squares of the same colour as a1 and h8
+	DarkSquaresBitboard Bitboard = ^LightSquaresBitboard
+)
+
+// Returns the bitboard of the squares on the given file
+func FileBitboard(file File) Bitboard {
+	return FileABitboard << uint(file)
+}
+
+// Returns the bitboard of the squares on the given rank
+func RankBitboard(rank Rank) Bitboard {
+	return Rank8Bitboard << (8 * uint(rank))
+}
+
+// Returns the bitboard of the squares on the diagonal through `sq` running in the direction of
+// a1 to h8, including `sq` itself
+func DiagonalBitboard(sq Square) Bitboard {
+	return (rayBitboard(sq, EmptyBitboard, 1, -1) | rayBitboard(sq, EmptyBitboard, -1, 1)).Set(sq)
+}
+
+// Returns the bitboard of the squares on the diagonal through `sq` running in the direction of
+// a8 to h1, including `sq` itself
+func AntiDiagonalBitboard(sq Square) Bitboard {
+	return (rayBitboard(sq, EmptyBitboard, 1, 1) | rayBitboard(sq, EmptyBitboard, -1, -1)).Set(sq)
+}
+
+// Returns the bitboard of the king's square and the squares adjacent to it, used to evaluate
+// attacks on the king
+func KingZone(sq Square) Bitboard {
+	return kingAttackSets[uint(sq)].Set(sq)
+}
@@ -0,0 +1,56 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func TestFileAndRankBitboards(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(chess.BitboardFromSquares(chess.E8, chess.E7, chess.E6, chess.E5, chess.E4, chess.E3, chess.E2, chess.E1), chess.FileBitboard(chess.FileE))
+	assert.Equal(chess.BitboardFromSquares(chess.A5, chess.B5, chess.C5, chess.D5, chess.E5, chess.F5, chess.G5, chess.H5), chess.RankBitboard(chess.Rank5))
+	assert.Equal(chess.FileHBitboard, chess.FileBitboard(chess.FileH))
+	assert.Equal(chess.Rank1Bitboard, chess.RankBitboard(chess.Rank1))
+
+	for sq := chess.Square(0); sq < 64; sq++ {
+		assert.True(chess.FileBitboard(sq.File()).Get(sq))
+		assert.True(chess.RankBitboard(sq.Rank()).Get(sq))
+		assert.Equal(1, (chess.FileBitboard(sq.File()) & chess.RankBitboard(sq.Rank())).PopCount())
+	}
+}
+
+func TestRegionBitboards(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(chess.BitboardFromSquares(chess.D4, chess.E4, chess.D5, chess.E5), chess.CenterBitboard)
+	assert.Equal(16, chess.ExtendedCenterBitboard.PopCount())
+	assert.True(chess.ExtendedCenterBitboard.Get(chess.C3))
+	assert.True(chess.ExtendedCenterBitboard.Get(chess.F6))
+	assert.True(chess.KingsideBitboard.Get(chess.G1))
+	assert.True(chess.QueensideBitboard.Get(chess.B8))
+	assert.Equal(chess.EmptyBitboard, chess.KingsideBitboard & chess.QueensideBitboard)
+
+	assert.True(chess.LightSquaresBitboard.Get(chess.H1))
+	assert.True(chess.LightSquaresBitboard.Get(chess.A8))
+	assert.True(chess.DarkSquaresBitboard.Get(chess.A1))
+	assert.True(chess.DarkSquaresBitboard.Get(chess.E5))
+	assert.Equal(32, chess.LightSquaresBitboard.PopCount())
+}
+
+func TestDiagonalBitboards(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(chess.BitboardFromSquares(chess.A1, chess.B2, chess.C3, chess.D4, chess.E5, chess.F6, chess.G7, chess.H8), chess.DiagonalBitboard(chess.D4))
+	assert.Equal(chess.BitboardFromSquares(chess.A7, chess.B8), chess.DiagonalBitboard(chess.B8))
+	assert.Equal(chess.BitboardFromSquares(chess.A8, chess.B7, chess.C6, chess.D5, chess.E4, chess.F3, chess.G2, chess.H1), chess.AntiDiagonalBitboard(chess.E4))
+	assert.Equal(chess.BitboardFromSquares(chess.A1), chess.AntiDiagonalBitboard(chess.A1))
+}
+
+func TestKingZone(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(chess.BitboardFromSquares(chess.G1, chess.H1, chess.G2, chess.H2), chess.KingZone(chess.H1))
+	assert.Equal(9, chess.KingZone(chess.E4).PopCount())
+}
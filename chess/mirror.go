@@ -0,0 +1,42 @@
+package chess
+
+// Returns the position flipped vertically with the colours of the pieces swapped, so that the
+// side to move has the same position as its opponent had (e.g. after 1. e4, white to move with a
+// pawn on e5). Castling rights, the en passant target, the halfmove clock and the rules are kept,
+// but the positions before the current one are forgotten
+// A symmetric evaluation gives the same score for the side to move in both positions
+func (board *Board) Flipped() Board {
+	flipped := NewBoard()
+	flipped.rules = board.rules
+
+	for v := board.GetOccupiedBitboard(); v != EmptyBitboard; v = v.ClearLSB() {
+		sq := v.LSB()
+		piece := board.squareContents[sq]
+		flipped.putPiece(RelativeSquare(sq, true), piece.Kind, !piece.IsBlack)
+	}
+
+	flipped.blackToMove = !board.blackToMove
+
+	flipped.castlingRights = CastlingRights{
+		WhiteKingside:  board.castlingRights.BlackKingside,
+		WhiteQueenside: board.castlingRights.BlackQueenside,
+		BlackKingside:  board.castlingRights.WhiteKingside,
+		BlackQueenside: board.castlingRights.WhiteQueenside,
+	}
+
+	flipped.hasEnPassantTarget = board.hasEnPassantTarget
+	flipped.enPassantTarget = RelativeSquare(board.enPassantTarget, true)
+	flipped.halfmoveClock = board.halfmoveClock
+
+	// The pieces were hashed as they were placed
+	flipped.hash ^= flipped.stateKey()
+
+	return flipped
+}
+
+// Returns the move flipped vertically, the same move in the position given by Board.Flipped
+func (move Move) Mirrored() Move {
+	move.Source = RelativeSquare(move.Source, true)
+	move.Destination = RelativeSquare(move.Destination, true)
+	return move
+}
@@ -0,0 +1,55 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"sort"
+	"testing"
+)
+
+func TestFlipped(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 2")
+	assert.Nil(err)
+
+	board.MakeMove(chess.Move{Source: chess.D2, Destination: chess.D4})
+
+	flipped := board.Flipped()
+	assert.Equal("rnbqkbnr/ppp2ppp/8/3pp3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq", flipped.Fen())
+
+	target, ok := flipped.GetEnPassantTarget()
+	assert.True(ok)
+	assert.Equal(chess.D6, target)
+
+	// Flipping twice gives back the same position
+	twice := flipped.Flipped()
+	assert.Equal(board.Fen(), twice.Fen())
+	assert.Equal(board.Hash(), twice.Hash())
+
+	reloaded, err := chess.LoadFen(flipped.Fen() + " d6")
+	assert.Nil(err)
+	assert.Equal(reloaded.Hash(), flipped.Hash())
+}
+
+func TestMirroredMoves(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(chess.Move{Source: chess.E7, Destination: chess.E5}, chess.Move{Source: chess.E2, Destination: chess.E4}.Mirrored())
+
+	board, err := chess.LoadFen("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	assert.Nil(err)
+	flipped := board.Flipped()
+
+	var expected, actual []string
+	for _, move := range board.GetLegalMoves(false) {
+		expected = append(expected, move.Mirrored().String())
+	}
+	for _, move := range flipped.GetLegalMoves(false) {
+		actual = append(actual, move.String())
+	}
+
+	sort.Strings(expected)
+	sort.Strings(actual)
+	assert.Equal(expected, actual)
+}
@@ -0,0 +1,39 @@
+package chess
+
+// Returns the number of squares the knights, bishops, rooks and queens of the given side attack
+// that are not occupied by their own pieces, a cheap measure of mobility for evaluation
+// This is computed from the attack sets without generating moves, so pins and checks are ignored
+func (board *Board) MobilityCount(isBlack bool) (count int) {
+	side := sideIndex(isBlack)
+	pieces := &board.pieceBitboards[side]
+	notFriendly := ^board.sideBitboards[side]
+
+	mobilePieces := pieces[Knight] | pieces[Bishop] | pieces[Rook] | pieces[Queen]
+
+	for v := mobilePieces; v != EmptyBitboard; v = v.ClearLSB() {
+		count += (board.getCachedAttackSet(v.LSB()) & notFriendly).PopCount()
+	}
+
+	return
+}
+
+// Returns the squares attacked by at least one piece of the given side, whether or not they are
+// occupied
+func (board *Board) ControlledSquares(isBlack bool) Bitboard {
+	side := sideIndex(isBlack)
+	pieces := &board.pieceBitboards[side]
+
+	// Pawn attacks for all pawns at once
+	var result Bitboard
+	if isBlack {
+		result = pieces[Pawn].Shift(SouthEast) | pieces[Pawn].Shift(SouthWest)
+	} else {
+		result = pieces[Pawn].Shift(NorthEast) | pieces[Pawn].Shift(NorthWest)
+	}
+
+	for v := board.sideBitboards[side] & ^pieces[Pawn]; v != EmptyBitboard; v = v.ClearLSB() {
+		result |= board.getCachedAttackSet(v.LSB())
+	}
+
+	return result
+}
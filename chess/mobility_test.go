@@ -0,0 +1,54 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func TestMobilityCount(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen(chess.StartingPositionFen)
+	assert.Nil(err)
+
+	// Only the knights can move
+	assert.Equal(4, board.MobilityCount(false))
+	assert.Equal(4, board.MobilityCount(true))
+
+	// Rook on an open board, blocked by its own king but able to capture the enemy knight
+	board, err = chess.LoadFen("4k3/8/8/8/R2n4/8/8/K7 w - - 0 1")
+	assert.Nil(err)
+	assert.Equal(4 + 2 + 3, board.MobilityCount(false))
+	assert.Equal(8, board.MobilityCount(true))
+}
+
+func TestControlledSquares(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen(chess.StartingPositionFen)
+	assert.Nil(err)
+
+	// Every square on the third rank, plus the squares defended on the first and second ranks
+	controlled := board.ControlledSquares(false)
+	assert.Equal(chess.Rank3Bitboard, controlled & chess.Rank3Bitboard)
+	assert.Equal(chess.EmptyBitboard, controlled & (chess.Rank4Bitboard | chess.Rank5Bitboard))
+	assert.False(controlled.Get(chess.A1))
+	assert.False(controlled.Get(chess.H1))
+	assert.True(controlled.Get(chess.E2))
+
+	board, err = chess.LoadFen("4k3/8/8/8/8/8/4P3/K7 w - - 0 1")
+	assert.Nil(err)
+	assert.Equal(chess.BitboardFromSquares(chess.D3, chess.F3, chess.A2, chess.B2, chess.B1), board.ControlledSquares(false))
+
+	// Matches the union of the individual attack sets
+	board, err = chess.LoadFen("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	assert.Nil(err)
+	for _, isBlack := range []bool{false, true} {
+		var expected chess.Bitboard
+		for _, piece := range board.GetPiecesForColor(chess.ColorWithIsBlack(isBlack)) {
+			expected |= chess.PieceAttacks(piece.Kind, piece.Square, isBlack, board.GetOccupiedBitboard())
+		}
+		assert.Equal(expected, board.ControlledSquares(isBlack))
+	}
+}
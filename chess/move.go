@@ -1,6 +1,9 @@
 package chess
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // Information necessary to make a move
 type Move struct {
@@ -10,6 +13,14 @@ type Move struct {
 	IsPromotion   bool
 }
 
+// A legal move along with properties useful for displaying it
+type MoveInfo struct {
+	Move
+	IsCapture   bool
+	IsEnPassant bool
+	IsCastle    bool
+}
+
 // Information necessary to undo a move
 type Unmove struct {
 	source             Square
@@ -20,6 +31,42 @@ type Unmove struct {
 	isCapture          bool
 	isPromotion        bool
 	hadEnPassantTarget bool
+	oldHalfmoveClock   int
+}
+
+// Information necessary to undo a null move
+type NullUnmove struct {
+	oldEnPassantTarget Square
+	hadEnPassantTarget bool
+	oldHalfmoveClock   int
+}
+
+// Returns the move written in UCI notation, e.g. e2e4 or e7e8q
+// Castling is written as the king moving two squares
+func MoveWithUciNotation(notation string) (move Move, err error) {
+	if len(notation) != 4 && len(notation) != 5 {
+		return move, errors.New(fmt.Sprintf("bad move length: %v", notation))
+	}
+
+	if move.Source, err = SquareWithAlgebraicName(notation[0:2]); err != nil {
+		return move, errors.New(fmt.Sprintf("bad source square in move %v: %v", notation, err))
+	}
+
+	if move.Destination, err = SquareWithAlgebraicName(notation[2:4]); err != nil {
+		return move, errors.New(fmt.Sprintf("bad destination square in move %v: %v", notation, err))
+	}
+
+	if len(notation) == 5 {
+		move.IsPromotion = true
+		move.PromotedPiece, err = PieceWithAlgebraicLetter(rune(notation[4]))
+
+		// Promoting to a king is allowed in antichess, so is left to the move generator to reject
+		if err != nil || move.PromotedPiece == Pawn {
+			return move, errors.New(fmt.Sprintf("bad promotion piece in move %v", notation))
+		}
+	}
+
+	return move, nil
 }
 
 // Format a move in UCI notation
@@ -29,7 +76,7 @@ func (move Move) String() string {
 		dst, _ := move.Destination.AlgebraicName()
 		promoted := move.PromotedPiece.AlgebraicLetter()
 
-		return fmt.Sprintf("%v%v%v", src, dst, promoted)
+		return fmt.Sprintf("%v%v%c", src, dst, promoted)
 	} else {
 		src, _ := move.Source.AlgebraicName()
 		dst, _ := move.Destination.AlgebraicName()
@@ -0,0 +1,30 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func TestMoveWithUciNotation(t *testing.T) {
+	assert := assert.New(t)
+
+	move, err := chess.MoveWithUciNotation("e2e4")
+	assert.Nil(err)
+	assert.Equal(chess.Move{Source: chess.E2, Destination: chess.E4}, move)
+
+	move, err = chess.MoveWithUciNotation("b7a8n")
+	assert.Nil(err)
+	assert.Equal(chess.Move{Source: chess.B7, Destination: chess.A8, IsPromotion: true, PromotedPiece: chess.Knight}, move)
+	assert.Equal("b7a8n", move.String())
+
+	// Promoting to a king is only legal in antichess, but is written the same way
+	move, err = chess.MoveWithUciNotation("e7e8k")
+	assert.Nil(err)
+	assert.Equal(chess.Move{Source: chess.E7, Destination: chess.E8, IsPromotion: true, PromotedPiece: chess.King}, move)
+
+	for _, notation := range []string{"", "e2", "e2e9", "i2e4", "e7e8p", "e7e8x", "e2e4qq"} {
+		_, err = chess.MoveWithUciNotation(notation)
+		assert.NotNil(err, notation)
+	}
+}
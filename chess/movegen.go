@@ -1,169 +1,70 @@
 package chess
 
 import (
+	"errors"
 	"fmt"
-	"math/bits"
+	"gogm/chess/internal/slider"
 )
 
-// Implements the "magic bitboards" approach to sliding piece move generation
-type SlidingAttackTable struct {
-	attackSetBitboards     [64][]Bitboard
-	magics                 [64]Bitboard
-	relevantBits           [64]uint
-	relevantOccupancyMasks [64]Bitboard
-}
-
-func (table SlidingAttackTable) GetAttackSet(sq Square, allPiecesBitboard Bitboard) Bitboard {
-	relevantOccupancyBitboard := allPiecesBitboard & table.relevantOccupancyMasks[uint(sq)]
-
-	key := (relevantOccupancyBitboard * table.magics[uint(sq)]) >> (64 - table.relevantBits[uint(sq)])
-
-	return table.attackSetBitboards[uint(sq)][key]
-}
-
-func CreateSlidingAttackTable(
-	magics                 [64]Bitboard,
-	relevantBits           [64]uint,
-	relevantOccupancyMasks [64]Bitboard,
-	attackSetGenerator     func(Square, Bitboard) Bitboard,
-) SlidingAttackTable {
-	var attackSetBitboards [64][]Bitboard
-
-	for squareIndex := 0; squareIndex < 64; squareIndex++ {
-		tableSize := 1 << relevantBits[squareIndex]
-		attackSetBitboards[squareIndex] = make([]Bitboard, tableSize, tableSize)
-
-		for _, relevantOccupancyBitboard := range allRelevantOccupacyBitboards(relevantOccupancyMasks[squareIndex]) {
-			key := (relevantOccupancyBitboard * magics[squareIndex]) >> (64 - relevantBits[squareIndex])
-			attackSetBitboards[squareIndex][key] = attackSetGenerator(Square(squareIndex), relevantOccupancyBitboard)
-		}
-	}
+// Attack tables for sliding pieces, shared between all boards
+// These are built once when the package is initialized as building them is relatively expensive
+var (
+	bishopAttackTable = slider.NewTable(slider.BishopMagics, slider.BishopRelevantBits, slider.BishopRelevantOccupancyMasks, rawAttacks(bishopAttacks))
+	rookAttackTable   = slider.NewTable(slider.RookMagics, slider.RookRelevantBits, slider.RookRelevantOccupancyMasks, rawAttacks(rookAttacks))
+)
 
-	return SlidingAttackTable{ attackSetBitboards, magics, relevantBits, relevantOccupancyMasks }
+// Returns the name of the indexing scheme used by the sliding attack tables, either "pext" or
+// "magic"
+func SlidingAttackBackend() string {
+	return slider.Backend()
 }
 
-func CreateBishopAttackTable() SlidingAttackTable {
-	return CreateSlidingAttackTable(bishopMagicNumbers, bishopRelevantBits, bishopRelevantOccupancyMasks, bishopAttacks)
+func bishopAttackSet(sq Square, allPiecesBitboard Bitboard) Bitboard {
+	return Bitboard(bishopAttackTable.Attacks(int(sq), uint64(allPiecesBitboard)))
 }
 
-func CreateRookAttackTable() SlidingAttackTable {
-	return CreateSlidingAttackTable(rookMagicNumbers, rookRelevantBits, rookRelevantOccupancyMasks, rookAttacks)
+func rookAttackSet(sq Square, allPiecesBitboard Bitboard) Bitboard {
+	return Bitboard(rookAttackTable.Attacks(int(sq), uint64(allPiecesBitboard)))
 }
 
-func PawnMoveSet(
-	sq                     Square,
-	isBlack                bool,
-	friendlyPiecesBitboard Bitboard,
-	enemyPiecesBitboard    Bitboard,
-	kingSquare             Square,
-	board                  *Board,
-) (result Bitboard) {
-	allPiecesBitboard := friendlyPiecesBitboard | enemyPiecesBitboard
-
-	var forwardsDirection int
-	var initialRank Rank
-
-	if isBlack {
-		forwardsDirection = 1
-		initialRank = Rank7
-	} else {
-		forwardsDirection = -1
-		initialRank = Rank2
-	}
-
-	// One-square advance
-	advanceSq, advanceSqValid := sq.Offset(0, forwardsDirection)
-	if advanceSqValid {
-		result |= EmptyBitboard.Set(advanceSq) & ^allPiecesBitboard
-	}
-
-	// Two-square thrust
-	if sq.Rank() == initialRank {
-		thrustSq, thrustSqValid := sq.Offset(0, 2*forwardsDirection)
-		advanceSqEmpty := EmptyBitboard.Set(advanceSq) & allPiecesBitboard == EmptyBitboard
-		if thrustSqValid && advanceSqEmpty {
-			result |= EmptyBitboard.Set(thrustSq) & ^allPiecesBitboard
-		}
-	}
-
-	// Captures
-	var attackSetBitboard Bitboard
-	var enPassantBitboard Bitboard
-
-	if isBlack {
-		attackSetBitboard = blackPawnAttackSets[uint(sq)]
-	} else {
-		attackSetBitboard = whitePawnAttackSets[uint(sq)]
-	}
-
-	if board.hasEnPassantTarget {
-		// Handle the annoying en passant pin, when the capturing pawn and the captured pawn
-		// are the only two pieces blocking an attack by a rook against the king on the same rank
-		enPassantPin := detectEnPassantPin(sq, kingSquare, board)
-
-		if !enPassantPin {
-			enPassantBitboard = EmptyBitboard.Set(board.enPassantTarget)
-		}
+// Adapts an attack set generator to the raw types used by the slider package
+func rawAttacks(attacks func(Square, Bitboard) Bitboard) func(int, uint64) uint64 {
+	return func(sq int, occupied uint64) uint64 {
+		return uint64(attacks(Square(sq), Bitboard(occupied)))
 	}
-
-	result |= attackSetBitboard & (enemyPiecesBitboard | enPassantBitboard)
-
-	return
 }
 
-func GetPieceAttackSet(piece Piece, allPiecesBitboard Bitboard, board *Board) Bitboard {
-	switch piece.Kind {
-	case Pawn:
-		if piece.IsBlack {
-			return blackPawnAttackSets[uint(piece.Square)]
-		} else {
-			return whitePawnAttackSets[uint(piece.Square)]
-		}
-
-	case Knight:
-		return knightAttackSets[uint(piece.Square)]
-
-	case Bishop:
-		return board.bishopAttackTable.GetAttackSet(piece.Square, allPiecesBitboard)
-
-	case Rook:
-		return board.rookAttackTable.GetAttackSet(piece.Square, allPiecesBitboard)
-
-	case Queen:
-		return board.rookAttackTable.GetAttackSet(piece.Square, allPiecesBitboard) |
-			board.bishopAttackTable.GetAttackSet(piece.Square, allPiecesBitboard)
+// Returns the legal moves in the current position
+// If `capturesOnly` is true, only moves capturing an enemy piece are returned
+func (board *Board) GetLegalMoves(capturesOnly bool) []Move {
+	return board.AppendLegalMoves(make([]Move, 0, 256), capturesOnly)
+}
 
-	case King:
-		return kingAttackSets[uint(piece.Square)]
+// Appends the legal moves in the current position to `moves`, returning the updated slice
+// Reusing the same slice between calls allows moves to be generated without allocating
+// The moves are generated by the board's rules (see SetRules)
+func (board *Board) AppendLegalMoves(moves []Move, capturesOnly bool) []Move {
+	if board.rules != nil {
+		return board.rules.AppendLegalMoves(board, moves, capturesOnly)
 	}
 
-	return EmptyBitboard
+	return board.appendStandardLegalMoves(moves, capturesOnly)
 }
 
-func (board *Board) GetLegalMoves(capturesOnly bool) []Move {
-	moves := make([]Move, 0, 256)
+// Appends the legal moves in the current position under the rules of standard chess
+func (board *Board) appendStandardLegalMoves(moves []Move, capturesOnly bool) []Move {
+	friendlySide := sideIndex(board.blackToMove)
+	enemySide := sideIndex(!board.blackToMove)
 
-	friendlyPieces := board.GetPiecesForSide(board.blackToMove)
-	enemyPieces := board.GetPiecesForSide(!board.blackToMove)
-
-	friendlyPiecesBitboard := board.GetPiecesBitboard(board.blackToMove)
-	enemyPiecesBitboard := board.GetPiecesBitboard(!board.blackToMove)
+	friendlyPiecesBitboard := board.sideBitboards[friendlySide]
+	enemyPiecesBitboard := board.sideBitboards[enemySide]
 	allPiecesBitboard := friendlyPiecesBitboard | enemyPiecesBitboard
 
 	kingSquare := board.GetKingSquare(board.blackToMove)
-	kingDangerMask, checkingPieces := getKingDangerMaskAndCheckingPieces(
-		enemyPieces,
-		allPiecesBitboard,
-		kingSquare,
-		board,
-	)
-	pinMask := getPinMask(
-		enemyPieces,
-		allPiecesBitboard,
-		kingSquare,
-		board,
-	)
-	isCheck := kingDangerMask.IntersectsSquare(kingSquare)
+	kingDangerMask := board.getKingDangerMask(kingSquare, allPiecesBitboard)
+	checkersBitboard := board.getCheckers(kingSquare, allPiecesBitboard)
+	pinMask := board.getPinMask(kingSquare, allPiecesBitboard)
+	numCheckers := checkersBitboard.PopCount()
 
 	var promotionRank Rank
 	if board.blackToMove {
@@ -172,109 +73,77 @@ func (board *Board) GetLegalMoves(capturesOnly bool) []Move {
 		promotionRank = Rank8
 	}
 
-	for _, piece := range friendlyPieces {
-		// Get pseudolegal move set
-		var moveSet Bitboard
+	// Bitboard of squares pieces may move to
+	targetMask := ^friendlyPiecesBitboard
+	if capturesOnly {
+		targetMask &= enemyPiecesBitboard
+	}
 
-		// If we are in double check, filter only for king moves
-		if len(checkingPieces) > 1 && piece.Kind != King {
-			continue
-		}
+	// King moves, preventing the king from walking into danger
+	moves = appendMovesFromSquare(moves, kingSquare, kingAttackSets[uint(kingSquare)] & targetMask & ^kingDangerMask)
 
-		// Get bitboard of pseudolegal destination squares
-		if piece.Kind == Pawn {
-			moveSet = PawnMoveSet(piece.Square, piece.IsBlack, friendlyPiecesBitboard, enemyPiecesBitboard, kingSquare, board)
-		} else {
-			moveSet = GetPieceAttackSet(piece, friendlyPiecesBitboard | enemyPiecesBitboard, board) & ^friendlyPiecesBitboard
-		}
+	// If we are in double check, only king moves are possible
+	if numCheckers > 1 {
+		return moves
+	}
 
-		// Prevent king from walking into danger
-		if piece.Kind == King {
-			moveSet &= ^kingDangerMask
-		}
+	// If we are in single check, filter only for moves that capture the checking piece or block
+	// the check
+	validMovesMask := ^EmptyBitboard
+	if numCheckers == 1 {
+		checkerSquare := checkersBitboard.LSB()
 
-		// Handle pins
-		isPinned := pinMask.IntersectsSquare(piece.Square)
-		if isPinned {
-			kingToPieceH := int(piece.Square.File()) - int(kingSquare.File())
-			kingToPieceV := int(piece.Square.Rank()) - int(kingSquare.Rank())
-			pinRayBitboard := rayBitboard(kingSquare, EmptyBitboard, signum(kingToPieceH), signum(kingToPieceV))
-			moveSet &= pinRayBitboard
-		}
+		// Capturing the checking piece, or interposing if it is a sliding piece (there are no
+		// squares between the king and a checking knight or pawn)
+		validMovesMask = checkersBitboard | BetweenBB(kingSquare, checkerSquare)
+	}
 
-		// If we are in single check, filter only for king moves or moves that capture the checking
-		// piece
-		if len(checkingPieces) == 1 {
-			if piece.Kind != King {
-				checkingPiece := checkingPieces[0]
+	for kind := Queen; kind <= Pawn; kind++ {
+		for v := board.pieceBitboards[friendlySide][kind]; v != EmptyBitboard; v = v.ClearLSB() {
+			sq := v.LSB()
 
-				// Capturing the checking piece
-				validMovesMask := EmptyBitboard.Set(checkingPiece.Square)
+			// Get bitboard of pseudolegal destination squares
+			var moveSet Bitboard
+			if kind == Pawn {
+				moveSet = pawnPushSet(sq, board.blackToMove, allPiecesBitboard) |
+					pawnAttackSet(sq, board.blackToMove) & enemyPiecesBitboard
+			} else {
+				moveSet = board.getCachedAttackSet(sq)
+			}
 
-				// Interpositions
-				if checkingPiece.Kind == Queen || checkingPiece.Kind == Rook || checkingPiece.Kind == Bishop {
-					kingToPieceH := int(checkingPiece.Square.File()) - int(kingSquare.File())
-					kingToPieceV := int(checkingPiece.Square.Rank()) - int(kingSquare.Rank())
-					rayBitboard := rayBitboard(kingSquare, allPiecesBitboard, signum(kingToPieceH), signum(kingToPieceV))
-					validMovesMask |= rayBitboard
-				}
+			moveSet &= targetMask & validMovesMask
 
-				moveSet &= validMovesMask
+			// Handle pins
+			if pinMask.Get(sq) {
+				moveSet &= LineBB(kingSquare, sq)
 			}
-		}
 
-		if capturesOnly {
-			// Filter for only captures
-			moveSet &= enemyPiecesBitboard
+			if kind == Pawn {
+				moves = appendPawnMovesFromSquare(moves, sq, moveSet, promotionRank)
+			} else {
+				moves = appendMovesFromSquare(moves, sq, moveSet)
+			}
 		}
+	}
 
-		// Add all moves in move set to the list of legal moves
-		bitIndex := 0
-		for v := uint64(moveSet); v != 0; {
-			trailingZeroes := bits.TrailingZeros64(v)
-			bitIndex += trailingZeroes
-			v >>= trailingZeroes + 1
+	// En passant captures are checked separately by making sure the king is not attacked after the
+	// capture, as removing two pieces from the same rank can expose the king to an attack
+	if board.hasEnPassantTarget {
+		capturingPawns := pawnAttackSet(board.enPassantTarget, !board.blackToMove) & board.pieceBitboards[friendlySide][Pawn]
 
-			destinationSquare := Square(bitIndex)
+		for v := capturingPawns; v != EmptyBitboard; v = v.ClearLSB() {
+			sq := v.LSB()
 
-			// Handle promotions
-			if piece.Kind == Pawn && destinationSquare.Rank() == promotionRank {
-				moves = append(moves, Move{
-					Source: piece.Square,
-					Destination: destinationSquare,
-					IsPromotion: true,
-					PromotedPiece: Queen,
-				});
-				moves = append(moves, Move{
-					Source: piece.Square,
-					Destination: destinationSquare,
-					IsPromotion: true,
-					PromotedPiece: Rook,
-				});
+			if board.isEnPassantLegal(sq, kingSquare) {
 				moves = append(moves, Move{
-					Source: piece.Square,
-					Destination: destinationSquare,
-					IsPromotion: true,
-					PromotedPiece: Bishop,
-				});
-				moves = append(moves, Move{
-					Source: piece.Square,
-					Destination: destinationSquare,
-					IsPromotion: true,
-					PromotedPiece: Knight,
-				});
-			} else {
-				moves = append(moves, Move{
-					Source: piece.Square,
-					Destination: destinationSquare,
-				});
+					Source: sq,
+					Destination: board.enPassantTarget,
+				})
 			}
-
-			bitIndex += 1
 		}
 	}
 
-	if (capturesOnly) {
+	if capturesOnly || numCheckers > 0 {
 		return moves
 	}
 
@@ -286,30 +155,26 @@ func (board *Board) GetLegalMoves(capturesOnly bool) []Move {
 		backRank = Rank1
 	}
 
-	kingsideCastlingRight, queensideCastlingRight := board.GetCastlingRights(board.blackToMove)
+	kingsideCastlingRight, queensideCastlingRight := board.GetCastlingRightsForColor(board.SideToMove())
 
 	kingsideCastleOccupancyMask := EmptyBitboard.Set(SquareAt(FileF, backRank)).Set(SquareAt(FileG, backRank))
 	queensideCastleDangerMask := EmptyBitboard.Set(SquareAt(FileD, backRank)).Set(SquareAt(FileC, backRank))
 	queensideCastleOccupancyMask := queensideCastleDangerMask.Set(SquareAt(FileB, backRank))
 
-	// Make sure there is still a black rook on A1 (i.e. it wasn't captured)
-	var aRook, hRook bool
-	if aPiece := board.GetPiece(SquareAt(FileA, backRank)); aPiece != nil {
-		aRook = aPiece.Kind == Rook && aPiece.IsBlack == board.blackToMove
-	}
-	if hPiece := board.GetPiece(SquareAt(FileH, backRank)); hPiece != nil {
-		hRook = hPiece.Kind == Rook && hPiece.IsBlack == board.blackToMove
-	}
+	// Make sure there is still a rook of our colour in the corner (i.e. it wasn't captured)
+	friendlyRooksBitboard := board.pieceBitboards[friendlySide][Rook]
+	aRook := friendlyRooksBitboard.Get(SquareAt(FileA, backRank))
+	hRook := friendlyRooksBitboard.Get(SquareAt(FileH, backRank))
 
 	canCastleKingside := kingsideCastlingRight &&
 		kingsideCastleOccupancyMask & allPiecesBitboard == EmptyBitboard &&
 		kingsideCastleOccupancyMask & kingDangerMask == EmptyBitboard &&
-		hRook && !isCheck
+		hRook
 
 	canCastleQueenside := queensideCastlingRight &&
 		queensideCastleOccupancyMask & allPiecesBitboard == EmptyBitboard &&
 		queensideCastleDangerMask & kingDangerMask == EmptyBitboard &&
-		aRook && !isCheck
+		aRook
 
 	if canCastleKingside {
 		moves = append(moves, Move {
@@ -328,6 +193,33 @@ func (board *Board) GetLegalMoves(capturesOnly bool) []Move {
 	return moves
 }
 
+// True if the move is legal in the current position
+// The promoted piece is ignored for moves that are not promotions
+func (board *Board) IsLegalMove(move Move) bool {
+	if !move.IsPromotion {
+		move.PromotedPiece = 0
+	}
+
+	for _, legalMove := range board.GetLegalMoves(false) {
+		if legalMove == move {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Make the move if it is legal in the current position, otherwise leave the board unchanged and
+// return an error. Moves from users, bots and protocols should be made this way, as MakeMove
+// trusts that the move is legal and corrupts the board if it is not
+func (board *Board) MakeLegalMove(move Move) (Unmove, error) {
+	if !board.IsLegalMove(move) {
+		return Unmove{}, errors.New(fmt.Sprintf("illegal move: %v", move))
+	}
+
+	return board.MakeMove(move), nil
+}
+
 func (board *Board) GetLegalMovesFromSquare(sq Square) (result []Move) {
 	legalMoves := board.GetLegalMoves(false)
 
@@ -340,54 +232,170 @@ func (board *Board) GetLegalMovesFromSquare(sq Square) (result []Move) {
 	return
 }
 
-func (board *Board) IsCheck() bool {
-	friendlyPiecesBitboard := board.GetPiecesBitboard(board.blackToMove)
-	enemyPiecesBitboard := board.GetPiecesBitboard(!board.blackToMove)
+// Returns the legal moves of the piece on the given square, along with whether each move is a
+// capture, en passant capture or castling move
+func (board *Board) GetLegalMoveInfoFromSquare(sq Square) (result []MoveInfo) {
+	for _, move := range board.GetLegalMovesFromSquare(sq) {
+		result = append(result, MoveInfo{
+			Move: move,
+			IsCapture: board.IsCaptureMove(move),
+			IsEnPassant: board.IsEnPassantMove(move),
+			IsCastle: board.IsCastlingMove(move),
+		})
+	}
+
+	return
+}
 
+func (board *Board) IsCheck() bool {
 	kingSquare := board.GetKingSquare(board.blackToMove)
-	kingDangerMask, _ := getKingDangerMaskAndCheckingPieces(
-		board.GetPiecesForSide(!board.blackToMove),
-		friendlyPiecesBitboard | enemyPiecesBitboard,
-		kingSquare,
-		board,
-	)
-
-	return kingDangerMask.IntersectsSquare(kingSquare)
+	return board.getCheckers(kingSquare, board.GetOccupiedBitboard()) != EmptyBitboard
+}
+
+// Add a move from `source` to each square in `moveSet`
+func appendMovesFromSquare(moves []Move, source Square, moveSet Bitboard) []Move {
+	for v := moveSet; v != EmptyBitboard; v = v.ClearLSB() {
+		moves = append(moves, Move{
+			Source: source,
+			Destination: v.LSB(),
+		})
+	}
+
+	return moves
+}
+
+// Add a pawn move from `source` to each square in `moveSet`, generating all four promotions for moves
+// to the promotion rank
+func appendPawnMovesFromSquare(moves []Move, source Square, moveSet Bitboard, promotionRank Rank) []Move {
+	for v := moveSet; v != EmptyBitboard; v = v.ClearLSB() {
+		destinationSquare := v.LSB()
+
+		if destinationSquare.Rank() == promotionRank {
+			for _, promotedPiece := range [4]PieceKind{Queen, Rook, Bishop, Knight} {
+				moves = append(moves, Move{
+					Source: source,
+					Destination: destinationSquare,
+					IsPromotion: true,
+					PromotedPiece: promotedPiece,
+				})
+			}
+		} else {
+			moves = append(moves, Move{
+				Source: source,
+				Destination: destinationSquare,
+			})
+		}
+	}
+
+	return moves
+}
+
+// Returns the bitboard of squares attacked by a piece of the given kind and side on `sq`
+func (board *Board) getAttackSet(kind PieceKind, sq Square, isBlack bool, allPiecesBitboard Bitboard) Bitboard {
+	switch kind {
+	case Pawn:
+		return pawnAttackSet(sq, isBlack)
+
+	case Knight:
+		return knightAttackSets[uint(sq)]
+
+	case Bishop:
+		return bishopAttackSet(sq, allPiecesBitboard)
+
+	case Rook:
+		return rookAttackSet(sq, allPiecesBitboard)
+
+	case Queen:
+		return rookAttackSet(sq, allPiecesBitboard) |
+			bishopAttackSet(sq, allPiecesBitboard)
+
+	case King:
+		return kingAttackSets[uint(sq)]
+	}
+
+	return EmptyBitboard
+}
+
+// Returns the bitboard of squares attacked by a pawn of the given side on `sq`
+func pawnAttackSet(sq Square, isBlack bool) Bitboard {
+	if isBlack {
+		return blackPawnAttackSets[uint(sq)]
+	} else {
+		return whitePawnAttackSets[uint(sq)]
+	}
+}
+
+// Returns the bitboard of squares a pawn on `sq` can advance to
+func pawnPushSet(sq Square, isBlack bool, allPiecesBitboard Bitboard) (result Bitboard) {
+	var forwardsDirection int
+	var initialRank Rank
+
+	if isBlack {
+		forwardsDirection = 1
+		initialRank = Rank7
+	} else {
+		forwardsDirection = -1
+		initialRank = Rank2
+	}
+
+	// One-square advance
+	advanceSq, advanceSqValid := sq.Offset(0, forwardsDirection)
+	if !advanceSqValid || allPiecesBitboard.Get(advanceSq) {
+		return
+	}
+	result = result.Set(advanceSq)
+
+	// Two-square thrust
+	if sq.Rank() == initialRank {
+		thrustSq, _ := sq.Offset(0, 2*forwardsDirection)
+		if !allPiecesBitboard.Get(thrustSq) {
+			result = result.Set(thrustSq)
+		}
+	}
+
+	return
 }
 
 // Returns the bitboard of squares on which the king would be placed in check
 // This is the set of squares attacked by enemy pieces, with the king excluded as a blocker -
 // the king cannot block an attack against itself
-func getKingDangerMaskAndCheckingPieces(
-	enemyPieces       []Piece,
-	allPiecesBitboard Bitboard,
-	kingSquare        Square,
-	board             *Board,
-) (result Bitboard, checkingPieces []Piece) {
+func (board *Board) getKingDangerMask(kingSquare Square, allPiecesBitboard Bitboard) (result Bitboard) {
 	allPiecesExceptKingBitboard := allPiecesBitboard.Unset(kingSquare)
 
-	for _, piece := range enemyPieces {
-		attackSet := GetPieceAttackSet(piece, allPiecesExceptKingBitboard, board)
-		result |= attackSet
+	for v := board.sideBitboards[sideIndex(!board.blackToMove)]; v != EmptyBitboard; v = v.ClearLSB() {
+		sq := v.LSB()
+		attackSet := board.getCachedAttackSet(sq)
 
-		if attackSet.IntersectsSquare(kingSquare) {
-			checkingPieces = append(checkingPieces, piece)
+		// The cached attack set of a sliding piece checking the king stops at the king, so it
+		// must be recomputed to include the squares behind the king
+		kind := board.squareContents[uint32(sq)].Kind
+		if attackSet.Get(kingSquare) && (kind == Queen || kind == Rook || kind == Bishop) {
+			attackSet = board.getAttackSet(kind, sq, !board.blackToMove, allPiecesExceptKingBitboard)
 		}
+
+		result |= attackSet
 	}
 
 	return
 }
 
+// Returns the bitboard of enemy pieces attacking the king
+func (board *Board) getCheckers(kingSquare Square, allPiecesBitboard Bitboard) Bitboard {
+	enemyPieces := &board.pieceBitboards[sideIndex(!board.blackToMove)]
+
+	return knightAttackSets[uint(kingSquare)] & enemyPieces[Knight] |
+		pawnAttackSet(kingSquare, board.blackToMove) & enemyPieces[Pawn] |
+		bishopAttackSet(kingSquare, allPiecesBitboard) & (enemyPieces[Bishop] | enemyPieces[Queen]) |
+		rookAttackSet(kingSquare, allPiecesBitboard) & (enemyPieces[Rook] | enemyPieces[Queen])
+}
+
 // Returns the bitboard of squares containing pieces that are pinned to the king
-func getPinMask(
-	enemyPieces       []Piece,
-	allPiecesBitboard Bitboard,
-	kingSquare        Square,
-	board             *Board,
-) Bitboard {
+func (board *Board) getPinMask(kingSquare Square, allPiecesBitboard Bitboard) Bitboard {
 	// Approach: a piece is pinned if it is attacked by an enemy rook/bishop and both the pinned
 	// piece and the pinning piece would be attacked by our king if it were an enemy rook/bishop
 
+	enemyPieces := &board.pieceBitboards[sideIndex(!board.blackToMove)]
+
 	// Bitboard of all squares attacked by enemy rooks and queens
 	var enemyRookAttacks Bitboard
 
@@ -395,58 +403,42 @@ func getPinMask(
 	var enemyBishopAttacks Bitboard
 
 	// Bitboard of squares that would be attacked by a rook on the same square as our king
-	kingRookAttacks := board.rookAttackTable.GetAttackSet(kingSquare, allPiecesBitboard)
+	kingRookAttacks := rookAttackSet(kingSquare, allPiecesBitboard)
 
 	// Bitboard of squares that would be attacked by a bishop on the same square as our king
-	kingBishopAttacks := board.bishopAttackTable.GetAttackSet(kingSquare, allPiecesBitboard)
+	kingBishopAttacks := bishopAttackSet(kingSquare, allPiecesBitboard)
+
+	// The cached attack sets are masked to the rook or bishop directions so that only the relevant
+	// half of a queen's attacks is considered
+	enemyRookPinners := (enemyPieces[Rook] | enemyPieces[Queen]) & unobstructedRookAttacks[uint(kingSquare)]
+	for v := enemyRookPinners; v != EmptyBitboard; v = v.ClearLSB() {
+		sq := v.LSB()
+		enemyRookAttacks |= board.getCachedAttackSet(sq) & unobstructedRookAttacks[uint(sq)]
+	}
 
-	for _, enemyPiece := range enemyPieces {
-		if (enemyPiece.Kind == Rook || enemyPiece.Kind == Queen) && unobstructedRookAttacks[uint(kingSquare)].IntersectsSquare(enemyPiece.Square) {
-			enemyRookAttacks |= board.rookAttackTable.GetAttackSet(enemyPiece.Square, allPiecesBitboard)
-		}
-		if (enemyPiece.Kind == Bishop || enemyPiece.Kind == Queen) && unobstructedBishopAttacks[uint(kingSquare)].IntersectsSquare(enemyPiece.Square) {
-			enemyBishopAttacks |= board.bishopAttackTable.GetAttackSet(enemyPiece.Square, allPiecesBitboard)
-		}
+	enemyBishopPinners := (enemyPieces[Bishop] | enemyPieces[Queen]) & unobstructedBishopAttacks[uint(kingSquare)]
+	for v := enemyBishopPinners; v != EmptyBitboard; v = v.ClearLSB() {
+		sq := v.LSB()
+		enemyBishopAttacks |= board.getCachedAttackSet(sq) & unobstructedBishopAttacks[uint(sq)]
 	}
 
 	return (enemyRookAttacks & kingRookAttacks) | (enemyBishopAttacks & kingBishopAttacks)
 }
 
-// Given a bitboard, returns the bitboards for all combinations of pieces
-// occupying only the marked squares
-func allRelevantOccupacyBitboards(bitboard Bitboard) []Bitboard {
-	// Find the total number of combinations
-	numSetBits := bits.OnesCount64(uint64(bitboard))
-	numCombinations := 1 << uint(numSetBits);
-
-	// Find the indices of all ones in the set
-	setBitsIndices := make([]int, numSetBits, numSetBits)
-	bitIndex := 0
-	for i, v := 0, uint64(bitboard); v != 0; i++ {
-		trailingZeroes := bits.TrailingZeros64(v)
-		bitIndex += trailingZeroes
-		v >>= trailingZeroes + 1
-		setBitsIndices[i] = bitIndex
-		bitIndex += 1
-	}
+// True if capturing en passant with the pawn on `pawnSquare` does not leave the king in check
+func (board *Board) isEnPassantLegal(pawnSquare Square, kingSquare Square) bool {
+	enemyPieces := &board.pieceBitboards[sideIndex(!board.blackToMove)]
+	capturedPawnSquare := SquareAt(board.enPassantTarget.File(), pawnSquare.Rank())
 
-	// For each combination, project the bits onto those at the marked square indiceso
-	relevantOccupancyBitboards := make([]Bitboard, numCombinations, numCombinations)
-	for combination := 0; combination < numCombinations; combination++ {
-		relevantOccupancyBitboard := EmptyBitboard
-
-		for index, squareIndex := range setBitsIndices {
-			bit := 1 << index
-
-			if (combination & bit) != 0 {
-				relevantOccupancyBitboard = relevantOccupancyBitboard.Set(Square(squareIndex))
-			}
-		}
-
-		relevantOccupancyBitboards[combination] = relevantOccupancyBitboard
-	}
+	allPiecesAfterCapture := board.GetOccupiedBitboard().
+		Unset(pawnSquare).
+		Unset(capturedPawnSquare).
+		Set(board.enPassantTarget)
 
-	return relevantOccupancyBitboards
+	return knightAttackSets[uint(kingSquare)] & enemyPieces[Knight] == EmptyBitboard &&
+		pawnAttackSet(kingSquare, board.blackToMove) & enemyPieces[Pawn].Unset(capturedPawnSquare) == EmptyBitboard &&
+		bishopAttackSet(kingSquare, allPiecesAfterCapture) & (enemyPieces[Bishop] | enemyPieces[Queen]) == EmptyBitboard &&
+		rookAttackSet(kingSquare, allPiecesAfterCapture) & (enemyPieces[Rook] | enemyPieces[Queen]) == EmptyBitboard
 }
 
 func rayBitboard(square Square, occupancyBitboard Bitboard, offsetH int, offsetV int) (result Bitboard) {
@@ -481,74 +473,6 @@ func rookAttacks(square Square, occupancyBitboard Bitboard) Bitboard {
 		rayBitboard(square, occupancyBitboard, 0, -1)
 }
 
-// Detect the annoying en passant pin, when the capturing pawn and the captured pawn
-// are the only two pieces blocking an attack by a rook against the king on the same rank
-func detectEnPassantPin(pawnSquare Square, kingSquare Square, board *Board) bool {
-	var rank Rank
-	if board.blackToMove {
-		rank = Rank4
-	} else {
-		rank = Rank5
-	}
-
-	if kingSquare.Rank() != rank || pawnSquare.Rank() != rank {
-		return false
-	}
-
-	var potentialPinningRooks [2]Square
-	numPotentialPinningRooks := 0
-
-	for _, enemyPiece := range board.GetPiecesForSide(!board.blackToMove) {
-		if enemyPiece.Kind == Rook && enemyPiece.Square.Rank() == rank {
-			potentialPinningRooks[numPotentialPinningRooks] = enemyPiece.Square
-			numPotentialPinningRooks += 1
-		}
-	}
-
-	if numPotentialPinningRooks == 0 {
-		return false
-	}
-
-	// March from king square to rook square, loooking for intervening pieces that aren't the capturing
-	// pawn or the captured pawn
-	for i := 0; i < numPotentialPinningRooks; i++ {
-		rookSquare := potentialPinningRooks[i]
-		marchDirection := signum(int(rookSquare.File()) - int(kingSquare.File()))
-		capturedPawnSquare := SquareAt(board.enPassantTarget.File(), rank)
-
-		for fileIndex := int(kingSquare.File()) + marchDirection; fileIndex != int(rookSquare.File()); fileIndex += marchDirection {
-			if fileIndex < 0 || fileIndex >= 8 {
-				break
-			}
-
-			piece := board.GetPiece(SquareAt(File(fileIndex), rank))
-			if piece == nil {
-				continue
-			}
-
-			isCapturingPawn := piece.Square == pawnSquare
-			isCapturedPawn := piece.Square == capturedPawnSquare
-
-			if !isCapturedPawn && !isCapturingPawn {
-				return false
-			}
-		}
-	}
-	fmt.Print(board.Fen())
-
-
-	return true
-}
-
-// Magic numbers, relevant bit counts and relevant occupancy masks for rook and bishop move lookup tables
-var rookMagicNumbers           [64]Bitboard = [64]Bitboard {9259400972386469971, 378302682768609280, 432363709392882176, 792669819509149696, 72066390400761862, 3170696865660274184, 1297037800784303112, 4647724711070220416, 4621115431220971552, 9223935263835750912, 36451421809283073, 288371182435059713, 18155170357837952, 4630263409842586640, 577023710847092737, 45317473469333539, 4611827305877078112, 2904830830706688580, 1152992973133185794, 342560544483450920, 108228778483778560, 282574823891480, 2891324155045679234, 1126037350074400, 18155146737369096, 18049583955431424, 1153308569208094848, 72066392286826496, 1776900986372352, 9223409422399963264, 5764609739238410520, 9224515531128766592, 5875016085073297442, 1585302321930175552, 704374661713920, 5084146078588940, 11745466994378413313, 5512828164964881408, 9225711969732920610, 9232942392284283008, 72239288342839296, 306315145569697824, 576479445611774016, 6953593077768454216, 287006911430672, 180781701872517248, 180706952246067208, 142010875916, 337840929260044800, 9403691945961718400, 2328362177773175424, 140771849142400, 5630049374437760, 10450040056571232768, 4611967510617523456, 585471421900161088, 72077798662996233, 882706631853350929, 4900479379968131474, 1450198702706655489, 1234550484871155714, 36310289176725537, 8804951458436, 9224570659152134278}
-var rookRelevantBits           [64]uint = [64]uint {12, 11, 11, 11, 11, 11, 11, 12, 11, 10, 10, 10, 10, 10, 10, 12, 11, 10, 10, 10, 10, 10, 10, 12, 11, 10, 10, 10, 10, 10, 10, 12, 11, 10, 10, 10, 10, 10, 10, 12, 11, 10, 10, 10, 10, 10, 10, 12, 11, 10, 10, 10, 10, 10, 10, 12, 12, 11, 11, 11, 11, 11, 11, 12}
-var rookRelevantOccupancyMasks [64]Bitboard = [64]Bitboard {282578800148862, 565157600297596, 1130315200595066, 2260630401190006, 4521260802379886, 9042521604759646, 18085043209519166, 36170086419038334, 282578800180736, 565157600328704, 1130315200625152, 2260630401218048, 4521260802403840, 9042521604775424, 18085043209518592, 36170086419037696, 282578808340736, 565157608292864, 1130315208328192, 2260630408398848, 4521260808540160, 9042521608822784, 18085043209388032, 36170086418907136, 282580897300736, 565159647117824, 1130317180306432, 2260632246683648, 4521262379438080, 9042522644946944, 18085043175964672, 36170086385483776, 283115671060736, 565681586307584, 1130822006735872, 2261102847592448, 4521664529305600, 9042787892731904, 18085034619584512, 36170077829103616, 420017753620736, 699298018886144, 1260057572672512, 2381576680245248, 4624614895390720, 9110691325681664, 18082844186263552, 36167887395782656, 35466950888980736, 34905104758997504, 34344362452452352, 33222877839362048, 30979908613181440, 26493970160820224, 17522093256097792, 35607136465616896, 9079539427579068672, 8935706818303361536, 8792156787827803136, 8505056726876686336, 7930856604974452736, 6782456361169985536, 4485655873561051136, 9115426935197958144}
-
-var bishopMagicNumbers           [64]Bitboard = [64]Bitboard {1197960816612343840, 4617882883208794114, 653093423084995074, 4617386739290521616, 14989110974533862472, 572914420221952, 4908998412701533200, 2306124760412063760, 585476782154056016, 18017998161904128, 72392550023438368, 3458804182147399905, 9259420651123378193, 2306970077755607104, 435029482951155712, 648887928310865920, 1162529866810458368, 633971801333888, 650770163337013762, 423690235822100, 577587822632894465, 10376857041717168152, 4648841011882624096, 141841941139536, 38316060780400640, 13537191561528131, 288318389024809024, 9800396290612297738, 216317917724155908, 290764750756774016, 6923163202255728640, 1688987399946496, 1171222463469592576, 577596583250494016, 1165332809042758657, 157637533978067456, 2323859615337422976, 4503883095228672, 293583075781183497, 1209219799029448834, 4693331422714283008, 79184970449920, 13792415643078688, 1105014558976, 450364374104806400, 2314859073283040320, 9008307524117124, 4756366364089260544, 3458909666573025296, 4622140733466347521, 3479059935378539538, 18157336128520192, 2449958335278022658, 35872145809408, 2287265841741824, 2308097025236406560, 9264187854190821376, 577950457856, 288230453478983712, 7512461579728978944, 292734285302071809, 220711703620370826, 1152925937684251661, 666541566856396826}
-var bishopRelevantBits           [64]uint   = [64]uint {6, 5, 5, 5, 5, 5, 5, 6, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 7, 7, 7, 7, 5, 5, 5, 5, 7, 9, 9, 7, 5, 5, 5, 5, 7, 9, 9, 7, 5, 5, 5, 5, 7, 7, 7, 7, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 6, 5, 5, 5, 5, 5, 5, 6}
-var bishopRelevantOccupancyMasks [64]Bitboard = [64]Bitboard {18049651735527936, 70506452091904, 275415828992, 1075975168, 38021120, 8657588224, 2216338399232, 567382630219776, 9024825867763712, 18049651735527424, 70506452221952, 275449643008, 9733406720, 2216342585344, 567382630203392, 1134765260406784, 4512412933816832, 9024825867633664, 18049651768822272, 70515108615168, 2491752130560, 567383701868544, 1134765256220672, 2269530512441344, 2256206450263040, 4512412900526080, 9024834391117824, 18051867805491712, 637888545440768, 1135039602493440, 2269529440784384, 4539058881568768, 1128098963916800, 2256197927833600, 4514594912477184, 9592139778506752, 19184279556981248, 2339762086609920, 4538784537380864, 9077569074761728, 562958610993152, 1125917221986304, 2814792987328512, 5629586008178688, 11259172008099840, 22518341868716544, 9007336962655232, 18014673925310464, 2216338399232, 4432676798464, 11064376819712, 22137335185408, 44272556441600, 87995357200384, 35253226045952, 70506452091904, 567382630219776, 1134765260406784, 2832480465846272, 5667157807464448, 11333774449049600, 22526811443298304, 9024825867763712, 18049651735527936}
-
 // Attack set bitboards for non-sliding pieces
 var whitePawnAttackSets [64]Bitboard = [64]Bitboard {0, 0, 0, 0, 0, 0, 0, 0, 2, 5, 10, 20, 40, 80, 160, 64, 512, 1280, 2560, 5120, 10240, 20480, 40960, 16384, 131072, 327680, 655360, 1310720, 2621440, 5242880, 10485760, 4194304, 33554432, 83886080, 167772160, 335544320, 671088640, 1342177280, 2684354560, 1073741824, 8589934592, 21474836480, 42949672960, 85899345920, 171798691840, 343597383680, 687194767360, 274877906944, 2199023255552, 5497558138880, 10995116277760, 21990232555520, 43980465111040, 87960930222080, 175921860444160, 70368744177664, 562949953421312, 1407374883553280, 2814749767106560, 5629499534213120, 11258999068426240, 22517998136852480, 45035996273704960, 18014398509481984}
 var blackPawnAttackSets [64]Bitboard = [64]Bitboard {512, 1280, 2560, 5120, 10240, 20480, 40960, 16384, 131072, 327680, 655360, 1310720, 2621440, 5242880, 10485760, 4194304, 33554432, 83886080, 167772160, 335544320, 671088640, 1342177280, 2684354560, 1073741824, 8589934592, 21474836480, 42949672960, 85899345920, 171798691840, 343597383680, 687194767360, 274877906944, 2199023255552, 5497558138880, 10995116277760, 21990232555520, 43980465111040, 87960930222080, 175921860444160, 70368744177664, 562949953421312, 1407374883553280, 2814749767106560, 5629499534213120, 11258999068426240, 22517998136852480, 45035996273704960, 18014398509481984, 144115188075855872, 360287970189639680, 720575940379279360, 1441151880758558720, 2882303761517117440, 5764607523034234880, 11529215046068469760, 4611686018427387904, 0, 0, 0, 0, 0, 0, 0, 0}
@@ -0,0 +1,67 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"gogm/chess/magic"
+	"math/rand"
+	"testing"
+)
+
+// Checks the sliding attack tables against the reference ray-based attack generator
+// Run with -tags pext to check the PEXT backend
+func TestSlidingAttackTables(t *testing.T) {
+	assert := assert.New(t)
+	rng := rand.New(rand.NewSource(1))
+
+	t.Logf("sliding attack backend: %v", chess.SlidingAttackBackend())
+
+	bishopTable := chess.CreateBishopAttackTable()
+	rookTable := chess.CreateRookAttackTable()
+
+	for sq := chess.Square(0); sq < 64; sq++ {
+		for i := 0; i < 1000; i++ {
+			// Sparse random occupancy, as in real positions
+			occupancy := chess.Bitboard(rng.Uint64() & rng.Uint64() & rng.Uint64())
+
+			assert.Equal(magic.Attacks(sq, chess.Bishop, occupancy), bishopTable.GetAttackSet(sq, occupancy))
+			assert.Equal(magic.Attacks(sq, chess.Rook, occupancy), rookTable.GetAttackSet(sq, occupancy))
+		}
+	}
+}
+
+func BenchmarkGetLegalMoves(b *testing.B) {
+	board, err := chess.LoadFen("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	moves := make([]chess.Move, 0, 256)
+
+	for i := 0; i < b.N; i++ {
+		moves = board.AppendLegalMoves(moves[:0], false)
+	}
+}
+
+func TestBetweenAndLine(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(chess.EmptyBitboard.Set(chess.B2).Set(chess.C3), chess.BetweenBB(chess.A1, chess.D4))
+	assert.Equal(chess.BetweenBB(chess.A1, chess.D4), chess.BetweenBB(chess.D4, chess.A1))
+	assert.Equal(chess.EmptyBitboard, chess.BetweenBB(chess.E1, chess.E2))
+	assert.Equal(chess.EmptyBitboard, chess.BetweenBB(chess.A1, chess.B3))
+
+	mainDiagonal := chess.EmptyBitboard
+	for sq := chess.A1; ; {
+		mainDiagonal = mainDiagonal.Set(sq)
+		var ok bool
+		if sq, ok = sq.Offset(1, -1); !ok {
+			break
+		}
+	}
+
+	assert.Equal(mainDiagonal, chess.LineBB(chess.C3, chess.F6))
+	assert.Equal(mainDiagonal, chess.LineBB(chess.H8, chess.A1))
+	assert.Equal(chess.EmptyBitboard, chess.LineBB(chess.A1, chess.B3))
+	assert.Equal(chess.EmptyBitboard, chess.LineBB(chess.A1, chess.A1))
+}
@@ -0,0 +1,102 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Language of the piece letters used to write moves in algebraic notation
+// English is the standard used by SAN, UCI and PGN; the others are for display and typed moves
+type Notation uint8
+
+const (
+	EnglishNotation Notation = iota
+	GermanNotation
+	FrenchNotation
+	SpanishNotation
+
+	// Piece symbols (e.g. ♘f3) instead of letters
+	FigurineNotation
+)
+
+// Letters of the king, queen, bishop, knight and rook in each notation, in PieceKind order
+var notationPieceLetters = [...][5]rune{
+	EnglishNotation:  {'K', 'Q', 'B', 'N', 'R'},
+	GermanNotation:   {'K', 'D', 'L', 'S', 'T'},
+	FrenchNotation:   {'R', 'D', 'F', 'C', 'T'},
+	SpanishNotation:  {'R', 'D', 'A', 'C', 'T'},
+	FigurineNotation: {'♔', '♕', '♗', '♘', '♖'},
+}
+
+// Black figurines, accepted when reading moves in figurine notation
+var blackFigurines = [5]rune{'♚', '♛', '♝', '♞', '♜'}
+
+func (notation Notation) String() string {
+	switch notation {
+	case GermanNotation:
+		return "german"
+	case FrenchNotation:
+		return "french"
+	case SpanishNotation:
+		return "spanish"
+	case FigurineNotation:
+		return "figurine"
+	default:
+		return "english"
+	}
+}
+
+// Returns the notation with the given name (english, german, french, spanish or figurine)
+func NotationWithName(name string) (Notation, error) {
+	for notation := range notationPieceLetters {
+		if Notation(notation).String() == name {
+			return Notation(notation), nil
+		}
+	}
+
+	return EnglishNotation, errors.New(fmt.Sprintf("unknown notation: %v", name))
+}
+
+// Returns the letter or symbol representing the piece, which must not be a pawn
+func (notation Notation) PieceLetter(kind PieceKind) rune {
+	return notationPieceLetters[notation][kind]
+}
+
+// Returns the move written in algebraic notation with the piece letters of the given notation,
+// e.g. Sf3 in German. The move must be legal in the current position
+func (board *Board) LocalizedSan(move Move, notation Notation) string {
+	return translatePieceLetters(board.San(move), notationPieceLetters[EnglishNotation], notationPieceLetters[notation])
+}
+
+// Returns the legal move written in algebraic notation with the piece letters of the given
+// notation, accepting the same variations as MoveWithSan
+func (board *Board) MoveWithLocalizedSan(san string, notation Notation) (Move, error) {
+	if notation == FigurineNotation {
+		san = translatePieceLetters(san, blackFigurines, notationPieceLetters[FigurineNotation])
+	}
+
+	english := translatePieceLetters(san, notationPieceLetters[notation], notationPieceLetters[EnglishNotation])
+
+	move, err := board.MoveWithSan(english)
+	if err != nil {
+		// Report the move as it was written
+		return Move{}, errors.New(strings.Replace(err.Error(), english, san, 1))
+	}
+
+	return move, nil
+}
+
+// Replace each piece letter in `from` with the letter of the same piece in `to`
+// Castling (O-O) is unaffected, as O is not a piece letter in any notation
+func translatePieceLetters(san string, from [5]rune, to [5]rune) string {
+	return strings.Map(func(char rune) rune {
+		for index, letter := range from {
+			if char == letter {
+				return to[index]
+			}
+		}
+
+		return char
+	}, san)
+}
@@ -0,0 +1,59 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func TestLocalizedSan(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	assert.Nil(err)
+
+	tests := []struct {
+		notation chess.Notation
+		uci      string
+		san      string
+	}{
+		{chess.EnglishNotation, "e5f7", "Nxf7"},
+		{chess.GermanNotation, "e5f7", "Sxf7"},
+		{chess.GermanNotation, "f3f6", "Dxf6"},
+		{chess.FrenchNotation, "e1d1", "Rd1"},
+		{chess.FrenchNotation, "a1b1", "Tb1"},
+		{chess.SpanishNotation, "e2a6", "Axa6"},
+		{chess.FigurineNotation, "c3b5", "♘b5"},
+		{chess.GermanNotation, "e1g1", "O-O"},
+	}
+
+	for _, test := range tests {
+		move, err := chess.MoveWithUciNotation(test.uci)
+		assert.Nil(err)
+		assert.Equal(test.san, board.LocalizedSan(move, test.notation), test.uci)
+
+		parsed, err := board.MoveWithLocalizedSan(test.san, test.notation)
+		assert.Nil(err, test.san)
+		assert.Equal(move, parsed, test.san)
+	}
+
+	// Black figurines are accepted when reading moves
+	board, err = chess.LoadFen(chess.StartingPositionFen)
+	assert.Nil(err)
+	board.MakeMove(chess.Move{Source: chess.E2, Destination: chess.E4})
+
+	move, err := board.MoveWithLocalizedSan("♞f6", chess.FigurineNotation)
+	assert.Nil(err)
+	assert.Equal(chess.Move{Source: chess.G8, Destination: chess.F6}, move)
+
+	// Errors show the move as it was written
+	_, err = board.MoveWithLocalizedSan("Sd4", chess.GermanNotation)
+	assert.EqualError(err, "illegal move: Sd4")
+
+	notation, err := chess.NotationWithName("french")
+	assert.Nil(err)
+	assert.Equal(chess.FrenchNotation, notation)
+
+	_, err = chess.NotationWithName("klingon")
+	assert.NotNil(err)
+}
@@ -0,0 +1,139 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Implemented by bots whose options, listed in BotInfo.Options, can be set by name, e.g. by the
+// UCI "setoption" command. Values are written as in UCI: numbers for spin options, "true" or
+// "false" for check options, one of the choices for combo options, and nothing for buttons
+type ConfigurableBot interface {
+	IdentifiableBot
+	SetOption(name string, value string) error
+}
+
+// The options of a bot and the functions applying them. Each part of a bot registers its own
+// options, and front ends list and set them all through BotOptions and Set without knowing about
+// any of them, so a new option needs no change to the front ends
+// Options are found by name regardless of case, as in UCI
+type OptionRegistry struct {
+	options []registeredOption
+}
+
+type registeredOption struct {
+	option BotOption
+	apply  func(value string) error
+}
+
+// Register a spin option, which takes whole numbers from min to max
+func (registry *OptionRegistry) AddSpin(name string, defaultValue int, min int, max int, apply func(int)) {
+	option := BotOption{Name: name, Type: SpinOption, Default: strconv.Itoa(defaultValue), Min: min, Max: max}
+
+	registry.add(option, func(value string) error {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < min || number > max {
+			return errors.New(fmt.Sprintf("%v must be a whole number from %v to %v: %v", name, min, max, value))
+		}
+
+		apply(number)
+		return nil
+	})
+}
+
+// Register a check option, which is either on or off
+func (registry *OptionRegistry) AddCheck(name string, defaultValue bool, apply func(bool)) {
+	option := BotOption{Name: name, Type: CheckOption, Default: strconv.FormatBool(defaultValue)}
+
+	registry.add(option, func(value string) error {
+		switch strings.ToLower(value) {
+		case "true":
+			apply(true)
+		case "false":
+			apply(false)
+		default:
+			return errors.New(fmt.Sprintf("%v must be true or false: %v", name, value))
+		}
+
+		return nil
+	})
+}
+
+// Register a combo option, which takes one of the choices
+func (registry *OptionRegistry) AddCombo(name string, defaultValue string, choices []string, apply func(string)) {
+	option := BotOption{Name: name, Type: ComboOption, Default: defaultValue, Choices: choices}
+
+	registry.add(option, func(value string) error {
+		for _, choice := range choices {
+			if strings.EqualFold(choice, value) {
+				apply(choice)
+				return nil
+			}
+		}
+
+		return errors.New(fmt.Sprintf("%v must be one of %v: %v", name, strings.Join(choices, ", "), value))
+	})
+}
+
+// Register a string option, which takes any text that `apply` accepts
+func (registry *OptionRegistry) AddString(name string, defaultValue string, apply func(string) error) {
+	registry.add(BotOption{Name: name, Type: StringOption, Default: defaultValue}, apply)
+}
+
+// Register a button, which does something when pressed rather than holding a value
+func (registry *OptionRegistry) AddButton(name string, press func()) {
+	registry.add(BotOption{Name: name, Type: ButtonOption}, func(string) error {
+		press()
+		return nil
+	})
+}
+
+// Options registered under the same name as an earlier one replace it
+func (registry *OptionRegistry) add(option BotOption, apply func(value string) error) {
+	for index := range registry.options {
+		if strings.EqualFold(registry.options[index].option.Name, option.Name) {
+			registry.options[index] = registeredOption{option, apply}
+			return
+		}
+	}
+
+	registry.options = append(registry.options, registeredOption{option, apply})
+}
+
+// Returns the descriptions of the options, in the order they were registered
+func (registry *OptionRegistry) BotOptions() []BotOption {
+	options := make([]BotOption, 0, len(registry.options))
+	for _, registered := range registry.options {
+		options = append(options, registered.option)
+	}
+
+	return options
+}
+
+// Returns whether an option is registered under the name
+func (registry *OptionRegistry) Has(name string) bool {
+	return registry.find(name) != nil
+}
+
+// Set the option with the name to the value, returning an error if there is no such option or it
+// does not take the value
+func (registry *OptionRegistry) Set(name string, value string) error {
+	registered := registry.find(name)
+	if registered == nil {
+		return errors.New(fmt.Sprintf("unknown option: %v", name))
+	}
+
+	return registered.apply(value)
+}
+
+func (registry *OptionRegistry) find(name string) *registeredOption {
+	for index := range registry.options {
+		if strings.EqualFold(registry.options[index].option.Name, name) {
+			return &registry.options[index]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func TestOptionRegistryLists(t *testing.T) {
+	var registry chess.OptionRegistry
+	registry.AddSpin("Hash", 16, 1, 4096, func(int) {})
+	registry.AddCheck("Ponder", false, func(bool) {})
+	registry.AddCombo("Style", "Normal", []string{"Solid", "Normal", "Risky"}, func(string) {})
+	registry.AddString("EvalFile", "", func(string) error { return nil })
+	registry.AddButton("Clear Hash", func() {})
+
+	assert.Equal(t, []chess.BotOption{
+		{Name: "Hash", Type: chess.SpinOption, Default: "16", Min: 1, Max: 4096},
+		{Name: "Ponder", Type: chess.CheckOption, Default: "false"},
+		{Name: "Style", Type: chess.ComboOption, Default: "Normal", Choices: []string{"Solid", "Normal", "Risky"}},
+		{Name: "EvalFile", Type: chess.StringOption},
+		{Name: "Clear Hash", Type: chess.ButtonOption},
+	}, registry.BotOptions())
+}
+
+func TestOptionRegistrySet(t *testing.T) {
+	assert := assert.New(t)
+
+	var registry chess.OptionRegistry
+	hash, ponder, style, pressed := 0, false, "", false
+	registry.AddSpin("Hash", 16, 1, 4096, func(value int) { hash = value })
+	registry.AddCheck("Ponder", false, func(value bool) { ponder = value })
+	registry.AddCombo("Style", "Normal", []string{"Solid", "Normal", "Risky"}, func(value string) { style = value })
+	registry.AddButton("Clear Hash", func() { pressed = true })
+
+	assert.NoError(registry.Set("hash", "64"))
+	assert.Equal(64, hash)
+	assert.NoError(registry.Set("Ponder", "true"))
+	assert.True(ponder)
+	assert.NoError(registry.Set("style", "risky"))
+	assert.Equal("Risky", style)
+	assert.NoError(registry.Set("Clear Hash", ""))
+	assert.True(pressed)
+
+	assert.Error(registry.Set("Hash", "0"))
+	assert.Error(registry.Set("Hash", "lots"))
+	assert.Error(registry.Set("Ponder", "yes"))
+	assert.Error(registry.Set("Style", "Reckless"))
+	assert.Error(registry.Set("Threads", "4"))
+	assert.Equal(64, hash)
+
+	assert.True(registry.Has("clear hash"))
+	assert.False(registry.Has("Threads"))
+}
+
+func TestOptionRegistryReplaces(t *testing.T) {
+	var registry chess.OptionRegistry
+	registry.AddSpin("Hash", 16, 1, 4096, func(int) {})
+	registry.AddSpin("hash", 32, 1, 1024, func(int) {})
+
+	assert.Equal(t, []chess.BotOption{
+		{Name: "hash", Type: chess.SpinOption, Default: "32", Min: 1, Max: 1024},
+	}, registry.BotOptions())
+}
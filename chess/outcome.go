@@ -0,0 +1,91 @@
+package chess
+
+// Result of a game
+type Result uint8
+
+const (
+	// The game is not over
+	NoResult Result = iota
+	WhiteWins
+	BlackWins
+	Draw
+)
+
+// Returns the result as written in PGN
+func (result Result) String() string {
+	switch result {
+	case WhiteWins:
+		return "1-0"
+	case BlackWins:
+		return "0-1"
+	case Draw:
+		return "1/2-1/2"
+	default:
+		return "*"
+	}
+}
+
+// How a game ended
+type Termination uint8
+
+const (
+	// The game is not over
+	NoTermination Termination = iota
+	Checkmate
+	Stalemate
+
+	// No capture or pawn move in the last 75 moves by each side
+	SeventyFiveMoveRule
+
+	// The same position occurred five times
+	FivefoldRepetition
+
+	// Neither side can checkmate the other, e.g. due to insufficient material
+	DeadPosition
+
+	// The game ended by a rule specific to a variant (e.g. a king reaching the centre), with the
+	// winner given by the result
+	VariantEnd
+)
+
+func (termination Termination) String() string {
+	switch termination {
+	case Checkmate:
+		return "checkmate"
+	case Stalemate:
+		return "stalemate"
+	case SeventyFiveMoveRule:
+		return "75-move rule"
+	case FivefoldRepetition:
+		return "fivefold repetition"
+	case DeadPosition:
+		return "dead position"
+	case VariantEnd:
+		return "variant end"
+	default:
+		return "none"
+	}
+}
+
+// Result of a game and how it ended. The zero value means the game is not over
+type Outcome struct {
+	Result      Result
+	Termination Termination
+}
+
+// Whether the game is over
+func (outcome Outcome) IsOver() bool {
+	return outcome.Result != NoResult
+}
+
+// Returns the winner of the game, and whether there is one
+func (outcome Outcome) Winner() (isBlack bool, ok bool) {
+	switch outcome.Result {
+	case WhiteWins:
+		return false, true
+	case BlackWins:
+		return true, true
+	default:
+		return false, false
+	}
+}
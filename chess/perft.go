@@ -0,0 +1,82 @@
+package chess
+
+import (
+	"context"
+)
+
+// https://www.chessprogramming.org/Perft
+
+// Returns the number of positions reached by playing every sequence of `depth` legal moves from the
+// position, which can be compared with known results to test move generation
+// The moves at the last ply are counted without being made ("bulk counting"), so this is not a test
+// of make/unmake; VerifyPosition checks that as well
+func Perft(board *Board, depth int) uint64 {
+	nodes, _ := PerftContext(context.Background(), board, depth)
+	return nodes
+}
+
+// Like Perft, but gives up and returns the context's error once it is cancelled, so that a count
+// that would take too long can be abandoned
+func PerftContext(ctx context.Context, board *Board, depth int) (uint64, error) {
+	if depth == 0 {
+		return 1, nil
+	}
+
+	moves := board.GetLegalMoves(false)
+	if depth == 1 {
+		return uint64(len(moves)), nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	nodes := uint64(0)
+	for _, move := range moves {
+		unmove := board.MakeMove(move)
+		childNodes, err := PerftContext(ctx, board, depth - 1)
+		board.UnmakeMove(unmove)
+
+		if err != nil {
+			return 0, err
+		}
+		nodes += childNodes
+	}
+
+	return nodes, nil
+}
+
+// A legal move and the number of positions reached below it, as counted by Divide
+type DivideResult struct {
+	Move  Move
+	Nodes uint64
+}
+
+// Returns the perft count to the given depth below each legal move of the position, so that the
+// counts add up to Perft(board, depth). Comparing them with another engine's narrows a wrong count
+// down to the moves it comes from
+func Divide(board *Board, depth int) []DivideResult {
+	results, _ := DivideContext(context.Background(), board, depth)
+	return results
+}
+
+// Like Divide, but gives up and returns the context's error once it is cancelled
+func DivideContext(ctx context.Context, board *Board, depth int) ([]DivideResult, error) {
+	if depth == 0 {
+		return nil, nil
+	}
+
+	var results []DivideResult
+	for _, move := range board.GetLegalMoves(false) {
+		unmove := board.MakeMove(move)
+		nodes, err := PerftContext(ctx, board, depth - 1)
+		board.UnmakeMove(unmove)
+
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, DivideResult{Move: move, Nodes: nodes})
+	}
+
+	return results, nil
+}
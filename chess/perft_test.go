@@ -0,0 +1,65 @@
+package chess_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func TestPerft(t *testing.T) {
+	for _, position := range perftPositions {
+		board, err := chess.LoadFen(position.fen)
+		assert.Nil(t, err)
+
+		assert.Equal(t, uint64(1), chess.Perft(board, 0))
+
+		for index, nodes := range position.nodes {
+			assert.Equal(t, nodes, chess.Perft(board, index + 1), "%v depth %v", position.fen, index + 1)
+		}
+	}
+}
+
+func TestDivide(t *testing.T) {
+	for _, position := range perftPositions {
+		board, err := chess.LoadFen(position.fen)
+		assert.Nil(t, err)
+
+		depth := len(position.nodes)
+		results := chess.Divide(board, depth)
+		assert.Len(t, results, int(position.nodes[0]))
+
+		total := uint64(0)
+		for _, result := range results {
+			total += result.Nodes
+		}
+		assert.Equal(t, position.nodes[depth - 1], total, position.fen)
+	}
+}
+
+func TestPerftContext(t *testing.T) {
+	board, _ := chess.LoadFen(chess.StartingPositionFen)
+	fen, hash := board.Fen(), board.Hash()
+
+	nodes, err := chess.PerftContext(context.Background(), board, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(8902), nodes)
+
+	// A cancelled count gives up and leaves the board as it was
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = chess.PerftContext(ctx, board, 3)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	results, err := chess.DivideContext(ctx, board, 3)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, results)
+	assert.Equal(t, fen, board.Fen())
+	assert.Equal(t, hash, board.Hash())
+
+	// Counts to depth 1 need no moves to be made, so they always finish
+	nodes, err = chess.PerftContext(ctx, board, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(20), nodes)
+}
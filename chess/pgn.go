@@ -0,0 +1,529 @@
+package chess
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Reads games one at a time from text in Portable Game Notation
+type PgnReader struct {
+	reader *bufio.Reader
+
+	// Whether the last rune read ended a line, as escape lines (%) and tags start at the beginning
+	// of a line
+	atLineStart bool
+}
+
+type pgnTokenKind uint8
+
+const (
+	pgnEnd pgnTokenKind = iota
+	pgnTagStart
+	pgnSymbol
+	pgnComment
+	pgnNag
+	pgnVariationStart
+	pgnVariationEnd
+	pgnResult
+)
+
+type pgnToken struct {
+	kind pgnTokenKind
+	text string
+}
+
+// Returns a reader of the games in the PGN text read from `reader`
+func NewPgnReader(reader io.Reader) *PgnReader {
+	return &PgnReader{reader: bufio.NewReader(reader), atLineStart: true}
+}
+
+// Returns every game in the PGN text, stopping at the first game that cannot be read
+func ParsePgn(text string) ([]*Game, error) {
+	pgn := NewPgnReader(strings.NewReader(text))
+
+	var games []*Game
+	for {
+		game, err := pgn.Read()
+		if err == io.EOF {
+			return games, nil
+		}
+		if err != nil {
+			return games, err
+		}
+
+		games = append(games, game)
+	}
+}
+
+// Returns the next game, or io.EOF if there are no more games
+// Moves are checked to be legal. If a game cannot be read, the error says why and the reader skips
+// to the next game, so that the remaining games can still be read
+func (pgn *PgnReader) Read() (*Game, error) {
+	game := &Game{}
+
+	// Tags
+	for {
+		pgn.skipWhitespace()
+
+		char, _, err := pgn.reader.ReadRune()
+		if err == io.EOF {
+			if len(game.Tags) == 0 {
+				return nil, io.EOF
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if char != '[' {
+			pgn.unreadRune()
+			break
+		}
+
+		if err := pgn.readTag(game); err != nil {
+			pgn.skipToNextGame()
+			return nil, err
+		}
+	}
+
+	board, err := game.StartingBoard()
+	if err != nil {
+		pgn.skipToNextGame()
+		return nil, errors.New(fmt.Sprintf("bad FEN tag: %v", err))
+	}
+
+	game.Moves, err = pgn.readLine(game, board, false)
+	if err != nil {
+		pgn.skipToNextGame()
+		return nil, err
+	}
+
+	if game.Result == NoResult {
+		game.Result = resultWithPgnName(game.Tag("Result"))
+	}
+
+	return game, nil
+}
+
+// Read the tag following a [
+func (pgn *PgnReader) readTag(game *Game) error {
+	pgn.atLineStart = false
+
+	var name strings.Builder
+	for {
+		char, _, err := pgn.reader.ReadRune()
+		if err != nil {
+			return errors.New("unterminated tag")
+		}
+
+		if unicode.IsSpace(char) {
+			break
+		}
+
+		name.WriteRune(char)
+	}
+
+	pgn.skipWhitespace()
+
+	if char, _, err := pgn.reader.ReadRune(); err != nil || char != '"' {
+		return errors.New(fmt.Sprintf("missing value of tag %v", name.String()))
+	}
+
+	var value strings.Builder
+	for {
+		char, _, err := pgn.reader.ReadRune()
+		if err != nil {
+			return errors.New(fmt.Sprintf("unterminated value of tag %v", name.String()))
+		}
+
+		if char == '"' {
+			break
+		}
+
+		if char == '\\' {
+			if char, _, err = pgn.reader.ReadRune(); err != nil {
+				return errors.New(fmt.Sprintf("unterminated value of tag %v", name.String()))
+			}
+		}
+
+		value.WriteRune(char)
+	}
+
+	pgn.skipWhitespace()
+
+	if char, _, err := pgn.reader.ReadRune(); err != nil || char != ']' {
+		return errors.New(fmt.Sprintf("missing ] after tag %v", name.String()))
+	}
+
+	game.Tags = append(game.Tags, GameTag{Name: name.String(), Value: value.String()})
+	return nil
+}
+
+// Read the moves of a line, starting from the position on the board, until the end of the
+// variation or game. The board is returned to its original position
+func (pgn *PgnReader) readLine(game *Game, board *Board, isVariation bool) ([]GameMove, error) {
+	var moves []GameMove
+	var unmoves []Unmove
+
+	defer func() {
+		for index := len(unmoves) - 1; index >= 0; index-- {
+			board.UnmakeMove(unmoves[index])
+		}
+	}()
+
+	for {
+		token, err := pgn.readToken()
+		if err != nil {
+			return nil, err
+		}
+
+		switch token.kind {
+		case pgnEnd, pgnTagStart, pgnResult:
+			if isVariation {
+				return nil, errors.New("unterminated variation")
+			}
+
+			if token.kind == pgnResult {
+				game.Result = resultWithPgnName(token.text)
+			}
+
+			return moves, nil
+
+		case pgnVariationEnd:
+			if !isVariation {
+				return nil, errors.New("unexpected )")
+			}
+
+			return moves, nil
+
+		case pgnVariationStart:
+			if len(moves) == 0 {
+				return nil, errors.New("variation before the first move")
+			}
+
+			// The variation replaces the last move
+			last := len(moves) - 1
+			board.UnmakeMove(unmoves[last])
+
+			variation, err := pgn.readLine(game, board, true)
+			if err != nil {
+				unmoves = unmoves[:last]
+				return nil, err
+			}
+
+			moves[last].Variations = append(moves[last].Variations, variation)
+			unmoves[last] = board.MakeMove(moves[last].Move)
+
+		case pgnComment:
+			if len(moves) > 0 {
+				moves[len(moves) - 1].Comment = joinComments(moves[len(moves) - 1].Comment, token.text)
+			} else if !isVariation {
+				game.Comment = joinComments(game.Comment, token.text)
+			}
+
+		case pgnNag:
+			nag, err := strconv.Atoi(token.text)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("bad annotation: $%v", token.text))
+			}
+
+			if len(moves) > 0 {
+				moves[len(moves) - 1].Nags = append(moves[len(moves) - 1].Nags, nag)
+			}
+
+		case pgnSymbol:
+			san := strings.TrimRight(token.text, "!?")
+
+			move, err := board.MoveWithSan(san)
+			if err != nil {
+				return nil, err
+			}
+
+			gameMove := GameMove{Move: move}
+			if nag := nagWithSuffix(token.text[len(san):]); nag != 0 {
+				gameMove.Nags = append(gameMove.Nags, nag)
+			}
+
+			moves = append(moves, gameMove)
+			unmoves = append(unmoves, board.MakeMove(move))
+		}
+	}
+}
+
+// Returns the next token of the movetext, skipping move numbers
+func (pgn *PgnReader) readToken() (pgnToken, error) {
+	for {
+		pgn.skipWhitespace()
+
+		if pgn.atLineStart {
+			if peeked, err := pgn.reader.Peek(1); err == nil && peeked[0] == '[' {
+				return pgnToken{kind: pgnTagStart}, nil
+			}
+		}
+
+		char, _, err := pgn.reader.ReadRune()
+		if err == io.EOF {
+			return pgnToken{kind: pgnEnd}, nil
+		}
+		if err != nil {
+			return pgnToken{}, err
+		}
+
+		pgn.atLineStart = false
+
+		switch char {
+		case '{':
+			text, err := pgn.reader.ReadString('}')
+			if err != nil {
+				return pgnToken{}, errors.New("unterminated comment")
+			}
+
+			return pgnToken{kind: pgnComment, text: strings.Join(strings.Fields(strings.TrimSuffix(text, "}")), " ")}, nil
+
+		case ';':
+			text, _ := pgn.reader.ReadString('\n')
+			pgn.atLineStart = true
+			return pgnToken{kind: pgnComment, text: strings.TrimSpace(text)}, nil
+
+		case '(':
+			return pgnToken{kind: pgnVariationStart}, nil
+
+		case ')':
+			return pgnToken{kind: pgnVariationEnd}, nil
+
+		case '$':
+			return pgnToken{kind: pgnNag, text: pgn.readSymbol()}, nil
+
+		case '.':
+			// Dots after a move number
+			continue
+		}
+
+		pgn.unreadRune()
+		symbol := pgn.readSymbol()
+
+		if symbol == "" {
+			return pgnToken{}, errors.New(fmt.Sprintf("unexpected character: %q", char))
+		}
+
+		if resultWithPgnName(symbol) != NoResult || symbol == "*" {
+			return pgnToken{kind: pgnResult, text: symbol}, nil
+		}
+
+		// Move numbers are followed by dots, which are skipped above
+		if strings.Trim(symbol, "0123456789") == "" {
+			continue
+		}
+
+		return pgnToken{kind: pgnSymbol, text: symbol}, nil
+	}
+}
+
+// Read the characters of a move, move number, result or annotation number
+func (pgn *PgnReader) readSymbol() string {
+	var sb strings.Builder
+
+	for {
+		char, _, err := pgn.reader.ReadRune()
+		if err != nil {
+			break
+		}
+
+		if !unicode.IsLetter(char) && !unicode.IsDigit(char) && !strings.ContainsRune("+#=-:/!?*_", char) {
+			pgn.unreadRune()
+			break
+		}
+
+		sb.WriteRune(char)
+	}
+
+	return sb.String()
+}
+
+// Skip whitespace and escaped lines (starting with %)
+func (pgn *PgnReader) skipWhitespace() {
+	for {
+		char, _, err := pgn.reader.ReadRune()
+		if err != nil {
+			return
+		}
+
+		if char == '%' && pgn.atLineStart {
+			pgn.reader.ReadString('\n')
+			pgn.atLineStart = true
+			continue
+		}
+
+		if !unicode.IsSpace(char) {
+			pgn.unreadRune()
+			return
+		}
+
+		pgn.atLineStart = char == '\n'
+	}
+}
+
+// Skip the rest of the game after an error, up to the tags of the next game
+func (pgn *PgnReader) skipToNextGame() {
+	for {
+		if pgn.atLineStart {
+			if peeked, err := pgn.reader.Peek(1); err != nil || peeked[0] == '[' {
+				return
+			}
+		}
+
+		char, _, err := pgn.reader.ReadRune()
+		if err != nil {
+			return
+		}
+
+		pgn.atLineStart = char == '\n'
+	}
+}
+
+func (pgn *PgnReader) unreadRune() {
+	pgn.reader.UnreadRune()
+}
+
+func joinComments(a string, b string) string {
+	if a == "" {
+		return b
+	}
+
+	return a + " " + b
+}
+
+// Returns the annotation glyph for a move suffix such as ! or ?!, or 0 if there is none
+func nagWithSuffix(suffix string) int {
+	switch suffix {
+	case "!":
+		return NagGoodMove
+	case "?":
+		return NagMistake
+	case "!!":
+		return NagBrilliantMove
+	case "??":
+		return NagBlunder
+	case "!?":
+		return NagInterestingMove
+	case "?!":
+		return NagDubiousMove
+	}
+
+	return 0
+}
+
+// Returns the result written in PGN as `name`, or NoResult if it is not a finished result
+func resultWithPgnName(name string) Result {
+	for _, result := range []Result{WhiteWins, BlackWins, Draw} {
+		if result.String() == name {
+			return result
+		}
+	}
+
+	return NoResult
+}
+
+// Returns the game in Portable Game Notation, with the tags in order followed by the moves,
+// comments, annotations and variations, wrapped to 80 columns
+func (game *Game) Pgn() string {
+	var sb strings.Builder
+
+	for _, tag := range game.Tags {
+		value := strings.ReplaceAll(strings.ReplaceAll(tag.Value, `\`, `\\`), `"`, `\"`)
+		sb.WriteString(fmt.Sprintf("[%v \"%v\"]\n", tag.Name, value))
+	}
+
+	if len(game.Tags) > 0 {
+		sb.WriteRune('\n')
+	}
+
+	var tokens []string
+	if game.Comment != "" {
+		tokens = append(tokens, "{" + game.Comment + "}")
+	}
+
+	// Moves that cannot be read back are left out rather than written wrongly
+	if board, err := game.StartingBoard(); err == nil {
+		tokens = appendPgnLine(tokens, board, game.Moves, game.startingMoveNumber())
+	}
+
+	tokens = append(tokens, game.Result.String())
+
+	// Wrap the movetext
+	lineLength := 0
+	for _, token := range tokens {
+		if lineLength > 0 && lineLength + 1 + len(token) > 80 {
+			sb.WriteRune('\n')
+			lineLength = 0
+		} else if lineLength > 0 {
+			sb.WriteRune(' ')
+			lineLength++
+		}
+
+		sb.WriteString(token)
+		lineLength += len(token)
+	}
+
+	sb.WriteRune('\n')
+	return sb.String()
+}
+
+// Append the tokens of the line starting from the position on the board, which is returned to its
+// original position
+func appendPgnLine(tokens []string, board *Board, moves []GameMove, moveNumber int) []string {
+	var unmoves []Unmove
+
+	// Black's moves are numbered at the start of a line and after comments and variations
+	numberNextMove := true
+
+	for _, move := range moves {
+		if !board.blackToMove {
+			tokens = append(tokens, fmt.Sprintf("%v.", moveNumber))
+		} else if numberNextMove {
+			tokens = append(tokens, fmt.Sprintf("%v...", moveNumber))
+		}
+		numberNextMove = false
+
+		tokens = append(tokens, board.San(move.Move))
+
+		for _, nag := range move.Nags {
+			tokens = append(tokens, fmt.Sprintf("$%v", nag))
+		}
+
+		if move.Comment != "" {
+			tokens = append(tokens, "{" + move.Comment + "}")
+			numberNextMove = true
+		}
+
+		for _, variation := range move.Variations {
+			if len(variation) == 0 {
+				continue
+			}
+
+			start := len(tokens)
+			tokens = appendPgnLine(tokens, board, variation, moveNumber)
+			tokens[start] = "(" + tokens[start]
+			tokens[len(tokens) - 1] += ")"
+			numberNextMove = true
+		}
+
+		if board.blackToMove {
+			moveNumber++
+		}
+
+		unmoves = append(unmoves, board.MakeMove(move.Move))
+	}
+
+	for index := len(unmoves) - 1; index >= 0; index-- {
+		board.UnmakeMove(unmoves[index])
+	}
+
+	return tokens
+}
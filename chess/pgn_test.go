@@ -0,0 +1,154 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"strings"
+	"testing"
+)
+
+const testPgn = `[Event "Casual game"]
+[White "Anderssen, Adolf"]
+[Black "Kieseritzky, Lionel"]
+[Result "1-0"]
+
+{The Immortal Game} 1. e4 e5 2. f4 exf4 3. Bc4 Qh4+ 4. Kf1 b5?! 5. Bxb5 Nf6 6. Nf3
+Qh6 7. d3 Nh5 8. Nh4 Qg5 9. Nf5 c6 10. g4 Nf6 11. Rg1 cxb5 12. h4 Qg6 13. h5 Qg5
+14. Qf3 Ng8 15. Bxf4 Qf6 16. Nc3 Bc5 17. Nd5 Qxb2 18. Bd6 Bxg1 (18... Qxa1+ 19.
+Ke2 Qb2 {is also lost}) 19. e5 Qxa1+ 20. Ke2 Na6 21. Nxg7+ Kd8 22. Qf6+ Nxf6 23.
+Be7# 1-0
+
+% An escaped line
+[Event "Second game"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 b - - 0 40"]
+
+40... Kd7 $1 41. e4 ; rest of line comment
+Ke6 *
+`
+
+func TestParsePgn(t *testing.T) {
+	assert := assert.New(t)
+
+	games, err := chess.ParsePgn(testPgn)
+	assert.Nil(err)
+	assert.Len(games, 2)
+
+	game := games[0]
+	assert.Equal("Anderssen, Adolf", game.Tag("White"))
+	assert.Equal("", game.Tag("Round"))
+	assert.Equal("The Immortal Game", game.Comment)
+	assert.Equal(chess.WhiteWins, game.Result)
+	assert.Len(game.Moves, 45)
+	assert.Equal([]int{chess.NagDubiousMove}, game.Moves[7].Nags)
+
+	// The variation replaces black's 18th move
+	assert.Len(game.Moves[35].Variations, 1)
+	assert.Len(game.Moves[35].Variations[0], 3)
+	assert.Equal("is also lost", game.Moves[35].Variations[0][2].Comment)
+
+	board, err := game.FinalBoard()
+	assert.Nil(err)
+	assert.Equal(chess.Outcome{Result: chess.WhiteWins, Termination: chess.Checkmate}, board.Outcome())
+
+	game = games[1]
+	assert.Equal(chess.NoResult, game.Result)
+	assert.Len(game.Moves, 3)
+	assert.Equal([]int{1}, game.Moves[0].Nags)
+	assert.Equal("rest of line comment", game.Moves[1].Comment)
+}
+
+func TestWritePgn(t *testing.T) {
+	assert := assert.New(t)
+
+	games, err := chess.ParsePgn(testPgn)
+	assert.Nil(err)
+
+	expected := `[Event "Second game"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 b - - 0 40"]
+
+40... Kd7 $1 41. e4 {rest of line comment} 41... Ke6 *
+`
+	assert.Equal(expected, games[1].Pgn())
+
+	// Writing and reading a game gives back the same game
+	for _, game := range games {
+		reread, err := chess.ParsePgn(game.Pgn())
+		assert.Nil(err)
+		assert.Equal([]*chess.Game{game}, reread)
+	}
+
+	pgn := strings.ReplaceAll(games[0].Pgn(), "\n", " ")
+	assert.Contains(pgn, "18. Bd6 Bxg1 (18... Qxa1+ 19. Ke2 Qb2 {is also lost}) 19. e5")
+	assert.Contains(pgn, "23. Be7# 1-0 ")
+}
+
+func TestPgnErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	// Games that cannot be read are skipped
+	games, err := chess.ParsePgn("[Event \"Bad\"]\n\n1. e4 e5 2. Ke3 *\n\n[Event \"Good\"]\n\n1. d4 *\n")
+	assert.NotNil(err)
+	assert.Len(games, 0)
+
+	pgn := chess.NewPgnReader(strings.NewReader("[Event \"Bad\"]\n\n1. e4 (e5\n\n[Event \"Good\"]\n\n1. d4 *\n"))
+	_, err = pgn.Read()
+	assert.NotNil(err)
+
+	game, err := pgn.Read()
+	assert.Nil(err)
+	assert.Equal("Good", game.Tag("Event"))
+}
+
+type testBot struct{}
+
+func (testBot) Think(board *chess.Board) chess.Move {
+	return board.GetLegalMoves(false)[0]
+}
+
+func (testBot) Info() chess.BotInfo {
+	return chess.BotInfo{Name: "Test Bot", Version: "1.0"}
+}
+
+func TestCompleteTags(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("rnbqkbnr/pppp1ppp/8/4p3/6P1/5P2/PPPPP2P/RNBQKBNR b KQkq g3 0 2")
+	assert.Nil(err)
+
+	game := chess.NewGame(board)
+	game.SetTag("Event", "Test")
+	game.SetPlayers(nil, testBot{})
+	game.Moves = []chess.GameMove{{Move: chess.Move{Source: chess.D8, Destination: chess.H4}}}
+
+	assert.Nil(game.CompleteTags())
+	assert.Equal(chess.BlackWins, game.Result)
+
+	expected := `[Event "Test"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "?"]
+[Black "Test Bot 1.0"]
+[Result "0-1"]
+[SetUp "1"]
+[FEN "rnbqkbnr/pppp1ppp/8/4p3/6P1/5P2/PPPPP2P/RNBQKBNR b KQkq g3 0 1"]
+[WhiteType "human"]
+[BlackType "program"]
+[Termination "normal"]
+
+1... Qh4# 0-1
+`
+	assert.Equal(expected, game.Pgn())
+
+	// A game that is still going on is unterminated, and one that ended by resignation keeps its
+	// result
+	game = chess.NewGame(board)
+	assert.Nil(game.CompleteTags())
+	assert.Equal("*", game.Tag("Result"))
+	assert.Equal("unterminated", game.Tag("Termination"))
+
+	game.Result = chess.WhiteWins
+	assert.Nil(game.CompleteTags())
+	assert.Equal("1-0", game.Tag("Result"))
+	assert.Equal("normal", game.Tag("Termination"))
+}
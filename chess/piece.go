@@ -58,3 +58,23 @@ func (piece PieceKind) AlgebraicLetter() rune {
 	log.Fatalf("unknown piece kind: %v", piece)
 	return '_'
 }
+
+// Returns the English name of the piece, e.g. "knight"
+func (piece PieceKind) Name() string {
+	switch piece {
+	case Pawn:
+		return "pawn"
+	case Knight:
+		return "knight"
+	case Bishop:
+		return "bishop"
+	case Rook:
+		return "rook"
+	case Queen:
+		return "queen"
+	case King:
+		return "king"
+	}
+
+	return "unknown"
+}
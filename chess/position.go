@@ -0,0 +1,120 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+)
+
+// State of a position other than the placement of the pieces
+type PositionState struct {
+	BlackToMove    bool
+	CastlingRights CastlingRights
+
+	// Square a pawn can capture en passant on, if HasEnPassantTarget is true
+	EnPassantTarget    Square
+	HasEnPassantTarget bool
+
+	// Number of halfmoves since the last capture or pawn move
+	HalfmoveClock int
+}
+
+// Returns a board with the given pieces and state, after checking that the position is legal:
+// each side has one king, the side not to move is not in check, no pawns are on the first or last
+// rank, and the castling rights and en passant target agree with the pieces
+// The Square field of each piece is ignored in favour of its key in the map
+func NewBoardFromPosition(pieces map[Square]Piece, state PositionState) (*Board, error) {
+	board := NewBoard()
+
+	for sq, piece := range pieces {
+		if sq >= 64 {
+			return nil, errors.New(fmt.Sprintf("bad square: %v", uint8(sq)))
+		}
+		if piece.Kind > Pawn {
+			return nil, errors.New(fmt.Sprintf("bad piece kind on %v: %v", sq, piece.Kind))
+		}
+
+		board.putPiece(sq, piece.Kind, piece.IsBlack)
+	}
+
+	if state.HalfmoveClock < 0 {
+		return nil, errors.New(fmt.Sprintf("bad halfmove clock: %v", state.HalfmoveClock))
+	}
+
+	board.blackToMove = state.BlackToMove
+	board.castlingRights = state.CastlingRights
+	board.hasEnPassantTarget = state.HasEnPassantTarget
+	board.enPassantTarget = state.EnPassantTarget
+	board.halfmoveClock = state.HalfmoveClock
+
+	if err := board.validatePosition(); err != nil {
+		return nil, err
+	}
+
+	// The pieces were hashed as they were placed
+	board.hash ^= board.stateKey()
+
+	return &board, nil
+}
+
+// Returns an error describing why the position is illegal in standard chess, or nil if it is legal,
+// by the checks of NewBoardFromPosition. LoadFen accepts any placement of the pieces, so positions
+// from outside the program can be checked with this before they are searched
+func (board *Board) Validate() error {
+	return board.validatePosition()
+}
+
+// Returns an error describing why the position is illegal, or nil if it is legal
+func (board *Board) validatePosition() error {
+	for _, isBlack := range []bool{false, true} {
+		if count := board.PiecesBB(King, isBlack).PopCount(); count != 1 {
+			return errors.New(fmt.Sprintf("%v has %v kings", ColorWithIsBlack(isBlack), count))
+		}
+	}
+
+	if (board.PiecesBB(Pawn, false) | board.PiecesBB(Pawn, true)) & (Rank1Bitboard | Rank8Bitboard) != EmptyBitboard {
+		return errors.New("pawn on the first or last rank")
+	}
+
+	if board.DetectIllegalMove() {
+		return errors.New("the side not to move is in check")
+	}
+
+	rights := board.castlingRights
+	castlingPieces := []struct {
+		hasRight bool
+		king     Square
+		rook     Square
+		isBlack  bool
+	}{
+		{rights.WhiteKingside, E1, H1, false},
+		{rights.WhiteQueenside, E1, A1, false},
+		{rights.BlackKingside, E8, H8, true},
+		{rights.BlackQueenside, E8, A8, true},
+	}
+
+	for _, castling := range castlingPieces {
+		if castling.hasRight && (!board.PiecesBB(King, castling.isBlack).Get(castling.king) || !board.PiecesBB(Rook, castling.isBlack).Get(castling.rook)) {
+			return errors.New(fmt.Sprintf("castling right with the king or rook moved from %v or %v", castling.king, castling.rook))
+		}
+	}
+
+	if board.hasEnPassantTarget {
+		// The pawn that just moved two squares must be in front of the target, with the square it
+		// passed through and the square it came from empty
+		target := board.enPassantTarget
+		expectedRank, direction := Rank6, 1
+		if board.blackToMove {
+			expectedRank, direction = Rank3, -1
+		}
+
+		pawnSquare, _ := target.Offset(0, direction)
+		origin, _ := target.Offset(0, -direction)
+
+		if target.Rank() != expectedRank || board.HasPiece(target) || board.HasPiece(origin) ||
+			!board.PiecesBB(Pawn, !board.blackToMove).Get(pawnSquare) {
+			return errors.New(fmt.Sprintf("bad en passant target: %v", target))
+		}
+	}
+
+	return nil
+}
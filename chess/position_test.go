@@ -0,0 +1,100 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func TestNewBoardFromPosition(t *testing.T) {
+	assert := assert.New(t)
+
+	pieces := map[chess.Square]chess.Piece{
+		chess.E1: {Kind: chess.King},
+		chess.H1: {Kind: chess.Rook},
+		chess.E4: {Kind: chess.Pawn},
+		chess.E8: {Kind: chess.King, IsBlack: true},
+		chess.D4: {Kind: chess.Pawn, IsBlack: true},
+	}
+	state := chess.PositionState{
+		BlackToMove:        true,
+		CastlingRights:     chess.CastlingRights{WhiteKingside: true},
+		EnPassantTarget:    chess.E3,
+		HasEnPassantTarget: true,
+		HalfmoveClock:      0,
+	}
+
+	board, err := chess.NewBoardFromPosition(pieces, state)
+	assert.Nil(err)
+
+	expected, err := chess.LoadFen("4k3/8/8/8/3pP3/8/8/4K2R b K e3 0 1")
+	assert.Nil(err)
+	assert.Equal(expected.Fen(), board.Fen())
+	assert.Equal(expected.Hash(), board.Hash())
+	assert.Len(board.GetLegalMoves(false), len(expected.GetLegalMoves(false)))
+
+	// Illegal positions
+	invalid := []struct {
+		description string
+		modify      func(pieces map[chess.Square]chess.Piece, state *chess.PositionState)
+	}{
+		{"missing king", func(pieces map[chess.Square]chess.Piece, state *chess.PositionState) {
+			delete(pieces, chess.E8)
+		}},
+		{"two kings", func(pieces map[chess.Square]chess.Piece, state *chess.PositionState) {
+			pieces[chess.A3] = chess.Piece{Kind: chess.King}
+		}},
+		{"pawn on the last rank", func(pieces map[chess.Square]chess.Piece, state *chess.PositionState) {
+			pieces[chess.A8] = chess.Piece{Kind: chess.Pawn}
+		}},
+		{"side not to move in check", func(pieces map[chess.Square]chess.Piece, state *chess.PositionState) {
+			pieces[chess.E2] = chess.Piece{Kind: chess.Rook, IsBlack: true}
+		}},
+		{"castling without a rook", func(pieces map[chess.Square]chess.Piece, state *chess.PositionState) {
+			state.CastlingRights.WhiteQueenside = true
+		}},
+		{"en passant without a pawn", func(pieces map[chess.Square]chess.Piece, state *chess.PositionState) {
+			state.EnPassantTarget = chess.D3
+		}},
+		{"negative halfmove clock", func(pieces map[chess.Square]chess.Piece, state *chess.PositionState) {
+			state.HalfmoveClock = -1
+		}},
+	}
+
+	for _, test := range invalid {
+		modifiedPieces := make(map[chess.Square]chess.Piece)
+		for sq, piece := range pieces {
+			modifiedPieces[sq] = piece
+		}
+		modifiedState := state
+		test.modify(modifiedPieces, &modifiedState)
+
+		_, err := chess.NewBoardFromPosition(modifiedPieces, modifiedState)
+		assert.NotNil(err, test.description)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, fen := range []string{
+		chess.StartingPositionFen,
+		"rnbqkbnr/ppp1pppp/8/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 3",
+	} {
+		board, _ := chess.LoadFen(fen)
+		assert.Nil(board.Validate(), fen)
+	}
+
+	for _, fen := range []string{
+		"8/8/8/8/8/8/8/K7 w - - 0 1",
+		"k7/8/8/8/8/8/8/R6K w - - 0 1",
+		"k6P/8/8/8/8/8/8/K7 w - - 0 1",
+		"k7/8/8/8/8/8/8/K7 w K - 0 1",
+	} {
+		board, _ := chess.LoadFen(fen)
+		assert.NotNil(board.Validate(), fen)
+	}
+
+	board, _ := chess.LoadFen("8/8/8/8/8/8/8/K7 w - - 0 1")
+	assert.EqualError(board.Validate(), "black has 0 kings")
+}
@@ -0,0 +1,60 @@
+package chess
+
+// Returns the number of times the current position has occurred in the game, including now
+// Positions are the same when the same pieces are on the same squares with the same side to move,
+// castling rights and possible en passant captures. Only positions since the board was loaded are
+// known
+func (board *Board) RepetitionCount() int {
+	key := board.repetitionKey()
+	count := 1
+
+	// Positions before the last capture or pawn move cannot repeat, and positions with the other
+	// side to move are skipped
+	lookBack := min(board.halfmoveClock, len(board.history))
+
+	for i := 2; i <= lookBack; i += 2 {
+		if board.history[len(board.history) - i] == key {
+			count += 1
+		}
+	}
+
+	return count
+}
+
+// Returns a hash identifying the position, for finding the same position in other games or reached
+// by other move orders. This is Hash without an en passant target that no pawn can capture on
+func (board *Board) PositionKey() uint64 {
+	return board.repetitionKey()
+}
+
+// Returns the hash of the position, leaving out the en passant target if no pawn can capture
+// there, so that the position after a double pawn push matches later occurrences of it
+// Whether the capturing pawn is pinned is not checked
+func (board *Board) repetitionKey() uint64 {
+	if !board.hasEnPassantTarget {
+		return board.hash
+	}
+
+	capturers := PawnAttacks(board.enPassantTarget, !board.blackToMove) & board.pieceBitboards[sideIndex(board.blackToMove)][Pawn]
+	if capturers != EmptyBitboard {
+		return board.hash
+	}
+
+	return board.hash ^ zobristEnPassantKeys[board.enPassantTarget.File()]
+}
+
+// Returns the outcome if the game is drawn automatically by the 75-move rule or fivefold
+// repetition, without either player claiming a draw
+// Checkmate on the 150th halfmove takes precedence, so rules should only use this once the
+// position is known not to be checkmate
+func (board *Board) AutomaticDraw() Outcome {
+	if board.halfmoveClock >= 150 {
+		return Outcome{Result: Draw, Termination: SeventyFiveMoveRule}
+	}
+
+	if board.RepetitionCount() >= 5 {
+		return Outcome{Result: Draw, Termination: FivefoldRepetition}
+	}
+
+	return Outcome{}
+}
@@ -0,0 +1,81 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func playUciMoves(t *testing.T, board *chess.Board, moves ...string) {
+	for _, uci := range moves {
+		move, err := chess.MoveWithUciNotation(uci)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		board.MakeMove(move)
+	}
+}
+
+func TestFivefoldRepetition(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen(chess.StartingPositionFen)
+	assert.Nil(err)
+
+	for i := 1; i <= 4; i++ {
+		assert.Equal(i, board.RepetitionCount())
+		assert.False(board.Outcome().IsOver())
+
+		playUciMoves(t, board, "g1f3", "g8f6", "f3g1", "f6g8")
+	}
+
+	assert.Equal(5, board.RepetitionCount())
+	assert.Equal(chess.Outcome{Result: chess.Draw, Termination: chess.FivefoldRepetition}, board.Outcome())
+}
+
+func TestRepetitionIgnoresImpossibleEnPassant(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen(chess.StartingPositionFen)
+	assert.Nil(err)
+
+	// No black pawn can capture on e3, so the position after 1. e4 occurs again
+	playUciMoves(t, board, "e2e4", "g8f6", "g1f3", "f6g8", "f3g1")
+	assert.Equal(2, board.RepetitionCount())
+
+	// Here d4 can be captured en passant, so the position after the double push differs
+	board, err = chess.LoadFen("4k3/8/8/8/4p3/8/3P4/4K2N w - - 0 1")
+	assert.Nil(err)
+
+	playUciMoves(t, board, "d2d4", "e8d8", "h1g3", "d8e8", "g3h1")
+	assert.Equal(1, board.RepetitionCount())
+}
+
+func TestSeventyFiveMoveRule(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("4k3/8/8/8/8/8/8/4K2R w - - 149 1")
+	assert.Nil(err)
+	assert.Equal(149, board.HalfmoveClock())
+	assert.False(board.Outcome().IsOver())
+
+	playUciMoves(t, board, "h1h2")
+	assert.Equal(150, board.HalfmoveClock())
+	assert.Equal(chess.Outcome{Result: chess.Draw, Termination: chess.SeventyFiveMoveRule}, board.Outcome())
+
+	// Pawn moves reset the clock
+	board, err = chess.LoadFen("4k3/8/8/8/8/8/4P3/4K3 w - - 149 1")
+	assert.Nil(err)
+
+	playUciMoves(t, board, "e2e3")
+	assert.Equal(0, board.HalfmoveClock())
+	assert.False(board.Outcome().IsOver())
+
+	// Checkmate on the last move takes precedence
+	board, err = chess.LoadFen("6k1/5ppp/8/8/8/8/8/R5K1 w - - 149 1")
+	assert.Nil(err)
+
+	playUciMoves(t, board, "a1a8")
+	assert.Equal(chess.Outcome{Result: chess.WhiteWins, Termination: chess.Checkmate}, board.Outcome())
+}
@@ -0,0 +1,134 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The rules of the game played on a board, deciding which moves are legal and when the game is
+// over
+// Variants, handicap games and custom rulesets implement this interface, usually by embedding
+// StandardRules and overriding the methods for the rules they change, and are used by setting them
+// on a board with SetRules or LoadFenWithRules
+type Rules interface {
+	// Name of the rules, e.g. "standard"
+	Name() string
+
+	// FEN of the position games start from
+	StartingFen() string
+
+	// Append the legal moves in the position to `moves`, returning the updated slice
+	// If `capturesOnly` is true, only moves capturing an enemy piece should be appended
+	// This must not call Outcome or the board's move generation methods, which call this
+	AppendLegalMoves(board *Board, moves []Move, capturesOnly bool) []Move
+
+	// Returns the result of the game if it is over in the position
+	Outcome(board *Board) Outcome
+}
+
+// Implemented by rules in which the king can or cannot be in check, such as the antichess family,
+// where it is an ordinary piece. Rules that do not implement it have check, as in standard chess
+type CheckRules interface {
+	// Returns whether a king attacked by an enemy piece is in check
+	HasCheck() bool
+}
+
+// The rules of standard chess, which boards follow unless given other rules
+type StandardRules struct{}
+
+func (StandardRules) Name() string {
+	return "standard"
+}
+
+func (StandardRules) StartingFen() string {
+	return StartingPositionFen
+}
+
+func (StandardRules) AppendLegalMoves(board *Board, moves []Move, capturesOnly bool) []Move {
+	return board.appendStandardLegalMoves(moves, capturesOnly)
+}
+
+// The game is over by checkmate or stalemate when the side to move has no legal moves. Legal
+// moves are generated by the board's rules, so variants that only change move generation get
+// checkmate and stalemate detection for free
+// Otherwise, the game is drawn automatically by the 75-move rule, fivefold repetition or a dead
+// position. Dead positions are judged as in standard chess, where only checkmate wins, so variants
+// with other ways to win should not fall back on this
+func (StandardRules) Outcome(board *Board) Outcome {
+	if len(board.GetLegalMoves(false)) > 0 {
+		if outcome := board.AutomaticDraw(); outcome.IsOver() {
+			return outcome
+		}
+
+		if board.IsDeadPosition() {
+			return Outcome{Result: Draw, Termination: DeadPosition}
+		}
+
+		return Outcome{}
+	}
+
+	if !board.IsCheck() {
+		return Outcome{Result: Draw, Termination: Stalemate}
+	}
+
+	if board.blackToMove {
+		return Outcome{Result: WhiteWins, Termination: Checkmate}
+	} else {
+		return Outcome{Result: BlackWins, Termination: Checkmate}
+	}
+}
+
+// Returns the built-in rules with the given name: standard, giveaway (also called antichess, as on
+// Lichess) or suicide
+func VariantWithName(name string) (Rules, error) {
+	switch name {
+	case "standard":
+		return StandardRules{}, nil
+	case "giveaway", "antichess":
+		return Giveaway, nil
+	case "suicide":
+		return Suicide, nil
+	}
+
+	return StandardRules{}, errors.New(fmt.Sprintf("unknown variant: %v", name))
+}
+
+// Set the rules the game on the board is played by, or nil for standard chess
+func (board *Board) SetRules(rules Rules) {
+	board.rules = rules
+}
+
+// Returns the rules the game on the board is played by
+func (board *Board) Rules() Rules {
+	if board.rules == nil {
+		return StandardRules{}
+	}
+
+	return board.rules
+}
+
+// Returns whether the king can be in check under the board's rules
+func (board *Board) HasCheck() bool {
+	if rules, ok := board.Rules().(CheckRules); ok {
+		return rules.HasCheck()
+	}
+
+	return true
+}
+
+// Returns the result of the game if it is over in the current position, according to the board's
+// rules
+func (board *Board) Outcome() Outcome {
+	return board.Rules().Outcome(board)
+}
+
+// Load a position from FEN, to be played by the given rules
+func LoadFenWithRules(fen string, rules Rules) (*Board, error) {
+	board, err := LoadFen(fen)
+	if err != nil {
+		return nil, err
+	}
+
+	board.rules = rules
+	return board, nil
+}
@@ -0,0 +1,105 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+// Variant without castling where a king reaching the centre wins, implemented outside the package
+type testVariant struct {
+	chess.StandardRules
+}
+
+func (testVariant) Name() string {
+	return "test variant"
+}
+
+func (variant testVariant) AppendLegalMoves(board *chess.Board, moves []chess.Move, capturesOnly bool) []chess.Move {
+	// No moves once a king is in the centre. This must not call Outcome, which generates the legal
+	// moves
+	if kingInCentre(board) {
+		return moves
+	}
+
+	start := len(moves)
+	moves = variant.StandardRules.AppendLegalMoves(board, moves, capturesOnly)
+
+	result := moves[:start]
+	for _, move := range moves[start:] {
+		if !board.IsCastlingMove(move) {
+			result = append(result, move)
+		}
+	}
+
+	return result
+}
+
+func kingInCentre(board *chess.Board) bool {
+	return (board.PiecesBB(chess.King, false) | board.PiecesBB(chess.King, true)) & chess.CenterBitboard != chess.EmptyBitboard
+}
+
+func (variant testVariant) Outcome(board *chess.Board) chess.Outcome {
+	if board.PiecesBB(chess.King, false) & chess.CenterBitboard != chess.EmptyBitboard {
+		return chess.Outcome{Result: chess.WhiteWins, Termination: chess.VariantEnd}
+	}
+	if board.PiecesBB(chess.King, true) & chess.CenterBitboard != chess.EmptyBitboard {
+		return chess.Outcome{Result: chess.BlackWins, Termination: chess.VariantEnd}
+	}
+
+	return variant.StandardRules.Outcome(board)
+}
+
+func TestStandardOutcome(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen(chess.StartingPositionFen)
+	assert.Nil(err)
+	assert.False(board.Outcome().IsOver())
+	assert.Equal("standard", board.Rules().Name())
+
+	board, err = chess.LoadFen("6k1/5ppp/8/8/8/8/8/R5K1 b - - 0 1")
+	assert.Nil(err)
+	assert.False(board.Outcome().IsOver())
+
+	board, err = chess.LoadFen("R5k1/5ppp/8/8/8/8/8/6K1 b - - 0 1")
+	assert.Nil(err)
+	assert.Equal(chess.Outcome{Result: chess.WhiteWins, Termination: chess.Checkmate}, board.Outcome())
+
+	isBlack, ok := board.Outcome().Winner()
+	assert.True(ok)
+	assert.False(isBlack)
+	assert.Equal("1-0", board.Outcome().Result.String())
+
+	board, err = chess.LoadFen("k7/2Q5/1K6/8/8/8/8/8 b - - 0 1")
+	assert.Nil(err)
+	assert.Equal(chess.Outcome{Result: chess.Draw, Termination: chess.Stalemate}, board.Outcome())
+}
+
+func TestCustomRules(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFenWithRules("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", testVariant{})
+	assert.Nil(err)
+	assert.Equal("test variant", board.Rules().Name())
+
+	for _, move := range board.GetLegalMoves(false) {
+		assert.False(board.IsCastlingMove(move))
+	}
+
+	// The rules are kept when copying the board
+	child := board.MakeMoveCopy(chess.Move{Source: chess.E1, Destination: chess.E2})
+	assert.Equal("test variant", child.Rules().Name())
+
+	board, err = chess.LoadFenWithRules("4k3/8/8/8/8/4K3/8/R7 w - - 0 1", testVariant{})
+	assert.Nil(err)
+	assert.False(board.Outcome().IsOver())
+
+	board.MakeMove(chess.Move{Source: chess.E3, Destination: chess.E4})
+	assert.Equal(chess.Outcome{Result: chess.WhiteWins, Termination: chess.VariantEnd}, board.Outcome())
+	assert.Empty(board.GetLegalMoves(false))
+
+	// Standard rules can be restored
+	board.SetRules(nil)
+	assert.NotEmpty(board.GetLegalMoves(false))
+}
@@ -0,0 +1,234 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Returns the move written in Standard Algebraic Notation (e.g. Nf3, exd5, O-O, e8=Q+), which
+// must be legal in the current position
+func (board *Board) San(move Move) string {
+	var sb strings.Builder
+
+	sb.WriteString(board.sanWithoutCheck(move))
+
+	// Check and checkmate indicators, unless the rules have no check
+	afterMove := board.MakeMoveCopy(move)
+	if board.HasCheck() && afterMove.IsCheck() {
+		if len(afterMove.GetLegalMoves(false)) == 0 {
+			sb.WriteRune('#')
+		} else {
+			sb.WriteRune('+')
+		}
+	}
+
+	return sb.String()
+}
+
+// Returns the SAN of the move without the check or checkmate indicator
+func (board *Board) sanWithoutCheck(move Move) string {
+	if board.IsCastlingMove(move) {
+		if move.Destination.File() == FileG {
+			return "O-O"
+		} else {
+			return "O-O-O"
+		}
+	}
+
+	var sb strings.Builder
+
+	kind := board.squareContents[uint32(move.Source)].Kind
+	isCapture := board.IsCaptureMove(move)
+
+	if kind == Pawn {
+		if isCapture {
+			sb.WriteRune(rune('a' + int(move.Source.File())))
+		}
+	} else {
+		sb.WriteRune(unicode.ToUpper(kind.AlgebraicLetter()))
+
+		// Disambiguate between pieces of the same kind that can move to the same square, using
+		// the file if that is enough, then the rank, then both
+		ambiguous, sameFile, sameRank := false, false, false
+
+		for _, other := range board.GetLegalMoves(false) {
+			if other.Destination != move.Destination || other.Source == move.Source {
+				continue
+			}
+			if board.squareContents[uint32(other.Source)].Kind != kind {
+				continue
+			}
+
+			ambiguous = true
+			sameFile = sameFile || other.Source.File() == move.Source.File()
+			sameRank = sameRank || other.Source.Rank() == move.Source.Rank()
+		}
+
+		if ambiguous {
+			sourceName := move.Source.String()
+
+			if !sameFile {
+				sb.WriteByte(sourceName[0])
+			} else if !sameRank {
+				sb.WriteByte(sourceName[1])
+			} else {
+				sb.WriteString(sourceName)
+			}
+		}
+	}
+
+	if isCapture {
+		sb.WriteRune('x')
+	}
+
+	sb.WriteString(move.Destination.String())
+
+	if move.IsPromotion {
+		sb.WriteRune('=')
+		sb.WriteRune(unicode.ToUpper(move.PromotedPiece.AlgebraicLetter()))
+	}
+
+	return sb.String()
+}
+
+// Returns the legal move written in Standard Algebraic Notation
+// Check indicators and annotations (+, #, !, ?) are ignored, castling may be written with zeros,
+// the = before a promoted piece may be omitted and unnecessary disambiguation is accepted
+func (board *Board) MoveWithSan(san string) (Move, error) {
+	notation := strings.TrimRight(san, "+#!?")
+
+	// Castling
+	switch notation {
+	case "O-O", "0-0":
+		return board.findLegalMove(san, func(move Move) bool {
+			return board.IsCastlingMove(move) && move.Destination.File() == FileG
+		})
+
+	case "O-O-O", "0-0-0":
+		return board.findLegalMove(san, func(move Move) bool {
+			return board.IsCastlingMove(move) && move.Destination.File() == FileC
+		})
+	}
+
+	// Promotion
+	isPromotion := false
+	promotedPiece := Queen
+
+	if index := strings.IndexRune(notation, '='); index >= 0 {
+		if index != len(notation) - 2 {
+			return Move{}, errors.New(fmt.Sprintf("bad promotion in move %v", san))
+		}
+
+		isPromotion = true
+		notation, promotedPiece = notation[:index], sanPieceKind(rune(notation[index + 1]))
+	} else if len(notation) >= 3 && unicode.IsDigit(rune(notation[len(notation) - 2])) && sanPieceKind(rune(notation[len(notation) - 1])) != Pawn {
+		isPromotion = true
+		notation, promotedPiece = notation[:len(notation) - 1], sanPieceKind(rune(notation[len(notation) - 1]))
+	}
+
+	if isPromotion && promotedPiece == Pawn {
+		return Move{}, errors.New(fmt.Sprintf("bad promotion piece in move %v", san))
+	}
+
+	// Piece moved
+	kind := Pawn
+	if len(notation) > 0 && unicode.IsUpper(rune(notation[0])) {
+		kind = sanPieceKind(rune(notation[0]))
+		if kind == Pawn {
+			return Move{}, errors.New(fmt.Sprintf("bad piece in move %v", san))
+		}
+
+		notation = notation[1:]
+	}
+
+	// Destination square
+	if len(notation) < 2 {
+		return Move{}, errors.New(fmt.Sprintf("missing destination square in move %v", san))
+	}
+
+	destination, err := SquareWithAlgebraicName(notation[len(notation) - 2:])
+	if err != nil {
+		return Move{}, errors.New(fmt.Sprintf("bad destination square in move %v", san))
+	}
+
+	// Whatever remains is the disambiguating file and/or rank of the source square
+	disambiguation := strings.TrimSuffix(notation[:len(notation) - 2], "x")
+	sourceFile, sourceRank := File(-1), Rank(-1)
+
+	for _, char := range disambiguation {
+		if char >= 'a' && char <= 'h' {
+			sourceFile = File(char - 'a')
+		} else if char >= '1' && char <= '8' {
+			sourceRank = Rank('8' - char)
+		} else {
+			return Move{}, errors.New(fmt.Sprintf("bad source square in move %v", san))
+		}
+	}
+
+	return board.findLegalMove(san, func(move Move) bool {
+		return move.Destination == destination &&
+			move.IsPromotion == isPromotion &&
+			(!isPromotion || move.PromotedPiece == promotedPiece) &&
+			board.squareContents[uint32(move.Source)].Kind == kind &&
+			(sourceFile < 0 || move.Source.File() == sourceFile) &&
+			(sourceRank < 0 || move.Source.Rank() == sourceRank)
+	})
+}
+
+// Returns the legal move written in UCI notation or SAN, for moves typed or sent by people and
+// programs that may use either
+func (board *Board) ParseMove(notation string) (Move, error) {
+	if move, err := MoveWithUciNotation(notation); err == nil {
+		if !board.IsLegalMove(move) {
+			return Move{}, errors.New(fmt.Sprintf("illegal move: %v", notation))
+		}
+
+		return move, nil
+	}
+
+	return board.MoveWithSan(notation)
+}
+
+// Returns the only legal move matching the predicate
+func (board *Board) findLegalMove(san string, matches func(Move) bool) (Move, error) {
+	var result Move
+	found := false
+
+	for _, move := range board.GetLegalMoves(false) {
+		if matches(move) {
+			if found {
+				return Move{}, errors.New(fmt.Sprintf("ambiguous move: %v", san))
+			}
+
+			result = move
+			found = true
+		}
+	}
+
+	if !found {
+		return Move{}, errors.New(fmt.Sprintf("illegal move: %v", san))
+	}
+
+	return result, nil
+}
+
+// Returns the kind of piece represented by the uppercase letter in SAN, or Pawn for any other
+// character
+func sanPieceKind(letter rune) PieceKind {
+	switch letter {
+	case 'N':
+		return Knight
+	case 'B':
+		return Bishop
+	case 'R':
+		return Rook
+	case 'Q':
+		return Queen
+	case 'K':
+		return King
+	}
+
+	return Pawn
+}
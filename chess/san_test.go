@@ -0,0 +1,131 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func TestSan(t *testing.T) {
+	assert := assert.New(t)
+
+	// Kiwipete
+	board, err := chess.LoadFen("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	assert.Nil(err)
+
+	assert.Equal("O-O", board.San(chess.Move{Source: chess.E1, Destination: chess.G1}))
+	assert.Equal("O-O-O", board.San(chess.Move{Source: chess.E1, Destination: chess.C1}))
+	assert.Equal("Bxa6", board.San(chess.Move{Source: chess.E2, Destination: chess.A6}))
+	assert.Equal("dxe6", board.San(chess.Move{Source: chess.D5, Destination: chess.E6}))
+	assert.Equal("d6", board.San(chess.Move{Source: chess.D5, Destination: chess.D6}))
+	assert.Equal("Nxf7", board.San(chess.Move{Source: chess.E5, Destination: chess.F7}))
+	assert.Equal("Qxf6", board.San(chess.Move{Source: chess.F3, Destination: chess.F6}))
+	assert.Equal("Rb1", board.San(chess.Move{Source: chess.A1, Destination: chess.B1}))
+	assert.Equal("Nb5", board.San(chess.Move{Source: chess.C3, Destination: chess.B5}))
+
+	// Every legal move round trips through SAN
+	for _, move := range board.GetLegalMoves(false) {
+		parsed, err := board.MoveWithSan(board.San(move))
+		assert.Nil(err)
+		assert.Equal(move, parsed)
+	}
+}
+
+func TestSanCheckAndPromotion(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("7k/1P4p1/8/8/8/8/8/R3K3 w Q - 0 1")
+	assert.Nil(err)
+
+	assert.Equal("Ra8+", board.San(chess.Move{Source: chess.A1, Destination: chess.A8}))
+	assert.Equal("b8=Q+", board.San(chess.Move{Source: chess.B7, Destination: chess.B8, IsPromotion: true, PromotedPiece: chess.Queen}))
+	assert.Equal("b8=N", board.San(chess.Move{Source: chess.B7, Destination: chess.B8, IsPromotion: true, PromotedPiece: chess.Knight}))
+
+	board, err = chess.LoadFen("7k/6pp/8/8/8/8/8/R3K3 w Q - 0 1")
+	assert.Nil(err)
+	assert.Equal("Ra8#", board.San(chess.Move{Source: chess.A1, Destination: chess.A8}))
+}
+
+func TestSanWithoutCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	// 1.e3 d6 in giveaway, where the king is an ordinary piece
+	board, err := chess.LoadFenWithRules("rnbqkbnr/ppp1pppp/3p4/8/8/4P3/PPPP1PPP/RNBQKBNR w - - 0 2", chess.Giveaway)
+	assert.Nil(err)
+	assert.False(board.HasCheck())
+	assert.Equal("Bb5", board.San(chess.Move{Source: chess.F1, Destination: chess.B5}))
+
+	board.SetRules(nil)
+	assert.True(board.HasCheck())
+	assert.Equal("Bb5+", board.San(chess.Move{Source: chess.F1, Destination: chess.B5}))
+}
+
+func TestMoveWithSan(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("7k/1P4pp/8/8/8/8/8/R3K2R w KQ - 0 1")
+	assert.Nil(err)
+
+	move, err := board.MoveWithSan("b8Q")
+	assert.Nil(err)
+	assert.Equal(chess.Move{Source: chess.B7, Destination: chess.B8, IsPromotion: true, PromotedPiece: chess.Queen}, move)
+
+	move, err = board.MoveWithSan("0-0-0")
+	assert.Nil(err)
+	assert.Equal(chess.Move{Source: chess.E1, Destination: chess.C1}, move)
+
+	move, err = board.MoveWithSan("Rhf1")
+	assert.Nil(err)
+	assert.Equal(chess.Move{Source: chess.H1, Destination: chess.F1}, move)
+
+	board, err = chess.LoadFen("7k/8/8/8/8/8/4K3/R6R w - - 0 1")
+	assert.Nil(err)
+
+	assert.Equal("Rhd1", board.San(chess.Move{Source: chess.H1, Destination: chess.D1}))
+
+	_, err = board.MoveWithSan("Rd1")
+	assert.NotNil(err, "ambiguous")
+
+	move, err = board.MoveWithSan("Rhd1")
+	assert.Nil(err)
+	assert.Equal(chess.Move{Source: chess.H1, Destination: chess.D1}, move)
+
+	_, err = board.MoveWithSan("Nc3")
+	assert.NotNil(err, "illegal")
+
+	_, err = board.MoveWithSan("b8=K")
+	assert.NotNil(err)
+}
+
+func TestParseMove(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("7k/1P4pp/8/8/8/8/8/R3K2R w KQ - 0 1")
+	assert.Nil(err)
+
+	move, err := board.ParseMove("b7b8n")
+	assert.Nil(err)
+	assert.Equal(chess.Move{Source: chess.B7, Destination: chess.B8, IsPromotion: true, PromotedPiece: chess.Knight}, move)
+
+	move, err = board.ParseMove("e1c1")
+	assert.Nil(err)
+	assert.Equal(chess.Move{Source: chess.E1, Destination: chess.C1}, move)
+
+	move, err = board.ParseMove("O-O")
+	assert.Nil(err)
+	assert.Equal(chess.Move{Source: chess.E1, Destination: chess.G1}, move)
+
+	move, err = board.ParseMove("Ra8+")
+	assert.Nil(err)
+	assert.Equal(chess.Move{Source: chess.A1, Destination: chess.A8}, move)
+
+	_, err = board.ParseMove("e1e3")
+	assert.EqualError(err, "illegal move: e1e3")
+
+	// A pawn reaching the last rank must say what it promotes to
+	_, err = board.ParseMove("b7b8")
+	assert.NotNil(err)
+
+	_, err = board.ParseMove("Nc3")
+	assert.NotNil(err)
+}
@@ -0,0 +1,60 @@
+package chess
+
+import (
+	"math"
+)
+
+// Scores of positions with a forced mate, for bots that score positions in pawns
+// A side that can force mate in n ply scores MateScore - n, and a side that will be mated in n ply
+// scores -(MateScore - n), so that a faster mate scores higher and a slower one is preferred when
+// losing. Any score further from zero than MateScore - MaxMatePly is a mate score
+const (
+	MateScore  float64 = 100000
+	MaxMatePly int     = 1000
+)
+
+// Returns the score of a position where the side to move mates in `ply` ply
+func MateInPly(ply int) float64 {
+	return MateScore - float64(ply)
+}
+
+// Returns the score of a position where the side to move is mated in `ply` ply, 0 if it is already
+// checkmated
+func MatedInPly(ply int) float64 {
+	return -MateInPly(ply)
+}
+
+// Returns whether the score is a mate score. The infinities count as mate scores
+func IsMateScore(score float64) bool {
+	return math.Abs(score) > MateScore - float64(MaxMatePly)
+}
+
+// Returns the number of ply until mate for a mate score, positive if the side whose perspective the
+// score is from mates and negative if it is mated, and false if the score is not a mate score
+func MatePly(score float64) (ply int, ok bool) {
+	if !IsMateScore(score) || math.IsInf(score, 0) {
+		return 0, false
+	}
+
+	if score > 0 {
+		return int(math.Round(MateScore - score)), true
+	} else {
+		return -int(math.Round(MateScore + score)), true
+	}
+}
+
+// Returns the number of moves until mate for a mate score as in "mate in N", positive if the side
+// whose perspective the score is from mates and negative if it is mated, and false if the score is
+// not a mate score
+func MateIn(score float64) (moves int, ok bool) {
+	ply, ok := MatePly(score)
+	if !ok {
+		return 0, false
+	}
+
+	if ply < 0 {
+		return -(1 - ply) / 2, true
+	}
+
+	return (ply + 1) / 2, true
+}
@@ -0,0 +1,42 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"math"
+	"testing"
+)
+
+func TestMateIn(t *testing.T) {
+	assert := assert.New(t)
+
+	moves, ok := chess.MateIn(chess.MateInPly(1))
+	assert.True(ok)
+	assert.Equal(1, moves)
+
+	moves, ok = chess.MateIn(chess.MateInPly(5))
+	assert.True(ok)
+	assert.Equal(3, moves)
+
+	moves, ok = chess.MateIn(chess.MatedInPly(4))
+	assert.True(ok)
+	assert.Equal(-2, moves)
+
+	moves, ok = chess.MateIn(chess.MatedInPly(0))
+	assert.True(ok)
+	assert.Equal(0, moves)
+
+	ply, ok := chess.MatePly(chess.MatedInPly(3))
+	assert.True(ok)
+	assert.Equal(-3, ply)
+
+	_, ok = chess.MateIn(3.5)
+	assert.False(ok)
+	_, ok = chess.MateIn(math.Inf(1))
+	assert.False(ok)
+
+	assert.Greater(chess.MateInPly(1), chess.MateInPly(3))
+	assert.Greater(chess.MatedInPly(4), chess.MatedInPly(2))
+	assert.True(chess.IsMateScore(chess.MatedInPly(chess.MaxMatePly - 1)))
+	assert.False(chess.IsMateScore(50))
+}
@@ -0,0 +1,110 @@
+package chess
+
+// Piece values in centipawns used by static exchange evaluation, indexed by PieceKind
+// The king's value is large enough that an exchange never ends with it being captured
+var seePieceValues = [6]int{
+	King:   20000,
+	Queen:  900,
+	Bishop: 300,
+	Knight: 300,
+	Rook:   500,
+	Pawn:   100,
+}
+
+// Order in which pieces are used to capture in an exchange, least valuable first
+var seeCaptureOrder = [6]PieceKind{Pawn, Knight, Bishop, Rook, Queen, King}
+
+// Returns the value in centipawns used by static exchange evaluation for the given kind of piece
+func SeeValue(kind PieceKind) int {
+	return seePieceValues[kind]
+}
+
+// Static exchange evaluation: returns the material the side to move gains (in centipawns, negative
+// if it loses material) from making the move and then the sequence of captures on its destination
+// square that follows, with each side recapturing with its least valuable piece and stopping when
+// continuing would lose material
+// Sliding pieces behind other attackers (x-rays) join the exchange as the pieces in front of them
+// capture. Pins and promotions during the exchange are ignored
+// https://www.chessprogramming.org/Static_Exchange_Evaluation
+func (board *Board) SEE(move Move) int {
+	destination := move.Destination
+	occupied := board.GetOccupiedBitboard().Unset(move.Source)
+
+	// Gains[depth] is the material won by the side making the capture at that depth, assuming the
+	// opponent then makes no further captures
+	var gains [32]int
+
+	attackerKind := board.squareContents[move.Source].Kind
+
+	if board.IsEnPassantMove(move) {
+		gains[0] = seePieceValues[Pawn]
+		occupied = occupied.Unset(SquareAt(destination.File(), move.Source.Rank()))
+	} else if board.HasPiece(destination) {
+		gains[0] = seePieceValues[board.squareContents[destination].Kind]
+	}
+
+	if move.IsPromotion {
+		gains[0] += seePieceValues[move.PromotedPiece] - seePieceValues[Pawn]
+		attackerKind = move.PromotedPiece
+	}
+
+	isBlack := board.blackToMove
+	depth := 0
+
+	for depth < len(gains) - 1 {
+		isBlack = !isBlack
+
+		sq, kind, ok := board.leastValuableAttacker(destination, occupied, isBlack)
+		if !ok {
+			break
+		}
+
+		// Capture the piece that captured last
+		depth++
+		gains[depth] = seePieceValues[attackerKind] - gains[depth - 1]
+
+		attackerKind = kind
+		occupied = occupied.Unset(sq)
+	}
+
+	// Each side can choose to stop capturing instead of continuing the exchange
+	for ; depth > 0; depth-- {
+		gains[depth - 1] = -max(-gains[depth - 1], gains[depth])
+	}
+
+	return gains[0]
+}
+
+// Returns the least valuable piece of the given side attacking `sq`, considering only the pieces
+// in `occupied` (so that pieces that have already captured are left out and the sliding pieces
+// behind them can attack)
+func (board *Board) leastValuableAttacker(sq Square, occupied Bitboard, isBlack bool) (attacker Square, kind PieceKind, ok bool) {
+	pieces := &board.pieceBitboards[sideIndex(isBlack)]
+
+	for _, kind := range seeCaptureOrder {
+		var attackers Bitboard
+
+		switch kind {
+		case Pawn:
+			// Pawns attacking `sq` are on the squares a pawn of the other side on `sq` would attack
+			attackers = pawnAttackSet(sq, !isBlack)
+		case Knight:
+			attackers = knightAttackSets[uint(sq)]
+		case Bishop:
+			attackers = bishopAttackSet(sq, occupied)
+		case Rook:
+			attackers = rookAttackSet(sq, occupied)
+		case Queen:
+			attackers = bishopAttackSet(sq, occupied) | rookAttackSet(sq, occupied)
+		case King:
+			attackers = kingAttackSets[uint(sq)]
+		}
+
+		attackers &= pieces[kind] & occupied
+		if attackers != EmptyBitboard {
+			return attackers.LSB(), kind, true
+		}
+	}
+
+	return A1, Pawn, false
+}
@@ -0,0 +1,48 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+func seeOf(t *testing.T, fen string, notation string) int {
+	board, err := chess.LoadFen(fen)
+	assert.Nil(t, err)
+
+	move, err := board.MoveWithSan(notation)
+	assert.Nil(t, err)
+
+	return board.SEE(move)
+}
+
+func TestSEE(t *testing.T) {
+	assert := assert.New(t)
+
+	// Undefended pawn
+	assert.Equal(100, seeOf(t, "1k1r4/1pp4p/p7/4p3/8/P5P1/1PP4P/2K1R3 w - - 0 1", "Rxe5"))
+
+	// Knight takes a pawn defended by a bishop, with the rook and queen behind it x-rayed
+	assert.Equal(-200, seeOf(t, "1k1r3q/1ppn3p/p4b2/4p3/8/P2N2P1/1PP1R1BP/2K1Q3 w - - 0 1", "Nxe5"))
+
+	// Equal trade
+	assert.Equal(0, seeOf(t, "4k3/8/3p4/4n3/8/5N2/8/4K3 w - - 0 1", "Nxe5"))
+
+	// Queen takes a defended pawn
+	assert.Equal(-800, seeOf(t, "4k3/8/3p4/4p3/8/8/4Q3/4K3 w - - 0 1", "Qxe5"))
+
+	// Non-captures can lose material by moving onto an attacked square
+	assert.Equal(-300, seeOf(t, "4k3/8/3p4/8/8/5N2/8/4K3 w - - 0 1", "Ne5"))
+	assert.Equal(0, seeOf(t, chess.StartingPositionFen, "Nf3"))
+
+	// The king can only capture undefended pieces
+	assert.Equal(100, seeOf(t, "4k3/8/8/8/8/8/3p4/4K3 w - - 0 1", "Kxd2"))
+
+	// En passant
+	assert.Equal(100, seeOf(t, "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "exd6"))
+
+	// Promotion gains the difference between the queen and the pawn, and loses the queen if
+	// recaptured
+	assert.Equal(800, seeOf(t, "4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b8=Q"))
+	assert.Equal(-100, seeOf(t, "1r2k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a8=Q"))
+}
@@ -1,6 +1,9 @@
 package chess
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // A square of the board
 type Square uint8
@@ -87,6 +90,97 @@ func (sq Square) Offset(horizontal int, vertical int) (Square, bool) {
 	}
 }
 
+// Returns the square as seen from the perspective of the given side: unchanged for white and
+// mirrored vertically for black (e.g. a2 becomes a7), so that tables written from white's
+// perspective with a8 first can be indexed for either side
+func RelativeSquare(sq Square, isBlack bool) Square {
+	if isBlack {
+		return sq ^ 56
+	} else {
+		return sq
+	}
+}
+
+// Colour of a square of the board
+type SquareColor uint8
+
+const (
+	DarkSquare SquareColor = iota
+	LightSquare
+)
+
+// Returns the colour of the square (a1 is dark)
+func (sq Square) Color() SquareColor {
+	if LightSquaresBitboard.Get(sq) {
+		return LightSquare
+	} else {
+		return DarkSquare
+	}
+}
+
+func (color SquareColor) String() string {
+	if color == LightSquare {
+		return "light"
+	} else {
+		return "dark"
+	}
+}
+
+// Returns the number of king moves between the squares
+func ChebyshevDistance(a Square, b Square) int {
+	return max(abs(int(a.File()) - int(b.File())), abs(int(a.Rank()) - int(b.Rank())))
+}
+
+// Returns the number of rook moves of one square between the squares, i.e. the number of files
+// apart plus the number of ranks apart
+func ManhattanDistance(a Square, b Square) int {
+	return abs(int(a.File()) - int(b.File())) + abs(int(a.Rank()) - int(b.Rank()))
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
+
+// Returns the file with the given letter, e.g. "e"
+func ParseFile(name string) (File, error) {
+	if len(name) != 1 || name[0] < 'a' || name[0] > 'h' {
+		return FileA, errors.New(fmt.Sprintf("bad file: %v", name))
+	}
+
+	return File(name[0] - 'a'), nil
+}
+
+// Returns the rank with the given number, e.g. "4"
+func ParseRank(name string) (Rank, error) {
+	if len(name) != 1 || name[0] < '1' || name[0] > '8' {
+		return Rank1, errors.New(fmt.Sprintf("bad rank: %v", name))
+	}
+
+	return Rank('8' - name[0]), nil
+}
+
+// Returns the letter of the file, e.g. "e"
+func (file File) String() string {
+	if file < FileA || file > FileH {
+		return "?"
+	}
+
+	return string(rune('a' + int(file)))
+}
+
+// Returns the number of the rank, e.g. "4"
+func (rank Rank) String() string {
+	if rank < Rank8 || rank > Rank1 {
+		return "?"
+	}
+
+	return string(rune('8' - int(rank)))
+}
+
 const (
 	A8 Square = iota
 	B8
@@ -8,58 +8,118 @@ import (
 
 func TestSquareAt(t *testing.T) {
 	assert := assert.New(t)
-	assert.Equal(board.SquareAt(board.FileA, board.Rank1), board.A1)
-	assert.Equal(board.SquareAt(board.FileB, board.Rank1), board.B1)
-	assert.Equal(board.SquareAt(board.FileA, board.Rank8), board.A8)
+	assert.Equal(chess.SquareAt(chess.FileA, chess.Rank1), chess.A1)
+	assert.Equal(chess.SquareAt(chess.FileB, chess.Rank1), chess.B1)
+	assert.Equal(chess.SquareAt(chess.FileA, chess.Rank8), chess.A8)
 }
 
 func TestSquareWithAlgebraicName(t *testing.T) {
 	assert := assert.New(t)
 
-	a1, err := board.SquareWithAlgebraicName("a1")
-	assert.Equal(a1, board.A1)
+	a1, err := chess.SquareWithAlgebraicName("a1")
+	assert.Equal(a1, chess.A1)
 	assert.Nil(err)
 
-	a2, err := board.SquareWithAlgebraicName("a2")
-	assert.Equal(a2, board.A2)
+	a2, err := chess.SquareWithAlgebraicName("a2")
+	assert.Equal(a2, chess.A2)
 	assert.Nil(err)
 
-	b1, err := board.SquareWithAlgebraicName("b1")
-	assert.Equal(b1, board.B1)
+	b1, err := chess.SquareWithAlgebraicName("b1")
+	assert.Equal(b1, chess.B1)
 	assert.Nil(err)
 }
 
 func TestFile(t *testing.T) {
 	assert := assert.New(t)
-	assert.Equal(board.A1.File(), board.File(0))
-	assert.Equal(board.B1.File(), board.File(1))
-	assert.Equal(board.A2.File(), board.File(0))
-	assert.Equal(board.B2.File(), board.File(1))
+	assert.Equal(chess.A1.File(), chess.File(0))
+	assert.Equal(chess.B1.File(), chess.File(1))
+	assert.Equal(chess.A2.File(), chess.File(0))
+	assert.Equal(chess.B2.File(), chess.File(1))
 }
 
 func TestRank(t *testing.T) {
 	assert := assert.New(t)
-	assert.Equal(board.A1.Rank(), board.Rank(7))
-	assert.Equal(board.B1.Rank(), board.Rank(7))
-	assert.Equal(board.A2.Rank(), board.Rank(6))
-	assert.Equal(board.B2.Rank(), board.Rank(6))
+	assert.Equal(chess.A1.Rank(), chess.Rank(7))
+	assert.Equal(chess.B1.Rank(), chess.Rank(7))
+	assert.Equal(chess.A2.Rank(), chess.Rank(6))
+	assert.Equal(chess.B2.Rank(), chess.Rank(6))
 }
 
 func TestAlgebraicName(t *testing.T) {
 	assert := assert.New(t)
 
-	a1Name, err := board.A1.AlgebraicName()
+	a1Name, err := chess.A1.AlgebraicName()
 	assert.Equal(a1Name, "a1")
 	assert.Nil(err)
 
-	a2Name, err := board.A2.AlgebraicName()
+	a2Name, err := chess.A2.AlgebraicName()
 	assert.Equal(a2Name, "a2")
 	assert.Nil(err)
 
-	b1Name, err := board.B1.AlgebraicName()
+	b1Name, err := chess.B1.AlgebraicName()
 	assert.Equal(b1Name, "b1")
 	assert.Nil(err)
 
-	_, err = board.Square(64).AlgebraicName()
+	_, err = chess.Square(64).AlgebraicName()
 	assert.NotNil(err)
 }
+
+func TestRelativeSquare(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(chess.A2, chess.RelativeSquare(chess.A2, false))
+	assert.Equal(chess.A7, chess.RelativeSquare(chess.A2, true))
+	assert.Equal(chess.H8, chess.RelativeSquare(chess.H1, true))
+	assert.Equal(chess.E4, chess.RelativeSquare(chess.E5, true))
+
+	for sq := chess.Square(0); sq < 64; sq++ {
+		assert.Equal(sq, chess.RelativeSquare(chess.RelativeSquare(sq, true), true))
+		assert.Equal(sq.File(), chess.RelativeSquare(sq, true).File())
+	}
+}
+
+func TestFileAndRankNames(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("a", chess.FileA.String())
+	assert.Equal("h", chess.FileH.String())
+	assert.Equal("8", chess.Rank8.String())
+	assert.Equal("1", chess.Rank1.String())
+
+	for sq := chess.Square(0); sq < 64; sq++ {
+		assert.Equal(sq.String(), sq.File().String() + sq.Rank().String())
+
+		file, err := chess.ParseFile(sq.File().String())
+		assert.Nil(err)
+		assert.Equal(sq.File(), file)
+
+		rank, err := chess.ParseRank(sq.Rank().String())
+		assert.Nil(err)
+		assert.Equal(sq.Rank(), rank)
+	}
+
+	_, err := chess.ParseFile("i")
+	assert.NotNil(err)
+	_, err = chess.ParseRank("9")
+	assert.NotNil(err)
+	_, err = chess.ParseRank("")
+	assert.NotNil(err)
+}
+
+func TestSquareColor(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(chess.DarkSquare, chess.A1.Color())
+	assert.Equal(chess.LightSquare, chess.H1.Color())
+	assert.Equal(chess.LightSquare, chess.D1.Color())
+	assert.Equal(chess.DarkSquare, chess.H8.Color())
+	assert.Equal("light", chess.E4.Color().String())
+}
+
+func TestDistance(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(0, chess.ChebyshevDistance(chess.E4, chess.E4))
+	assert.Equal(7, chess.ChebyshevDistance(chess.A1, chess.H8))
+	assert.Equal(2, chess.ChebyshevDistance(chess.E4, chess.F6))
+	assert.Equal(14, chess.ManhattanDistance(chess.A1, chess.H8))
+	assert.Equal(3, chess.ManhattanDistance(chess.E4, chess.F6))
+	assert.Equal(chess.ManhattanDistance(chess.C2, chess.G5), chess.ManhattanDistance(chess.G5, chess.C2))
+}
@@ -0,0 +1,170 @@
+package chess
+
+import "fmt"
+
+// Maximum number of errors collected by VerifyPosition before it stops checking
+const maxVerificationErrors int = 20
+
+// Results of verifying move generation, make/unmake and hashing below a position
+type VerificationReport struct {
+	Fen   string
+	Depth int
+
+	// Number of positions reached at each depth, starting from depth 1, as counted by perft
+	// https://www.chessprogramming.org/Perft_Results
+	NodesByDepth []uint64
+
+	// Descriptions of the inconsistencies found, each with the position they were found in
+	Errors []string
+}
+
+// Whether no inconsistencies were found
+func (report *VerificationReport) OK() bool {
+	return len(report.Errors) == 0
+}
+
+// Returns the number of positions reached at the full depth
+func (report *VerificationReport) Nodes() uint64 {
+	if len(report.NodesByDepth) == 0 {
+		return 1
+	}
+
+	return report.NodesByDepth[len(report.NodesByDepth) - 1]
+}
+
+// Walk the game tree below the position to the given depth, counting the positions reached at each
+// depth and checking at every position that
+// - the bitboards agree with the pieces on each square, and each side has one king
+// - the incrementally updated hash and sub-keys match the keys computed from scratch
+// - no move generated leaves the king of the side that moved in check
+// - unmaking each move restores the position exactly
+// The node counts can be compared with known perft results to check move generation itself
+func VerifyPosition(fen string, depth int) (report VerificationReport, err error) {
+	board, err := LoadFen(fen)
+	if err != nil {
+		return report, err
+	}
+
+	report.Fen = fen
+	report.Depth = depth
+	report.NodesByDepth = make([]uint64, depth)
+
+	board.verify(&report, 0)
+	return report, nil
+}
+
+func (board *Board) verify(report *VerificationReport, ply int) {
+	if len(report.Errors) >= maxVerificationErrors {
+		return
+	}
+
+	board.verifyConsistency(report)
+
+	if ply == report.Depth {
+		return
+	}
+
+	for _, move := range board.GetLegalMoves(false) {
+		before := *board
+		unmove := board.MakeMove(move)
+		report.NodesByDepth[ply]++
+
+		if board.DetectIllegalMove() {
+			board.addError(report, fmt.Sprintf("illegal move %v generated", move))
+		}
+
+		board.verify(report, ply + 1)
+		board.UnmakeMove(unmove)
+
+		if difference := before.stateDifference(board); difference != "" {
+			board.addError(report, fmt.Sprintf("unmaking %v did not restore the %v", move, difference))
+		}
+	}
+}
+
+func (board *Board) addError(report *VerificationReport, description string) {
+	if len(report.Errors) < maxVerificationErrors {
+		epd := EpdRecord{Board: board}.String()
+		report.Errors = append(report.Errors, fmt.Sprintf("%v in %v", description, epd))
+	}
+}
+
+// Check that the bitboards, the pieces on each square and the hashes agree
+func (board *Board) verifyConsistency(report *VerificationReport) {
+	var fresh Board
+
+	for sq := Square(0); sq < 64; sq++ {
+		piece := board.squareContents[sq]
+		occupied := board.HasPiece(sq)
+
+		for side := range board.pieceBitboards {
+			for kind := range board.pieceBitboards[side] {
+				expected := occupied && sideIndex(piece.IsBlack) == side && piece.Kind == PieceKind(kind)
+
+				if board.pieceBitboards[side][kind].Get(sq) != expected {
+					board.addError(report, fmt.Sprintf("bitboard of piece kind %v side %v disagrees at %v", kind, side, sq))
+				}
+			}
+		}
+
+		if occupied {
+			if piece.Square != sq {
+				board.addError(report, fmt.Sprintf("piece on %v thinks it is on %v", sq, piece.Square))
+			}
+
+			fresh.togglePieceKey(sq, piece.Kind, piece.IsBlack)
+		}
+	}
+
+	if board.sideBitboards[0] & board.sideBitboards[1] != EmptyBitboard {
+		board.addError(report, "squares occupied by both sides")
+	}
+
+	for _, isBlack := range []bool{false, true} {
+		if board.PiecesBB(King, isBlack).PopCount() != 1 {
+			board.addError(report, fmt.Sprintf("%v has %v kings", ColorWithIsBlack(isBlack), board.PiecesBB(King, isBlack).PopCount()))
+		}
+	}
+
+	fresh.hash ^= board.stateKey()
+
+	if board.hash != fresh.hash || board.pawnKey != fresh.pawnKey || board.minorPieceKey != fresh.minorPieceKey ||
+		board.majorPieceKey != fresh.majorPieceKey || board.kingKey != fresh.kingKey {
+		board.addError(report, "incremental hash does not match hash computed from scratch")
+	}
+}
+
+// Returns the name of a part of the position that differs between the boards, or "" if the
+// positions are the same. Cached attack sets are not compared
+func (board *Board) stateDifference(other *Board) string {
+	switch {
+	case board.pieceBitboards != other.pieceBitboards || board.sideBitboards != other.sideBitboards:
+		return "bitboards"
+	case board.blackToMove != other.blackToMove:
+		return "side to move"
+	case board.castlingRights != other.castlingRights:
+		return "castling rights"
+	case board.hasEnPassantTarget != other.hasEnPassantTarget ||
+		(board.hasEnPassantTarget && board.enPassantTarget != other.enPassantTarget):
+		return "en passant target"
+	case board.hash != other.hash:
+		return "hash"
+	case board.halfmoveClock != other.halfmoveClock:
+		return "halfmove clock"
+	case len(board.history) != len(other.history):
+		return "position history"
+	}
+
+	for sq := Square(0); sq < 64; sq++ {
+		if !board.HasPiece(sq) {
+			continue
+		}
+
+		a, b := board.squareContents[sq], other.squareContents[sq]
+		if a.Kind != b.Kind || a.IsBlack != b.IsBlack || a.Square != b.Square {
+			return fmt.Sprintf("piece on %v", sq)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,43 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+// Standard perft positions with their node counts at each depth
+// https://www.chessprogramming.org/Perft_Results
+var perftPositions = []struct {
+	fen   string
+	nodes []uint64
+}{
+	{chess.StartingPositionFen, []uint64{20, 400, 8902}},
+	{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", []uint64{48, 2039, 97862}},
+	{"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", []uint64{14, 191, 2812, 43238}},
+	{"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", []uint64{6, 264, 9467}},
+	{"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", []uint64{44, 1486, 62379}},
+	{"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10", []uint64{46, 2079, 89890}},
+}
+
+func TestVerifyPosition(t *testing.T) {
+	for _, position := range perftPositions {
+		report, err := chess.VerifyPosition(position.fen, len(position.nodes))
+		assert.Nil(t, err)
+
+		assert.True(t, report.OK(), "%v", report.Errors)
+		assert.Equal(t, position.nodes, report.NodesByDepth, position.fen)
+		assert.Equal(t, position.nodes[len(position.nodes) - 1], report.Nodes())
+	}
+}
+
+func TestVerifyPositionFindsErrors(t *testing.T) {
+	// Two white kings
+	report, err := chess.VerifyPosition("4k3/8/8/8/8/8/8/K3K3 w - - 0 1", 1)
+	assert.Nil(t, err)
+	assert.False(t, report.OK())
+	assert.Contains(t, report.Errors[0], "white has 2 kings")
+
+	_, err = chess.VerifyPosition("not a fen", 1)
+	assert.NotNil(t, err)
+}
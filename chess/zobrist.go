@@ -0,0 +1,140 @@
+package chess
+
+// Zobrist hashing: each piece on each square, each set of castling rights, each en passant file and
+// the side to move is given a random key, and the hash of a position is the XOR of the keys of its
+// features, which can be updated incrementally as pieces move
+// Alongside the hash of the whole position, sub-keys hash only some of the pieces so that caches of
+// terms that only depend on those pieces (pawn structure, material, king safety) can be keyed by
+// them
+// https://www.chessprogramming.org/Zobrist_Hashing
+var (
+	zobristPieceKeys     [2][6][64]uint64
+	zobristCastlingKeys  [16]uint64
+	zobristEnPassantKeys [8]uint64
+	zobristBlackToMove   uint64
+)
+
+func init() {
+	// A fixed seed keeps hashes the same between runs, so they can be saved along with positions
+	rng := splitMix64(0x676f676d)
+
+	for side := range zobristPieceKeys {
+		for kind := range zobristPieceKeys[side] {
+			for sq := range zobristPieceKeys[side][kind] {
+				zobristPieceKeys[side][kind][sq] = rng.next()
+			}
+		}
+	}
+
+	// The castling keys are combinations of the keys for each right, so that removing one right
+	// changes the hash in the same way whichever other rights remain
+	var rightKeys [4]uint64
+	for index := range rightKeys {
+		rightKeys[index] = rng.next()
+	}
+	for rights := range zobristCastlingKeys {
+		for index, key := range rightKeys {
+			if rights & (1 << index) != 0 {
+				zobristCastlingKeys[rights] ^= key
+			}
+		}
+	}
+
+	for file := range zobristEnPassantKeys {
+		zobristEnPassantKeys[file] = rng.next()
+	}
+
+	zobristBlackToMove = rng.next()
+}
+
+// Pseudorandom number generator used to generate the keys
+// https://prng.di.unimi.it/splitmix64.c
+type splitMix64 uint64
+
+func (state *splitMix64) next() uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := uint64(*state)
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Returns the Zobrist hash of the position: the pieces, side to move, castling rights and en passant
+// target
+// The en passant target is included whenever there is one, even if no pawn can capture on it
+func (board *Board) Hash() uint64 {
+	return board.hash
+}
+
+// Returns the hash of the pawns of both sides, for caching pawn structure evaluation
+func (board *Board) PawnKey() uint64 {
+	return board.pawnKey
+}
+
+// Returns the hash of the knights and bishops of both sides
+func (board *Board) MinorPieceKey() uint64 {
+	return board.minorPieceKey
+}
+
+// Returns the hash of the rooks and queens of both sides
+func (board *Board) MajorPieceKey() uint64 {
+	return board.majorPieceKey
+}
+
+// Returns the hash of the positions of both kings, for caching king safety evaluation together
+// with the pawn key
+func (board *Board) KingKey() uint64 {
+	return board.kingKey
+}
+
+// Update the hash and the sub-key of the piece's kind for a piece being placed on or removed from
+// the square
+func (board *Board) togglePieceKey(sq Square, kind PieceKind, isBlack bool) {
+	key := zobristPieceKeys[sideIndex(isBlack)][kind][sq]
+
+	board.hash ^= key
+
+	switch kind {
+	case Pawn:
+		board.pawnKey ^= key
+	case Knight, Bishop:
+		board.minorPieceKey ^= key
+	case Rook, Queen:
+		board.majorPieceKey ^= key
+	case King:
+		board.kingKey ^= key
+	}
+}
+
+// Returns the part of the hash for the side to move, castling rights and en passant target
+func (board *Board) stateKey() (key uint64) {
+	if board.blackToMove {
+		key ^= zobristBlackToMove
+	}
+
+	key ^= zobristCastlingKeys[board.castlingRights.index()]
+
+	if board.hasEnPassantTarget {
+		key ^= zobristEnPassantKeys[board.enPassantTarget.File()]
+	}
+
+	return
+}
+
+// Returns the castling rights as a 4-bit number, one bit for each right
+func (rights CastlingRights) index() (index int) {
+	if rights.WhiteKingside {
+		index |= 1
+	}
+	if rights.WhiteQueenside {
+		index |= 2
+	}
+	if rights.BlackKingside {
+		index |= 4
+	}
+	if rights.BlackQueenside {
+		index |= 8
+	}
+
+	return
+}
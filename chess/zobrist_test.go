@@ -0,0 +1,128 @@
+package chess_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"testing"
+)
+
+// Returns the board loaded from scratch from its position, with the keys computed from nothing
+func reloadBoard(t *testing.T, board *chess.Board) *chess.Board {
+	reloaded, err := chess.ParseEpd(chess.EpdRecord{Board: board}.String())
+	assert.Nil(t, err)
+	return reloaded.Board
+}
+
+// Check that the incrementally updated keys match the keys of the same position loaded from
+// scratch, at every node of the tree below the position, and are restored by unmaking moves
+func checkKeys(t *testing.T, board *chess.Board, depth int) {
+	reloaded := reloadBoard(t, board)
+
+	if !assert.Equal(t, reloaded.Hash(), board.Hash(), "hash of %v", board.Fen()) ||
+		!assert.Equal(t, reloaded.PawnKey(), board.PawnKey()) ||
+		!assert.Equal(t, reloaded.MinorPieceKey(), board.MinorPieceKey()) ||
+		!assert.Equal(t, reloaded.MajorPieceKey(), board.MajorPieceKey()) ||
+		!assert.Equal(t, reloaded.KingKey(), board.KingKey()) {
+		t.FailNow()
+	}
+
+	if depth == 0 {
+		return
+	}
+
+	hash := board.Hash()
+
+	for _, move := range board.GetLegalMoves(false) {
+		unmove := board.MakeMove(move)
+		checkKeys(t, board, depth - 1)
+		board.UnmakeMove(unmove)
+
+		assert.Equal(t, hash, board.Hash(), "after unmaking %v", move)
+	}
+}
+
+func TestZobristIncremental(t *testing.T) {
+	for _, fen := range []string{
+		chess.StartingPositionFen,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"r2q1rk1/pP1p2pp/Q4n2/bbp1p3/Np6/1B3NBn/pPPP1PPP/R3K2R b KQ - 0 1",
+		"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+	} {
+		board, err := chess.LoadFen(fen)
+		assert.Nil(t, err)
+
+		checkKeys(t, board, 2)
+	}
+}
+
+func TestZobristTranspositions(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen(chess.StartingPositionFen)
+	assert.Nil(err)
+	start := board.Hash()
+
+	// Knights out and back again
+	for _, notation := range []string{"g1f3", "g8f6", "f3g1", "f6g8"} {
+		move, err := chess.MoveWithUciNotation(notation)
+		assert.Nil(err)
+		board.MakeMove(move)
+	}
+	assert.Equal(start, board.Hash())
+
+	// A different side to move gives a different hash
+	blackToMove, err := chess.LoadFen("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1")
+	assert.Nil(err)
+	assert.NotEqual(start, blackToMove.Hash())
+	assert.Equal(board.PawnKey(), blackToMove.PawnKey())
+
+	// As do different castling rights and en passant targets
+	noCastling, err := chess.LoadFen("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w Kkq - 0 1")
+	assert.Nil(err)
+	assert.NotEqual(start, noCastling.Hash())
+
+	withEnPassant, err := chess.LoadFen("rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3")
+	assert.Nil(err)
+	withoutEnPassant, err := chess.LoadFen("rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq - 0 3")
+	assert.Nil(err)
+	assert.NotEqual(withEnPassant.Hash(), withoutEnPassant.Hash())
+}
+
+func TestZobristSubKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	assert.Nil(err)
+
+	pawnKey, minorPieceKey, majorPieceKey, kingKey := board.PawnKey(), board.MinorPieceKey(), board.MajorPieceKey(), board.KingKey()
+
+	// A knight move only changes the minor piece key
+	board.MakeMove(chess.Move{Source: chess.E5, Destination: chess.D3})
+	assert.Equal(pawnKey, board.PawnKey())
+	assert.NotEqual(minorPieceKey, board.MinorPieceKey())
+	assert.Equal(majorPieceKey, board.MajorPieceKey())
+	assert.Equal(kingKey, board.KingKey())
+
+	// Castling changes the king and major piece keys
+	minorPieceKey = board.MinorPieceKey()
+	board.MakeMove(chess.Move{Source: chess.E8, Destination: chess.G8})
+	assert.Equal(pawnKey, board.PawnKey())
+	assert.Equal(minorPieceKey, board.MinorPieceKey())
+	assert.NotEqual(majorPieceKey, board.MajorPieceKey())
+	assert.NotEqual(kingKey, board.KingKey())
+}
+
+func TestZobristNullMove(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen("rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3")
+	assert.Nil(err)
+	hash := board.Hash()
+
+	unmove := board.MakeNullMove()
+	assert.Equal(reloadBoard(t, board).Hash(), board.Hash())
+
+	board.UnmakeNullMove(unmove)
+	assert.Equal(hash, board.Hash())
+}
@@ -0,0 +1,349 @@
+package chessgui
+
+import (
+	"fmt"
+	"gogm/chess"
+	"log"
+	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Depth the bot is allowed to search to on the analysis board (ply). The search of a position is
+// stopped when the user moves on, so bots that can be stopped keep deepening until then
+const analysisDepth int = 64
+
+// State of the analysis board, where the user moves the pieces of both sides through a game while a
+// bot evaluates the position shown. Moves that differ from those of the game are added to it as
+// variations
+type analysisState struct {
+	game   *chess.Game
+	cursor treeCursor
+	bot    chess.Bot
+
+	// Progress shown in the title bar, and whether the title needs updating anyway because the
+	// position has changed
+	shownInfo  *chess.SearchInfo
+	titleStale bool
+}
+
+// Position in the game tree: the line being viewed and the number of its moves that have been
+// played. The line is reached from the main line by taking a variation at each branch in turn
+type treeCursor struct {
+	branches []treeBranch
+	ply      int
+}
+
+// Variation `variation` of the move at index `moveIndex` of a line
+type treeBranch struct {
+	moveIndex int
+	variation int
+}
+
+// Open a window showing the game on an analysis board, starting from its first position
+// The user can play moves for both sides, which follow the game while they match its moves and
+// otherwise branch off into new variations. These are added to the game, so can be saved once the
+// window is closed. The left and right arrow keys step backwards and forwards through the line
+// being viewed, and the up and down arrow keys jump to the start of the game and the end of the
+// line
+// If `bot` is not nil, it evaluates the position shown and its progress is shown in the title bar
+// Pressing E logs the breakdown of the bot's static evaluation of the position, if it can give one
+func RunAnalysis(game *chess.Game, bot chess.Bot, options Options) error {
+	board, err := game.StartingBoard()
+	if err != nil {
+		return err
+	}
+
+	state := setup(board, nil, nil, options)
+	defer state.destroy()
+
+	state.title = "Analysis"
+	if white, black := game.Tag("White"), game.Tag("Black"); white != "" || black != "" {
+		state.title = fmt.Sprintf("Analysis: %v vs %v", white, black)
+	}
+	state.window.SetTitle(state.title)
+
+	state.analysis = &analysisState{game: game, bot: bot}
+	defer state.cancelBotMove()
+
+	if analysingBot, ok := bot.(chess.AnalysingBot); ok {
+		analysingBot.SetSearchInfoCallback(state.onSearchInfo)
+		defer analysingBot.SetSearchInfoCallback(nil)
+	}
+
+	// Bots that cannot be stopped would keep the user waiting on each move if allowed to search deep
+	if depthLimitedBot, ok := bot.(chess.DepthLimitedBot); ok {
+		if _, ok := bot.(chess.StoppableBot); ok {
+			depthLimitedBot.SetMaxDepth(analysisDepth)
+		}
+	}
+
+	chess.WarmUp(bot)
+	state.showAnalysisCursor()
+
+	exit := false
+	for !exit {
+		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			switch event := event.(type) {
+			case *sdl.QuitEvent:
+				exit = true
+
+			case *sdl.MouseMotionEvent:
+				state.mouseX = event.X
+				state.mouseY = event.Y
+
+			case *sdl.MouseButtonEvent:
+				if event.Button == sdl.BUTTON_LEFT && event.Type == sdl.MOUSEBUTTONDOWN {
+					state.onLeftMouseButtonDown()
+				}
+
+			case *sdl.KeyboardEvent:
+				if event.Type != sdl.KEYDOWN {
+					break
+				}
+
+				switch event.Keysym.Sym {
+				case sdl.K_LEFT:
+					state.analysis.cursor.back()
+				case sdl.K_RIGHT:
+					state.analysis.cursor.forward(state.analysis.game)
+				case sdl.K_UP:
+					state.analysis.cursor = treeCursor{}
+				case sdl.K_DOWN:
+					state.analysis.cursor.ply = len(*state.analysis.cursor.line(state.analysis.game))
+				case sdl.GetKeyFromName("t"):
+					state.onTKeyDown()
+					continue
+				case sdl.GetKeyFromName("e"):
+					state.logEvalTrace()
+					continue
+				default:
+					continue
+				}
+
+				state.showAnalysisCursor()
+			}
+		}
+
+		state.showSearchInfo()
+		state.render()
+	}
+
+	return nil
+}
+
+// Play the move on the analysis board, following the game or a variation if it has the move and
+// otherwise adding the move to it
+func (state *guiState) playAnalysisMove(move chess.Move) {
+	if !state.board.IsLegalMove(move) {
+		return
+	}
+
+	state.analysis.play(move)
+	state.showAnalysisCursor()
+}
+
+// Show the position at the cursor and start the bot evaluating it
+func (state *guiState) showAnalysisCursor() {
+	board, lastMove := state.analysis.boardAtCursor()
+
+	state.board = board
+	state.lastMove = lastMove
+	state.movingPiece = false
+	state.choosingPromotion = false
+	state.analysis.titleStale = true
+
+	// The move the bot chooses is not made, so its search only needs stopping when the position
+	// changes
+	state.cancelBotMove()
+	if state.analysis.bot != nil && !state.board.Outcome().IsOver() {
+		state.startBotMove(state.analysis.bot)
+	}
+
+	if state.options.Explorer != nil {
+		state.logExplorerMoves()
+	}
+}
+
+// Log the moves played from the position shown in the games of the explorer, with the number of
+// games each was played in and white's score in them
+func (state *guiState) logExplorerMoves() {
+	stats := state.options.Explorer.MoveStats(state.board)
+	if len(stats) == 0 {
+		log.Printf("explorer: no games")
+		return
+	}
+
+	var moves []string
+	for _, move := range stats {
+		moves = append(moves, fmt.Sprintf(
+			"%v (%v games, %.0f%%)",
+			state.board.LocalizedSan(move.Move, state.options.Notation),
+			move.Games,
+			move.WhiteScore() * 100,
+		))
+	}
+
+	log.Printf("explorer: %v", strings.Join(moves, ", "))
+}
+
+// Log the breakdown of the bot's evaluation of the position shown, term by term
+func (state *guiState) logEvalTrace() {
+	tracingBot, ok := state.analysis.bot.(chess.EvalTracingBot)
+	if !ok {
+		log.Printf("no bot that can break down its evaluation")
+		return
+	}
+
+	log.Printf("evaluation of %v:\n%v", state.board.Fen(), tracingBot.EvaluateTrace(state.board))
+}
+
+// Show the outcome of the game or the latest progress of the bot's search in the title bar, if
+// either has changed
+func (state *guiState) showSearchInfo() {
+	info := state.currentSearchInfo()
+
+	if info == state.analysis.shownInfo && !state.analysis.titleStale {
+		return
+	}
+	state.analysis.shownInfo = info
+	state.analysis.titleStale = false
+
+	if outcome := state.board.Outcome(); outcome.IsOver() {
+		state.window.SetTitle(fmt.Sprintf("%v - %v", state.title, outcome))
+		return
+	}
+
+	if info == nil {
+		state.window.SetTitle(state.title)
+		return
+	}
+
+	depth := fmt.Sprint(info.Depth)
+	if info.SelectiveDepth > info.Depth {
+		depth = fmt.Sprintf("%v/%v", info.Depth, info.SelectiveDepth)
+	}
+
+	title := fmt.Sprintf(
+		"%v - depth %v: %v %v",
+		state.title,
+		depth,
+		state.board.LocalizedSan(info.BestMove, state.options.Notation),
+		formatScore(info.Score, state.board.IsBlackToMove()),
+	)
+
+	if nps := info.NodesPerSecond(); nps > 0 {
+		title += fmt.Sprintf(" (%v kN/s)", nps / 1000)
+	}
+
+	state.window.SetTitle(title)
+}
+
+// Returns the score from white's perspective, e.g. +0.35, or the side with a forced mate
+func formatScore(score float64, isBlackToMove bool) string {
+	if isBlackToMove {
+		score = -score
+	}
+
+	mateIn, ok := chess.MateIn(score)
+	switch {
+	case ok && mateIn > 0:
+		return fmt.Sprintf("white mates in %v", mateIn)
+	case ok && mateIn < 0:
+		return fmt.Sprintf("black mates in %v", -mateIn)
+	case ok:
+		return "checkmate"
+	default:
+		return fmt.Sprintf("%+.2f", score)
+	}
+}
+
+// Returns the board at the cursor, with the moves leading to it played so that repetitions are
+// detected, and the last of those moves, if any
+func (analysis *analysisState) boardAtCursor() (*chess.Board, *chess.Move) {
+	// The starting position was loaded successfully by RunAnalysis
+	board, _ := analysis.game.StartingBoard()
+	var lastMove *chess.Move
+
+	play := func(moves []chess.GameMove) {
+		for _, move := range moves {
+			board.MakeMove(move.Move)
+			lastMove = &move.Move
+		}
+	}
+
+	line := analysis.game.Moves
+	for _, branch := range analysis.cursor.branches {
+		play(line[:branch.moveIndex])
+		line = line[branch.moveIndex].Variations[branch.variation]
+	}
+	play(line[:analysis.cursor.ply])
+
+	return board, lastMove
+}
+
+// Move the cursor on by the move, following the line or one of its variations if it has the move
+// and otherwise adding it to the game
+func (analysis *analysisState) play(move chess.Move) {
+	cursor := &analysis.cursor
+	line := cursor.line(analysis.game)
+
+	if cursor.ply == len(*line) {
+		*line = append(*line, chess.GameMove{Move: move})
+		cursor.ply++
+		return
+	}
+
+	next := &(*line)[cursor.ply]
+	if next.Move == move {
+		cursor.ply++
+		return
+	}
+
+	variation := -1
+	for index, moves := range next.Variations {
+		if len(moves) > 0 && moves[0].Move == move {
+			variation = index
+			break
+		}
+	}
+
+	if variation == -1 {
+		next.Variations = append(next.Variations, []chess.GameMove{{Move: move}})
+		variation = len(next.Variations) - 1
+	}
+
+	cursor.branches = append(cursor.branches, treeBranch{moveIndex: cursor.ply, variation: variation})
+	cursor.ply = 1
+}
+
+// Returns the line the cursor is in
+func (cursor *treeCursor) line(game *chess.Game) *[]chess.GameMove {
+	line := &game.Moves
+	for _, branch := range cursor.branches {
+		line = &(*line)[branch.moveIndex].Variations[branch.variation]
+	}
+
+	return line
+}
+
+// Step back one move. Stepping back to the start of a variation returns to the line it branches
+// from, where the position is the same
+func (cursor *treeCursor) back() {
+	if cursor.ply > 0 {
+		cursor.ply--
+	}
+
+	if cursor.ply == 0 && len(cursor.branches) > 0 {
+		last := cursor.branches[len(cursor.branches) - 1]
+		cursor.branches = cursor.branches[:len(cursor.branches) - 1]
+		cursor.ply = last.moveIndex
+	}
+}
+
+// Step forward one move along the line, if it has another move
+func (cursor *treeCursor) forward(game *chess.Game) {
+	if cursor.ply < len(*cursor.line(game)) {
+		cursor.ply++
+	}
+}
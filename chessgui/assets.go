@@ -0,0 +1,83 @@
+package chessgui
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/veandco/go-sdl2/img"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Images built into the binary, so that the GUI runs from any working directory
+//
+//go:embed assets
+var embeddedAssets embed.FS
+
+const piecesImageName string = "pieces.png"
+
+// Returns the contents of the asset with the given name, read from `assetsPath` if it is set and has
+// the file, otherwise the copy built into the binary
+func readAsset(assetsPath string, name string) []byte {
+	if assetsPath != "" {
+		data, err := os.ReadFile(filepath.Join(assetsPath, name))
+		if err == nil {
+			return data
+		}
+		log.Printf("using built-in %v: %v", name, err)
+	}
+
+	data, err := embeddedAssets.ReadFile("assets/" + name)
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
+
+// Decode a PNG image to a surface, using SDL_image if it supports PNG and Go's decoder otherwise
+func loadPngSurface(data []byte) (*sdl.Surface, error) {
+	rw, err := sdl.RWFromMem(data)
+	if err == nil {
+		surface, err := img.LoadRW(rw, true)
+		if err == nil {
+			return surface, nil
+		}
+	}
+
+	decoded, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("failed to decode image: %v", err))
+	}
+
+	return surfaceFromImage(decoded)
+}
+
+// Load the image of the pieces: a row of white pieces above a row of black pieces, in the order
+// of chess.PieceKind. An image in `assetsPath` that cannot be loaded is replaced by the built-in one
+func loadPiecesTexture(renderer *sdl.Renderer, assetsPath string) (piecesTexture *sdl.Texture, piecesTextureW int32, piecesTextureH int32) {
+	piecesImage, err := loadPngSurface(readAsset(assetsPath, piecesImageName))
+	if err != nil && assetsPath != "" {
+		log.Printf("using built-in %v: %v", piecesImageName, err)
+		piecesImage, err = loadPngSurface(readAsset("", piecesImageName))
+	}
+	if err != nil {
+		panic(err)
+	}
+	defer piecesImage.Free()
+
+	piecesTextureW = piecesImage.W
+	piecesTextureH = piecesImage.H
+
+	piecesTexture, err = renderer.CreateTextureFromSurface(piecesImage)
+	if err != nil {
+		panic(err)
+	}
+
+	return
+}
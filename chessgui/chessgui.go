@@ -1,9 +1,13 @@
 package chessgui
 
 import (
+	"context"
+	"fmt"
 	"gogm/chess"
+	"gogm/gamedb"
+	"log"
+	"sync"
 
-	"github.com/veandco/go-sdl2/img"
 	"github.com/veandco/go-sdl2/sdl"
 )
 
@@ -13,10 +17,15 @@ const boardWidth int32 = windowHeight
 const boardHeight int32 = windowHeight
 const squareWidth int32 = boardWidth / 8
 const squareHeight int32 = boardHeight / 8
-const piecesImagePath string = "assets/pieces.png"
+
+// Implemented by bots whose evaluation parameters can be reloaded while running
+type evalReloader interface {
+	ReloadEvalParams() error
+}
 
 type guiState struct {
 	board                   *chess.Board
+	initialBoard            chess.Board
 	whiteBot                chess.Bot
 	blackBot                chess.Bot
 	window                  *sdl.Window
@@ -24,22 +33,92 @@ type guiState struct {
 	piecesTexture           *sdl.Texture
 	piecesTextureW          int32
 	piecesTextureH          int32
+	text                    *textRenderer
 	mouseX                  int32
 	mouseY                  int32
 	movingPiece             bool
 	pieceSourceSquare       chess.Square
-	pieceDestinationSquares []chess.Square
+	pieceMoves              []chess.MoveInfo
+	choosingPromotion       bool
+	promotionSquare         chess.Square
 	lastMove                *chess.Move
 	unmoveHistory           []chess.Unmove
+	moveHistory             []chess.Move
+	options                 Options
+	title                   string
+	profile                 *Profile
+	resultRecorded          bool
+	gameSaved               bool
+	analysis                *analysisState
+
+	// Move of the bot thinking in the background, received once it has chosen, or nil if no bot
+	// is thinking, and the function cancelling its search
+	botMove      chan chess.Move
+	stopThinking context.CancelFunc
+
+	// Comment and search progress of the bot thinking, set from its goroutine
+	botLock        sync.Mutex
+	pendingComment *botComment
+	searchInfo     *chess.SearchInfo
+}
+
+// Settings for the GUI
+type Options struct {
+	// How the piece to promote to is chosen, which can be changed while running with the P key
+	Promotion PromotionMode
+
+	// If set, the results of games between the user and a bot are recorded in the profile stored
+	// at this path (see DefaultProfilePath)
+	ProfilePath string
+
+	// Language of the piece letters in moves shown to the user
+	Notation chess.Notation
+
+	// If set, images in this directory (e.g. pieces.png) are used instead of the built-in ones
+	AssetsPath string
+
+	// If set, the analysis board logs the moves played from each position shown in these games
+	Explorer *gamedb.Database
+
+	// If set, each game played is added to the end of the PGN file at this path when it ends, or
+	// when a rematch is started or the window closed before then
+	PgnPath string
+
+	// Whether to draw arrows for the moves the bot expects while it thinks, which can be changed
+	// while running with the T key
+	ShowThinking bool
 }
 
 // Open a window displaying the match between `whiteBot` and `blackBot`
 // If either `whiteBot` or `blackBot` or both are `nil`, then that side will be
 // played by the user
 func Run(board *chess.Board, whiteBot chess.Bot, blackBot chess.Bot) {
-	state := setup(board, whiteBot, blackBot)
+	RunWithOptions(board, whiteBot, blackBot, Options{})
+}
+
+// Like Run, with the given settings
+func RunWithOptions(board *chess.Board, whiteBot chess.Bot, blackBot chess.Bot, options Options) {
+	state := setup(board, whiteBot, blackBot, options)
 	defer state.destroy()
 
+	// Show the comments of bots on their moves in the title bar, and what they are considering
+	for _, bot := range []chess.Bot{whiteBot, blackBot} {
+		if commentingBot, ok := bot.(chess.CommentingBot); ok {
+			commentingBot.SetCommentCallback(state.onBotComment)
+		}
+		if analysingBot, ok := bot.(chess.AnalysingBot); ok {
+			analysingBot.SetSearchInfoCallback(state.onSearchInfo)
+		}
+	}
+	defer state.cancelBotMove()
+
+	for _, bot := range []chess.Bot{whiteBot, blackBot} {
+		chess.WarmUp(bot)
+	}
+
+	state.loadProfile()
+	defer state.saveUnfinishedGame()
+
 	exit := false
 	for !exit {
 		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
@@ -63,6 +142,18 @@ func Run(board *chess.Board, whiteBot chess.Bot, blackBot chess.Bot) {
 				if event.Keysym.Sym == sdl.GetKeyFromName("b") && event.Type == sdl.KEYDOWN {
 					state.onBKeyDown()
 				}
+				if event.Keysym.Sym == sdl.GetKeyFromName("r") && event.Type == sdl.KEYDOWN {
+					state.onRKeyDown()
+				}
+				if event.Keysym.Sym == sdl.GetKeyFromName("p") && event.Type == sdl.KEYDOWN {
+					state.onPKeyDown()
+				}
+				if event.Keysym.Sym == sdl.GetKeyFromName("n") && event.Type == sdl.KEYDOWN {
+					state.onNKeyDown()
+				}
+				if event.Keysym.Sym == sdl.GetKeyFromName("t") && event.Type == sdl.KEYDOWN {
+					state.onTKeyDown()
+				}
 			}
 		}
 
@@ -74,24 +165,35 @@ func Run(board *chess.Board, whiteBot chess.Bot, blackBot chess.Bot) {
 			activeBot = whiteBot
 		}
 
-		if activeBot != nil {
+		// Bots are not asked to move once the game is over
+		if activeBot != nil && state.botMove == nil && !state.board.Outcome().IsOver() {
 			// currently giving the bot infinite time, TODO: time control
-			botMove := activeBot.Think(board)
-			state.makeMove(botMove)
+			state.startBotMove(activeBot)
+		}
+
+		state.receiveBotMove()
+
+		if !state.resultRecorded && state.board.Outcome().IsOver() {
+			state.recordResult()
+		}
+
+		if !state.gameSaved && state.board.Outcome().IsOver() {
+			state.saveGame()
 		}
 
 		state.render()
 	}
 }
 
-func setup(board *chess.Board, whiteBot chess.Bot, blackBot chess.Bot) (state guiState) {
+func setup(board *chess.Board, whiteBot chess.Bot, blackBot chess.Bot, options Options) (state guiState) {
 	// Initialize SDL
 	if err := sdl.Init(sdl.INIT_EVERYTHING); err != nil {
 		panic(err)
 	}
 
 	// Create window
-	window, err := sdl.CreateWindow("Chess", sdl.WINDOWPOS_CENTERED, sdl.WINDOWPOS_CENTERED, windowWidth, windowHeight, sdl.WINDOW_SHOWN)
+	title := fmt.Sprintf("%v vs %v", playerName(whiteBot), playerName(blackBot))
+	window, err := sdl.CreateWindow(title, sdl.WINDOWPOS_CENTERED, sdl.WINDOWPOS_CENTERED, windowWidth, windowHeight, sdl.WINDOW_SHOWN)
 	if err != nil {
 		panic(err)
 	}
@@ -103,21 +205,40 @@ func setup(board *chess.Board, whiteBot chess.Bot, blackBot chess.Bot) (state gu
 	}
 
 	// Load pieces texture
-	piecesTexture, piecesTextureW, piecesTextureH := loadPiecesTexture(renderer)
+	piecesTexture, piecesTextureW, piecesTextureH := loadPiecesTexture(renderer, options.AssetsPath)
+
+	text, err := newTextRenderer(renderer)
+	if err != nil {
+		panic(err)
+	}
 
+	state.title = title
 	state.board = board
+	state.initialBoard = *board
 	state.whiteBot = whiteBot
 	state.blackBot = blackBot
+	state.options = options
 	state.window = window
 	state.renderer = renderer
 	state.piecesTexture = piecesTexture
 	state.piecesTextureW = piecesTextureW
 	state.piecesTextureH = piecesTextureH
+	state.text = text
 
 	return
 }
 
+// Returns the name to display for the side played by `bot`
+func playerName(bot chess.Bot) string {
+	if bot == nil {
+		return "Human"
+	}
+
+	return chess.GetBotInfo(bot).FullName()
+}
+
 func (state *guiState) destroy() {
+	state.text.destroy()
 	state.piecesTexture.Destroy()
 	state.renderer.Destroy()
 	state.window.Destroy()
@@ -129,6 +250,12 @@ func (state *guiState) render() {
 	state.renderer.SetDrawColor(0, 0, 0, 255)
 	state.renderer.Clear()
 	state.drawBoard()
+	if state.options.ShowThinking {
+		state.drawThinking()
+	}
+	if state.choosingPromotion {
+		state.drawPromotionPicker()
+	}
 	state.renderer.Present()
 }
 
@@ -137,6 +264,7 @@ func (state *guiState) drawBoard() {
 	darkSquareColor := []uint8{181, 136, 99, 255}
 	lastMoveColor := []uint8{255, 240, 0, 150}
 	destinationSquareColor := []uint8{0, 0, 255, 100}
+	captureSquareColor := []uint8{255, 120, 0, 140}
 	checkColor := []uint8{255, 0, 0, 100}
 
 	for x := 0; x < 8; x++ {
@@ -153,16 +281,22 @@ func (state *guiState) drawBoard() {
 			}
 			state.renderer.FillRect(&squareRect)
 
-			// When moving a piece, highlight source and destination square
+			// When moving a piece, highlight source and destination square, with captures highlighted
+			// in a different colour
 			isDestinationSquare := false
+			isCaptureSquare := false
 			if state.movingPiece {
-				for _, destinationSquare := range state.pieceDestinationSquares {
-					if square == destinationSquare {
+				for _, moveInfo := range state.pieceMoves {
+					if square == moveInfo.Destination {
 						isDestinationSquare = true
+						isCaptureSquare = moveInfo.IsCapture
 					}
 				}
 
-				if square == state.pieceSourceSquare || isDestinationSquare {
+				if isCaptureSquare {
+					state.renderer.SetDrawColorArray(captureSquareColor...)
+					state.renderer.FillRect(&squareRect)
+				} else if square == state.pieceSourceSquare || isDestinationSquare {
 					state.renderer.SetDrawColorArray(destinationSquareColor...)
 					state.renderer.FillRect(&squareRect)
 				}
@@ -176,33 +310,157 @@ func (state *guiState) drawBoard() {
 				}
 			}
 
+			state.drawCoordinates(square, &squareRect, isLightSquare)
+
 			// Draw piece on square
 			if piece := state.board.GetPiece(square); piece != nil {
 				// Highlight king square in check
-				if piece.Kind == chess.King && state.board.IsCheck() && piece.IsBlack == state.board.IsBlackToMove() && !isDestinationSquare {
+				if piece.Kind == chess.King && state.board.IsCheck() && piece.Color() == state.board.SideToMove() && !isDestinationSquare {
 					state.renderer.SetDrawColorArray(checkColor...)
 					state.renderer.FillRect(&squareRect)
 				}
 
-				var sourceRect sdl.Rect
-				sourceRect.W = state.piecesTextureW / 6
-				sourceRect.H = state.piecesTextureH / 2
-				sourceRect.X = sourceRect.W * (int32(piece.Kind))
-				if piece.IsBlack {
-					sourceRect.Y = sourceRect.H
-				}
-
-				state.renderer.SetDrawColor(255, 255, 255, 255)
-				state.renderer.Copy(state.piecesTexture, &sourceRect, &squareRect)
+				state.drawPiece(piece.Kind, piece.IsBlack, &squareRect)
 			}
 		}
 	}
 }
 
+// Draw the rank number in the corner of squares on the a-file and the file letter in the corner of
+// squares on the first rank, in the colour of the other squares
+func (state *guiState) drawCoordinates(square chess.Square, squareRect *sdl.Rect, isLightSquare bool) {
+	color := sdl.Color{R: 240, G: 217, B: 181, A: 255}
+	if isLightSquare {
+		color = sdl.Color{R: 181, G: 136, B: 99, A: 255}
+	}
+
+	size := int(squareHeight / 6)
+	margin := squareHeight / 24
+
+	if square.File() == chess.FileA {
+		state.text.draw(square.Rank().String(), squareRect.X + margin, squareRect.Y + margin, size, color)
+	}
+
+	if square.Rank() == chess.Rank1 {
+		label := square.File().String()
+		width, height := state.text.measure(label, size)
+		state.text.draw(label, squareRect.X + squareRect.W - width - margin, squareRect.Y + squareRect.H - height, size, color)
+	}
+}
+
+// Draw the choice of pieces to promote to over the board
+func (state *guiState) drawPromotionPicker() {
+	shadeColor := []uint8{0, 0, 0, 120}
+	pickerColor := []uint8{240, 240, 240, 255}
+
+	state.renderer.SetDrawColorArray(shadeColor...)
+	state.renderer.FillRect(&sdl.Rect{X: 0, Y: 0, W: boardWidth, H: boardHeight})
+
+	for index, square := range state.promotionPickerSquares() {
+		squareRect := sdl.Rect{
+			X: int32(square.File()) * squareWidth,
+			Y: int32(square.Rank()) * squareHeight,
+			W: squareWidth,
+			H: squareHeight,
+		}
+
+		state.renderer.SetDrawColorArray(pickerColor...)
+		state.renderer.FillRect(&squareRect)
+		state.drawPiece(promotionPieces[index], state.board.IsBlackToMove(), &squareRect)
+	}
+}
+
+func (state *guiState) drawPiece(kind chess.PieceKind, isBlack bool, destinationRect *sdl.Rect) {
+	var sourceRect sdl.Rect
+	sourceRect.W = state.piecesTextureW / 6
+	sourceRect.H = state.piecesTextureH / 2
+	sourceRect.X = sourceRect.W * (int32(kind))
+	if isBlack {
+		sourceRect.Y = sourceRect.H
+	}
+
+	state.renderer.SetDrawColor(255, 255, 255, 255)
+	state.renderer.Copy(state.piecesTexture, &sourceRect, destinationRect)
+}
+
 func (state *guiState) makeMove(move chess.Move) {
-	unmove := state.board.MakeMove(move)
+	if state.analysis != nil {
+		state.playAnalysisMove(move)
+		return
+	}
+
+	unmove, err := state.board.MakeLegalMove(move)
+	if err != nil {
+		log.Printf("ignoring move: %v", err)
+		return
+	}
+
 	state.lastMove = &move
 	state.unmoveHistory = append(state.unmoveHistory, unmove)
+	state.moveHistory = append(state.moveHistory, move)
+}
+
+// Load the profile if results are being tracked and the user is playing a bot, and suggest an
+// opponent for the next game
+func (state *guiState) loadProfile() {
+	if state.options.ProfilePath == "" || (state.whiteBot == nil) == (state.blackBot == nil) {
+		return
+	}
+
+	profile, err := LoadProfile(state.options.ProfilePath)
+	if err != nil {
+		log.Printf("failed to load profile, results will not be recorded: %v", err)
+		return
+	}
+	state.profile = profile
+
+	log.Printf("your rating: %.0f", profile.Rating)
+
+	opponent := playerName(state.whiteBot)
+	if state.whiteBot == nil {
+		opponent = playerName(state.blackBot)
+	}
+
+	if record, ok := profile.Opponents[opponent]; ok {
+		log.Printf("your results against %v: %v wins, %v draws, %v losses", opponent, record.Wins, record.Draws, record.Losses)
+	}
+
+	if suggestion, ok := profile.SuggestedOpponent(); ok && suggestion != opponent {
+		log.Printf("suggested opponent for an even game: %v", suggestion)
+	}
+}
+
+// Record the result of the finished game in the profile
+// Only the first result is recorded, so taking back moves after the game has ended cannot change it
+func (state *guiState) recordResult() {
+	state.resultRecorded = true
+
+	if state.profile == nil {
+		return
+	}
+
+	userIsBlack := state.blackBot == nil
+	opponent := playerName(state.blackBot)
+	if userIsBlack {
+		opponent = playerName(state.whiteBot)
+	}
+
+	result := PlayerDrew
+	if winnerIsBlack, ok := state.board.Outcome().Winner(); ok {
+		if winnerIsBlack == userIsBlack {
+			result = PlayerWon
+		} else {
+			result = PlayerLost
+		}
+	}
+
+	state.profile.RecordResult(opponent, result)
+
+	if err := state.profile.Save(state.options.ProfilePath); err != nil {
+		log.Printf("failed to save profile: %v", err)
+	}
+
+	log.Printf("you %v against %v, your rating is now %.0f", result, opponent, state.profile.Rating)
 }
 
 func (state *guiState) onLeftMouseButtonDown() {
@@ -219,27 +477,39 @@ func (state *guiState) onLeftMouseButtonDown() {
 
 	hoverSquare := chess.SquareAt(chess.File(state.mouseX/squareWidth), chess.Rank(state.mouseY/squareHeight))
 
-	if state.movingPiece {
+	if state.choosingPromotion {
+		// Finish promoting, or cancel the move if a square outside the picker was clicked
+		for index, square := range state.promotionPickerSquares() {
+			if square == hoverSquare {
+				state.makeMove(chess.Move{
+					Source:        state.pieceSourceSquare,
+					Destination:   state.promotionSquare,
+					IsPromotion:   true,
+					PromotedPiece: promotionPieces[index],
+				})
+			}
+		}
+
+		state.choosingPromotion = false
+	} else if state.movingPiece {
 		// Finish moving piece
-		for _, destinationSquare := range state.pieceDestinationSquares {
-			if destinationSquare == hoverSquare {
-				// TODO: underpromotions
-				var promotionRank chess.Rank
-				if state.board.IsBlackToMove() {
-					promotionRank = chess.Rank1
+		for _, moveInfo := range state.pieceMoves {
+			if moveInfo.Destination == hoverSquare {
+				if moveInfo.IsPromotion && state.options.Promotion.shouldAsk(state.board, state.pieceSourceSquare, hoverSquare) {
+					// Let the user choose which piece to promote to
+					state.choosingPromotion = true
+					state.promotionSquare = hoverSquare
+				} else if moveInfo.IsPromotion {
+					state.makeMove(chess.Move{
+						Source:        state.pieceSourceSquare,
+						Destination:   hoverSquare,
+						IsPromotion:   true,
+						PromotedPiece: chess.Queen,
+					})
 				} else {
-					promotionRank = chess.Rank8
+					state.makeMove(moveInfo.Move)
 				}
 
-				isPromotion := state.board.GetPiece(state.pieceSourceSquare).Kind == chess.Pawn && destinationSquare.Rank() == promotionRank
-
-				state.makeMove(chess.Move{
-					Source:      state.pieceSourceSquare,
-					Destination: destinationSquare,
-					IsPromotion: isPromotion,
-					PromotedPiece: chess.Queen,
-				})
-
 				break
 			}
 		}
@@ -249,20 +519,36 @@ func (state *guiState) onLeftMouseButtonDown() {
 		// Start moving piece
 		hoverPiece := state.board.GetPiece(hoverSquare)
 		if hoverPiece != nil {
-			if hoverPiece.IsBlack == state.board.IsBlackToMove() {
+			if hoverPiece.Color() == state.board.SideToMove() {
 				state.movingPiece = true
 				state.pieceSourceSquare = hoverSquare
-				state.pieceDestinationSquares = []chess.Square{}
-
-				for _, move := range state.board.GetLegalMovesFromSquare(hoverSquare) {
-					state.pieceDestinationSquares = append(state.pieceDestinationSquares, move.Destination)
-				}
+				state.pieceMoves = state.board.GetLegalMoveInfoFromSquare(hoverSquare)
 			}
 		}
 	}
 }
 
+// Pieces offered when promoting, in the order they are displayed
+var promotionPieces = [4]chess.PieceKind{chess.Queen, chess.Knight, chess.Rook, chess.Bishop}
+
+// Returns the squares the promotion choices are displayed on, extending from the promotion square
+// towards the centre of the board
+func (state *guiState) promotionPickerSquares() (squares [4]chess.Square) {
+	direction := 1
+	if state.promotionSquare.Rank() == chess.Rank1 {
+		direction = -1
+	}
+
+	for index := range squares {
+		squares[index], _ = state.promotionSquare.Offset(0, index*direction)
+	}
+
+	return
+}
+
 func (state *guiState) onBKeyDown() {
+	state.cancelBotMove()
+
 	if len(state.unmoveHistory) == 0 {
 		return
 	}
@@ -270,25 +556,50 @@ func (state *guiState) onBKeyDown() {
 	// Undo last move
 	unmove := state.unmoveHistory[len(state.unmoveHistory) - 1]
 	state.unmoveHistory = state.unmoveHistory[:len(state.unmoveHistory) - 1]
+	state.moveHistory = state.moveHistory[:len(state.moveHistory) - 1]
 
 	state.board.UnmakeMove(unmove)
 }
 
-func loadPiecesTexture(renderer *sdl.Renderer) (piecesTexture *sdl.Texture, piecesTextureW int32, piecesTextureH int32) {
-	piecesImage, err := img.Load(piecesImagePath)
-	if err != nil {
-		panic(err)
-	}
-	defer piecesImage.Free()
+// Start a rematch from the position the first game started from
+func (state *guiState) onNKeyDown() {
+	state.cancelBotMove()
+	state.saveUnfinishedGame()
 
-	piecesTextureW = piecesImage.W
-	piecesTextureH = piecesImage.H
+	*state.board = state.initialBoard
 
-	piecesTexture, err = renderer.CreateTextureFromSurface(piecesImage)
-	if err != nil {
-		panic(err)
+	state.movingPiece = false
+	state.choosingPromotion = false
+	state.lastMove = nil
+	state.unmoveHistory = state.unmoveHistory[:0]
+	state.moveHistory = state.moveHistory[:0]
+	state.resultRecorded = false
+	state.gameSaved = false
+	state.window.SetTitle(state.title)
+
+	for _, bot := range []chess.Bot{state.whiteBot, state.blackBot} {
+		chess.StartNewGame(bot)
 	}
+}
 
-	return
+// Switch to the next way of choosing the piece to promote to
+func (state *guiState) onPKeyDown() {
+	state.options.Promotion = (state.options.Promotion + 1) % 3
+	log.Printf("promotion: %v", state.options.Promotion)
 }
 
+// Reload the evaluation parameters of any bots that support it
+func (state *guiState) onRKeyDown() {
+	for _, bot := range []chess.Bot{state.whiteBot, state.blackBot} {
+		reloader, ok := bot.(evalReloader)
+		if !ok {
+			continue
+		}
+
+		if err := reloader.ReloadEvalParams(); err != nil {
+			log.Printf("failed to reload evaluation parameters for %v: %v", playerName(bot), err)
+		} else {
+			log.Printf("reloaded evaluation parameters for %v", playerName(bot))
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package chessgui
+
+import (
+	"gogm/chess"
+	"log"
+	"os"
+	"time"
+)
+
+// Returns the game played since the window was opened or the last rematch, with its tags filled in
+func (state *guiState) currentGame() (*chess.Game, error) {
+	game := chess.NewGame(&state.initialBoard)
+	game.SetTag("Date", time.Now().Format("2006.01.02"))
+	game.SetPlayers(state.whiteBot, state.blackBot)
+
+	// The GUI gives bots as long as they want, and has no clocks
+	game.SetTag("TimeControl", "-")
+
+	for _, move := range state.moveHistory {
+		game.Moves = append(game.Moves, chess.GameMove{Move: move})
+	}
+
+	if err := game.CompleteTags(); err != nil {
+		return nil, err
+	}
+
+	return game, nil
+}
+
+// Add the game to the end of the PGN file, if games are being saved
+// Only the first time a game ends is saved, so taking back moves after it has ended does not save it
+// again
+func (state *guiState) saveGame() {
+	state.gameSaved = true
+
+	if state.options.PgnPath == "" {
+		return
+	}
+
+	game, err := state.currentGame()
+	if err != nil {
+		log.Printf("failed to save game: %v", err)
+		return
+	}
+
+	file, err := os.OpenFile(state.options.PgnPath, os.O_APPEND | os.O_CREATE | os.O_WRONLY, 0644)
+	if err != nil {
+		log.Printf("failed to save game: %v", err)
+		return
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(game.Pgn() + "\n"); err != nil {
+		log.Printf("failed to save game: %v", err)
+		return
+	}
+
+	log.Printf("saved game to %v", state.options.PgnPath)
+}
+
+// Save the game if it has not ended, so that games abandoned for a rematch or by closing the window
+// are kept too. Games with no moves are not saved
+func (state *guiState) saveUnfinishedGame() {
+	if !state.gameSaved && len(state.moveHistory) > 0 {
+		state.saveGame()
+	}
+}
@@ -2,9 +2,19 @@ module gogm/chessgui
 
 go 1.22.5
 
+replace gogm/bot => ../bot
+
+replace gogm/botv1 => ../botv1
+
 replace gogm/chess => ../chess
 
+replace gogm/gamedb => ../gamedb
+
 require (
 	github.com/veandco/go-sdl2 v0.4.40
+	gogm/bot v0.0.0-00010101000000-000000000000
+	gogm/botv1 v0.0.0-00010101000000-000000000000
 	gogm/chess v0.0.0-00010101000000-000000000000
+	gogm/gamedb v0.0.0-00010101000000-000000000000
+	golang.org/x/image v0.18.0
 )
@@ -0,0 +1,143 @@
+package chessgui
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// Rating given to the player and to bots they have not played yet
+const initialRating float64 = 1500.0
+
+// How much a single game changes the ratings
+const ratingKFactor float64 = 32.0
+
+// Results of the human player against each bot across sessions, with a rating for the player and
+// each bot estimated from the results
+type Profile struct {
+	Rating    float64                    `json:"rating"`
+	Opponents map[string]*OpponentRecord `json:"opponents"`
+}
+
+// Results against one bot, identified by its full name (including the version and any skill
+// level in the name)
+type OpponentRecord struct {
+	Wins   int     `json:"wins"`
+	Draws  int     `json:"draws"`
+	Losses int     `json:"losses"`
+	Rating float64 `json:"rating"`
+}
+
+// Result of a game from the perspective of the human player
+type GameResult int8
+
+const (
+	PlayerLost GameResult = iota
+	PlayerDrew
+	PlayerWon
+)
+
+func (result GameResult) String() string {
+	switch result {
+	case PlayerWon:
+		return "won"
+	case PlayerDrew:
+		return "drew"
+	default:
+		return "lost"
+	}
+}
+
+// Returns where the profile is stored by default, in the user's config directory
+func DefaultProfilePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "gogm", "profile.json"), nil
+}
+
+// Load the profile from a JSON file, returning a new profile if the file does not exist
+func LoadProfile(path string) (*Profile, error) {
+	profile := &Profile{Rating: initialRating, Opponents: make(map[string]*OpponentRecord)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return profile, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, profile); err != nil {
+		return nil, err
+	}
+
+	if profile.Opponents == nil {
+		profile.Opponents = make(map[string]*OpponentRecord)
+	}
+
+	return profile, nil
+}
+
+// Write the profile to a JSON file, creating the directory if needed
+func (profile *Profile) Save(path string) error {
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// Add the result of a game against the named bot, updating the ratings of the player and the bot
+func (profile *Profile) RecordResult(opponent string, result GameResult) {
+	record, ok := profile.Opponents[opponent]
+	if !ok {
+		record = &OpponentRecord{Rating: initialRating}
+		profile.Opponents[opponent] = record
+	}
+
+	switch result {
+	case PlayerWon:
+		record.Wins++
+	case PlayerDrew:
+		record.Draws++
+	case PlayerLost:
+		record.Losses++
+	}
+
+	// Elo update: the ratings move by the difference between the score and the expected score
+	score := float64(result) / 2.0
+	expectedScore := 1.0 / (1.0 + math.Pow(10.0, (record.Rating - profile.Rating) / 400.0))
+	change := ratingKFactor * (score - expectedScore)
+
+	profile.Rating += change
+	record.Rating -= change
+}
+
+// Returns the bot the player has played whose rating is closest to theirs, making for the most
+// even game, and whether the player has played any bots
+func (profile *Profile) SuggestedOpponent() (name string, ok bool) {
+	closestDifference := math.Inf(1)
+
+	for opponent, record := range profile.Opponents {
+		difference := math.Abs(record.Rating - profile.Rating)
+
+		// Ties are broken by name so that the suggestion does not depend on map order
+		if difference < closestDifference || (difference == closestDifference && opponent < name) {
+			name = opponent
+			closestDifference = difference
+			ok = true
+		}
+	}
+
+	return
+}
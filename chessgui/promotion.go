@@ -0,0 +1,105 @@
+package chessgui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gogm/botv1"
+	"gogm/chess"
+)
+
+// How the GUI chooses the piece to promote to when the user moves a pawn to the last rank
+type PromotionMode uint8
+
+const (
+	// Always let the user choose the piece
+	PromotionAsk PromotionMode = iota
+
+	// Always promote to a queen
+	PromotionAlwaysQueen
+
+	// Promote to a queen unless a shallow search scores an underpromotion higher, in which case let
+	// the user choose
+	PromotionSmart
+)
+
+func (mode PromotionMode) String() string {
+	switch mode {
+	case PromotionAlwaysQueen:
+		return "queen"
+	case PromotionSmart:
+		return "smart"
+	default:
+		return "ask"
+	}
+}
+
+// Returns the promotion mode with the given name (ask, queen or smart)
+func PromotionModeWithName(name string) (PromotionMode, error) {
+	for _, mode := range []PromotionMode{PromotionAsk, PromotionAlwaysQueen, PromotionSmart} {
+		if mode.String() == name {
+			return mode, nil
+		}
+	}
+
+	return PromotionAsk, errors.New(fmt.Sprintf("unknown promotion mode: %v", name))
+}
+
+// Returns whether the user should choose the piece to promote to when moving a pawn from `source`
+// to `destination`, rather than promoting to a queen automatically
+func (mode PromotionMode) shouldAsk(board *chess.Board, source chess.Square, destination chess.Square) bool {
+	switch mode {
+	case PromotionAlwaysQueen:
+		return false
+	case PromotionSmart:
+		return underpromotionMayBeBetter(board, source, destination)
+	default:
+		return true
+	}
+}
+
+// Depth of the search after each promotion that decides whether an underpromotion may be better
+// (ply). This is deep enough for the opponent to reply and the promoted piece to win material, e.g.
+// with a knight fork, and for a stalemate trap set by the opponent's reply
+const promotionSearchDepth int = 4
+
+// Bot searching the promotions for PromotionSmart, created when first needed. It is kept apart
+// from the bots playing so that their search state and callbacks are left alone, and is
+// deterministic so that the same promotion is always treated the same way
+var promotionBot *botv1.BotV1
+
+// Returns whether a shallow search scores an underpromotion higher than promoting to a queen, e.g.
+// when a knight forks the king and queen or a queen would stalemate the opponent
+func underpromotionMayBeBetter(board *chess.Board, source chess.Square, destination chess.Square) bool {
+	queenScore := promotionScore(board, chess.Move{Source: source, Destination: destination, IsPromotion: true, PromotedPiece: chess.Queen})
+
+	for _, kind := range promotionPieces[1:] {
+		move := chess.Move{Source: source, Destination: destination, IsPromotion: true, PromotedPiece: kind}
+		if promotionScore(board, move) > queenScore {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the score of the promotion for the side promoting, from a search of the position after it
+// to promotionSearchDepth
+func promotionScore(board *chess.Board, move chess.Move) float64 {
+	afterMove := board.MakeMoveCopy(move)
+
+	if len(afterMove.GetLegalMoves(false)) == 0 {
+		if afterMove.IsCheck() {
+			return chess.MateInPly(0)
+		}
+
+		return 0.0
+	}
+
+	if promotionBot == nil {
+		promotionBot, _ = botv1.New(botv1.Options{HashSizeMB: 1, Deterministic: true})
+	}
+
+	_, info := promotionBot.ThinkContext(context.Background(), &afterMove, chess.SearchLimits{Depth: promotionSearchDepth})
+	return -info.Score
+}
@@ -0,0 +1,175 @@
+package chessgui
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/veandco/go-sdl2/sdl"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// First and last characters that can be drawn, the printable ASCII characters. Others are drawn as
+// a question mark
+const (
+	firstGlyph rune = ' '
+	lastGlyph  rune = '~'
+)
+
+// Draws text in the Go font, which is built into the binary so needs no font files or SDL_ttf
+// The characters of each size of text drawn are rasterised once, into a texture that text of that
+// size is then drawn from
+type textRenderer struct {
+	renderer *sdl.Renderer
+	font     *opentype.Font
+	atlases  map[int]*glyphAtlas
+}
+
+// Texture holding the characters at one size, side by side
+type glyphAtlas struct {
+	texture *sdl.Texture
+	height  int32
+
+	// Position of each character in the texture. Each is as wide as the distance to the next
+	// character drawn after it
+	glyphs [lastGlyph - firstGlyph + 1]sdl.Rect
+}
+
+func newTextRenderer(renderer *sdl.Renderer) (*textRenderer, error) {
+	font, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, err
+	}
+
+	return &textRenderer{renderer: renderer, font: font, atlases: make(map[int]*glyphAtlas)}, nil
+}
+
+func (text *textRenderer) destroy() {
+	for _, atlas := range text.atlases {
+		atlas.texture.Destroy()
+	}
+}
+
+// Draw the text with its top left corner at (x, y), `size` pixels high
+func (text *textRenderer) draw(s string, x int32, y int32, size int, color sdl.Color) {
+	atlas, err := text.atlas(size)
+	if err != nil {
+		return
+	}
+
+	atlas.texture.SetColorMod(color.R, color.G, color.B)
+	atlas.texture.SetAlphaMod(color.A)
+
+	for _, r := range s {
+		source := atlas.glyph(r)
+		destination := sdl.Rect{X: x, Y: y, W: source.W, H: source.H}
+
+		text.renderer.Copy(atlas.texture, &source, &destination)
+		x += source.W
+	}
+}
+
+// Returns the width and height of the text in pixels when drawn at the given size
+func (text *textRenderer) measure(s string, size int) (width int32, height int32) {
+	atlas, err := text.atlas(size)
+	if err != nil {
+		return 0, 0
+	}
+
+	for _, r := range s {
+		width += atlas.glyph(r).W
+	}
+
+	return width, atlas.height
+}
+
+// Returns the atlas for text of the given size, creating it the first time the size is used
+func (text *textRenderer) atlas(size int) (*glyphAtlas, error) {
+	if atlas, ok := text.atlases[size]; ok {
+		return atlas, nil
+	}
+
+	face, err := opentype.NewFace(text.font, &opentype.FaceOptions{Size: float64(size), DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	metrics := face.Metrics()
+	atlas := &glyphAtlas{height: int32((metrics.Ascent + metrics.Descent).Ceil())}
+
+	// Each character gets a cell as wide as its advance, so the atlas can be drawn from directly
+	width := int32(0)
+	for r := firstGlyph; r <= lastGlyph; r++ {
+		advance, _ := face.GlyphAdvance(r)
+
+		atlas.glyphs[r - firstGlyph] = sdl.Rect{X: width, Y: 0, W: int32(advance.Ceil()), H: atlas.height}
+		width += int32(advance.Ceil())
+	}
+
+	pixels := image.NewNRGBA(image.Rect(0, 0, int(width), int(atlas.height)))
+	drawer := font.Drawer{Dst: pixels, Src: image.White, Face: face}
+
+	for r := firstGlyph; r <= lastGlyph; r++ {
+		drawer.Dot = fixed.P(int(atlas.glyphs[r - firstGlyph].X), metrics.Ascent.Ceil())
+		drawer.DrawString(string(r))
+	}
+
+	atlas.texture, err = textureFromImage(text.renderer, pixels)
+	if err != nil {
+		return nil, err
+	}
+	atlas.texture.SetBlendMode(sdl.BLENDMODE_BLEND)
+
+	text.atlases[size] = atlas
+	return atlas, nil
+}
+
+// Returns the position of the character in the atlas
+func (atlas *glyphAtlas) glyph(r rune) sdl.Rect {
+	if r < firstGlyph || r > lastGlyph {
+		r = '?'
+	}
+
+	return atlas.glyphs[r - firstGlyph]
+}
+
+// Returns a texture with the pixels of the image
+func textureFromImage(renderer *sdl.Renderer, pixels *image.NRGBA) (*sdl.Texture, error) {
+	surface, err := surfaceFromImage(pixels)
+	if err != nil {
+		return nil, err
+	}
+	defer surface.Free()
+
+	return renderer.CreateTextureFromSurface(surface)
+}
+
+// Returns a surface with the pixels of the image
+func surfaceFromImage(img image.Image) (*sdl.Surface, error) {
+	bounds := img.Bounds()
+
+	rgba, ok := img.(*image.NRGBA)
+	if !ok || bounds.Min != (image.Point{}) {
+		rgba = image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	}
+
+	surface, err := sdl.CreateRGBSurfaceWithFormat(0, int32(bounds.Dx()), int32(bounds.Dy()), 32, uint32(sdl.PIXELFORMAT_RGBA32))
+	if err != nil {
+		return nil, err
+	}
+
+	// Copy row by row, as the surface's rows may be padded
+	surface.Lock()
+	pixels := surface.Pixels()
+	for y := 0; y < bounds.Dy(); y++ {
+		row := rgba.Pix[y * rgba.Stride : y * rgba.Stride + bounds.Dx() * 4]
+		copy(pixels[y * int(surface.Pitch):], row)
+	}
+	surface.Unlock()
+
+	return surface, nil
+}
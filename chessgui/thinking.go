@@ -0,0 +1,181 @@
+package chessgui
+
+import (
+	"context"
+	"fmt"
+	"gogm/bot"
+	"gogm/chess"
+	"log"
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Colours of the arrows showing the moves the bot expects, for the side to move and its opponent.
+// Arrows further along the line are fainter
+var (
+	thinkingColor         = sdl.Color{R: 0, G: 130, B: 60, A: 150}
+	thinkingOpponentColor = sdl.Color{R: 150, G: 30, B: 30, A: 150}
+)
+
+// A comment on a move from a bot thinking in the background, shown once the move is made
+type botComment struct {
+	move    chess.Move
+	comment string
+}
+
+// Start the bot choosing a move for the position in the background, so that the window stays
+// responsive while it thinks
+func (state *guiState) startBotMove(chessBot chess.Bot) {
+	// The bot makes moves on the board while searching, so gets its own copy
+	board := state.board.Copy()
+	result := make(chan chess.Move, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	state.botMove = result
+	state.stopThinking = cancel
+	state.setSearchInfo(nil)
+
+	go func() {
+		move, _ := bot.FromChessBot(chessBot).Think(ctx, &board, bot.Limits{})
+		result <- move
+	}()
+}
+
+// Make the move of the bot thinking in the background, if it has chosen one
+func (state *guiState) receiveBotMove() {
+	if state.botMove == nil {
+		return
+	}
+
+	select {
+	case move := <-state.botMove:
+		state.botMove = nil
+		state.stopThinking()
+		state.setSearchInfo(nil)
+
+		state.botLock.Lock()
+		comment := state.pendingComment
+		state.pendingComment = nil
+		state.botLock.Unlock()
+
+		// The comment is on the move about to be made, so is written from the position before it
+		if comment != nil && comment.move == move {
+			state.window.SetTitle(fmt.Sprintf("%v - %v: %v", state.title, state.board.LocalizedSan(move, state.options.Notation), comment.comment))
+		}
+
+		state.makeMove(move)
+
+	default:
+	}
+}
+
+// Stop the bot thinking in the background, if there is one, and discard its move. This must be
+// done before the position on the board is changed by anything other than the bot's move
+func (state *guiState) cancelBotMove() {
+	if state.botMove == nil {
+		return
+	}
+
+	// Bots that cannot be stopped finish their search
+	state.stopThinking()
+	<-state.botMove
+	state.botMove = nil
+	state.setSearchInfo(nil)
+
+	state.botLock.Lock()
+	state.pendingComment = nil
+	state.botLock.Unlock()
+}
+
+// Record the comment of a bot thinking in the background, called from its goroutine
+func (state *guiState) onBotComment(move chess.Move, comment string) {
+	state.botLock.Lock()
+	defer state.botLock.Unlock()
+
+	state.pendingComment = &botComment{move: move, comment: comment}
+}
+
+// Record the progress of a bot thinking in the background, called from its goroutine
+func (state *guiState) onSearchInfo(info chess.SearchInfo) {
+	state.setSearchInfo(&info)
+}
+
+func (state *guiState) setSearchInfo(info *chess.SearchInfo) {
+	state.botLock.Lock()
+	defer state.botLock.Unlock()
+
+	state.searchInfo = info
+}
+
+// Returns the latest progress of the bot thinking about the position on the board, or nil if it
+// has not reported any
+func (state *guiState) currentSearchInfo() *chess.SearchInfo {
+	state.botLock.Lock()
+	defer state.botLock.Unlock()
+
+	return state.searchInfo
+}
+
+// Switch showing the moves the bot is considering on and off
+func (state *guiState) onTKeyDown() {
+	state.options.ShowThinking = !state.options.ShowThinking
+	log.Printf("show thinking: %v", state.options.ShowThinking)
+}
+
+// Draw arrows for the moves the bot thinking about the position expects to be played, fading
+// along the line
+func (state *guiState) drawThinking() {
+	info := state.currentSearchInfo()
+	if info == nil {
+		return
+	}
+
+	for index, move := range info.PrincipalVariation {
+		color := thinkingColor
+		if index % 2 == 1 {
+			color = thinkingOpponentColor
+		}
+		color.A = uint8(float64(color.A) * (1 - float64(index) / float64(len(info.PrincipalVariation) + 1)))
+
+		state.drawArrow(move.Source, move.Destination, color)
+	}
+}
+
+// Draw an arrow from the centre of one square to the centre of another
+func (state *guiState) drawArrow(source chess.Square, destination chess.Square, color sdl.Color) {
+	centre := func(sq chess.Square) (float64, float64) {
+		return (float64(sq.File()) + 0.5) * float64(squareWidth), (float64(sq.Rank()) + 0.5) * float64(squareHeight)
+	}
+
+	x0, y0 := centre(source)
+	x1, y1 := centre(destination)
+
+	// Unit vectors along and across the arrow
+	length := math.Hypot(x1 - x0, y1 - y0)
+	alongX, alongY := (x1 - x0) / length, (y1 - y0) / length
+	acrossX, acrossY := -alongY, alongX
+
+	shaftWidth := 0.12 * float64(squareWidth)
+	headWidth := 0.4 * float64(squareWidth)
+	headLength := math.Min(0.4 * float64(squareWidth), length)
+
+	// Where the shaft meets the head
+	neckX, neckY := x1 - alongX * headLength, y1 - alongY * headLength
+
+	vertex := func(x float64, y float64) sdl.Vertex {
+		return sdl.Vertex{Position: sdl.FPoint{X: float32(x), Y: float32(y)}, Color: color}
+	}
+
+	vertices := []sdl.Vertex{
+		vertex(x0 + acrossX * shaftWidth / 2, y0 + acrossY * shaftWidth / 2),
+		vertex(neckX + acrossX * shaftWidth / 2, neckY + acrossY * shaftWidth / 2),
+		vertex(neckX - acrossX * shaftWidth / 2, neckY - acrossY * shaftWidth / 2),
+		vertex(x0 - acrossX * shaftWidth / 2, y0 - acrossY * shaftWidth / 2),
+		vertex(neckX + acrossX * headWidth / 2, neckY + acrossY * headWidth / 2),
+		vertex(x1, y1),
+		vertex(neckX - acrossX * headWidth / 2, neckY - acrossY * headWidth / 2),
+	}
+
+	state.renderer.RenderGeometry(nil, vertices, []int32{0, 1, 2, 0, 2, 3, 4, 5, 6})
+}
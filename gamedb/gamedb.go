@@ -0,0 +1,160 @@
+// Package gamedb indexes collections of games by the positions reached in them, to find the games
+// reaching a position and the moves played from it, as in an opening explorer
+package gamedb
+
+import (
+	"errors"
+	"fmt"
+	"gogm/chess"
+	"io"
+	"sort"
+)
+
+// Games indexed by the positions reached in their main lines
+type Database struct {
+	games []*chess.Game
+
+	// Where each position was reached, by chess.Board.PositionKey
+	positions map[uint64][]occurrence
+}
+
+// A position reached in a game, before the move with index `ply` of its main line
+type occurrence struct {
+	game int32
+	ply  int32
+}
+
+// Moves played from a position and how the games continuing with them ended
+type MoveStats struct {
+	Move chess.Move
+
+	Games     int
+	WhiteWins int
+	Draws     int
+	BlackWins int
+}
+
+// Returns an empty database
+func New() *Database {
+	return &Database{positions: make(map[uint64][]occurrence)}
+}
+
+// Add the game to the database, indexing every position of its main line
+func (db *Database) Add(game *chess.Game) error {
+	board, err := game.StartingBoard()
+	if err != nil {
+		return err
+	}
+
+	index := int32(len(db.games))
+	db.games = append(db.games, game)
+
+	for ply := 0; ; ply++ {
+		key := board.PositionKey()
+
+		// A position repeated within a game counts once, continuing with the first move played from it
+		occurrences := db.positions[key]
+		if len(occurrences) == 0 || occurrences[len(occurrences) - 1].game != index {
+			db.positions[key] = append(occurrences, occurrence{game: index, ply: int32(ply)})
+		}
+
+		if ply == len(game.Moves) {
+			break
+		}
+
+		board.MakeMove(game.Moves[ply].Move)
+	}
+
+	return nil
+}
+
+// Read every game of the PGN and add it to the database, skipping games that cannot be read
+// Returns the number of games added and an error describing the first game skipped, if any
+func (db *Database) AddPgn(reader io.Reader) (added int, err error) {
+	pgn := chess.NewPgnReader(reader)
+
+	for number := 1; ; number++ {
+		game, readErr := pgn.Read()
+		if readErr == io.EOF {
+			return
+		}
+
+		if readErr == nil {
+			readErr = db.Add(game)
+		}
+
+		if readErr != nil {
+			if err == nil {
+				err = errors.New(fmt.Sprintf("game %v: %v", number, readErr))
+			}
+			continue
+		}
+
+		added++
+	}
+}
+
+// Returns the number of games in the database
+func (db *Database) Len() int {
+	return len(db.games)
+}
+
+// Returns the games whose main lines reach the position, in the order they were added
+func (db *Database) Games(board *chess.Board) []*chess.Game {
+	var games []*chess.Game
+	for _, occurrence := range db.positions[board.PositionKey()] {
+		games = append(games, db.games[occurrence.game])
+	}
+
+	return games
+}
+
+// Returns the moves played from the position in the games reaching it, most frequent first
+// Games that end in the position are not counted
+func (db *Database) MoveStats(board *chess.Board) []MoveStats {
+	var stats []MoveStats
+
+	for _, occurrence := range db.positions[board.PositionKey()] {
+		game := db.games[occurrence.game]
+		if int(occurrence.ply) == len(game.Moves) {
+			continue
+		}
+
+		move := game.Moves[occurrence.ply].Move
+
+		index := 0
+		for index < len(stats) && stats[index].Move != move {
+			index++
+		}
+		if index == len(stats) {
+			stats = append(stats, MoveStats{Move: move})
+		}
+
+		stats[index].Games++
+		switch game.Result {
+		case chess.WhiteWins:
+			stats[index].WhiteWins++
+		case chess.BlackWins:
+			stats[index].BlackWins++
+		case chess.Draw:
+			stats[index].Draws++
+		}
+	}
+
+	sort.SliceStable(stats, func(i int, j int) bool {
+		return stats[i].Games > stats[j].Games
+	})
+
+	return stats
+}
+
+// Returns the proportion of points scored by white in the finished games, counting a draw as half
+// a point, or 0.5 if none of the games are finished
+func (stats MoveStats) WhiteScore() float64 {
+	finished := stats.WhiteWins + stats.Draws + stats.BlackWins
+	if finished == 0 {
+		return 0.5
+	}
+
+	return (float64(stats.WhiteWins) + 0.5 * float64(stats.Draws)) / float64(finished)
+}
@@ -0,0 +1,93 @@
+package gamedb_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"gogm/gamedb"
+	"strings"
+	"testing"
+)
+
+const testPgn = `[White "A"]
+[Black "B"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 1-0
+
+[White "C"]
+[Black "D"]
+[Result "1/2-1/2"]
+
+1. Nf3 Nc6 2. e4 e5 3. Bb5 1/2-1/2
+
+[White "E"]
+[Black "F"]
+[Result "0-1"]
+
+1. d4 d5 0-1
+
+[White "G"]
+[Black "H"]
+[Result "*"]
+
+1. e4 c5 *
+`
+
+func loadTestDatabase(t *testing.T) *gamedb.Database {
+	db := gamedb.New()
+	added, err := db.AddPgn(strings.NewReader(testPgn))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, added)
+
+	return db
+}
+
+func TestMoveStats(t *testing.T) {
+	assert := assert.New(t)
+
+	db := loadTestDatabase(t)
+	board, err := chess.LoadFen(chess.StartingPositionFen)
+	assert.Nil(err)
+
+	stats := db.MoveStats(board)
+	assert.Len(stats, 3)
+
+	assert.Equal(chess.Move{Source: chess.E2, Destination: chess.E4}, stats[0].Move)
+	assert.Equal(2, stats[0].Games)
+	assert.Equal(1, stats[0].WhiteWins)
+	assert.Equal(1.0, stats[0].WhiteScore())
+
+	assert.Equal(0.5, stats[1].WhiteScore())
+	assert.Equal(0.0, stats[2].WhiteScore())
+}
+
+func TestTranspositions(t *testing.T) {
+	assert := assert.New(t)
+
+	db := loadTestDatabase(t)
+
+	// Reached by both 1. e4 e5 2. Nf3 Nc6 and 1. Nf3 Nc6 2. e4 e5, whose en passant targets differ
+	board, err := chess.LoadFen("r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3")
+	assert.Nil(err)
+
+	games := db.Games(board)
+	assert.Len(games, 2)
+	assert.Equal("A", games[0].Tag("White"))
+	assert.Equal("C", games[1].Tag("White"))
+
+	// The first game ends in the position, so only the second continues from it
+	stats := db.MoveStats(board)
+	assert.Len(stats, 1)
+	assert.Equal(chess.Move{Source: chess.F1, Destination: chess.B5}, stats[0].Move)
+	assert.Equal(1, stats[0].Draws)
+}
+
+func TestAddPgnSkipsBadGames(t *testing.T) {
+	assert := assert.New(t)
+
+	db := gamedb.New()
+	added, err := db.AddPgn(strings.NewReader("[White \"A\"]\n\n1. e5 *\n\n[White \"B\"]\n\n1. e4 *\n"))
+	assert.NotNil(err)
+	assert.Equal(1, added)
+	assert.Equal(1, db.Len())
+}
@@ -0,0 +1,128 @@
+// Package jsonapi describes positions, moves and searches as the JSON objects that frontends not
+// written in Go receive from gogm, so that the server and the WebAssembly bindings give the same
+// answers
+package jsonapi
+
+import (
+	"errors"
+	"fmt"
+	"gogm/chess"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A move in both notations
+type Move struct {
+	Uci string `json:"uci"`
+	San string `json:"san"`
+}
+
+func NewMove(board *chess.Board, move chess.Move) Move {
+	return Move{Uci: move.String(), San: board.San(move)}
+}
+
+// Returns the legal moves of the position
+func LegalMoves(board *chess.Board) []Move {
+	moves := []Move{}
+	for _, move := range board.GetLegalMoves(false) {
+		moves = append(moves, NewMove(board, move))
+	}
+
+	return moves
+}
+
+// A position with its legal moves and outcome
+type Position struct {
+	Fen         string `json:"fen"`
+	SideToMove  string `json:"sideToMove"`
+	Check       bool   `json:"check"`
+	Moves       []Move `json:"moves"`
+	Result      string `json:"result"`
+	Termination string `json:"termination"`
+}
+
+func NewPosition(board *chess.Board, fullmoveNumber int) Position {
+	outcome := board.Outcome()
+
+	return Position{
+		Fen:         board.FullFen(fullmoveNumber),
+		SideToMove:  board.SideToMove().String(),
+		Check:       board.IsCheck(),
+		Moves:       LegalMoves(board),
+		Result:      outcome.Result.String(),
+		Termination: outcome.Termination.String(),
+	}
+}
+
+// Returns the position of the FEN, or the starting position if it is empty, after checking that it
+// is legal, and the fullmove number of the FEN, which the board does not keep
+func LoadPosition(fen string) (*chess.Board, int, error) {
+	if fen == "" {
+		fen = chess.StartingPositionFen
+	}
+
+	board, err := chess.LoadFen(fen)
+	if err != nil {
+		return nil, 0, errors.New(fmt.Sprintf("bad FEN: %v", err))
+	}
+
+	if err := board.Validate(); err != nil {
+		return nil, 0, errors.New(fmt.Sprintf("illegal position: %v", err))
+	}
+
+	fullmoveNumber := 1
+	if fields := strings.Fields(fen); len(fields) > 5 {
+		if number, err := strconv.Atoi(fields[5]); err == nil && number > 0 {
+			fullmoveNumber = number
+		}
+	}
+
+	return board, fullmoveNumber, nil
+}
+
+// Score from the side to move's point of view, in centipawns or as the number of moves to mate,
+// negative if it is being mated
+type Score struct {
+	Centipawns *int `json:"cp,omitempty"`
+	Mate       *int `json:"mate,omitempty"`
+}
+
+func NewScore(score float64) Score {
+	if moves, ok := chess.MateIn(score); ok {
+		return Score{Mate: &moves}
+	}
+
+	centipawns := int(math.Round(score * 100.0))
+	return Score{Centipawns: &centipawns}
+}
+
+// Progress of a search, or its result once it has finished (time in milliseconds)
+type Search struct {
+	Move  Move   `json:"move"`
+	Score Score  `json:"score"`
+	Depth int    `json:"depth"`
+	Nodes uint64 `json:"nodes"`
+	Time  int64  `json:"time"`
+	Pv    []Move `json:"pv"`
+}
+
+func NewSearch(board *chess.Board, move chess.Move, info chess.SearchInfo) Search {
+	search := Search{
+		Move:  NewMove(board, move),
+		Score: NewScore(info.Score),
+		Depth: info.Depth,
+		Nodes: info.Nodes,
+		Time:  info.Time.Milliseconds(),
+		Pv:    []Move{},
+	}
+
+	// The moves of the principal variation are written in SAN from the positions they are played in
+	line := board.Copy()
+	for _, pvMove := range info.PrincipalVariation {
+		search.Pv = append(search.Pv, NewMove(&line, pvMove))
+		line.MakeMove(pvMove)
+	}
+
+	return search
+}
@@ -0,0 +1,68 @@
+package jsonapi_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"gogm/jsonapi"
+	"testing"
+	"time"
+)
+
+func TestLoadPosition(t *testing.T) {
+	assert := assert.New(t)
+
+	board, fullmoveNumber, err := jsonapi.LoadPosition("")
+	assert.Nil(err)
+	assert.Equal(1, fullmoveNumber)
+	assert.Equal(chess.StartingPositionFen, board.FullFen(fullmoveNumber))
+
+	_, fullmoveNumber, err = jsonapi.LoadPosition("rnbqkbnr/ppp1pppp/8/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 3")
+	assert.Nil(err)
+	assert.Equal(3, fullmoveNumber)
+
+	_, _, err = jsonapi.LoadPosition("not a fen")
+	assert.ErrorContains(err, "bad FEN")
+
+	_, _, err = jsonapi.LoadPosition("8/8/8/8/8/8/8/K7 w - - 0 1")
+	assert.EqualError(err, "illegal position: black has 0 kings")
+}
+
+func TestNewPosition(t *testing.T) {
+	board, _ := chess.LoadFen("7k/6Q1/6K1/8/8/8/8/8 b - - 0 1")
+
+	encoded, err := json.Marshal(jsonapi.NewPosition(board, 40))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"fen": "7k/6Q1/6K1/8/8/8/8/8 b - - 0 40",
+		"sideToMove": "black",
+		"check": true,
+		"moves": [],
+		"result": "1-0",
+		"termination": "checkmate"
+	}`, string(encoded))
+}
+
+func TestNewSearch(t *testing.T) {
+	board, _ := chess.LoadFen(chess.StartingPositionFen)
+
+	e4 := chess.Move{Source: chess.E2, Destination: chess.E4}
+	e5 := chess.Move{Source: chess.E7, Destination: chess.E5}
+	info := chess.SearchInfo{Depth: 2, Score: 0.256, Nodes: 100, Time: 1500 * time.Millisecond, PrincipalVariation: []chess.Move{e4, e5}}
+
+	encoded, err := json.Marshal(jsonapi.NewSearch(board, e4, info))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"move": {"uci": "e2e4", "san": "e4"},
+		"score": {"cp": 26},
+		"depth": 2,
+		"nodes": 100,
+		"time": 1500,
+		"pv": [{"uci": "e2e4", "san": "e4"}, {"uci": "e7e5", "san": "e5"}]
+	}`, string(encoded))
+
+	// Mate scores are given in moves
+	encoded, err = json.Marshal(jsonapi.NewScore(chess.MatedInPly(4)))
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"mate": -2}`, string(encoded))
+}
@@ -0,0 +1,7 @@
+module gogm/magicgen
+
+go 1.22.5
+
+replace gogm/chess => ../chess
+
+require gogm/chess v0.0.0-00010101000000-000000000000
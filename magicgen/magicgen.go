@@ -0,0 +1,82 @@
+package main
+
+// Finds magic numbers for the sliding piece attack tables and prints them as Go source, or checks
+// the magic numbers built into the chess package
+
+import (
+	"flag"
+	"fmt"
+	"gogm/chess"
+	"gogm/chess/magic"
+	"math/rand"
+	"os"
+	"time"
+)
+
+func main() {
+    verify := flag.Bool("verify", false, "verify the built-in magic numbers instead of generating new ones")
+    pieces := flag.String("pieces", "both", "which tables to generate: rook, bishop or both")
+    seed := flag.Int64("seed", 0, "random seed (defaults to the current time)")
+    reduceBits := flag.Uint("reduce", 0, "try to find magics using this many fewer index bits than relevant squares, for denser tables")
+    maxAttempts := flag.Int("attempts", 10_000_000, "candidates to try per square before giving up on a bit count")
+    flag.Parse()
+
+    var kinds []chess.PieceKind
+    switch *pieces {
+    case "rook":
+        kinds = []chess.PieceKind{chess.Rook}
+    case "bishop":
+        kinds = []chess.PieceKind{chess.Bishop}
+    case "both":
+        kinds = []chess.PieceKind{chess.Rook, chess.Bishop}
+    default:
+        fmt.Fprintf(os.Stderr, "unknown pieces: %v\n", *pieces)
+        os.Exit(2)
+    }
+
+    if *verify {
+        ok := true
+        for _, kind := range kinds {
+            table := magic.BuiltinTable(kind)
+            if err := table.Verify(); err != nil {
+                fmt.Printf("%v table: %v\n", pieceName(kind), err)
+                ok = false
+            } else {
+                fmt.Printf("%v table: ok (%v entries)\n", pieceName(kind), table.Size())
+            }
+        }
+
+        if !ok {
+            os.Exit(1)
+        }
+        return
+    }
+
+    if *seed == 0 {
+        *seed = time.Now().UnixNano()
+    }
+    rng := rand.New(rand.NewSource(*seed))
+
+    fmt.Printf("// Generated by magicgen -seed %v -reduce %v\n", *seed, *reduceBits)
+
+    for _, kind := range kinds {
+        table, err := magic.FindTable(kind, *reduceBits, *maxAttempts, rng)
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "%v table: %v\n", pieceName(kind), err)
+            os.Exit(1)
+        }
+
+        fmt.Printf("// %v table: %v entries\n", pieceName(kind), table.Size())
+        if err := table.WriteGo(os.Stdout); err != nil {
+            panic(err)
+        }
+    }
+}
+
+func pieceName(kind chess.PieceKind) string {
+    if kind == chess.Rook {
+        return "rook"
+    } else {
+        return "bishop"
+    }
+}
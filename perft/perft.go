@@ -3,124 +3,214 @@ package main
 // https://www.chessprogramming.org/Perft
 
 import (
+	"bufio"
+	"errors"
+	"flag"
 	"fmt"
 	"gogm/chess"
-	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 var red   string = "\033[31m"
 var green string = "\033[32m"
 var reset string = "\033[0m"
 
-const detectIllegalMoves bool = false
+// Depth counted to for a single position when none is given
+const defaultDepth int = 5
 
-func perft(board *chess.Board, depth int) uint64 {
-    if depth == 0 {
-        return 1
+// A position of a perft suite and the number of positions reachable from it at each depth, with
+// the count for depth n at index n - 1
+type suitePosition struct {
+    fen   string
+    nodes []uint64
+}
+
+func main() {
+    fen := flag.String("fen", "", "position to count from (default the variant's starting position)")
+    variant := flag.String("variant", "standard", "rules to generate moves by: standard, giveaway (antichess) or suicide")
+    depth := flag.Int("depth", 0, fmt.Sprintf("depth to count to in ply (default %v, or every depth of the suite)", defaultDepth))
+    divide := flag.Bool("divide", false, "show the count after each legal move, to narrow down which move is generated wrongly")
+    suitePath := flag.String("suite", "", "file of positions with their expected counts to check, one per line, e.g. <fen> ;D1 20 ;D2 400")
+    expected := flag.Uint64("expected", 0, "expected count for -fen at -depth, checked if given")
+    flag.Parse()
+
+    if flag.NArg() != 0 || (*suitePath != "" && (*divide || *expected != 0)) {
+        fmt.Fprintln(os.Stderr, "-divide and -expected apply to -fen and cannot be used with -suite")
+        flag.Usage()
+        os.Exit(2)
     }
 
-    nodeCount := uint64(0)
+    rules, err := chess.VariantWithName(*variant)
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(2)
+    }
 
-    for _, move := range board.GetLegalMoves(false) {
-        unmove := board.MakeMove(move)
+    fmt.Printf("Sliding attack backend: %v\n", chess.SlidingAttackBackend())
 
-        if detectIllegalMoves {
-            if board.DetectIllegalMove() {
-                board.UnmakeMove(unmove)
-                log.Fatalf("illegal move detected %v in position %v\n", move, board.Fen())
-            }
+    var ok bool
+    if *suitePath != "" {
+        suite, err := loadSuite(*suitePath)
+        if err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+
+        ok = runSuite(suite, rules, *depth)
+    } else {
+        if *depth == 0 {
+            *depth = defaultDepth
         }
 
-        nodeCount += perft(board, depth - 1)
-        board.UnmakeMove(unmove)
+        if *fen == "" {
+            *fen = rules.StartingFen()
+        }
+
+        board, err := chess.LoadFenWithRules(*fen, rules)
+        if err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+
+        ok = countPosition(board, *depth, *divide, *expected)
+    }
+
+    if !ok {
+        os.Exit(1)
+    }
+}
+
+// Count the positions reachable from the board at the depth, listing the count after each move if
+// `divide` is set. Returns false if `expected` is not 0 and differs from the count
+func countPosition(board *chess.Board, depth int, divide bool, expected uint64) bool {
+    start := time.Now()
+
+    var nodes uint64
+    if divide {
+        for _, result := range chess.Divide(board, depth) {
+            nodes += result.Nodes
+            fmt.Printf("%v - %v\n", result.Move, result.Nodes)
+        }
+    } else {
+        nodes = chess.Perft(board, depth)
+    }
+
+    elapsed := time.Since(start)
+    fmt.Printf("Depth %v Result %v (%.2fs, %.0f nodes/s)", depth, nodes, elapsed.Seconds(), float64(nodes) / elapsed.Seconds())
+
+    if expected == 0 {
+        fmt.Println()
+        return true
     }
 
-    return nodeCount
+    fmt.Printf(" Expected %v ", expected)
+    return printVerdict(nodes == expected)
 }
 
-func testPosition(fen string, expectedResults []uint64) {
-    fmt.Printf("Testing position %v\n", fen)
+// Check the count of each position of the suite under the rules at each depth up to `maxDepth`
+// (0 for every depth given). Returns whether they all match
+func runSuite(suite []suitePosition, rules chess.Rules, maxDepth int) bool {
+    passed := 0
+    failed := 0
+    start := time.Now()
 
-    for depth := 0; depth < len(expectedResults); depth++ {
-        board, err := chess.LoadFen(fen)
+    for _, position := range suite {
+        fmt.Printf("Testing position %v\n", position.fen)
 
+        board, err := chess.LoadFenWithRules(position.fen, rules)
         if err != nil {
-            panic(err)
+            fmt.Printf("%vIncorrect%v: %v\n", red, reset, err)
+            failed++
+            continue
         }
 
-        result := perft(board, depth)
+        for depth := 1; depth <= len(position.nodes) && (maxDepth == 0 || depth <= maxDepth); depth++ {
+            result := chess.Perft(board, depth)
+            expected := position.nodes[depth - 1]
 
-        fmt.Printf("Depth %v Result %v Expected %v ", depth, result, expectedResults[depth])
+            fmt.Printf("Depth %v Result %v Expected %v ", depth, result, expected)
 
-        if result == expectedResults[depth] {
-            fmt.Printf("%vCorrect%v\n", green, reset)
-        } else {
-            fmt.Printf("%vIncorrect%v\n", red, reset)
+            if printVerdict(result == expected) {
+                passed++
+            } else {
+                failed++
+            }
         }
     }
+
+    fmt.Printf("\nPassed %v/%v in %.1fs\n", passed, passed + failed, time.Since(start).Seconds())
+    return failed == 0
 }
 
-func divide(fen string, depth int) {
-    board, err := chess.LoadFen(fen)
-    board.MakeMove(chess.Move{ Source: chess.C3, Destination: chess.B1 })
-    board.MakeMove(chess.Move{ Source: chess.B4, Destination: chess.B3 })
+func printVerdict(correct bool) bool {
+    if correct {
+        fmt.Printf("%vCorrect%v\n", green, reset)
+    } else {
+        fmt.Printf("%vIncorrect%v\n", red, reset)
+    }
+
+    return correct
+}
 
+// Read a perft suite, with a position per line followed by its expected counts, each a depth and
+// count after a semicolon, e.g.
+// rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1 ;D1 20 ;D2 400 ;D3 8902
+// Blank lines and lines starting with # are ignored
+func loadSuite(path string) ([]suitePosition, error) {
+    file, err := os.Open(path)
     if err != nil {
-        panic(err)
+        return nil, err
     }
+    defer file.Close()
 
-    var total uint64 = 0
+    var suite []suitePosition
+    scanner := bufio.NewScanner(file)
+    lineNumber := 0
+
+    for scanner.Scan() {
+        lineNumber++
+
+        line := strings.TrimSpace(scanner.Text())
+        if line == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+
+        position, err := parseSuiteLine(line)
+        if err != nil {
+            return nil, errors.New(fmt.Sprintf("%v:%v: %v", path, lineNumber, err))
+        }
 
-    for _, move := range board.GetLegalMoves() {
-        unmove := board.MakeMove(move)
-        perftResult := perft(board, depth)
-        board.UnmakeMove(unmove)
-        total += perftResult
-        fmt.Printf("%v - %v\n", move, perftResult)
+        suite = append(suite, position)
     }
 
-    fmt.Printf("Total: %v\n", total)
+    return suite, scanner.Err()
 }
 
-func main() {
-    // Starting position
-    testPosition(
-        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
-        []uint64 {
-            1,
-            20,
-            400,
-            8902,
-            197281,
-            4865609,
-            119060324,
-        },
-    )
-
-    // Kiwipete
-    testPosition(
-        "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq -",
-        []uint64 {
-            1,
-            48,
-            2039,
-            97862,
-            4085603,
-            193690690,
-            8031647685,
-        },
-    )
-
-    // "Position 5"
-    testPosition(
-        "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8  ",
-        []uint64 {
-            1,
-            44,
-            1486,
-            62379,
-            2103487,
-            89941194,
-        },
-    )
+func parseSuiteLine(line string) (suitePosition, error) {
+    fields := strings.Split(line, ";")
+    position := suitePosition { fen: strings.TrimSpace(fields[0]) }
+
+    for _, field := range fields[1:] {
+        parts := strings.Fields(field)
+        if len(parts) != 2 || !strings.HasPrefix(parts[0], "D") {
+            return position, errors.New(fmt.Sprintf("expected a depth and count, e.g. D1 20: %v", strings.TrimSpace(field)))
+        }
+
+        depth, err := strconv.Atoi(parts[0][1:])
+        if err != nil || depth != len(position.nodes) + 1 {
+            return position, errors.New(fmt.Sprintf("expected D%v: %v", len(position.nodes) + 1, parts[0]))
+        }
+
+        nodes, err := strconv.ParseUint(parts[1], 10, 64)
+        if err != nil {
+            return position, errors.New(fmt.Sprintf("bad count: %v", parts[1]))
+        }
+
+        position.nodes = append(position.nodes, nodes)
+    }
+
+    return position, nil
 }
@@ -2,18 +2,31 @@ module gogm/playbot
 
 go 1.22.5
 
+replace gogm/bot => ../bot
+
 replace gogm/chess => ../chess
 
 replace gogm/chessgui => ../chessgui
 
 replace gogm/botv1 => ../botv1
 
+replace gogm/gamedb => ../gamedb
+
+replace gogm/uci => ../uci
+
+replace gogm/uciclient => ../uciclient
+
 require (
 	gogm/botv1 v0.0.0-00010101000000-000000000000
 	gogm/chessgui v0.0.0-00010101000000-000000000000
+	gogm/gamedb v0.0.0-00010101000000-000000000000
+	gogm/uciclient v0.0.0-00010101000000-000000000000
 )
 
 require (
 	github.com/veandco/go-sdl2 v0.4.40 // indirect
+	gogm/bot v0.0.0-00010101000000-000000000000 // indirect
 	gogm/chess v0.0.0-00010101000000-000000000000 // indirect
+	gogm/uci v0.0.0-00010101000000-000000000000 // indirect
+	golang.org/x/image v0.18.0 // indirect
 )
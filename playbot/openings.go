@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"gogm/chess"
+	"math/rand"
+	"strings"
+)
+
+// Balanced opening lines in UCI notation, used to give bot games some variety
+var balancedOpenings = []string{
+    "e2e4 e7e5 g1f3 b8c6 f1b5 a7a6",                 // Ruy Lopez
+    "e2e4 e7e5 g1f3 b8c6 f1c4 f8c5",                 // Italian Game
+    "e2e4 e7e5 g1f3 b8c6 d2d4 e5d4 f3d4 g8f6",       // Scotch Game
+    "e2e4 e7e5 g1f3 g8f6 f3e5 d7d6 e5f3 f6e4",       // Petrov Defence
+    "e2e4 c7c5 g1f3 d7d6 d2d4 c5d4 f3d4 g8f6",       // Open Sicilian
+    "e2e4 c7c5 c2c3 g8f6 e4e5 f6d5",                 // Alapin Sicilian
+    "e2e4 e7e6 d2d4 d7d5 b1c3 g8f6",                 // French Defence
+    "e2e4 c7c6 d2d4 d7d5 b1c3 d5e4 c3e4 c8f5",       // Caro-Kann Defence
+    "e2e4 d7d6 d2d4 g8f6 b1c3 g7g6",                 // Pirc Defence
+    "e2e4 d7d5 e4d5 d8d5 b1c3 d5a5",                 // Scandinavian Defence
+    "d2d4 d7d5 c2c4 e7e6 b1c3 g8f6",                 // Queen's Gambit Declined
+    "d2d4 d7d5 c2c4 d5c4 g1f3 g8f6 e2e3 e7e6",       // Queen's Gambit Accepted
+    "d2d4 d7d5 c2c4 c7c6 g1f3 g8f6",                 // Slav Defence
+    "d2d4 g8f6 c2c4 e7e6 b1c3 f8b4",                 // Nimzo-Indian Defence
+    "d2d4 g8f6 c2c4 g7g6 b1c3 f8g7 e2e4 d7d6",       // King's Indian Defence
+    "d2d4 g8f6 c2c4 g7g6 b1c3 d7d5",                 // Grunfeld Defence
+    "d2d4 d7d5 c1f4 g8f6 e2e3 e7e6",                 // London System
+    "d2d4 f7f5 g2g3 g8f6 f1g2 e7e6",                 // Dutch Defence
+    "c2c4 e7e5 b1c3 g8f6 g2g3 d7d5",                 // English Opening
+    "g1f3 d7d5 c2c4 e7e6 g2g3 g8f6",                 // Reti Opening
+}
+
+// Play a randomly chosen line from the suite of balanced openings
+func playRandomOpening(board *chess.Board, rng *rand.Rand) error {
+    line := balancedOpenings[rng.Intn(len(balancedOpenings))]
+
+    for _, notation := range strings.Fields(line) {
+        move, err := chess.MoveWithUciNotation(notation)
+        if err != nil {
+            return err
+        }
+
+        if _, err := board.MakeLegalMove(move); err != nil {
+            return errors.New(fmt.Sprintf("%v in opening %v", err, line))
+        }
+    }
+
+    return nil
+}
+
+// Play between 4 and 8 random legal moves
+func playRandomWalk(board *chess.Board, rng *rand.Rand) {
+    numMoves := 4 + rng.Intn(5)
+
+    for i := 0; i < numMoves; i++ {
+        moves := board.GetLegalMoves(false)
+        if len(moves) == 0 {
+            return
+        }
+
+        board.MakeMove(moves[rng.Intn(len(moves))])
+    }
+}
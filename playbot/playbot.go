@@ -1,18 +1,221 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"gogm/botv1"
 	"gogm/chess"
 	"gogm/chessgui"
+	"gogm/gamedb"
+	"gogm/uciclient"
+	"io"
+	"math/rand"
+	"os"
+	"time"
 )
 
 func main() {
-    bot := botv1.BotV1 {}
+    evalParamsPath := flag.String("eval", "", "JSON file of evaluation parameters, reloaded on SIGHUP or by pressing R")
+    selfPlay := flag.Bool("selfplay", false, "let the bot play both sides")
+    skill := flag.Int("skill", 0, "strength of the bot from 1 to 20 (0 for full strength)")
+    variety := flag.Float64("variety", 0, "let the bot choose at random between moves scoring within this many pawns of the best, e.g. 0.1, so that games vary")
+    opening := flag.String("opening", "none", "how to start the game: none (starting position), suite (a random balanced opening) or random (4-8 random moves)")
+    seed := flag.Int64("seed", 0, "seed for choosing the opening and the bot's random choices (0 for a random seed)")
+    trackResults := flag.Bool("profile", true, "record your results and rating against the bot in the config directory")
+    promotion := flag.String("promotion", "ask", "how to choose the piece to promote to: ask, queen (always promote to a queen) or smart (ask only if an underpromotion gives check or promoting to a queen stalemates), switched with P")
+    notation := flag.String("notation", "english", "language of the piece letters in moves shown: english, german, french, spanish or figurine")
+    analysis := flag.Bool("analysis", false, "open an analysis board, where you move for both sides while the bot evaluates, instead of playing")
+    pgnPath := flag.String("pgn", "", "PGN file whose first game is shown on the analysis board, which is written to standard output with your variations when closed")
+    showThinking := flag.Bool("thinking", false, "draw arrows for the moves the bot is considering while it thinks, switched with T")
+    hashPath := flag.String("hash", "", "file to load the bot's transposition table from when the analysis board opens, if it exists, and save it to when it closes, so analysis can be resumed")
+    dbPath := flag.String("db", "", "PGN file of games to show the moves played from each position of the analysis board in")
+    savePath := flag.String("save", "", "PGN file to add each game played to")
+    assets := flag.String("assets", "", "directory of images (pieces.png) to use instead of the built-in ones")
+    enginePath := flag.String("engine", "", "UCI engine executable, such as stockfish, to play the bot in your place (or in self-play, in place of white's bot) or to analyse with on the analysis board")
+    flag.Parse()
+
+    promotionMode, err := chessgui.PromotionModeWithName(*promotion)
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(2)
+    }
+    options := chessgui.Options { Promotion: promotionMode, AssetsPath: *assets, ShowThinking: *showThinking, PgnPath: *savePath }
+
+    if options.Notation, err = chess.NotationWithName(*notation); err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(2)
+    }
+
+    if *trackResults && !*selfPlay && *enginePath == "" {
+        if options.ProfilePath, err = chessgui.DefaultProfilePath(); err != nil {
+            fmt.Fprintf(os.Stderr, "results will not be recorded: %v\n", err)
+        }
+    }
+
+    botOptions := botv1.Options { SkillLevel: *skill, RandomMargin: *variety, EvalParamsPath: *evalParamsPath }
+
+    bot, err := botv1.New(botOptions)
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(2)
+    }
+
+    var engine *uciclient.Engine
+    if *enginePath != "" {
+        if engine, err = uciclient.Start(*enginePath); err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+        defer engine.Close()
+    }
+
+    // In self-play, each side gets its own bot so that search state is not shared between them
+    var opponent *botv1.BotV1
+    if *selfPlay {
+        if opponent, err = botv1.New(botOptions); err != nil {
+            panic(err)
+        }
+    }
+
+    if *evalParamsPath != "" {
+        if opponent != nil {
+            go reloadOnHangup(bot, opponent)
+        } else {
+            go reloadOnHangup(bot)
+        }
+    }
 
     board, err := chess.LoadFen(chess.StartingPositionFen)
     if err != nil {
         panic(err)
     }
 
-    chessgui.Run(board, nil, &bot)
+    if *seed == 0 {
+        *seed = time.Now().UnixNano()
+    }
+    rng := rand.New(rand.NewSource(*seed))
+
+    switch *opening {
+    case "none":
+
+    case "suite":
+        if err := playRandomOpening(board, rng); err != nil {
+            panic(err)
+        }
+
+    case "random":
+        playRandomWalk(board, rng)
+
+    default:
+        fmt.Fprintf(os.Stderr, "unknown opening: %v\n", *opening)
+        os.Exit(2)
+    }
+
+    if *opening != "none" {
+        fmt.Printf("Starting from %v (seed %v)\n", board.Fen(), *seed)
+    }
+
+    // The bots are seeded after the opening is chosen, so that each seed still gives the same opening
+    bot.SetRandomSeed(rng.Int63())
+    if opponent != nil {
+        opponent.SetRandomSeed(rng.Int63())
+    }
+
+    if *analysis {
+        game, err := analysisGame(board, *pgnPath)
+        if err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+
+        if *dbPath != "" {
+            if options.Explorer, err = loadDatabase(*dbPath); err != nil {
+                fmt.Fprintln(os.Stderr, err)
+                os.Exit(1)
+            }
+        }
+
+        if *hashPath != "" {
+            if err := bot.LoadHash(*hashPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+                fmt.Fprintln(os.Stderr, err)
+                os.Exit(1)
+            }
+        }
+
+        var analysisBot chess.Bot = bot
+        if engine != nil {
+            analysisBot = engine
+        }
+
+        if err := chessgui.RunAnalysis(game, analysisBot, options); err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+
+        if *hashPath != "" {
+            if err := bot.SaveHash(*hashPath); err != nil {
+                fmt.Fprintln(os.Stderr, err)
+            }
+        }
+
+        if err := game.CompleteTags(); err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+
+        fmt.Print(game.Pgn())
+        return
+    }
+
+    switch {
+    case engine != nil:
+        chessgui.RunWithOptions(board, engine, bot, options)
+    case opponent != nil:
+        chessgui.RunWithOptions(board, opponent, bot, options)
+    default:
+        chessgui.RunWithOptions(board, nil, bot, options)
+    }
+}
+
+// Returns the first game of the PGN file, or a game starting from the position on the board if no
+// file is given
+func analysisGame(board *chess.Board, pgnPath string) (*chess.Game, error) {
+    if pgnPath == "" {
+        return chess.NewGame(board), nil
+    }
+
+    file, err := os.Open(pgnPath)
+    if err != nil {
+        return nil, err
+    }
+    defer file.Close()
+
+    game, err := chess.NewPgnReader(file).Read()
+    if err == io.EOF {
+        return nil, errors.New(fmt.Sprintf("%v: no games", pgnPath))
+    }
+    if err != nil {
+        return nil, errors.New(fmt.Sprintf("%v: %v", pgnPath, err))
+    }
+
+    return game, nil
+}
+
+// Index the games of the PGN file for the explorer, reporting games that cannot be read
+func loadDatabase(path string) (*gamedb.Database, error) {
+    file, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer file.Close()
+
+    db := gamedb.New()
+    added, err := db.AddPgn(file)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "%v: skipped games: %v\n", path, err)
+    }
+
+    fmt.Fprintf(os.Stderr, "indexed %v games from %v\n", added, path)
+    return db, nil
 }
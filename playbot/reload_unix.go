@@ -0,0 +1,27 @@
+//go:build !windows
+
+package main
+
+import (
+	"gogm/botv1"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Reload the bots' evaluation parameters whenever the process receives SIGHUP
+func reloadOnHangup(bots ...*botv1.BotV1) {
+    hangup := make(chan os.Signal, 1)
+    signal.Notify(hangup, syscall.SIGHUP)
+
+    for range hangup {
+        for _, bot := range bots {
+            if err := bot.ReloadEvalParams(); err != nil {
+                log.Printf("failed to reload evaluation parameters: %v", err)
+            } else {
+                log.Printf("reloaded evaluation parameters")
+            }
+        }
+    }
+}
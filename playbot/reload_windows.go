@@ -0,0 +1,6 @@
+package main
+
+import "gogm/botv1"
+
+// There is no SIGHUP on Windows, so evaluation parameters can only be reloaded from the GUI
+func reloadOnHangup(bots ...*botv1.BotV1) {}
@@ -0,0 +1,23 @@
+module gogm/server
+
+go 1.22.5
+
+replace gogm/chess => ../chess
+
+replace gogm/botv1 => ../botv1
+
+replace gogm/jsonapi => ../jsonapi
+
+require (
+	github.com/gorilla/websocket v1.5.3
+	github.com/stretchr/testify v1.9.0
+	gogm/botv1 v0.0.0-00010101000000-000000000000
+	gogm/chess v0.0.0-00010101000000-000000000000
+	gogm/jsonapi v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
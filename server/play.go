@@ -0,0 +1,736 @@
+package main
+
+// Games played over WebSocket between two connected players, or a player and the bot, with the
+// moves checked, the clocks kept and the state of the game sent to everyone connected to it after
+// each change, as the base of a frontend for playing online
+//
+// Clients connect to /play?game=<id>, which creates the game if it does not exist (or a new game
+// with a random id if no id is given). The other parameters set up the game when it is created,
+// except side, which chooses the seat to take:
+//
+//   side       white or black: the side to play if its seat is free, otherwise the first free seat
+//              is taken, and once both are taken the client watches
+//   bot        white or black: the side played by the bot
+//   time       seconds on each clock at the start, or 0 (the default) for no clocks
+//   increment  seconds added to a clock after each move
+//   fen        starting position
+//
+// Clients send {"type": "move", "move": "e2e4"}, with the move in UCI notation or SAN, and
+// {"type": "resign"}, and receive {"type": "state", ...} after every change to the game, and
+// {"type": "error", "message": "..."} when a message is refused. The clocks run once both seats are
+// taken, and keep running if a player disconnects. The bot's clock starts when one of the server's
+// game bots is free to think for it, so a game does not lose time waiting for other games. A free
+// seat can be taken by anyone: there are no accounts
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"gogm/chess"
+	"gogm/jsonapi"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+    // Time allowed to write a message to a client, and for a client to answer a ping
+    writeWait  = 10 * time.Second
+    pongWait   = 60 * time.Second
+    pingPeriod = pongWait * 9 / 10
+
+    // Size of the largest message read from a client (bytes)
+    maxMessageSize = 4096
+
+    // Number of messages kept for a client that is slow to read them before it is disconnected
+    sendBufferSize = 16
+
+    // Time the bot spends on each move in games without clocks
+    botMoveTime = time.Second
+)
+
+// Seats of a game, indexed by whether the side is black
+const (
+    white = 0
+    black = 1
+)
+
+var sideNames = [2]string{"white", "black"}
+
+func sideIndex(isBlack bool) int {
+    if isBlack {
+        return black
+    }
+    return white
+}
+
+// Returns the index of the side with the name, -1 for no side, or an error
+func parseSide(name string) (int, error) {
+    switch name {
+    case "":
+        return -1, nil
+    case "white":
+        return white, nil
+    case "black":
+        return black, nil
+    default:
+        return -1, badRequest("side must be white or black: %v", name)
+    }
+}
+
+// A game hosted by the server
+type game struct {
+    id     string
+    server *server
+
+    // Held while the game is read or changed, by the goroutines of the clients and the bot
+    lock sync.Mutex
+
+    board          *chess.Board
+    fullmoveNumber int
+    moves          []jsonapi.Move
+
+    // Clients playing each side, or nil for free seats, every client connected to the game, and the
+    // number of clients about to join, which keep the game from being removed in the meantime
+    seats   [2]*client
+    clients map[*client]bool
+    joining int
+
+    // Side played by the bot, or -1
+    botSide int
+
+    // Whether both seats have been taken, after which the clocks run
+    started bool
+
+    // Time left on each clock and the time added after each move, if the game has clocks, whether the
+    // side to move's clock is running, and when it started
+    hasClocks    bool
+    remaining    [2]time.Duration
+    increment    time.Duration
+    clockRunning bool
+    turnStart    time.Time
+    flagTimer    *time.Timer
+
+    // Result once the game is over, and why it ended
+    result chess.Result
+    reason string
+
+    // Cancelled when the game ends or is abandoned, to stop the bot thinking
+    ctx    context.Context
+    cancel context.CancelFunc
+}
+
+// A connection to a game
+type client struct {
+    conn *websocket.Conn
+
+    // Messages waiting to be written, closed when the client leaves
+    send chan []byte
+
+    // Side the client plays, or -1 if it watches
+    seat int
+}
+
+// Message from a client
+type clientMessage struct {
+    Type string `json:"type"`
+    Move string `json:"move"`
+}
+
+// A player as described in the state of a game
+type playerJSON struct {
+    Connected bool `json:"connected"`
+    Bot       bool `json:"bot"`
+
+    // Milliseconds left on the player's clock, if the game has clocks
+    Clock *int64 `json:"clock,omitempty"`
+}
+
+// State of a game, sent to each client after every change
+type stateJSON struct {
+    Type string `json:"type"`
+    Game string `json:"game"`
+
+    // Side the client receiving the state plays, or "spectator"
+    You string `json:"you"`
+
+    Position jsonapi.Position `json:"position"`
+    Moves    []jsonapi.Move   `json:"moves"`
+    White    playerJSON       `json:"white"`
+    Black    playerJSON       `json:"black"`
+    Started  bool             `json:"started"`
+
+    // Result as in PGN, "*" while the game is going on, and why it ended, e.g. "checkmate",
+    // "resignation" or "time forfeit"
+    Result string `json:"result"`
+    Reason string `json:"reason"`
+}
+
+type errorJSON struct {
+    Type    string `json:"type"`
+    Message string `json:"message"`
+}
+
+// Allows browsers on the pages of the origin to connect, besides pages served by the same host
+func newUpgrader(allowedOrigin string) *websocket.Upgrader {
+    upgrader := &websocket.Upgrader{}
+
+    if allowedOrigin == "*" {
+        upgrader.CheckOrigin = func(r *http.Request) bool { return true }
+    } else if allowedOrigin != "" {
+        upgrader.CheckOrigin = func(r *http.Request) bool {
+            origin := r.Header.Get("Origin")
+            return origin == "" || origin == allowedOrigin || origin == "http://" + r.Host || origin == "https://" + r.Host
+        }
+    }
+
+    return upgrader
+}
+
+// Connect the client to the game given by the request, creating it if it does not exist, and relay
+// its messages until it disconnects
+func (server *server) play(w http.ResponseWriter, r *http.Request) {
+    query := r.URL.Query()
+
+    side, err := parseSide(query.Get("side"))
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+
+    game, err := server.findOrCreateGame(query.Get("game"), r)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+
+    conn, err := server.upgrader.Upgrade(w, r, nil)
+    if err != nil {
+        // The upgrader has answered the request
+        game.cancelJoin()
+        return
+    }
+
+    client := &client { conn: conn, send: make(chan []byte, sendBufferSize), seat: -1 }
+    go client.writeMessages()
+
+    game.join(client, side)
+    defer game.leave(client)
+
+    client.readMessages(game)
+}
+
+// Returns the game with the id, or if there is none, a new game set up by the parameters of the
+// request. A game with a random id is created if the id is empty
+func (server *server) findOrCreateGame(id string, r *http.Request) (*game, error) {
+    server.gamesLock.Lock()
+    defer server.gamesLock.Unlock()
+
+    if game, ok := server.games[id]; ok {
+        game.lock.Lock()
+        defer game.lock.Unlock()
+
+        game.joining++
+        return game, nil
+    }
+
+    if id == "" {
+        id = randomID()
+    }
+
+    game, err := server.newGame(id, r)
+    if err != nil {
+        return nil, err
+    }
+
+    game.joining++
+    server.games[id] = game
+    return game, nil
+}
+
+func randomID() string {
+    bytes := make([]byte, 8)
+    rand.Read(bytes)
+    return hex.EncodeToString(bytes)
+}
+
+// Returns a game set up by the parameters of the request
+func (server *server) newGame(id string, r *http.Request) (*game, error) {
+    query := r.URL.Query()
+
+    board, fullmoveNumber, err := position { Fen: query.Get("fen") }.load()
+    if err != nil {
+        return nil, err
+    }
+
+    botSide, err := parseSide(query.Get("bot"))
+    if err != nil {
+        return nil, err
+    }
+
+    seconds := func(name string) (time.Duration, error) {
+        if query.Get(name) == "" {
+            return 0, nil
+        }
+
+        value, err := strconv.ParseFloat(query.Get(name), 64)
+        if err != nil || value < 0 {
+            return 0, badRequest("%v must be a number of seconds: %v", name, query.Get(name))
+        }
+
+        return time.Duration(value * float64(time.Second)), nil
+    }
+
+    startingTime, err := seconds("time")
+    if err != nil {
+        return nil, err
+    }
+
+    increment, err := seconds("increment")
+    if err != nil {
+        return nil, err
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+
+    game := &game {
+        id:             id,
+        server:         server,
+        board:          board,
+        fullmoveNumber: fullmoveNumber,
+        moves:          []jsonapi.Move{},
+        clients:        map[*client]bool{},
+        botSide:        botSide,
+        hasClocks:      startingTime > 0,
+        remaining:      [2]time.Duration{startingTime, startingTime},
+        increment:      increment,
+        ctx:            ctx,
+        cancel:         cancel,
+    }
+
+    if outcome := board.Outcome(); outcome.IsOver() {
+        game.result, game.reason = outcome.Result, outcome.Termination.String()
+    }
+
+    return game, nil
+}
+
+// Seat the client on the side if it is free, or on the first free seat, or let it watch, and start
+// the game once both seats are taken
+func (game *game) join(client *client, side int) {
+    game.lock.Lock()
+    defer game.lock.Unlock()
+
+    game.joining--
+    game.clients[client] = true
+
+    if side < 0 || !game.isFree(side) {
+        side = -1
+        for _, seat := range []int{white, black} {
+            if game.isFree(seat) {
+                side = seat
+                break
+            }
+        }
+    }
+
+    if side >= 0 {
+        client.seat = side
+        game.seats[side] = client
+    }
+
+    if !game.started && game.isTaken(white) && game.isTaken(black) {
+        game.started = true
+        game.startTurn()
+    }
+
+    game.broadcast()
+}
+
+func (game *game) isFree(side int) bool {
+    return game.seats[side] == nil && game.botSide != side
+}
+
+func (game *game) isTaken(side int) bool {
+    return !game.isFree(side)
+}
+
+// Remove the client from the game, freeing its seat
+func (game *game) leave(client *client) {
+    game.server.gamesLock.Lock()
+    defer game.server.gamesLock.Unlock()
+
+    game.lock.Lock()
+    defer game.lock.Unlock()
+
+    delete(game.clients, client)
+    close(client.send)
+
+    if client.seat >= 0 {
+        game.seats[client.seat] = nil
+    }
+
+    if !game.removeIfAbandoned() {
+        game.broadcast()
+    }
+}
+
+// Forget a client that was about to join but could not
+func (game *game) cancelJoin() {
+    game.server.gamesLock.Lock()
+    defer game.server.gamesLock.Unlock()
+
+    game.lock.Lock()
+    defer game.lock.Unlock()
+
+    game.joining--
+    game.removeIfAbandoned()
+}
+
+// Remove the game from the server if no client is connected to it or about to join, stopping the
+// clock and the bot, and return whether it was removed
+func (game *game) removeIfAbandoned() bool {
+    if len(game.clients) > 0 || game.joining > 0 {
+        return false
+    }
+
+    game.stopClock()
+    game.cancel()
+    delete(game.server.games, game.id)
+    return true
+}
+
+// Carry out the message from the client, answering it with an error if it cannot be
+func (game *game) handle(client *client, message clientMessage) {
+    game.lock.Lock()
+    defer game.lock.Unlock()
+
+    var err error
+
+    switch message.Type {
+    case "move":
+        err = game.playerMove(client, message.Move)
+    case "resign":
+        err = game.resign(client)
+    default:
+        err = badRequest("unknown message type: %v", message.Type)
+    }
+
+    if err != nil {
+        client.sendJSON(errorJSON { Type: "error", Message: err.Error() })
+        return
+    }
+
+    game.broadcast()
+}
+
+func (game *game) playerMove(client *client, notation string) error {
+    if err := game.checkCanMove(client); err != nil {
+        return err
+    }
+
+    move, err := game.board.ParseMove(notation)
+    if err != nil {
+        return badRequest("%v", err)
+    }
+
+    game.makeMove(move)
+    return nil
+}
+
+// Returns an error if it is not the client's turn
+func (game *game) checkCanMove(client *client) error {
+    switch {
+    case game.result != chess.NoResult:
+        return badRequest("the game is over")
+    case client.seat < 0:
+        return badRequest("you are watching")
+    case !game.started:
+        return badRequest("waiting for an opponent")
+    case client.seat != sideIndex(game.board.IsBlackToMove()):
+        return badRequest("not your turn")
+    default:
+        return nil
+    }
+}
+
+func (game *game) resign(client *client) error {
+    if game.result != chess.NoResult {
+        return badRequest("the game is over")
+    }
+    if client.seat < 0 {
+        return badRequest("you are watching")
+    }
+
+    result := chess.WhiteWins
+    if client.seat == white {
+        result = chess.BlackWins
+    }
+
+    game.end(result, "resignation")
+    return nil
+}
+
+// Make the legal move, charge the time taken to the mover's clock, and end the game or start the
+// other side's turn
+func (game *game) makeMove(move chess.Move) {
+    mover := sideIndex(game.board.IsBlackToMove())
+
+    if game.hasClocks {
+        if game.clockRunning {
+            game.remaining[mover] -= time.Since(game.turnStart)
+        }
+        game.stopClock()
+
+        if game.remaining[mover] <= 0 {
+            game.timeForfeit(mover)
+            return
+        }
+
+        game.remaining[mover] += game.increment
+    }
+
+    game.moves = append(game.moves, jsonapi.NewMove(game.board, move))
+    game.board.MakeMove(move)
+    if mover == black {
+        game.fullmoveNumber++
+    }
+
+    if outcome := game.board.Outcome(); outcome.IsOver() {
+        game.end(outcome.Result, outcome.Termination.String())
+        return
+    }
+
+    game.startTurn()
+}
+
+// Start the side to move's clock, or the bot thinking if it is the bot's turn, which starts the
+// bot's clock once it has a bot to think with
+func (game *game) startTurn() {
+    if sideIndex(game.board.IsBlackToMove()) == game.botSide {
+        game.startBotMove()
+    } else {
+        game.startClock()
+    }
+}
+
+// Start the side to move's clock, if the game has clocks
+func (game *game) startClock() {
+    if !game.hasClocks {
+        return
+    }
+
+    side := sideIndex(game.board.IsBlackToMove())
+    ply := len(game.moves)
+    game.clockRunning = true
+    game.turnStart = time.Now()
+
+    game.flagTimer = time.AfterFunc(game.remaining[side], func() {
+        game.lock.Lock()
+        defer game.lock.Unlock()
+
+        // The timer may fire just as the move is made
+        if game.result == chess.NoResult && len(game.moves) == ply {
+            game.remaining[side] = 0
+            game.timeForfeit(side)
+            game.broadcast()
+        }
+    })
+}
+
+func (game *game) stopClock() {
+    game.clockRunning = false
+
+    if game.flagTimer != nil {
+        game.flagTimer.Stop()
+        game.flagTimer = nil
+    }
+}
+
+// End the game with the side losing on time, or drawn if its opponent has too little material to
+// checkmate: a lone king, or a king and a single bishop or knight
+func (game *game) timeForfeit(side int) {
+    opponentIsBlack := side == white
+
+    minorPieces := game.board.PiecesBB(chess.Bishop, opponentIsBlack).PopCount() + game.board.PiecesBB(chess.Knight, opponentIsBlack).PopCount()
+    others := game.board.PiecesBB(chess.Pawn, opponentIsBlack) | game.board.PiecesBB(chess.Rook, opponentIsBlack) | game.board.PiecesBB(chess.Queen, opponentIsBlack)
+
+    switch {
+    case others == chess.EmptyBitboard && minorPieces <= 1:
+        game.end(chess.Draw, "time forfeit")
+    case side == white:
+        game.end(chess.BlackWins, "time forfeit")
+    default:
+        game.end(chess.WhiteWins, "time forfeit")
+    }
+}
+
+func (game *game) end(result chess.Result, reason string) {
+    game.result, game.reason = result, reason
+    game.stopClock()
+    game.cancel()
+}
+
+// Let the bot choose a move in the background and play it, searching within the time on the clocks
+// if there are any. The bot's clock starts once one of the game bots is free, and the search is
+// abandoned if the game ends first
+func (game *game) startBotMove() {
+    ply := len(game.moves)
+
+    go func() {
+        bot := takeBot(game.ctx, game.server.gameBots)
+        if bot == nil {
+            return
+        }
+
+        game.lock.Lock()
+        if game.result != chess.NoResult || len(game.moves) != ply || game.ctx.Err() != nil {
+            game.lock.Unlock()
+            game.server.gameBots <- bot
+            return
+        }
+
+        board := game.board.Copy()
+        limits := chess.SearchLimits { MoveTime: botMoveTime }
+        if game.hasClocks {
+            game.startClock()
+            game.broadcast()
+
+            limits = chess.SearchLimits {
+                WhiteTime:      game.remaining[white],
+                BlackTime:      game.remaining[black],
+                WhiteIncrement: game.increment,
+                BlackIncrement: game.increment,
+            }
+        }
+        game.lock.Unlock()
+
+        move, _ := bot.ThinkContext(game.ctx, &board, limits)
+        game.server.gameBots <- bot
+
+        game.lock.Lock()
+        defer game.lock.Unlock()
+
+        if game.result != chess.NoResult || len(game.moves) != ply || game.ctx.Err() != nil {
+            return
+        }
+
+        game.makeMove(move)
+        game.broadcast()
+    }()
+}
+
+// Send the state of the game to every client
+func (game *game) broadcast() {
+    for client := range game.clients {
+        client.sendJSON(game.state(client))
+    }
+}
+
+// Returns the state of the game as seen by the client
+func (game *game) state(client *client) stateJSON {
+    state := stateJSON {
+        Type:     "state",
+        Game:     game.id,
+        You:      "spectator",
+        Position: jsonapi.NewPosition(game.board, game.fullmoveNumber),
+        Moves:    game.moves,
+        Started:  game.started,
+        Result:   game.result.String(),
+        Reason:   game.reason,
+    }
+
+    if client.seat >= 0 {
+        state.You = sideNames[client.seat]
+    }
+
+    players := [2]*playerJSON{&state.White, &state.Black}
+    for side, player := range players {
+        player.Connected = game.seats[side] != nil
+        player.Bot = game.botSide == side
+
+        if game.hasClocks {
+            remaining := game.remaining[side]
+            if game.clockRunning && side == sideIndex(game.board.IsBlackToMove()) {
+                remaining -= time.Since(game.turnStart)
+            }
+
+            milliseconds := max(remaining, 0).Milliseconds()
+            player.Clock = &milliseconds
+        }
+    }
+
+    return state
+}
+
+// Queue the message to be written to the client, disconnecting it if it has fallen too far behind
+func (client *client) sendJSON(value any) {
+    message, err := json.Marshal(value)
+    if err != nil {
+        log.Printf("failed to encode message: %v", err)
+        return
+    }
+
+    select {
+    case client.send <- message:
+    default:
+        client.conn.Close()
+    }
+}
+
+// Read messages from the client and carry them out until it disconnects
+func (client *client) readMessages(game *game) {
+    client.conn.SetReadLimit(maxMessageSize)
+    client.conn.SetReadDeadline(time.Now().Add(pongWait))
+    client.conn.SetPongHandler(func(string) error {
+        return client.conn.SetReadDeadline(time.Now().Add(pongWait))
+    })
+
+    for {
+        var message clientMessage
+        if err := client.conn.ReadJSON(&message); err != nil {
+            if _, ok := err.(*json.SyntaxError); ok {
+                client.sendJSON(errorJSON { Type: "error", Message: fmt.Sprintf("bad JSON: %v", err) })
+                continue
+            }
+            return
+        }
+
+        game.handle(client, message)
+    }
+}
+
+// Write the messages queued for the client until it leaves, pinging it to check it is still there
+func (client *client) writeMessages() {
+    ticker := time.NewTicker(pingPeriod)
+    defer ticker.Stop()
+    defer client.conn.Close()
+
+    for {
+        select {
+        case message, ok := <-client.send:
+            client.conn.SetWriteDeadline(time.Now().Add(writeWait))
+
+            if !ok {
+                client.conn.WriteMessage(websocket.CloseMessage, nil)
+                return
+            }
+
+            if err := client.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+                return
+            }
+
+        case <-ticker.C:
+            client.conn.SetWriteDeadline(time.Now().Add(writeWait))
+            if err := client.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+                return
+            }
+        }
+    }
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A message from the server: a state, or an error with its message
+type playMessage struct {
+	stateJSON
+	Message string `json:"message"`
+}
+
+// Connect to /play with the query, closing the connection when the test ends
+func dial(t *testing.T, httpServer *httptest.Server, query string) *websocket.Conn {
+	address := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/play?" + query
+
+	conn, _, err := websocket.DefaultDialer.Dial(address, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func send(t *testing.T, conn *websocket.Conn, message clientMessage) {
+	assert.Nil(t, conn.WriteJSON(message))
+}
+
+// Read messages until one satisfies `done`, failing the test if none does within a few seconds
+func readUntil(t *testing.T, conn *websocket.Conn, done func(message playMessage) bool) playMessage {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	for {
+		var message playMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			t.Fatal(err)
+		}
+
+		if done(message) {
+			return message
+		}
+	}
+}
+
+func readState(t *testing.T, conn *websocket.Conn) playMessage {
+	return readUntil(t, conn, func(message playMessage) bool { return message.Type == "state" })
+}
+
+func readError(t *testing.T, conn *websocket.Conn) string {
+	return readUntil(t, conn, func(message playMessage) bool { return message.Type == "error" }).Message
+}
+
+// Read states until the game has the number of moves
+func readMoves(t *testing.T, conn *websocket.Conn, moves int) playMessage {
+	return readUntil(t, conn, func(message playMessage) bool { return message.Type == "state" && len(message.Moves) == moves })
+}
+
+func readResult(t *testing.T, conn *websocket.Conn) playMessage {
+	return readUntil(t, conn, func(message playMessage) bool { return message.Type == "state" && message.Result != "*" })
+}
+
+func TestPlaySeats(t *testing.T) {
+	assert := assert.New(t)
+	_, httpServer := startTestServer(t)
+
+	first := dial(t, httpServer, "game=seats&side=black")
+	state := readState(t, first)
+	assert.Equal("black", state.You)
+	assert.Equal("seats", state.Game)
+	assert.True(state.Black.Connected)
+	assert.False(state.Started)
+
+	// The side asked for is taken, so the other is given
+	second := dial(t, httpServer, "game=seats&side=black")
+	state = readState(t, second)
+	assert.Equal("white", state.You)
+	assert.True(state.Started)
+
+	// Once both are taken, clients watch
+	spectator := dial(t, httpServer, "game=seats&side=white")
+	assert.Equal("spectator", readState(t, spectator).You)
+
+	// The first client sees its opponent arrive
+	state = readUntil(t, first, func(message playMessage) bool { return message.White.Connected })
+	assert.Equal("black", state.You)
+}
+
+func TestPlayBadParameters(t *testing.T) {
+	_, httpServer := startTestServer(t)
+	address := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/play?"
+
+	for _, query := range []string{"side=red", "bot=green", "time=-1", "increment=soon", "fen=nonsense"} {
+		_, response, err := websocket.DefaultDialer.Dial(address + query, nil)
+		assert.NotNil(t, err, query)
+		assert.Equal(t, 400, response.StatusCode, query)
+	}
+}
+
+func TestPlayMoves(t *testing.T) {
+	assert := assert.New(t)
+	_, httpServer := startTestServer(t)
+
+	white := dial(t, httpServer, "game=moves&side=white")
+	readState(t, white)
+
+	send(t, white, clientMessage{Type: "move", Move: "e4"})
+	assert.Equal("waiting for an opponent", readError(t, white))
+
+	black := dial(t, httpServer, "game=moves&side=black")
+	readState(t, black)
+	spectator := dial(t, httpServer, "game=moves")
+	readState(t, spectator)
+
+	// Moves out of turn are refused
+	send(t, black, clientMessage{Type: "move", Move: "e5"})
+	assert.Equal("not your turn", readError(t, black))
+	send(t, spectator, clientMessage{Type: "move", Move: "e4"})
+	assert.Equal("you are watching", readError(t, spectator))
+
+	send(t, white, clientMessage{Type: "move", Move: "e2e5"})
+	assert.NotEmpty(readError(t, white))
+
+	// Moves are accepted in UCI notation or SAN, and every client sees them
+	send(t, white, clientMessage{Type: "move", Move: "e2e4"})
+	state := readMoves(t, black, 1)
+	assert.Equal("e4", state.Moves[0].San)
+	assert.Equal("black", state.Position.SideToMove)
+
+	send(t, black, clientMessage{Type: "move", Move: "e5"})
+	state = readMoves(t, spectator, 2)
+	assert.Equal("e7e5", state.Moves[1].Uci)
+
+	send(t, white, clientMessage{Type: "castle"})
+	assert.Equal("unknown message type: castle", readError(t, white))
+}
+
+func TestPlayResign(t *testing.T) {
+	assert := assert.New(t)
+	_, httpServer := startTestServer(t)
+
+	white := dial(t, httpServer, "game=resign&side=white")
+	black := dial(t, httpServer, "game=resign&side=black")
+	spectator := dial(t, httpServer, "game=resign")
+	readState(t, spectator)
+
+	send(t, spectator, clientMessage{Type: "resign"})
+	assert.Equal("you are watching", readError(t, spectator))
+
+	send(t, black, clientMessage{Type: "resign"})
+	state := readResult(t, white)
+	assert.Equal("1-0", state.Result)
+	assert.Equal("resignation", state.Reason)
+
+	send(t, white, clientMessage{Type: "move", Move: "e4"})
+	assert.Equal("the game is over", readError(t, white))
+	send(t, white, clientMessage{Type: "resign"})
+	assert.Equal("the game is over", readError(t, white))
+}
+
+func TestPlayTimeForfeit(t *testing.T) {
+	_, httpServer := startTestServer(t)
+
+	for _, test := range []struct {
+		description string
+		fen         string
+		result      string
+	}{
+		{"black can checkmate", "", "0-1"},
+		{"black has a lone king", "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", "1/2-1/2"},
+		{"black has a king and a knight", "4k3/4n3/8/8/8/8/4P3/4K3 w - - 0 1", "1/2-1/2"},
+		{"black has a king and two knights", "3nk3/4n3/8/8/8/8/4P3/4K3 w - - 0 1", "0-1"},
+	} {
+		query := "time=0.05&fen=" + url.QueryEscape(test.fen) + "&game=" + url.QueryEscape(test.description)
+
+		white := dial(t, httpServer, query + "&side=white")
+		dial(t, httpServer, query + "&side=black")
+
+		// White's clock runs out without a move being made
+		state := readResult(t, white)
+		assert.Equal(t, test.result, state.Result, test.description)
+		assert.Equal(t, "time forfeit", state.Reason, test.description)
+		assert.Equal(t, int64(0), *state.White.Clock, test.description)
+	}
+}
+
+func TestPlayGameRemovedWhenClientsLeave(t *testing.T) {
+	server, httpServer := startTestServer(t)
+
+	games := func() int {
+		server.gamesLock.Lock()
+		defer server.gamesLock.Unlock()
+
+		return len(server.games)
+	}
+
+	white := dial(t, httpServer, "game=leave&side=white")
+	black := dial(t, httpServer, "game=leave&side=black")
+	readState(t, black)
+	assert.Equal(t, 1, games())
+
+	// The seat is freed, and the game kept for the client still connected
+	white.Close()
+	state := readUntil(t, black, func(message playMessage) bool { return !message.White.Connected })
+	assert.True(t, state.Started)
+	assert.Equal(t, 1, games())
+
+	black.Close()
+	assert.Eventually(t, func() bool { return games() == 0 }, 5 * time.Second, time.Millisecond)
+}
+
+func TestPlayBot(t *testing.T) {
+	assert := assert.New(t)
+	server, httpServer := startTestServer(t)
+
+	// The bot's seat counts as taken, so the game starts with the player
+	white := dial(t, httpServer, "game=bot&bot=black")
+	state := readState(t, white)
+	assert.Equal("white", state.You)
+	assert.True(state.Black.Bot)
+	assert.True(state.Started)
+
+	send(t, white, clientMessage{Type: "move", Move: "e4"})
+	state = readMoves(t, white, 2)
+	assert.Equal("white", state.Position.SideToMove)
+	assert.Equal("*", state.Result)
+
+	// The bot was given back once it had moved
+	assert.Eventually(func() bool { return len(server.gameBots) == 1 }, 5 * time.Second, time.Millisecond)
+}
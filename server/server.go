@@ -0,0 +1,411 @@
+package main
+
+// Serves gogm over HTTP with a JSON API, so that frontends not written in Go, such as web and mobile
+// apps, can use it as a backend. Each endpoint takes a POST request with a JSON object holding the
+// position as a FEN (the starting position if left out), and answers with a JSON object, or with
+// {"error": "..."} and a 4xx status if the request cannot be carried out
+//
+//   /moves     {fen}                         legal moves, check and outcome
+//   /move      {fen, move}                   the position after the move, in UCI notation or SAN
+//   /bestmove  {fen, depth, nodes, movetime} the bot's move, score and principal variation, searched
+//                                            within the limits (movetime in milliseconds)
+//   /eval      {fen}                         the bot's static evaluation, term by term
+//   /perft     {fen, depth, divide}          number of positions reachable to the depth, counted
+//                                            within a time limit
+//
+// Games can also be played over WebSocket at /play (see play.go)
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"gogm/botv1"
+	"gogm/chess"
+	"gogm/jsonapi"
+	"log"
+	"net/http"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Limits on the work one request can ask for
+const (
+    defaultMoveTime = time.Second
+    maxMoveTime     = 30 * time.Second
+    maxPerftDepth   = 5
+    maxPerftTime    = 10 * time.Second
+
+    // Size of the largest request body read (bytes)
+    maxRequestSize = 1 << 16
+)
+
+func main() {
+    address := flag.String("address", "localhost:8080", "address to listen on")
+    bots := flag.Int("bots", runtime.NumCPU(), "number of /bestmove searches and /perft counts that can run at once, each search with its own transposition table; further requests wait. Games at /play use the bots of -gamebots instead")
+    gameBots := flag.Int("gamebots", runtime.NumCPU(), "number of games at /play in which the bot can think at once, each with its own transposition table; the bot's clock in further games waits with it")
+    hashSizeMB := flag.Int("hash", 16, "size of each bot's transposition table in megabytes")
+    origin := flag.String("origin", "", "origin of web pages besides this server's own allowed to play at /play, e.g. https://example.com, or * for any")
+    flag.Parse()
+
+    if *bots < 1 || *gameBots < 1 || *hashSizeMB < 1 {
+        fmt.Fprintln(os.Stderr, "-bots, -gamebots and -hash must be at least 1")
+        os.Exit(2)
+    }
+
+    server, err := newServer(*bots, *gameBots, *hashSizeMB, *origin)
+    if err != nil {
+        log.Fatal(err)
+    }
+
+    log.Printf("listening on %v", *address)
+    log.Fatal(http.ListenAndServe(*address, server.handler()))
+}
+
+type server struct {
+    // Bots not searching. A /bestmove request takes one for the length of its search, and a /perft
+    // request for the length of its count, so that no more work runs at once than there are bots
+    bots chan *botv1.BotV1
+
+    // Bots not thinking in a game, kept apart from bots so that requests for analysis cannot make a
+    // game wait. The bot of a game takes one for each of its moves
+    gameBots chan *botv1.BotV1
+
+    // Bot whose evaluation is traced, which keeps no state while doing so
+    evaluator *botv1.BotV1
+
+    // Time a /perft count may take before it is abandoned, maxPerftTime unless shortened by tests
+    perftTime time.Duration
+
+    // Games being played, by id
+    gamesLock sync.Mutex
+    games     map[string]*game
+
+    upgrader *websocket.Upgrader
+}
+
+func newServer(bots int, gameBots int, hashSizeMB int, allowedOrigin string) (*server, error) {
+    server := &server {
+        games:     map[string]*game{},
+        upgrader:  newUpgrader(allowedOrigin),
+        perftTime: maxPerftTime,
+    }
+
+    var err error
+    if server.bots, err = newBotPool(bots, hashSizeMB); err != nil {
+        return nil, err
+    }
+    if server.gameBots, err = newBotPool(gameBots, hashSizeMB); err != nil {
+        return nil, err
+    }
+
+    server.evaluator = &botv1.BotV1{}
+    return server, nil
+}
+
+// Returns a channel holding `count` new bots, from which a bot is taken while it searches
+func newBotPool(count int, hashSizeMB int) (chan *botv1.BotV1, error) {
+    pool := make(chan *botv1.BotV1, count)
+
+    for i := 0; i < count; i++ {
+        bot, err := botv1.New(botv1.Options { HashSizeMB: hashSizeMB })
+        if err != nil {
+            return nil, err
+        }
+        pool <- bot
+    }
+
+    return pool, nil
+}
+
+func (server *server) handler() http.Handler {
+    mux := http.NewServeMux()
+    mux.HandleFunc("/moves", post(server.moves))
+    mux.HandleFunc("/move", post(server.move))
+    mux.HandleFunc("/bestmove", post(server.bestMove))
+    mux.HandleFunc("/eval", post(server.eval))
+    mux.HandleFunc("/perft", post(server.perft))
+    mux.HandleFunc("/play", server.play)
+    return mux
+}
+
+// A request that cannot be carried out, answered with the status
+type requestError struct {
+    status  int
+    message string
+}
+
+func (err requestError) Error() string {
+    return err.message
+}
+
+func badRequest(format string, args ...any) error {
+    return requestError { http.StatusBadRequest, fmt.Sprintf(format, args...) }
+}
+
+// Returns a handler that decodes the JSON body of POST requests into a new Request, answers with
+// the response of the endpoint as JSON, and answers errors with {"error": "..."}
+func post[Request any](endpoint func(r *http.Request, request Request) (any, error)) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if r.Method != http.MethodPost {
+            w.Header().Set("Allow", http.MethodPost)
+            writeError(w, requestError { http.StatusMethodNotAllowed, "expected POST" })
+            return
+        }
+
+        var request Request
+        if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&request); err != nil {
+            writeError(w, badRequest("bad JSON: %v", err))
+            return
+        }
+
+        response, err := endpoint(r, request)
+        if err != nil {
+            writeError(w, err)
+            return
+        }
+
+        writeJSON(w, http.StatusOK, response)
+    }
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+
+    if err := json.NewEncoder(w).Encode(value); err != nil {
+        log.Printf("failed to write response: %v", err)
+    }
+}
+
+func writeError(w http.ResponseWriter, err error) {
+    status := http.StatusInternalServerError
+
+    var requestErr requestError
+    if errors.As(err, &requestErr) {
+        status = requestErr.status
+    }
+
+    writeJSON(w, status, map[string]string { "error": err.Error() })
+}
+
+// A position given by a request as a FEN
+type position struct {
+    Fen string `json:"fen"`
+}
+
+// Returns the position of the FEN, or of the starting position if it is empty, after checking that
+// it is legal, and the fullmove number of the FEN, which the board does not keep
+func (position position) load() (*chess.Board, int, error) {
+    board, fullmoveNumber, err := jsonapi.LoadPosition(position.Fen)
+    if err != nil {
+        return nil, 0, badRequest("%v", err)
+    }
+
+    return board, fullmoveNumber, nil
+}
+
+// Answer with the legal moves of the position
+func (server *server) moves(r *http.Request, request position) (any, error) {
+    board, fullmoveNumber, err := request.load()
+    if err != nil {
+        return nil, err
+    }
+
+    return jsonapi.NewPosition(board, fullmoveNumber), nil
+}
+
+type moveRequest struct {
+    position
+    Move string `json:"move"`
+}
+
+type moveResponse struct {
+    Move     jsonapi.Move     `json:"move"`
+    Position jsonapi.Position `json:"position"`
+}
+
+// Answer with the position after the move
+func (server *server) move(r *http.Request, request moveRequest) (any, error) {
+    board, fullmoveNumber, err := request.load()
+    if err != nil {
+        return nil, err
+    }
+
+    move, err := board.ParseMove(request.Move)
+    if err != nil {
+        return nil, badRequest("%v", err)
+    }
+
+    if board.IsBlackToMove() {
+        fullmoveNumber++
+    }
+
+    response := moveResponse { Move: jsonapi.NewMove(board, move) }
+    board.MakeMove(move)
+    response.Position = jsonapi.NewPosition(board, fullmoveNumber)
+
+    return response, nil
+}
+
+type bestMoveRequest struct {
+    position
+    Depth int    `json:"depth"`
+    Nodes uint64 `json:"nodes"`
+
+    // Milliseconds
+    MoveTime int64 `json:"movetime"`
+}
+
+// Answer with the bot's move for the position, searched within the limits of the request, or for a
+// second if it gives none. Time limits are capped, and the search ends early if the client goes
+func (server *server) bestMove(r *http.Request, request bestMoveRequest) (any, error) {
+    board, _, err := request.load()
+    if err != nil {
+        return nil, err
+    }
+
+    if request.Depth < 0 || request.MoveTime < 0 {
+        return nil, badRequest("depth and movetime must not be negative")
+    }
+
+    if len(board.GetLegalMoves(false)) == 0 {
+        return nil, badRequest("the game is over: %v", board.Outcome().Termination)
+    }
+
+    // A search always has a time limit, so that requests cannot hold on to the bots forever
+    limits := chess.SearchLimits { Depth: request.Depth, Nodes: request.Nodes, MoveTime: time.Duration(request.MoveTime) * time.Millisecond }
+    if limits.MoveTime == 0 && limits.Depth == 0 && limits.Nodes == 0 {
+        limits.MoveTime = defaultMoveTime
+    }
+    if limits.MoveTime == 0 || limits.MoveTime > maxMoveTime {
+        limits.MoveTime = maxMoveTime
+    }
+
+    bot := takeBot(r.Context(), server.bots)
+    if bot == nil {
+        return nil, r.Context().Err()
+    }
+    defer func() { server.bots <- bot }()
+
+    move, info := bot.ThinkContext(r.Context(), board, limits)
+
+    return jsonapi.NewSearch(board, move, info), nil
+}
+
+// Returns a bot from the pool, waiting for one if they are all searching, or nil if the context is
+// cancelled first. The bot must be given back to the pool
+func takeBot(ctx context.Context, pool chan *botv1.BotV1) *botv1.BotV1 {
+    select {
+    case bot := <-pool:
+        return bot
+    case <-ctx.Done():
+        return nil
+    }
+}
+
+type termJSON struct {
+    Name  string  `json:"name"`
+    White float64 `json:"white"`
+    Black float64 `json:"black"`
+}
+
+// Evaluation in pawns, with white's advantage as the total
+type evalResponse struct {
+    Terms []termJSON `json:"terms"`
+    Total float64    `json:"total"`
+}
+
+// Answer with the bot's static evaluation of the position, term by term
+func (server *server) eval(r *http.Request, request position) (any, error) {
+    board, _, err := request.load()
+    if err != nil {
+        return nil, err
+    }
+
+    trace := server.evaluator.EvaluateTrace(board)
+
+    response := evalResponse { Terms: []termJSON{}, Total: trace.Total }
+    for _, term := range trace.Terms {
+        response.Terms = append(response.Terms, termJSON { Name: term.Name, White: term.White, Black: term.Black })
+    }
+
+    return response, nil
+}
+
+type perftRequest struct {
+    position
+    Depth  int  `json:"depth"`
+    Divide bool `json:"divide"`
+}
+
+type divideJSON struct {
+    Move  string `json:"move"`
+    Nodes uint64 `json:"nodes"`
+}
+
+type perftResponse struct {
+    Nodes  uint64       `json:"nodes"`
+    Divide []divideJSON `json:"divide,omitempty"`
+    Time   int64        `json:"time"`
+}
+
+// Answer with the number of positions reachable from the position to the depth, and if asked, the
+// number below each legal move. The count runs in place of a search, taking one of the bots, and is
+// abandoned if it takes longer than the server's perft time or the client goes
+func (server *server) perft(r *http.Request, request perftRequest) (any, error) {
+    board, _, err := request.load()
+    if err != nil {
+        return nil, err
+    }
+
+    if request.Depth < 1 || request.Depth > maxPerftDepth {
+        return nil, badRequest("depth must be from 1 to %v: %v", maxPerftDepth, request.Depth)
+    }
+
+    bot := takeBot(r.Context(), server.bots)
+    if bot == nil {
+        return nil, r.Context().Err()
+    }
+    defer func() { server.bots <- bot }()
+
+    ctx, cancel := context.WithTimeout(r.Context(), server.perftTime)
+    defer cancel()
+
+    start := time.Now()
+    var response perftResponse
+
+    if request.Divide {
+        results, err := chess.DivideContext(ctx, board, request.Depth)
+        if err != nil {
+            return nil, server.perftError(r.Context(), err)
+        }
+
+        for _, result := range results {
+            response.Divide = append(response.Divide, divideJSON { Move: result.Move.String(), Nodes: result.Nodes })
+            response.Nodes += result.Nodes
+        }
+    } else {
+        if response.Nodes, err = chess.PerftContext(ctx, board, request.Depth); err != nil {
+            return nil, server.perftError(r.Context(), err)
+        }
+    }
+
+    response.Time = time.Since(start).Milliseconds()
+    return response, nil
+}
+
+// Returns the error to answer a perft count abandoned with `err` with: the request's own error if the
+// client went, or otherwise that the count ran out of time
+func (server *server) perftError(requestCtx context.Context, err error) error {
+    if requestCtx.Err() != nil {
+        return requestCtx.Err()
+    }
+
+    return requestError { http.StatusUnprocessableEntity, fmt.Sprintf("perft did not finish within %v; try a lower depth", server.perftTime) }
+}
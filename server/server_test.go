@@ -0,0 +1,103 @@
+package main
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Position where white mates in one with Ra8#
+const mateInOneFen = "7k/8/6K1/8/8/8/8/R7 w - - 0 1"
+
+// Position where white has been mated
+const checkmateFen = "R6k/8/6K1/8/8/8/8/8 b - - 0 1"
+
+func startTestServer(t *testing.T) (*server, *httptest.Server) {
+	server, err := newServer(1, 1, 1, "")
+	assert.Nil(t, err)
+
+	httpServer := httptest.NewServer(server.handler())
+	t.Cleanup(httpServer.Close)
+
+	return server, httpServer
+}
+
+// Send the request and return the status and body of the response
+func request(t *testing.T, httpServer *httptest.Server, method string, path string, body string) (int, string) {
+	r, err := http.NewRequest(method, httpServer.URL + path, strings.NewReader(body))
+	assert.Nil(t, err)
+
+	response, err := http.DefaultClient.Do(r)
+	assert.Nil(t, err)
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	return response.StatusCode, string(responseBody)
+}
+
+func TestEndpoints(t *testing.T) {
+	_, httpServer := startTestServer(t)
+
+	for _, test := range []struct {
+		description string
+		method      string
+		path        string
+		body        string
+		status      int
+
+		// Parts of the response body
+		contains []string
+	}{
+		{"moves of the starting position", "POST", "/moves", `{}`, http.StatusOK, []string{`"sideToMove":"white"`, `"uci":"e2e4"`, `"termination":"none"`}},
+		{"moves of a checkmate", "POST", "/moves", `{"fen": "` + checkmateFen + `"}`, http.StatusOK, []string{`"check":true`, `"moves":[]`, `"result":"1-0"`}},
+		{"moves of a bad FEN", "POST", "/moves", `{"fen": "not a fen"}`, http.StatusBadRequest, []string{`"error":"bad FEN`}},
+		{"moves of an illegal position", "POST", "/moves", `{"fen": "8/8/8/8/8/8/8/K7 w - - 0 1"}`, http.StatusBadRequest, []string{`illegal position`}},
+
+		{"move in SAN", "POST", "/move", `{"move": "Nf3"}`, http.StatusOK, []string{`"uci":"g1f3"`, `"san":"Nf3"`, `"sideToMove":"black"`}},
+		{"move in UCI notation", "POST", "/move", `{"fen": "` + mateInOneFen + `", "move": "a1a8"}`, http.StatusOK, []string{`"san":"Ra8#"`, `"termination":"checkmate"`}},
+		{"illegal move", "POST", "/move", `{"move": "e2e5"}`, http.StatusBadRequest, []string{`"error"`}},
+
+		{"best move", "POST", "/bestmove", `{"fen": "` + mateInOneFen + `", "depth": 2}`, http.StatusOK, []string{`"move":{"uci":"a1a8","san":"Ra8#"}`, `"score":{"mate":1}`}},
+		{"best move within a node limit", "POST", "/bestmove", `{"nodes": 100}`, http.StatusOK, []string{`"depth":`}},
+		{"best move with a negative depth", "POST", "/bestmove", `{"depth": -1}`, http.StatusBadRequest, []string{`must not be negative`}},
+		{"best move with a negative move time", "POST", "/bestmove", `{"movetime": -1}`, http.StatusBadRequest, []string{`must not be negative`}},
+		{"best move with negative nodes", "POST", "/bestmove", `{"nodes": -1}`, http.StatusBadRequest, []string{`bad JSON`}},
+		{"best move when the game is over", "POST", "/bestmove", `{"fen": "` + checkmateFen + `"}`, http.StatusBadRequest, []string{`the game is over: checkmate`}},
+
+		{"evaluation", "POST", "/eval", `{"fen": "` + mateInOneFen + `"}`, http.StatusOK, []string{`"terms":[{"name":`, `"total":`}},
+
+		{"perft", "POST", "/perft", `{"depth": 3}`, http.StatusOK, []string{`"nodes":8902`}},
+		{"perft divided by move", "POST", "/perft", `{"depth": 2, "divide": true}`, http.StatusOK, []string{`"nodes":400`, `{"move":"e2e4","nodes":20}`}},
+		{"perft too shallow", "POST", "/perft", `{"depth": 0}`, http.StatusBadRequest, []string{`depth must be from 1 to 5: 0`}},
+		{"perft too deep", "POST", "/perft", `{"depth": 6}`, http.StatusBadRequest, []string{`depth must be from 1 to 5: 6`}},
+
+		{"GET request", "GET", "/moves", ``, http.StatusMethodNotAllowed, []string{`"error":"expected POST"`}},
+		{"bad JSON", "POST", "/moves", `{"fen": `, http.StatusBadRequest, []string{`"error":"bad JSON`}},
+		{"unknown field type", "POST", "/perft", `{"depth": "deep"}`, http.StatusBadRequest, []string{`"error":"bad JSON`}},
+	} {
+		status, body := request(t, httpServer, test.method, test.path, test.body)
+		assert.Equal(t, test.status, status, test.description)
+
+		for _, part := range test.contains {
+			assert.Contains(t, body, part, test.description)
+		}
+	}
+}
+
+func TestPerftTimeout(t *testing.T) {
+	server, httpServer := startTestServer(t)
+	server.perftTime = time.Millisecond
+
+	status, body := request(t, httpServer, "POST", "/perft", `{"depth": 5}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Contains(t, body, "perft did not finish within 1ms")
+
+	// The bot taken for the count was given back
+	assert.Len(t, server.bots, 1)
+}
@@ -0,0 +1,147 @@
+package tablebase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gogm/chess"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Address of the Lichess tablebase API for standard chess
+const LichessURL string = "https://tablebase.lichess.ovh/standard"
+
+// Tablebase backend querying the Lichess tablebase API, which covers positions with up to seven
+// pieces, for users who don't want to download tablebase files
+// https://github.com/lichess-org/lila-tablebase
+type LichessClient struct {
+	// Address of the API, usually LichessURL
+	URL string
+
+	HTTPClient *http.Client
+}
+
+// Returns a client for the Lichess tablebase API
+func NewLichessClient() *LichessClient {
+	return &LichessClient{
+		URL:        LichessURL,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (client *LichessClient) MaxPieces() int {
+	return 7
+}
+
+// Response of the API, with categories and distances from the perspective of the side to move
+// in the position (for the position itself) or after the move (for moves)
+type lichessResponse struct {
+	Category string        `json:"category"`
+	DTZ      *int          `json:"dtz"`
+	DTM      *int          `json:"dtm"`
+	Moves    []lichessMove `json:"moves"`
+}
+
+type lichessMove struct {
+	UCI      string `json:"uci"`
+	Category string `json:"category"`
+	DTZ      *int   `json:"dtz"`
+	DTM      *int   `json:"dtm"`
+}
+
+func (client *LichessClient) Probe(board *chess.Board) (result Result, err error) {
+	if PieceCount(board) > client.MaxPieces() {
+		return result, ErrTooManyPieces
+	}
+
+	response, err := client.HTTPClient.Get(client.URL + "?fen=" + url.QueryEscape(fullFen(board)))
+	if err != nil {
+		return result, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return result, errors.New(fmt.Sprintf("tablebase request failed: %v", response.Status))
+	}
+
+	var body lichessResponse
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		return result, errors.New(fmt.Sprintf("bad tablebase response: %v", err))
+	}
+
+	if body.Category == "unknown" {
+		return result, ErrNoResult
+	}
+
+	if result.WDL, err = lichessWDL(body.Category); err != nil {
+		return result, err
+	}
+	result.DTZ, result.HasDTZ = optionalInt(body.DTZ)
+	result.DTM, result.HasDTM = optionalInt(body.DTM)
+
+	for _, bodyMove := range body.Moves {
+		var moveResult MoveResult
+
+		if moveResult.Move, err = chess.MoveWithUciNotation(bodyMove.UCI); err != nil {
+			return result, err
+		}
+
+		// Flip the perspective to the side making the move
+		wdl, err := lichessWDL(bodyMove.Category)
+		if err != nil {
+			return result, err
+		}
+		moveResult.WDL = -wdl
+
+		dtz, hasDTZ := optionalInt(bodyMove.DTZ)
+		moveResult.DTZ, moveResult.HasDTZ = -dtz, hasDTZ
+		dtm, hasDTM := optionalInt(bodyMove.DTM)
+		moveResult.DTM, moveResult.HasDTM = -dtm, hasDTM
+
+		result.Moves = append(result.Moves, moveResult)
+	}
+
+	return result, nil
+}
+
+// Returns the result for a category of the API. Positions that are only known to be maybe won
+// or lost (because of the fifty-move rule and rounded distances) are treated as cursed wins and
+// blessed losses
+func lichessWDL(category string) (WDL, error) {
+	switch category {
+	case "win":
+		return Win, nil
+	case "cursed-win", "maybe-win":
+		return CursedWin, nil
+	case "draw":
+		return Draw, nil
+	case "blessed-loss", "maybe-loss":
+		return BlessedLoss, nil
+	case "loss":
+		return Loss, nil
+	}
+
+	return Draw, errors.New(fmt.Sprintf("unknown tablebase category: %v", category))
+}
+
+func optionalInt(value *int) (int, bool) {
+	if value == nil {
+		return 0, false
+	}
+
+	return *value, true
+}
+
+// Returns the FEN of the board with all six fields, as required by the API
+// The board does not track the move counters, so they are given as if the position has just been
+// reached
+func fullFen(board *chess.Board) string {
+	enPassant := "-"
+	if target, ok := board.GetEnPassantTarget(); ok {
+		enPassant = target.String()
+	}
+
+	return fmt.Sprintf("%v %v 0 1", board.Fen(), enPassant)
+}
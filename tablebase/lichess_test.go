@@ -0,0 +1,103 @@
+package tablebase_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"gogm/tablebase"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Response of the API for the KQ vs K position below, trimmed to the fields used
+const kqkResponse = `{
+	"category": "win",
+	"dtz": 1,
+	"dtm": 19,
+	"moves": [
+		{"uci": "d1d7", "san": "Qd7+", "category": "loss", "dtz": -2, "dtm": -18},
+		{"uci": "d1d4", "san": "Qd4", "category": "loss", "dtz": -4, "dtm": null},
+		{"uci": "d1d8", "san": "Qd8+", "category": "draw", "dtz": 0, "dtm": 0}
+	]
+}`
+
+func newTestClient(t *testing.T, expectedFen string, status int, body string) *tablebase.LichessClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, expectedFen, r.URL.Query().Get("fen"))
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client := tablebase.NewLichessClient()
+	client.URL = server.URL
+	return client
+}
+
+func TestLichessProbe(t *testing.T) {
+	assert := assert.New(t)
+
+	fen := "4k3/8/8/8/8/8/8/3QK3 w - - 0 1"
+	board, err := chess.LoadFen(fen)
+	assert.Nil(err)
+
+	client := newTestClient(t, fen, http.StatusOK, kqkResponse)
+
+	result, err := client.Probe(board)
+	assert.Nil(err)
+
+	assert.Equal(tablebase.Win, result.WDL)
+	assert.True(result.HasDTZ)
+	assert.Equal(1, result.DTZ)
+	assert.True(result.HasDTM)
+	assert.Equal(19, result.DTM)
+
+	assert.Len(result.Moves, 3)
+	assert.Equal(tablebase.Win, result.Moves[0].WDL)
+	assert.Equal(18, result.Moves[0].DTM)
+	assert.False(result.Moves[1].HasDTM)
+	assert.Equal(tablebase.Draw, result.Moves[2].WDL)
+
+	bestMove, ok := result.BestMove()
+	assert.True(ok)
+	assert.Equal(chess.Move{Source: chess.D1, Destination: chess.D7}, bestMove)
+}
+
+func TestLichessProbeEnPassant(t *testing.T) {
+	fen := "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1"
+	board, err := chess.LoadFen(fen)
+	assert.Nil(t, err)
+
+	client := newTestClient(t, fen, http.StatusOK, `{"category": "draw", "dtz": 0, "dtm": null, "moves": []}`)
+
+	result, err := client.Probe(board)
+	assert.Nil(t, err)
+	assert.Equal(t, tablebase.Draw, result.WDL)
+
+	_, ok := result.BestMove()
+	assert.False(t, ok)
+}
+
+func TestLichessProbeErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := chess.LoadFen(chess.StartingPositionFen)
+	assert.Nil(err)
+
+	// Too many pieces to send a request
+	client := tablebase.NewLichessClient()
+	client.URL = "http://127.0.0.1:0"
+	_, err = client.Probe(board)
+	assert.Equal(tablebase.ErrTooManyPieces, err)
+
+	board, err = chess.LoadFen("r3k3/8/8/8/8/8/8/4K3 b q - 0 1")
+	assert.Nil(err)
+
+	client = newTestClient(t, "r3k3/8/8/8/8/8/8/4K3 b q - 0 1", http.StatusOK, `{"category": "unknown", "moves": []}`)
+	_, err = client.Probe(board)
+	assert.Equal(tablebase.ErrNoResult, err)
+
+	client = newTestClient(t, "r3k3/8/8/8/8/8/8/4K3 b q - 0 1", http.StatusTooManyRequests, ``)
+	_, err = client.Probe(board)
+	assert.NotNil(err)
+}
@@ -0,0 +1,97 @@
+// Package tablebase looks up the exact result of endgame positions with few pieces in endgame
+// tablebases, behind an interface so that different backends can be used interchangeably
+// https://www.chessprogramming.org/Endgame_Tablebases
+package tablebase
+
+import (
+	"errors"
+	"fmt"
+	"gogm/chess"
+)
+
+// A source of tablebase results, such as an online service or local files
+type Tablebase interface {
+	// Look up the position, returning ErrTooManyPieces if it has more pieces than the tablebase
+	// covers
+	Probe(board *chess.Board) (Result, error)
+
+	// Maximum number of pieces (including kings) in positions the tablebase covers
+	MaxPieces() int
+}
+
+var ErrTooManyPieces = errors.New("too many pieces for the tablebase")
+
+// Returned for positions the tablebase does not cover despite their piece count, e.g. positions
+// with castling rights
+var ErrNoResult = errors.New("no tablebase result for the position")
+
+// Win/draw/loss result of a position from the perspective of the side to move
+// Cursed wins and blessed losses are wins and losses that become draws under the fifty-move rule
+type WDL int8
+
+const (
+	Loss        WDL = -2
+	BlessedLoss WDL = -1
+	Draw        WDL = 0
+	CursedWin   WDL = 1
+	Win         WDL = 2
+)
+
+func (wdl WDL) String() string {
+	switch wdl {
+	case Loss:
+		return "loss"
+	case BlessedLoss:
+		return "blessed loss"
+	case Draw:
+		return "draw"
+	case CursedWin:
+		return "cursed win"
+	case Win:
+		return "win"
+	}
+
+	return fmt.Sprintf("WDL(%d)", int8(wdl))
+}
+
+// Tablebase result of a position
+type Result struct {
+	// Result with best play from the perspective of the side to move
+	WDL WDL
+
+	// Distance to zeroing the halfmove clock (by a capture or pawn move) with best play, in plies,
+	// negative when losing. Only valid if HasDTZ is true
+	DTZ    int
+	HasDTZ bool
+
+	// Distance to mate with best play, in plies, negative when losing. Only valid if HasDTM is true
+	DTM    int
+	HasDTM bool
+
+	// Results of the legal moves, best first, if the backend provides them
+	Moves []MoveResult
+}
+
+// Tablebase result of a legal move, from the perspective of the side making the move
+type MoveResult struct {
+	Move   chess.Move
+	WDL    WDL
+	DTZ    int
+	HasDTZ bool
+	DTM    int
+	HasDTM bool
+}
+
+// Returns the best move in the position, and whether the backend provided the results of moves
+func (result Result) BestMove() (chess.Move, bool) {
+	if len(result.Moves) == 0 {
+		return chess.Move{}, false
+	}
+
+	return result.Moves[0].Move, true
+}
+
+// Returns the number of pieces on the board, including kings
+func PieceCount(board *chess.Board) int {
+	return board.GetOccupiedBitboard().PopCount()
+}
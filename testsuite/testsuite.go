@@ -0,0 +1,157 @@
+package main
+
+// Runs a bot on each position of an EPD test suite (e.g. WAC, STS) and counts how many it solves
+// A position is solved if the bot plays one of the best moves (bm) and none of the moves to avoid
+// (am)
+
+import (
+	"flag"
+	"fmt"
+	"gogm/botv1"
+	"gogm/chess"
+	"os"
+	"strings"
+	"time"
+)
+
+var red   string = "\033[31m"
+var green string = "\033[32m"
+var reset string = "\033[0m"
+
+func main() {
+    depth := flag.Int("depth", 0, "maximum search depth per position in ply (0 for the bot's default)")
+    moveTime := flag.Duration("time", 0, "time budget per position, e.g. 5s (0 for no limit)")
+    verifyHash := flag.Bool("verify-hash", false, "check the moves of transposition table entries for hash collisions and bugs (slow)")
+    deterministic := flag.Bool("deterministic", false, "search each position from a clear state with -time turned into nodes, and print node counts instead of times, so that the output of two runs can be compared")
+    flag.Usage = func() {
+        fmt.Fprintf(flag.CommandLine.Output(), "usage: %v [flags] suite.epd\n", os.Args[0])
+        flag.PrintDefaults()
+    }
+    flag.Parse()
+
+    if flag.NArg() != 1 {
+        flag.Usage()
+        os.Exit(2)
+    }
+
+    records, err := chess.ReadEpdFile(flag.Arg(0))
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+    }
+
+    bot := &botv1.BotV1 {}
+    if *depth > 0 {
+        bot.SetMaxDepth(*depth)
+    }
+    bot.SetVerifyHash(*verifyHash)
+
+    // The bot turns the time into nodes itself, which it cannot when stopped from outside
+    if *deterministic {
+        bot.SetDeterministic(true)
+        bot.SetMoveTime(*moveTime)
+        *moveTime = 0
+    }
+
+    // Allocate the transposition table now so that the first position is not timed with it
+    bot.Warmup()
+
+    solved := 0
+    hashMismatches := uint64(0)
+    tested := 0
+    start := time.Now()
+
+    for index, record := range records {
+        id := record.ID()
+        if id == "" {
+            id = fmt.Sprintf("#%v", index + 1)
+        }
+
+        bestMoves, err := record.BestMoves()
+        if err != nil {
+            fmt.Printf("%v: skipped: %v\n", id, err)
+            continue
+        }
+
+        avoidMoves, err := record.AvoidMoves()
+        if err != nil {
+            fmt.Printf("%v: skipped: %v\n", id, err)
+            continue
+        }
+
+        if len(bestMoves) == 0 && len(avoidMoves) == 0 {
+            fmt.Printf("%v: skipped: no bm or am operation\n", id)
+            continue
+        }
+
+        positionStart := time.Now()
+        move := think(bot, record.Board, *moveTime)
+        elapsed := time.Since(positionStart)
+
+        tested++
+        passed := record.IsSolution(move)
+
+        var result string
+        if passed {
+            solved++
+            result = green + "solved" + reset
+        } else {
+            result = red + "failed" + reset
+        }
+
+        cost := fmt.Sprintf("%.2fs", elapsed.Seconds())
+        if *deterministic {
+            stats := bot.LastSearchStats()
+            cost = fmt.Sprintf("%v nodes", stats.Nodes + stats.QuiescenceNodes)
+        }
+
+        fmt.Printf(
+            "%v: %v played %v expected %v (%v)\n",
+            id,
+            result,
+            record.Board.San(move),
+            expectation(record.Board, bestMoves, avoidMoves),
+            cost,
+        )
+
+        if mismatches := bot.LastSearchStats().HashMismatches; mismatches > 0 {
+            fmt.Printf("%v: %v hash mismatches\n", id, mismatches)
+            hashMismatches += mismatches
+        }
+    }
+
+    if *deterministic {
+        fmt.Printf("\nSolved %v/%v\n", solved, tested)
+    } else {
+        fmt.Printf("\nSolved %v/%v in %.1fs\n", solved, tested, time.Since(start).Seconds())
+    }
+
+    if *verifyHash {
+        fmt.Printf("%v hash mismatches\n", hashMismatches)
+    }
+}
+
+// Let the bot choose a move, stopping it once the time budget has run out if it can be stopped
+func think(bot chess.Bot, board *chess.Board, moveTime time.Duration) chess.Move {
+    if stoppableBot, ok := bot.(chess.StoppableBot); ok && moveTime > 0 {
+        timer := time.AfterFunc(moveTime, stoppableBot.Stop)
+        defer timer.Stop()
+    }
+
+    return bot.Think(board)
+}
+
+// Describe the expected moves, e.g. "Qg6" or "not Kf1" or "Qg6 Rxb2 not Kf1"
+func expectation(board *chess.Board, bestMoves []chess.Move, avoidMoves []chess.Move) string {
+    var parts []string
+
+    for _, move := range bestMoves {
+        parts = append(parts, board.San(move))
+    }
+
+    for _, move := range avoidMoves {
+        parts = append(parts, "not " + board.San(move))
+    }
+
+    return strings.Join(parts, " ")
+}
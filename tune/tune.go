@@ -0,0 +1,396 @@
+package main
+
+// Tunes the evaluation parameters of the bot with the Texel method: the evaluation of each position
+// is mapped to an expected score with a logistic function, and the parameters are adjusted one step
+// at a time to minimise the mean squared difference from the results of the games the positions
+// were taken from
+// https://www.chessprogramming.org/Texel%27s_Tuning_Method
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"gogm/botv1"
+	"gogm/chess"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A position and the result of the game it was taken from, as white's score
+type position struct {
+    board  *chess.Board
+    result float64
+}
+
+// A game result in a labelled position, e.g. c9 "1-0"; in EPD or [0.5] after a FEN
+var resultPattern = regexp.MustCompile(`(1-0|0-1|1/2-1/2)|\[(1\.0|0\.5|0\.0|1|0)\]`)
+
+func main() {
+    paramsPath := flag.String("params", "default", "file of evaluation parameters to start from, or \"default\" for the built-in ones")
+    outPath := flag.String("out", "tuned.json", "file to write the tuned parameters to after each iteration")
+    tunePattern := flag.String("tune", "", "regular expression matching the names of the parameters to tune, e.g. Value$ (default all of them)")
+    k := flag.Float64("k", 0, "scaling constant of the logistic function (default fitted to the positions)")
+    iterations := flag.Int("iterations", 0, "maximum number of passes over the parameters (default until none improves)")
+    includeNoisy := flag.Bool("noisy", false, "keep positions in check or with a winning capture, which the static evaluation scores badly")
+    printGo := flag.Bool("go", false, "print the tuned parameters as Go source for the piece-square tables and DefaultEvalParams")
+    workers := flag.Int("j", runtime.NumCPU(), "number of goroutines evaluating positions")
+    flag.Usage = func() {
+        fmt.Fprintf(flag.CommandLine.Output(), "usage: %v [flags] file ...\n", os.Args[0])
+        fmt.Fprintln(flag.CommandLine.Output(), "Each line of the files is a position labelled with the result of its game, either an")
+        fmt.Fprintln(flag.CommandLine.Output(), "EPD record such as <fen> c9 \"1-0\"; or a FEN followed by [1.0], [0.5] or [0.0]")
+        flag.PrintDefaults()
+    }
+    flag.Parse()
+
+    if flag.NArg() == 0 {
+        flag.Usage()
+        os.Exit(2)
+    }
+
+    if *workers < 1 {
+        *workers = 1
+    }
+
+    params := botv1.DefaultEvalParams()
+    if *paramsPath != "default" {
+        var err error
+        if params, err = botv1.LoadEvalParams(*paramsPath); err != nil {
+            fmt.Fprintln(os.Stderr, err)
+            os.Exit(1)
+        }
+    }
+
+    tuned, err := selectParams(*tunePattern)
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(2)
+    }
+
+    positions, skipped, err := loadPositions(flag.Args(), *includeNoisy)
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+    }
+
+    if len(positions) == 0 {
+        fmt.Fprintln(os.Stderr, "no positions to tune on")
+        os.Exit(1)
+    }
+
+    fmt.Printf("Loaded %v positions, skipped %v noisy positions\n", len(positions), skipped)
+
+    tuner := tuner { positions: positions, workers: *workers, k: *k }
+    if tuner.k == 0 {
+        tuner.k = tuner.fitK(params)
+    }
+
+    fmt.Printf("K = %.4f, tuning %v parameters\n", tuner.k, len(tuned))
+
+    err = tuner.tune(params, tuned, *iterations, func(params *botv1.EvalParams) error {
+        return params.Save(*outPath)
+    })
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+    }
+
+    if *printGo {
+        writeGo(os.Stdout, params)
+    }
+}
+
+// Returns the indices in the parameter vector of the parameters whose names match the pattern
+func selectParams(pattern string) ([]int, error) {
+    expression, err := regexp.Compile(pattern)
+    if err != nil {
+        return nil, err
+    }
+
+    var indices []int
+    for index, name := range botv1.EvalParamNames() {
+        if expression.MatchString(name) {
+            indices = append(indices, index)
+        }
+    }
+
+    if len(indices) == 0 {
+        return nil, errors.New(fmt.Sprintf("no parameters match %v", pattern))
+    }
+
+    return indices, nil
+}
+
+// Read the labelled positions from the files, leaving out positions the static evaluation cannot
+// be expected to score well unless `includeNoisy` is set
+func loadPositions(paths []string, includeNoisy bool) (positions []position, skipped int, err error) {
+    for _, path := range paths {
+        file, err := os.Open(path)
+        if err != nil {
+            return nil, 0, err
+        }
+
+        scanner := bufio.NewScanner(file)
+        lineNumber := 0
+
+        for scanner.Scan() {
+            lineNumber++
+
+            line := strings.TrimSpace(scanner.Text())
+            if line == "" || strings.HasPrefix(line, "#") {
+                continue
+            }
+
+            position, err := parsePosition(line)
+            if err != nil {
+                file.Close()
+                return nil, 0, errors.New(fmt.Sprintf("%v:%v: %v", path, lineNumber, err))
+            }
+
+            if !includeNoisy && isNoisy(position.board) {
+                skipped++
+                continue
+            }
+
+            positions = append(positions, position)
+        }
+
+        file.Close()
+
+        if err := scanner.Err(); err != nil {
+            return nil, 0, errors.New(fmt.Sprintf("%v: %v", path, err))
+        }
+    }
+
+    return positions, skipped, nil
+}
+
+// Parse a position from its first four FEN fields and the game result found after them
+func parsePosition(line string) (position, error) {
+    fields := strings.Fields(line)
+    if len(fields) < 5 {
+        return position{}, errors.New(fmt.Sprintf("expected a position followed by a result: %v", line))
+    }
+
+    board, err := chess.LoadFen(strings.Join(fields[:4], " "))
+    if err != nil {
+        return position{}, err
+    }
+
+    match := resultPattern.FindStringSubmatch(strings.Join(fields[4:], " "))
+    if match == nil {
+        return position{}, errors.New(fmt.Sprintf("no result (1-0, 0-1, 1/2-1/2 or [1.0], [0.5], [0.0]): %v", line))
+    }
+
+    result := 0.5
+    switch {
+    case match[1] == "1-0" || strings.HasPrefix(match[2], "1"):
+        result = 1.0
+    case match[1] == "0-1" || match[2] == "0" || match[2] == "0.0":
+        result = 0.0
+    }
+
+    return position { board: board, result: result }, nil
+}
+
+// Returns whether the position is in check, is over, or has a capture or promotion that wins
+// material, so that its evaluation says little about who is better
+func isNoisy(board *chess.Board) bool {
+    if board.IsCheck() || len(board.GetLegalMoves(false)) == 0 {
+        return true
+    }
+
+    for _, move := range board.GetLegalMoves(true) {
+        if move.IsPromotion || board.SEE(move) > 0 {
+            return true
+        }
+    }
+
+    return false
+}
+
+type tuner struct {
+    positions []position
+    workers   int
+
+    // Scaling constant of the logistic function mapping evaluations in pawns to expected scores
+    k float64
+}
+
+// Returns the expected score of a position for white given its evaluation from white's perspective
+func (tuner *tuner) expectedScore(eval float64) float64 {
+    return 1.0 / (1.0 + math.Pow(10.0, -tuner.k * eval / 4.0))
+}
+
+// Returns the mean squared difference between the expected scores of the positions under the
+// parameters and the results of their games
+func (tuner *tuner) meanError(params *botv1.EvalParams) float64 {
+    evaluator := botv1.HandCraftedEvaluator { Params: params }
+    sums := make([]float64, tuner.workers)
+    chunkSize := (len(tuner.positions) + tuner.workers - 1) / tuner.workers
+
+    var wait sync.WaitGroup
+    for worker := 0; worker < tuner.workers; worker++ {
+        start := min(worker * chunkSize, len(tuner.positions))
+        end := min(start + chunkSize, len(tuner.positions))
+
+        wait.Add(1)
+
+        go func(worker int, positions []position) {
+            defer wait.Done()
+
+            for _, position := range positions {
+                // The evaluation is from the perspective of the side to move
+                eval := evaluator.Evaluate(position.board)
+                if position.board.IsBlackToMove() {
+                    eval = -eval
+                }
+
+                difference := position.result - tuner.expectedScore(eval)
+                sums[worker] += difference * difference
+            }
+        }(worker, tuner.positions[start:end])
+    }
+
+    wait.Wait()
+
+    sum := 0.0
+    for _, workerSum := range sums {
+        sum += workerSum
+    }
+
+    return sum / float64(len(tuner.positions))
+}
+
+// Returns the scaling constant that minimises the error under the parameters, found by ternary
+// search, as the error is unimodal in it
+func (tuner *tuner) fitK(params *botv1.EvalParams) float64 {
+    low, high := 0.0, 10.0
+
+    for i := 0; i < 50; i++ {
+        a := low + (high - low) / 3.0
+        b := high - (high - low) / 3.0
+
+        tuner.k = a
+        errorA := tuner.meanError(params)
+        tuner.k = b
+        errorB := tuner.meanError(params)
+
+        if errorA < errorB {
+            high = b
+        } else {
+            low = a
+        }
+    }
+
+    return (low + high) / 2.0
+}
+
+// Minimise the error by local search: each pass tries raising and then lowering each tuned
+// parameter by a centipawn, keeping the change if it lowers the error, until a pass improves
+// nothing or `maxIterations` passes have been made (0 for no limit). `save` is called with the
+// parameters after each pass, so that tuning can be interrupted without losing progress
+func (tuner *tuner) tune(params *botv1.EvalParams, tuned []int, maxIterations int, save func(*botv1.EvalParams) error) error {
+    vector := params.Vector()
+    bestError := tuner.meanError(params)
+
+    fmt.Printf("Initial error %.6f\n", bestError)
+
+    // Change the parameter at the index by `step`, keeping the change and returning true if it
+    // lowers the error
+    try := func(index int, step float64) bool {
+        vector[index] += step
+        params.SetVector(vector)
+
+        if candidateError := tuner.meanError(params); candidateError < bestError {
+            bestError = candidateError
+            return true
+        }
+
+        vector[index] -= step
+        params.SetVector(vector)
+        return false
+    }
+
+    for iteration := 1; maxIterations == 0 || iteration <= maxIterations; iteration++ {
+        start := time.Now()
+        improved := 0
+
+        for _, index := range tuned {
+            if try(index, 1.0) || try(index, -1.0) {
+                improved++
+            }
+        }
+
+        fmt.Printf("Iteration %v: error %.6f, %v parameters improved (%.1fs)\n", iteration, bestError, improved, time.Since(start).Seconds())
+
+        if err := save(params); err != nil {
+            return err
+        }
+
+        if improved == 0 {
+            break
+        }
+    }
+
+    return nil
+}
+
+// Print the piece values and bonuses as fields of DefaultEvalParams, and the piece-square tables as
+// the variables of botv1/pieceSquareTables.go
+func writeGo(writer io.Writer, params *botv1.EvalParams) {
+    names := botv1.EvalParamNames()
+    vector := params.Vector()
+
+    fmt.Fprintln(writer)
+    for index, name := range names {
+        if strings.Contains(name, ".") {
+            break
+        }
+
+        fmt.Fprintf(writer, "    %v: %v,\n", strings.ToUpper(name[:1]) + name[1:], vector[index] / 100.0)
+    }
+
+    tables := []struct {
+        piece  string
+        tables botv1.PieceSquareTables
+    }{
+        {"Pawn", params.PawnTables},
+        {"Knight", params.KnightTables},
+        {"Bishop", params.BishopTables},
+        {"Rook", params.RookTables},
+        {"Queen", params.QueenTables},
+        {"King", params.KingTables},
+    }
+
+    for _, table := range tables {
+        writeTable(writer, "pieceSquareTable" + table.piece + "Middlegame", table.tables.Middlegame)
+        writeTable(writer, "pieceSquareTable" + table.piece + "Endgame", table.tables.Endgame)
+    }
+}
+
+func writeTable(writer io.Writer, name string, table [64]int) {
+    width := 0
+    for _, entry := range table {
+        width = max(width, len(fmt.Sprint(entry)))
+    }
+
+    fmt.Fprintf(writer, "\nvar %v = [64]int {\n", name)
+
+    for rank := 0; rank < 8; rank++ {
+        entries := make([]string, 8)
+        for file := 0; file < 8; file++ {
+            entries[file] = fmt.Sprintf("%*d", width, table[rank * 8 + file])
+        }
+
+        fmt.Fprintf(writer, "    %v,\n", strings.Join(entries, ", "))
+    }
+
+    fmt.Fprintln(writer, "}")
+}
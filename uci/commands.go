@@ -0,0 +1,208 @@
+package uci
+
+import (
+	"errors"
+	"fmt"
+	"gogm/chess"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Returns the position described by the arguments of the "position" command, e.g.
+// "startpos moves e2e4 e7e5" or "fen <fen> moves e2e4", with the moves played on the board so that
+// repetitions of earlier positions are recognised
+func ParsePosition(args []string) (*chess.Board, error) {
+	if len(args) == 0 {
+		return nil, errors.New("expected startpos or fen")
+	}
+
+	var fen string
+	var moves []string
+
+	switch args[0] {
+	case "startpos":
+		fen = chess.StartingPositionFen
+		args = args[1:]
+
+	case "fen":
+		end := 1
+		for end < len(args) && args[end] != "moves" {
+			end++
+		}
+
+		fen = strings.Join(args[1:end], " ")
+		args = args[end:]
+
+	default:
+		return nil, errors.New(fmt.Sprintf("expected startpos or fen: %v", args[0]))
+	}
+
+	if len(args) > 0 {
+		if args[0] != "moves" {
+			return nil, errors.New(fmt.Sprintf("expected moves: %v", args[0]))
+		}
+		moves = args[1:]
+	}
+
+	board, err := chess.LoadFen(fen)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, notation := range moves {
+		move, err := chess.MoveWithUciNotation(notation)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := board.MakeLegalMove(move); err != nil {
+			return nil, errors.New(fmt.Sprintf("illegal move %v: %v", notation, err))
+		}
+	}
+
+	return board, nil
+}
+
+// Returns the search limits given by the arguments of the "go" command, e.g.
+// "wtime 60000 btime 60000 winc 1000 binc 1000", and whether the engine is to ponder, searching
+// the position after the move it expects until the GUI sends "ponderhit" or "stop"
+// Times are in milliseconds. The "searchmoves" and "mate" arguments are not supported and are
+// ignored along with their values
+func ParseGo(args []string) (limits chess.SearchLimits, ponder bool, err error) {
+	for i := 0; i < len(args); i++ {
+		// Returns the number following the argument
+		value := func() (int64, error) {
+			if i + 1 >= len(args) {
+				return 0, errors.New(fmt.Sprintf("expected a value after %v", args[i]))
+			}
+
+			i++
+			number, err := strconv.ParseInt(args[i], 10, 64)
+			if err != nil {
+				return 0, errors.New(fmt.Sprintf("bad value for %v: %v", args[i - 1], args[i]))
+			}
+
+			return number, nil
+		}
+
+		var number int64
+
+		switch args[i] {
+		case "infinite":
+			limits.Infinite = true
+		case "ponder":
+			ponder = true
+		case "wtime":
+			number, err = value()
+			limits.WhiteTime = time.Duration(number) * time.Millisecond
+		case "btime":
+			number, err = value()
+			limits.BlackTime = time.Duration(number) * time.Millisecond
+		case "winc":
+			number, err = value()
+			limits.WhiteIncrement = time.Duration(number) * time.Millisecond
+		case "binc":
+			number, err = value()
+			limits.BlackIncrement = time.Duration(number) * time.Millisecond
+		case "movestogo":
+			number, err = value()
+			limits.MovesToGo = int(number)
+		case "depth":
+			number, err = value()
+			limits.Depth = int(number)
+		case "nodes":
+			number, err = value()
+			limits.Nodes = uint64(max(number, 0))
+		case "movetime":
+			number, err = value()
+			limits.MoveTime = time.Duration(number) * time.Millisecond
+		case "mate":
+			_, err = value()
+		case "searchmoves":
+			// The moves run until the next argument
+			for i + 1 < len(args) && isMove(args[i + 1]) {
+				i++
+			}
+		}
+
+		if err != nil {
+			return chess.SearchLimits{}, false, err
+		}
+	}
+
+	return limits, ponder, nil
+}
+
+// Returns the name and value given by the arguments of the "setoption" command, e.g.
+// "name Clear Hash" or "name Hash value 64". Names and values may contain spaces, and the value is
+// empty for button options
+func ParseSetOption(args []string) (name string, value string, err error) {
+	if len(args) < 2 || args[0] != "name" {
+		return "", "", errors.New("expected name")
+	}
+
+	end := 1
+	for end < len(args) && args[end] != "value" {
+		end++
+	}
+
+	name = strings.Join(args[1:end], " ")
+	if end < len(args) {
+		value = strings.Join(args[end + 1:], " ")
+	}
+
+	return name, value, nil
+}
+
+func isMove(notation string) bool {
+	_, err := chess.MoveWithUciNotation(notation)
+	return err == nil
+}
+
+// Returns the "info" line reporting the progress of a search
+func InfoLine(info chess.SearchInfo) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "info depth %v", info.Depth)
+
+	if info.SelectiveDepth > 0 {
+		fmt.Fprintf(&sb, " seldepth %v", info.SelectiveDepth)
+	}
+
+	sb.WriteString(" score ")
+	sb.WriteString(ScoreString(info.Score))
+
+	if info.Nodes > 0 {
+		fmt.Fprintf(&sb, " nodes %v", info.Nodes)
+	}
+
+	if info.Time > 0 {
+		fmt.Fprintf(&sb, " nps %v time %v", info.NodesPerSecond(), info.Time.Milliseconds())
+	}
+
+	if info.HashFull > 0 {
+		fmt.Fprintf(&sb, " hashfull %v", info.HashFull)
+	}
+
+	if len(info.PrincipalVariation) > 0 {
+		sb.WriteString(" pv")
+		for _, move := range info.PrincipalVariation {
+			sb.WriteString(" ")
+			sb.WriteString(MoveString(move))
+		}
+	}
+
+	return sb.String()
+}
+
+// Returns the score as in "info" lines: "cp" and the score in centipawns, or "mate" and the number
+// of moves until mate, negative if the engine is being mated
+func ScoreString(score float64) string {
+	if moves, ok := chess.MateIn(score); ok {
+		return fmt.Sprintf("mate %v", moves)
+	}
+
+	return fmt.Sprintf("cp %v", int(math.Round(score * 100.0)))
+}
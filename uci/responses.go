@@ -0,0 +1,241 @@
+package uci
+
+import (
+	"errors"
+	"fmt"
+	"gogm/chess"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Functions for the GUI side of the protocol, which sends commands to an engine and reads its
+// responses
+
+// Returns the "position" command setting up the position on the board
+// The board does not remember the moves that led to the position, so an engine cannot see
+// repetitions of positions before it
+func PositionLine(board *chess.Board) string {
+	return "position fen " + board.FullFen(1)
+}
+
+// Returns the "go" command asking the engine to search within the limits
+// An engine given no limits searches until it is sent "stop"
+func GoLine(limits chess.SearchLimits) string {
+	var sb strings.Builder
+
+	sb.WriteString("go")
+
+	if limits.Infinite {
+		sb.WriteString(" infinite")
+	}
+
+	durations := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"wtime", limits.WhiteTime},
+		{"btime", limits.BlackTime},
+		{"winc", limits.WhiteIncrement},
+		{"binc", limits.BlackIncrement},
+		{"movetime", limits.MoveTime},
+	}
+
+	for _, duration := range durations {
+		if duration.duration > 0 {
+			fmt.Fprintf(&sb, " %v %v", duration.name, duration.duration.Milliseconds())
+		}
+	}
+
+	if limits.MovesToGo > 0 {
+		fmt.Fprintf(&sb, " movestogo %v", limits.MovesToGo)
+	}
+
+	if limits.Depth > 0 {
+		fmt.Fprintf(&sb, " depth %v", limits.Depth)
+	}
+
+	if limits.Nodes > 0 {
+		fmt.Fprintf(&sb, " nodes %v", limits.Nodes)
+	}
+
+	return sb.String()
+}
+
+// Returns the progress of the search given by the arguments of an "info" line, e.g.
+// "depth 12 score cp 31 nodes 80000 pv e2e4 e7e5", and false if the line does not report a score
+// or a principal variation, like "info string" and "info currmove" lines, or reports a line other
+// than the best when the engine is searching several
+func ParseInfo(args []string) (info chess.SearchInfo, ok bool) {
+	for i := 0; i < len(args); i++ {
+		// Returns the number following the argument, or 0 if there is none
+		value := func() int64 {
+			if i + 1 >= len(args) {
+				return 0
+			}
+
+			i++
+			number, _ := strconv.ParseInt(args[i], 10, 64)
+			return number
+		}
+
+		switch args[i] {
+		case "string":
+			return chess.SearchInfo{}, false
+		case "depth":
+			info.Depth = int(value())
+		case "seldepth":
+			info.SelectiveDepth = int(value())
+		case "nodes":
+			info.Nodes = uint64(max(value(), 0))
+		case "time":
+			info.Time = time.Duration(value()) * time.Millisecond
+		case "hashfull":
+			info.HashFull = int(value())
+		case "multipv":
+			if value() > 1 {
+				return chess.SearchInfo{}, false
+			}
+		case "score":
+			if i + 2 >= len(args) {
+				continue
+			}
+
+			number, err := strconv.Atoi(args[i + 2])
+			if err != nil {
+				continue
+			}
+
+			switch args[i + 1] {
+			case "cp":
+				info.Score = float64(number) / 100.0
+				ok = true
+			case "mate":
+				info.Score = mateScore(number)
+				ok = true
+			}
+			i += 2
+
+		case "pv":
+			// The moves run to the end of the line
+			for i + 1 < len(args) && isMove(args[i + 1]) {
+				i++
+				move, _ := chess.MoveWithUciNotation(args[i])
+				info.PrincipalVariation = append(info.PrincipalVariation, move)
+			}
+
+			if len(info.PrincipalVariation) > 0 {
+				info.BestMove = info.PrincipalVariation[0]
+				ok = true
+			}
+		}
+	}
+
+	return info, ok
+}
+
+// Returns the score of a mate in the number of moves given in an "info" line, negative if the
+// engine is being mated
+func mateScore(moves int) float64 {
+	if moves > 0 {
+		return chess.MateInPly(2 * moves - 1)
+	}
+
+	return chess.MatedInPly(-2 * moves)
+}
+
+// Returns the move and the move the engine expects in reply, if it gave one, from the arguments of
+// a "bestmove" line, e.g. "e2e4 ponder e7e5". The move is the null move if the engine has no legal
+// moves
+func ParseBestMove(args []string) (move chess.Move, ponderMove *chess.Move, err error) {
+	if len(args) == 0 {
+		return chess.Move{}, nil, errors.New("expected a move")
+	}
+
+	if args[0] == "0000" || args[0] == "(none)" {
+		return chess.Move{}, nil, nil
+	}
+
+	if move, err = chess.MoveWithUciNotation(args[0]); err != nil {
+		return chess.Move{}, nil, err
+	}
+
+	if len(args) >= 3 && args[1] == "ponder" {
+		if reply, err := chess.MoveWithUciNotation(args[2]); err == nil {
+			ponderMove = &reply
+		}
+	}
+
+	return move, ponderMove, nil
+}
+
+// Keywords of "option" lines, which end the name or value before them
+var optionKeywords = []string{"name", "type", "default", "min", "max", "var"}
+
+// Returns the option described by the arguments of an "option" line, e.g.
+// "name Hash type spin default 16 min 1 max 33554432". Names and values may contain spaces
+func ParseOption(args []string) (chess.BotOption, error) {
+	var option chess.BotOption
+	var optionType string
+
+	for i := 0; i < len(args); {
+		keyword := args[i]
+
+		end := i + 1
+		for end < len(args) && !isOptionKeyword(args[end]) {
+			end++
+		}
+		value := strings.Join(args[i + 1:end], " ")
+		i = end
+
+		switch keyword {
+		case "name":
+			option.Name = value
+		case "type":
+			optionType = value
+		case "default":
+			if value != "<empty>" {
+				option.Default = value
+			}
+		case "min":
+			option.Min, _ = strconv.Atoi(value)
+		case "max":
+			option.Max, _ = strconv.Atoi(value)
+		case "var":
+			option.Choices = append(option.Choices, value)
+		default:
+			return chess.BotOption{}, errors.New(fmt.Sprintf("expected name: %v", keyword))
+		}
+	}
+
+	if option.Name == "" {
+		return chess.BotOption{}, errors.New("expected name")
+	}
+
+	switch optionType {
+	case "check":
+		option.Type = chess.CheckOption
+	case "spin":
+		option.Type = chess.SpinOption
+	case "combo":
+		option.Type = chess.ComboOption
+	case "string":
+		option.Type = chess.StringOption
+	case "button":
+		option.Type = chess.ButtonOption
+	default:
+		return chess.BotOption{}, errors.New(fmt.Sprintf("unknown type of option %v: %v", option.Name, optionType))
+	}
+
+	return option, nil
+}
+
+func isOptionKeyword(word string) bool {
+	for _, keyword := range optionKeywords {
+		if word == keyword {
+			return true
+		}
+	}
+
+	return false
+}
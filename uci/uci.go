@@ -0,0 +1,132 @@
+// Package uci formats engine output following the Universal Chess Interface protocol and the
+// conventions tournament GUIs such as Arena and BanksiaGUI build on top of it, parses the commands
+// GUIs send, and for running other engines, formats commands and parses engine output
+// https://www.wbec-ridderkerk.nl/html/UCIProtocol.html
+package uci
+
+import (
+	"fmt"
+	"gogm/chess"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Returns the "id" and "option" lines sent in response to the "uci" command, not including the
+// final "uciok"
+func IDLines(info chess.BotInfo) []string {
+	name := info.FullName()
+	if name == "" {
+		name = "Unknown"
+	}
+
+	lines := []string{fmt.Sprintf("id name %v", name)}
+
+	if info.Author != "" {
+		lines = append(lines, fmt.Sprintf("id author %v", info.Author))
+	}
+
+	for _, option := range info.Options {
+		lines = append(lines, OptionLine(option))
+	}
+
+	return lines
+}
+
+// Returns the "option" line describing the option
+func OptionLine(option chess.BotOption) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "option name %v type %v", option.Name, option.Type)
+
+	switch option.Type {
+	case chess.CheckOption, chess.StringOption:
+		fmt.Fprintf(&sb, " default %v", defaultOrEmpty(option.Default))
+
+	case chess.SpinOption:
+		fmt.Fprintf(&sb, " default %v min %v max %v", option.Default, option.Min, option.Max)
+
+	case chess.ComboOption:
+		fmt.Fprintf(&sb, " default %v", option.Default)
+		for _, choice := range option.Choices {
+			fmt.Fprintf(&sb, " var %v", choice)
+		}
+	}
+
+	return sb.String()
+}
+
+// Returns "info string" lines announcing the engine, which Arena shows in its engine output window
+// when the engine is loaded
+func IdentificationInfoStrings(info chess.BotInfo, logoPath string) []string {
+	lines := []string{InfoString(info.FullName())}
+
+	if info.Author != "" {
+		lines[0] = InfoString(fmt.Sprintf("%v by %v", info.FullName(), info.Author))
+	}
+
+	if logoPath != "" {
+		lines = append(lines, InfoString(fmt.Sprintf("logo %v", logoPath)))
+	}
+
+	return lines
+}
+
+// Returns an "info string" line carrying free-form text
+// GUIs treat the rest of the line as the string, so line breaks are replaced with spaces
+func InfoString(text string) string {
+	text = strings.ReplaceAll(text, "\r", " ")
+	text = strings.ReplaceAll(text, "\n", " ")
+	return "info string " + text
+}
+
+// Returns the "bestmove" line for the move, including the move the engine expects in reply if
+// `ponderMove` is not nil so that the GUI can start pondering on it
+func BestMoveLine(move chess.Move, ponderMove *chess.Move) string {
+	if ponderMove == nil {
+		return fmt.Sprintf("bestmove %v", MoveString(move))
+	} else {
+		return fmt.Sprintf("bestmove %v ponder %v", MoveString(move), MoveString(*ponderMove))
+	}
+}
+
+// Returns the move in UCI notation, using "0000" for the null move sent when there are no legal moves
+func MoveString(move chess.Move) string {
+	if move == (chess.Move{}) {
+		return "0000"
+	}
+
+	return move.String()
+}
+
+// Image formats GUIs accept for engine logos, in order of preference
+var logoExtensions = []string{".bmp", ".png", ".jpg"}
+
+// Search for the engine logo GUIs display next to the engine name
+// Arena looks for an image with the same name as the engine executable in the same directory
+// (e.g. gogm.bmp for gogm.exe, ideally 100x50 pixels); a file called logo.bmp in that directory
+// is also accepted. Returns the path of the logo and whether one was found
+func FindLogo(executablePath string) (string, bool) {
+	directory := filepath.Dir(executablePath)
+	baseName := strings.TrimSuffix(filepath.Base(executablePath), filepath.Ext(executablePath))
+
+	for _, name := range []string{baseName, "logo"} {
+		for _, extension := range logoExtensions {
+			path := filepath.Join(directory, name+extension)
+
+			if stat, err := os.Stat(path); err == nil && !stat.IsDir() {
+				return path, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func defaultOrEmpty(value string) string {
+	if value == "" {
+		return "<empty>"
+	}
+
+	return value
+}
@@ -0,0 +1,277 @@
+package uci_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"gogm/uci"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIDLines(t *testing.T) {
+	assert := assert.New(t)
+
+	lines := uci.IDLines(chess.BotInfo{
+		Name:    "gogm",
+		Version: "1.0.0",
+		Author:  "sixthsurge",
+		Options: []chess.BotOption{
+			{Name: "Hash", Type: chess.SpinOption, Default: "16", Min: 1, Max: 1024},
+			{Name: "Style", Type: chess.ComboOption, Default: "Normal", Choices: []string{"Solid", "Normal"}},
+		},
+	})
+
+	assert.Equal([]string{
+		"id name gogm 1.0.0",
+		"id author sixthsurge",
+		"option name Hash type spin default 16 min 1 max 1024",
+		"option name Style type combo default Normal var Solid var Normal",
+	}, lines)
+}
+
+func TestBestMoveLine(t *testing.T) {
+	assert := assert.New(t)
+
+	move := chess.Move{Source: chess.E2, Destination: chess.E4}
+	ponderMove := chess.Move{Source: chess.E7, Destination: chess.E5}
+
+	assert.Equal("bestmove e2e4", uci.BestMoveLine(move, nil))
+	assert.Equal("bestmove e2e4 ponder e7e5", uci.BestMoveLine(move, &ponderMove))
+	assert.Equal("bestmove 0000", uci.BestMoveLine(chess.Move{}, nil))
+}
+
+func TestFindLogo(t *testing.T) {
+	assert := assert.New(t)
+	directory := t.TempDir()
+	executablePath := filepath.Join(directory, "gogm.exe")
+
+	_, found := uci.FindLogo(executablePath)
+	assert.False(found)
+
+	assert.Nil(os.WriteFile(filepath.Join(directory, "logo.png"), nil, 0644))
+	path, found := uci.FindLogo(executablePath)
+	assert.True(found)
+	assert.Equal(filepath.Join(directory, "logo.png"), path)
+
+	assert.Nil(os.WriteFile(filepath.Join(directory, "gogm.bmp"), nil, 0644))
+	path, _ = uci.FindLogo(executablePath)
+	assert.Equal(filepath.Join(directory, "gogm.bmp"), path)
+}
+
+func TestParsePosition(t *testing.T) {
+	assert := assert.New(t)
+
+	board, err := uci.ParsePosition(strings.Fields("startpos moves e2e4 e7e5 g1f3"))
+	assert.Nil(err)
+	assert.Equal("rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq", board.Fen())
+
+	board, err = uci.ParsePosition(strings.Fields("fen 8/8/8/4k3/8/8/4P3/4K3 w - - 0 1 moves e2e4"))
+	assert.Nil(err)
+	assert.Equal("8/8/8/4k3/4P3/8/8/4K3 b -", board.Fen())
+
+	board, err = uci.ParsePosition(strings.Fields("fen 8/8/8/4k3/8/8/4P3/4K3 w - - 0 1"))
+	assert.Nil(err)
+	assert.Equal("8/8/8/4k3/8/8/4P3/4K3 w -", board.Fen())
+
+	_, err = uci.ParsePosition(strings.Fields("startpos moves e2e5"))
+	assert.NotNil(err)
+
+	_, err = uci.ParsePosition(strings.Fields("somewhere"))
+	assert.NotNil(err)
+
+	_, err = uci.ParsePosition(nil)
+	assert.NotNil(err)
+}
+
+func TestParseGo(t *testing.T) {
+	assert := assert.New(t)
+
+	limits, ponder, err := uci.ParseGo(strings.Fields("ponder wtime 60000 btime 30000 winc 1000 binc 500 movestogo 20"))
+	assert.Nil(err)
+	assert.True(ponder)
+	assert.Equal(chess.SearchLimits{
+		WhiteTime:      time.Minute,
+		BlackTime:      30 * time.Second,
+		WhiteIncrement: time.Second,
+		BlackIncrement: 500 * time.Millisecond,
+		MovesToGo:      20,
+	}, limits)
+
+	limits, ponder, err = uci.ParseGo(strings.Fields("searchmoves e2e4 d2d4 depth 7 nodes 10000 movetime 250"))
+	assert.Nil(err)
+	assert.False(ponder)
+	assert.Equal(chess.SearchLimits{Depth: 7, Nodes: 10000, MoveTime: 250 * time.Millisecond}, limits)
+
+	limits, _, err = uci.ParseGo(strings.Fields("infinite"))
+	assert.Nil(err)
+	assert.Equal(chess.SearchLimits{Infinite: true}, limits)
+
+	_, _, err = uci.ParseGo(strings.Fields("depth"))
+	assert.NotNil(err)
+
+	_, _, err = uci.ParseGo(strings.Fields("wtime soon"))
+	assert.NotNil(err)
+}
+
+func TestInfoLine(t *testing.T) {
+	assert := assert.New(t)
+
+	info := chess.SearchInfo{
+		Depth:              5,
+		SelectiveDepth:     9,
+		Score:              0.345,
+		PrincipalVariation: []chess.Move{{Source: chess.E2, Destination: chess.E4}, {Source: chess.E7, Destination: chess.E5}},
+		Nodes:              20000,
+		Time:               2 * time.Second,
+		HashFull:           12,
+	}
+
+	assert.Equal("info depth 5 seldepth 9 score cp 35 nodes 20000 nps 10000 time 2000 hashfull 12 pv e2e4 e7e5", uci.InfoLine(info))
+	assert.Equal("info depth 1 score cp -100", uci.InfoLine(chess.SearchInfo{Depth: 1, Score: -1.0}))
+}
+
+func TestScoreString(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("cp 0", uci.ScoreString(0.0))
+	assert.Equal("mate 2", uci.ScoreString(chess.MateInPly(3)))
+	assert.Equal("mate -1", uci.ScoreString(chess.MatedInPly(2)))
+}
+
+func TestParseSetOption(t *testing.T) {
+	assert := assert.New(t)
+
+	name, value, err := uci.ParseSetOption(strings.Fields("name Hash value 64"))
+	assert.Nil(err)
+	assert.Equal("Hash", name)
+	assert.Equal("64", value)
+
+	name, value, err = uci.ParseSetOption(strings.Fields("name Clear Hash"))
+	assert.Nil(err)
+	assert.Equal("Clear Hash", name)
+	assert.Equal("", value)
+
+	name, value, err = uci.ParseSetOption(strings.Fields("name Eval File value my params.json"))
+	assert.Nil(err)
+	assert.Equal("Eval File", name)
+	assert.Equal("my params.json", value)
+
+	_, _, err = uci.ParseSetOption(strings.Fields("Hash 64"))
+	assert.NotNil(err)
+}
+
+func TestPositionLine(t *testing.T) {
+	board, _ := chess.LoadFen("rnbqkbnr/ppp1pppp/8/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 3")
+	assert.Equal(t, "position fen rnbqkbnr/ppp1pppp/8/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 1", uci.PositionLine(board))
+
+	board, _ = chess.LoadFen("8/8/4k3/8/8/4K3/4P3/8 b - - 12 60")
+	assert.Equal(t, "position fen 8/8/4k3/8/8/4K3/4P3/8 b - - 12 1", uci.PositionLine(board))
+}
+
+func TestGoLine(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("go", uci.GoLine(chess.SearchLimits{}))
+	assert.Equal("go infinite", uci.GoLine(chess.SearchLimits{Infinite: true}))
+	assert.Equal("go depth 7 nodes 10000", uci.GoLine(chess.SearchLimits{Depth: 7, Nodes: 10000}))
+
+	limits := chess.SearchLimits{
+		WhiteTime:      time.Minute,
+		BlackTime:      30 * time.Second,
+		WhiteIncrement: time.Second,
+		BlackIncrement: 500 * time.Millisecond,
+		MovesToGo:      20,
+	}
+	line := uci.GoLine(limits)
+	assert.Equal("go wtime 60000 btime 30000 winc 1000 binc 500 movestogo 20", line)
+
+	parsed, _, err := uci.ParseGo(strings.Fields(line)[1:])
+	assert.Nil(err)
+	assert.Equal(limits, parsed)
+}
+
+func TestParseInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	info, ok := uci.ParseInfo(strings.Fields("depth 12 seldepth 18 multipv 1 score cp -31 upperbound nodes 80000 nps 400000 hashfull 12 time 200 pv e2e4 e7e5 g1f3"))
+	assert.True(ok)
+	assert.Equal(chess.SearchInfo{
+		Depth:          12,
+		SelectiveDepth: 18,
+		Score:          -0.31,
+		BestMove:       chess.Move{Source: chess.E2, Destination: chess.E4},
+		PrincipalVariation: []chess.Move{
+			{Source: chess.E2, Destination: chess.E4},
+			{Source: chess.E7, Destination: chess.E5},
+			{Source: chess.G1, Destination: chess.F3},
+		},
+		Nodes:    80000,
+		Time:     200 * time.Millisecond,
+		HashFull: 12,
+	}, info)
+
+	info, ok = uci.ParseInfo(strings.Fields("depth 5 score mate 2 pv d1h5"))
+	assert.True(ok)
+	assert.Equal(chess.MateInPly(3), info.Score)
+
+	info, ok = uci.ParseInfo(strings.Fields("depth 5 score mate -1"))
+	assert.True(ok)
+	assert.Equal(chess.MatedInPly(2), info.Score)
+
+	_, ok = uci.ParseInfo(strings.Fields("string depth is not a score"))
+	assert.False(ok)
+	_, ok = uci.ParseInfo(strings.Fields("depth 9 currmove e2e4 currmovenumber 1"))
+	assert.False(ok)
+	_, ok = uci.ParseInfo(strings.Fields("depth 9 multipv 2 score cp 10 pv d2d4"))
+	assert.False(ok)
+}
+
+func TestParseBestMove(t *testing.T) {
+	assert := assert.New(t)
+
+	move, ponderMove, err := uci.ParseBestMove(strings.Fields("e2e4 ponder e7e5"))
+	assert.Nil(err)
+	assert.Equal(chess.Move{Source: chess.E2, Destination: chess.E4}, move)
+	assert.Equal(&chess.Move{Source: chess.E7, Destination: chess.E5}, ponderMove)
+
+	move, ponderMove, err = uci.ParseBestMove(strings.Fields("(none)"))
+	assert.Nil(err)
+	assert.Equal(chess.Move{}, move)
+	assert.Nil(ponderMove)
+
+	_, _, err = uci.ParseBestMove(nil)
+	assert.NotNil(err)
+}
+
+func TestParseOption(t *testing.T) {
+	assert := assert.New(t)
+
+	option, err := uci.ParseOption(strings.Fields("name Hash type spin default 16 min 1 max 33554432"))
+	assert.Nil(err)
+	assert.Equal(chess.BotOption{Name: "Hash", Type: chess.SpinOption, Default: "16", Min: 1, Max: 33554432}, option)
+
+	option, err = uci.ParseOption(strings.Fields("name Analysis Contempt type combo default Both var Off var White var Black var Both"))
+	assert.Nil(err)
+	assert.Equal(chess.BotOption{Name: "Analysis Contempt", Type: chess.ComboOption, Default: "Both", Choices: []string{"Off", "White", "Black", "Both"}}, option)
+
+	option, err = uci.ParseOption(strings.Fields("name EvalFile type string default <empty>"))
+	assert.Nil(err)
+	assert.Equal(chess.BotOption{Name: "EvalFile", Type: chess.StringOption}, option)
+
+	for _, option := range []chess.BotOption{
+		{Name: "Clear Hash", Type: chess.ButtonOption},
+		{Name: "Ponder", Type: chess.CheckOption, Default: "false"},
+	} {
+		parsed, err := uci.ParseOption(strings.Fields(uci.OptionLine(option))[1:])
+		assert.Nil(err)
+		assert.Equal(option, parsed)
+	}
+
+	_, err = uci.ParseOption(strings.Fields("name Threads type dial"))
+	assert.NotNil(err)
+	_, err = uci.ParseOption(strings.Fields("type spin"))
+	assert.NotNil(err)
+}
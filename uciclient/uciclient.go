@@ -0,0 +1,415 @@
+// Package uciclient runs external engines that speak the Universal Chess Interface, such as
+// Stockfish, as bots, so that they can play gogm's bots in the GUI or analyse with it. The engine
+// runs as a child process, sent commands on its standard input and answering on its standard output
+// https://www.wbec-ridderkerk.nl/html/UCIProtocol.html
+package uciclient
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"gogm/chess"
+	"gogm/uci"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// How long the engine has to answer "uci" and "isready", which it should do at once apart from
+// allocating its tables
+const responseTimeout = 10 * time.Second
+
+// How long the engine has to exit after "quit" before it is killed
+const quitTimeout = time.Second
+
+// Time Think spends on each move if no limit has been set
+const defaultMoveTime = time.Second
+
+// An external UCI engine running as a bot
+// The engine must be closed with Close once it is no longer needed. If it exits or stops
+// responding, it plays the null move from then on and Err returns the reason
+type Engine struct {
+	process *exec.Cmd
+
+	// Commands are sent by the goroutine thinking and by Stop
+	inputLock sync.Mutex
+	input     io.WriteCloser
+	err       error
+
+	// Lines the engine has written, closed once it exits
+	lines chan string
+
+	info    chess.BotInfo
+	options chess.OptionRegistry
+
+	// Limits for Think
+	moveTime time.Duration
+	maxDepth int
+	maxNodes uint64
+
+	searchInfoCallback chess.SearchInfoCallback
+
+	// Whether the engine is searching, so that Stop only interrupts a search
+	searching atomic.Bool
+}
+
+// Start the engine executable at the path with the arguments and wait for it to identify itself
+// and list its options
+func Start(path string, args ...string) (*Engine, error) {
+	process := exec.Command(path, args...)
+
+	input, err := process.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := process.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := process.Start(); err != nil {
+		return nil, err
+	}
+
+	engine := &Engine{process: process, input: input, lines: make(chan string, 64)}
+
+	go func() {
+		defer close(engine.lines)
+
+		scanner := bufio.NewScanner(output)
+		for scanner.Scan() {
+			engine.lines <- scanner.Text()
+		}
+	}()
+
+	if err := engine.identify(); err != nil {
+		engine.Close()
+		return nil, errors.New(fmt.Sprintf("%v: %v", path, err))
+	}
+
+	return engine, nil
+}
+
+// Send "uci" and read the engine's name, author and options up to "uciok"
+func (engine *Engine) identify() error {
+	if err := engine.send("uci"); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(responseTimeout)
+
+	for {
+		fields, err := engine.readLine(time.Until(deadline))
+		if err != nil {
+			return err
+		}
+
+		switch fields[0] {
+		case "id":
+			if len(fields) < 2 {
+				continue
+			}
+
+			value := strings.Join(fields[2:], " ")
+			switch fields[1] {
+			case "name":
+				engine.info.Name = value
+			case "author":
+				engine.info.Author = value
+			}
+
+		case "option":
+			// Options the engine describes in a way that cannot be read are left out
+			if option, err := uci.ParseOption(fields[1:]); err == nil {
+				engine.addOption(option)
+			}
+
+		case "uciok":
+			engine.info.Options = engine.options.BotOptions()
+			return nil
+		}
+	}
+}
+
+// Register the option so that SetOption checks values for it before sending them to the engine
+func (engine *Engine) addOption(option chess.BotOption) {
+	send := func(value string) {
+		engine.send(fmt.Sprintf("setoption name %v value %v", option.Name, value))
+	}
+
+	switch option.Type {
+	case chess.CheckOption:
+		engine.options.AddCheck(option.Name, option.Default == "true", func(value bool) {
+			send(strconv.FormatBool(value))
+		})
+	case chess.SpinOption:
+		defaultValue, _ := strconv.Atoi(option.Default)
+		engine.options.AddSpin(option.Name, defaultValue, option.Min, option.Max, func(value int) {
+			send(strconv.Itoa(value))
+		})
+	case chess.ComboOption:
+		engine.options.AddCombo(option.Name, option.Default, option.Choices, send)
+	case chess.StringOption:
+		engine.options.AddString(option.Name, option.Default, func(value string) error {
+			send(value)
+			return nil
+		})
+	case chess.ButtonOption:
+		engine.options.AddButton(option.Name, func() {
+			engine.send(fmt.Sprintf("setoption name %v", option.Name))
+		})
+	}
+}
+
+// Send the lines to the engine, returning the error that ended the engine, if any
+func (engine *Engine) send(lines ...string) error {
+	engine.inputLock.Lock()
+	defer engine.inputLock.Unlock()
+
+	if engine.err != nil {
+		return engine.err
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(engine.input, line); err != nil {
+			engine.err = err
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Record that the engine can no longer be used, unless it already has been
+func (engine *Engine) fail(err error) {
+	engine.inputLock.Lock()
+	defer engine.inputLock.Unlock()
+
+	if engine.err == nil {
+		engine.err = err
+	}
+}
+
+// Returns the error that ended the engine, or nil if it is still running
+func (engine *Engine) Err() error {
+	engine.inputLock.Lock()
+	defer engine.inputLock.Unlock()
+
+	return engine.err
+}
+
+// Returns the fields of the next line the engine writes that is not blank, waiting at most the
+// timeout if it is positive
+func (engine *Engine) readLine(timeout time.Duration) ([]string, error) {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		select {
+		case line, ok := <-engine.lines:
+			if !ok {
+				return nil, errors.New("engine exited")
+			}
+
+			if fields := strings.Fields(line); len(fields) > 0 {
+				return fields, nil
+			}
+
+		case <-expired:
+			return nil, errors.New("engine stopped responding")
+		}
+	}
+}
+
+// Send "isready" and wait for "readyok", by which the engine has carried out the commands before
+func (engine *Engine) waitUntilReady() error {
+	if err := engine.send("isready"); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(responseTimeout)
+
+	for {
+		fields, err := engine.readLine(time.Until(deadline))
+		if err != nil {
+			engine.fail(err)
+			return err
+		}
+
+		if fields[0] == "readyok" {
+			return nil
+		}
+	}
+}
+
+// Ask the engine to quit, killing it if it has not exited within a second, and wait for it to
+// exit. This must not be called while the engine is thinking
+func (engine *Engine) Close() error {
+	engine.send("quit")
+	engine.fail(errors.New("engine closed"))
+	engine.input.Close()
+
+	// The engine may block writing its output until it is read
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		for range engine.lines {
+		}
+	}()
+
+	select {
+	case <-exited:
+	case <-time.After(quitTimeout):
+		engine.process.Process.Kill()
+		<-exited
+	}
+
+	return engine.process.Wait()
+}
+
+// Returns the name and author the engine gave, and its options. Engines do not give their version
+// separately from their name
+func (engine *Engine) Info() chess.BotInfo {
+	return engine.info
+}
+
+// Set one of the options listed in Info, returning an error if there is no such option, the value
+// is not valid for it, or the engine does not respond
+// This must not be called while the engine is thinking
+func (engine *Engine) SetOption(name string, value string) error {
+	if err := engine.options.Set(name, value); err != nil {
+		return err
+	}
+
+	return engine.waitUntilReady()
+}
+
+// Set the function called with the progress of the engine's search as it reports it, or nil to
+// stop reporting progress. Only the engine's best line is reported
+func (engine *Engine) SetSearchInfoCallback(callback chess.SearchInfoCallback) {
+	engine.searchInfoCallback = callback
+}
+
+// Make Think search each move for the given time, or 0 to go back to the default of a second unless
+// a depth or node limit is set
+func (engine *Engine) SetMoveTime(moveTime time.Duration) {
+	engine.moveTime = moveTime
+}
+
+// Limit the depth Think searches to (ply), or 0 for no limit
+func (engine *Engine) SetMaxDepth(depth int) {
+	engine.maxDepth = depth
+}
+
+// Limit the number of nodes Think searches for each move, or 0 for no limit
+func (engine *Engine) SetMaxNodes(nodes uint64) {
+	engine.maxNodes = nodes
+}
+
+// Tell the engine that a new game is starting
+func (engine *Engine) NewGame() {
+	if engine.send("ucinewgame") == nil {
+		engine.waitUntilReady()
+	}
+}
+
+// Clear the engine's transposition table with its Clear Hash button, or if it has none, by telling
+// it that a new game is starting
+func (engine *Engine) ClearHash() {
+	if engine.options.Has("Clear Hash") {
+		engine.SetOption("Clear Hash", "")
+	} else {
+		engine.NewGame()
+	}
+}
+
+// Ask the engine to stop searching and play the best move it has found
+// This is safe to call from another goroutine while the engine is thinking
+func (engine *Engine) Stop() {
+	if engine.searching.Load() {
+		engine.send("stop")
+	}
+}
+
+// Choose a move for the position within the limits set by SetMoveTime, SetMaxDepth and SetMaxNodes
+func (engine *Engine) Think(board *chess.Board) chess.Move {
+	move, _ := engine.ThinkContext(context.Background(), board, chess.SearchLimits{})
+	return move
+}
+
+// Choose a move for the position within the limits
+func (engine *Engine) ThinkWithLimits(board *chess.Board, limits chess.SearchLimits) chess.Move {
+	move, _ := engine.ThinkContext(context.Background(), board, limits)
+	return move
+}
+
+// Choose a move for the position within the limits, or if the context is cancelled first, the best
+// move the engine has found so far, and return it with the last progress the engine reported
+// Empty limits are replaced with those set for Think
+func (engine *Engine) ThinkContext(ctx context.Context, board *chess.Board, limits chess.SearchLimits) (chess.Move, chess.SearchInfo) {
+	if limits == (chess.SearchLimits{}) {
+		limits = chess.SearchLimits{MoveTime: engine.moveTime, Depth: engine.maxDepth, Nodes: engine.maxNodes}
+		if limits == (chess.SearchLimits{}) {
+			limits.MoveTime = defaultMoveTime
+		}
+	}
+
+	start := time.Now()
+
+	engine.searching.Store(true)
+	defer engine.searching.Store(false)
+
+	if err := engine.send(uci.PositionLine(board), uci.GoLine(limits)); err != nil {
+		return chess.Move{}, chess.SearchInfo{}
+	}
+
+	stop := context.AfterFunc(ctx, engine.Stop)
+	defer stop()
+
+	var info chess.SearchInfo
+
+	for {
+		fields, err := engine.readLine(0)
+		if err != nil {
+			engine.fail(err)
+			return chess.Move{}, info
+		}
+
+		switch fields[0] {
+		case "info":
+			if lineInfo, ok := uci.ParseInfo(fields[1:]); ok {
+				info = lineInfo
+				if engine.searchInfoCallback != nil {
+					engine.searchInfoCallback(info)
+				}
+			}
+
+		case "bestmove":
+			move, _, err := uci.ParseBestMove(fields[1:])
+			if err != nil {
+				engine.fail(errors.New(fmt.Sprintf("engine sent a bad move: %v", err)))
+				return chess.Move{}, info
+			}
+
+			info.BestMove = move
+			if len(info.PrincipalVariation) == 0 || info.PrincipalVariation[0] != move {
+				info.PrincipalVariation = []chess.Move{move}
+			}
+			if info.Time == 0 {
+				info.Time = time.Since(start)
+			}
+
+			return move, info
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package uciclient_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"gogm/chess"
+	"gogm/uci"
+	"gogm/uciclient"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Set in the environment of the test binary when it is started as the engine
+const fakeEngineVariable = "UCICLIENT_FAKE_ENGINE"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeEngineVariable) != "" {
+		runFakeEngine()
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// Plays the first legal move, reporting the depth it was asked to search to and a score of its
+// Hash option in centipawns. Infinite searches wait for "stop", and the Crash button exits
+func runFakeEngine() {
+	board, _ := chess.LoadFen(chess.StartingPositionFen)
+	hash := 16
+	stopped := make(chan struct{}, 1)
+
+	// Lines are read in the background so that "stop" is seen during a search
+	commands := make(chan []string)
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) > 0 && fields[0] == "stop" {
+				// A stop while one is already waiting is ignored
+				select {
+				case stopped <- struct{}{}:
+				default:
+				}
+			} else if len(fields) > 0 {
+				commands <- fields
+			}
+		}
+		close(commands)
+	}()
+
+	for fields := range commands {
+		switch fields[0] {
+		case "uci":
+			fmt.Println("id name Fake Engine")
+			fmt.Println("id author Tester")
+			fmt.Println("option name Hash type spin default 16 min 1 max 1024")
+			fmt.Println("option name Style type combo default Normal var Solid var Normal")
+			fmt.Println("option name Crash type button")
+			fmt.Println("uciok")
+
+		case "isready":
+			fmt.Println("readyok")
+
+		case "setoption":
+			name, value, _ := uci.ParseSetOption(fields[1:])
+			switch name {
+			case "Hash":
+				fmt.Sscan(value, &hash)
+			case "Crash":
+				return
+			}
+
+		case "position":
+			board, _ = uci.ParsePosition(fields[1:])
+
+		case "go":
+			limits, _, _ := uci.ParseGo(fields[1:])
+			if limits.Infinite {
+				<-stopped
+			}
+
+			move := board.GetLegalMoves(false)[0]
+			fmt.Printf("info depth %v score cp %v nodes 10 pv %v\n", limits.Depth, hash, move)
+			fmt.Printf("bestmove %v\n", move)
+
+		case "quit":
+			return
+		}
+	}
+}
+
+func startFakeEngine(t *testing.T) *uciclient.Engine {
+	t.Setenv(fakeEngineVariable, "1")
+
+	engine, err := uciclient.Start(os.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { engine.Close() })
+
+	return engine
+}
+
+func TestStartIdentifiesEngine(t *testing.T) {
+	engine := startFakeEngine(t)
+
+	assert.Equal(t, chess.BotInfo{
+		Name:   "Fake Engine",
+		Author: "Tester",
+		Options: []chess.BotOption{
+			{Name: "Hash", Type: chess.SpinOption, Default: "16", Min: 1, Max: 1024},
+			{Name: "Style", Type: chess.ComboOption, Default: "Normal", Choices: []string{"Solid", "Normal"}},
+			{Name: "Crash", Type: chess.ButtonOption},
+		},
+	}, engine.Info())
+}
+
+func TestThink(t *testing.T) {
+	assert := assert.New(t)
+
+	engine := startFakeEngine(t)
+	board, _ := chess.LoadFen("4k3/8/8/8/8/8/8/4K2R w K - 0 1")
+	expected := board.GetLegalMoves(false)[0]
+
+	var reported []chess.SearchInfo
+	engine.SetSearchInfoCallback(func(info chess.SearchInfo) {
+		reported = append(reported, info)
+	})
+
+	move, info := engine.ThinkContext(context.Background(), board, chess.SearchLimits{Depth: 3})
+	assert.Equal(expected, move)
+	assert.Equal(3, info.Depth)
+	assert.Equal(0.16, info.Score)
+	assert.Equal(expected, info.BestMove)
+	assert.Equal([]chess.Move{expected}, info.PrincipalVariation)
+	assert.Equal(10, int(info.Nodes))
+	assert.Greater(info.Time, time.Duration(0))
+
+	// The engine reported its progress once, without the time taken
+	assert.Len(reported, 1)
+	assert.Equal(0.16, reported[0].Score)
+
+	// Think searches within the limits set for it
+	engine.SetMaxDepth(5)
+	assert.Equal(expected, engine.Think(board))
+	assert.Equal(5, reported[len(reported) - 1].Depth)
+}
+
+func TestThinkStopsOnCancel(t *testing.T) {
+	engine := startFakeEngine(t)
+	board, _ := chess.LoadFen(chess.StartingPositionFen)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20 * time.Millisecond)
+	defer cancel()
+
+	move, _ := engine.ThinkContext(ctx, board, chess.SearchLimits{Infinite: true})
+	assert.Equal(t, board.GetLegalMoves(false)[0], move)
+}
+
+func TestSetOption(t *testing.T) {
+	assert := assert.New(t)
+
+	engine := startFakeEngine(t)
+	board, _ := chess.LoadFen(chess.StartingPositionFen)
+
+	assert.Nil(engine.SetOption("hash", "64"))
+	assert.Nil(engine.SetOption("Style", "solid"))
+	assert.NotNil(engine.SetOption("Hash", "0"))
+	assert.NotNil(engine.SetOption("Threads", "2"))
+
+	_, info := engine.ThinkContext(context.Background(), board, chess.SearchLimits{Depth: 1})
+	assert.Equal(0.64, info.Score)
+}
+
+func TestEngineExit(t *testing.T) {
+	assert := assert.New(t)
+
+	engine := startFakeEngine(t)
+	board, _ := chess.LoadFen(chess.StartingPositionFen)
+
+	assert.NotNil(engine.SetOption("Crash", ""))
+	assert.NotNil(engine.Err())
+	assert.Equal(chess.Move{}, engine.Think(board))
+}
+
+func TestStartMissingEngine(t *testing.T) {
+	_, err := uciclient.Start("/nonexistent/engine")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,276 @@
+package main
+
+// Runs the bot as a UCI engine, reading commands from standard input and answering on standard
+// output, so that it can be loaded into GUIs such as CuteChess, Arena and BanksiaGUI, or play on
+// lichess through lichess-bot
+// https://www.wbec-ridderkerk.nl/html/UCIProtocol.html
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"gogm/botv1"
+	"gogm/chess"
+	"gogm/uci"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+func main() {
+    evalParamsPath := flag.String("eval", "", "JSON file of evaluation parameters to play with instead of the built-in ones")
+    flag.Parse()
+
+    bot, err := botv1.New(botv1.Options { EvalParamsPath: *evalParamsPath })
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(2)
+    }
+
+    newEngine(bot, os.Stdout).run(os.Stdin)
+}
+
+type engine struct {
+    bot   *botv1.BotV1
+    board *chess.Board
+
+    // Lines are written by both the goroutine reading commands and the one searching
+    outputLock sync.Mutex
+    output     io.Writer
+
+    // Options of the engine itself rather than the bot, listed after the bot's
+    options chess.OptionRegistry
+
+    // Search running in the background, or nil
+    search *search
+}
+
+// A search running in the background, which sends its best move when it finishes
+type search struct {
+    cancel context.CancelFunc
+
+    // Closed to let the search send its best move. Pondering and infinite searches hold it back
+    // until the GUI sends "stop" or "ponderhit", even if they finish before
+    release chan struct{}
+
+    // Closed once the search has finished and sent its best move
+    done chan struct{}
+
+    // Whether the search is pondering on the move the engine expects, and the limits to search
+    // within if the move is played
+    pondering bool
+    limits    chess.SearchLimits
+
+    // Whether the best move is thrown away rather than sent, set before the release
+    discarded bool
+}
+
+func newEngine(bot *botv1.BotV1, output io.Writer) *engine {
+    engine := &engine { bot: bot, output: output }
+    engine.board, _ = chess.LoadFen(chess.StartingPositionFen)
+
+    // The GUI decides when to ponder, so there is nothing to set
+    engine.options.AddCheck("Ponder", false, func(bool) {})
+
+    bot.SetSearchInfoCallback(func(info chess.SearchInfo) {
+        engine.send(uci.InfoLine(info))
+    })
+    bot.SetCommentCallback(func(move chess.Move, comment string) {
+        engine.send(uci.InfoString(comment))
+    })
+
+    return engine
+}
+
+// Respond to the commands read from the input until it ends or "quit" is read
+func (engine *engine) run(input io.Reader) {
+    defer engine.stopSearch()
+
+    scanner := bufio.NewScanner(input)
+    for scanner.Scan() {
+        fields := strings.Fields(scanner.Text())
+        if len(fields) == 0 {
+            continue
+        }
+
+        command, args := fields[0], fields[1:]
+
+        switch command {
+        case "uci":
+            engine.identify()
+
+        case "isready":
+            // Allocate the transposition table now rather than in the first search
+            engine.bot.Init()
+            engine.send("readyok")
+
+        case "setoption":
+            if err := engine.setOption(args); err != nil {
+                engine.sendError(err)
+            }
+
+        case "ucinewgame":
+            engine.stopSearch()
+            engine.bot.NewGame()
+
+        case "position":
+            board, err := uci.ParsePosition(args)
+            if err != nil {
+                engine.sendError(err)
+                continue
+            }
+            engine.board = board
+
+        case "go":
+            limits, ponder, err := uci.ParseGo(args)
+            if err != nil {
+                engine.sendError(err)
+                continue
+            }
+            engine.stopSearch()
+            engine.startSearch(limits, ponder)
+
+        case "stop":
+            engine.stopSearch()
+
+        case "ponderhit":
+            engine.ponderHit()
+
+        case "quit":
+            return
+
+        case "debug", "register":
+
+        default:
+            engine.sendError(errors.New(fmt.Sprintf("unknown command: %v", command)))
+        }
+    }
+}
+
+// Send the lines to the GUI
+func (engine *engine) send(lines ...string) {
+    engine.outputLock.Lock()
+    defer engine.outputLock.Unlock()
+
+    for _, line := range lines {
+        fmt.Fprintln(engine.output, line)
+    }
+}
+
+func (engine *engine) sendError(err error) {
+    engine.send(uci.InfoString(fmt.Sprintf("error: %v", err)))
+}
+
+// Respond to "uci" with the engine's name, author and options
+func (engine *engine) identify() {
+    info := engine.bot.Info()
+    info.Options = append(info.Options, engine.options.BotOptions()...)
+
+    logoPath := ""
+    if executablePath, err := os.Executable(); err == nil {
+        logoPath, _ = uci.FindLogo(executablePath)
+    }
+
+    engine.send(uci.IdentificationInfoStrings(info, logoPath)...)
+    engine.send(uci.IDLines(info)...)
+    engine.send("uciok")
+}
+
+// Set an option of the engine or the bot from the arguments of "setoption". Option names are not
+// case sensitive
+func (engine *engine) setOption(args []string) error {
+    name, value, err := uci.ParseSetOption(args)
+    if err != nil {
+        return err
+    }
+
+    if engine.options.Has(name) {
+        return engine.options.Set(name, value)
+    }
+
+    // Options such as the hash size cannot change during a search
+    engine.stopSearch()
+    return engine.bot.SetOption(name, value)
+}
+
+// Start searching the current position in the background within the limits, or if pondering,
+// until the GUI sends "ponderhit" or "stop"
+func (engine *engine) startSearch(limits chess.SearchLimits, ponder bool) {
+    ctx, cancel := context.WithCancel(context.Background())
+
+    search := &search {
+        cancel:    cancel,
+        release:   make(chan struct{}),
+        done:      make(chan struct{}),
+        pondering: ponder,
+        limits:    limits,
+    }
+
+    searchLimits := limits
+    if ponder {
+        searchLimits = chess.SearchLimits { Infinite: true }
+    } else if !limits.Infinite {
+        close(search.release)
+    }
+
+    // The bot makes moves on the board while searching, so gets its own copy
+    board := engine.board.Copy()
+    engine.search = search
+
+    go func() {
+        defer close(search.done)
+
+        move, info := engine.bot.ThinkContext(ctx, &board, searchLimits)
+
+        <-search.release
+        if !search.discarded {
+            engine.send(uci.BestMoveLine(move, expectedReply(move, info)))
+        }
+    }()
+}
+
+// Stop the search running in the background, if there is one, once it has sent its best move
+func (engine *engine) stopSearch() {
+    search := engine.search
+    if search == nil {
+        return
+    }
+
+    search.cancel()
+
+    select {
+    case <-search.release:
+    default:
+        close(search.release)
+    }
+
+    <-search.done
+    engine.search = nil
+}
+
+// The move the engine was pondering on was played, so search within the limits of the "go ponder"
+// command. The search starts again, which the transposition table filled while pondering makes
+// quick
+func (engine *engine) ponderHit() {
+    search := engine.search
+    if search == nil || !search.pondering {
+        return
+    }
+
+    search.discarded = true
+    engine.stopSearch()
+    engine.startSearch(search.limits, false)
+}
+
+// Returns the move the engine expects in reply to its move, for the GUI to ponder on, or nil if the
+// search did not see that far
+func expectedReply(move chess.Move, info chess.SearchInfo) *chess.Move {
+    if len(info.PrincipalVariation) < 2 || info.PrincipalVariation[0] != move {
+        return nil
+    }
+
+    return &info.PrincipalVariation[1]
+}
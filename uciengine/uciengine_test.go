@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"gogm/botv1"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Position where white mates in one with Ra8#, which the bot finds in its first iteration
+const mateInOne = "position fen 7k/8/6K1/8/8/8/8/R7 w - - 0 1"
+
+// Output of the engine, which is written by the goroutine searching while the test reads it
+type engineOutput struct {
+	lock   sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (output *engineOutput) Write(p []byte) (int, error) {
+	output.lock.Lock()
+	defer output.lock.Unlock()
+
+	return output.buffer.Write(p)
+}
+
+// Returns the lines written so far that start with `prefix`
+func (output *engineOutput) lines(prefix string) []string {
+	output.lock.Lock()
+	defer output.lock.Unlock()
+
+	var lines []string
+	for _, line := range strings.Split(output.buffer.String(), "\n") {
+		if strings.HasPrefix(line, prefix) {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// Wait until a line starting with `prefix` has been written, failing the test after a few seconds
+func (output *engineOutput) waitFor(t *testing.T, prefix string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for len(output.lines(prefix)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("no line starting with %q", prefix)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// An engine reading the commands written to `commands`, running until it is closed
+type scriptedEngine struct {
+	commands *io.PipeWriter
+	output   *engineOutput
+	finished chan struct{}
+}
+
+func startEngine(t *testing.T) *scriptedEngine {
+	bot, err := botv1.New(botv1.Options{})
+	assert.Nil(t, err)
+
+	input, commands := io.Pipe()
+	scripted := &scriptedEngine{commands: commands, output: &engineOutput{}, finished: make(chan struct{})}
+
+	go func() {
+		defer close(scripted.finished)
+		newEngine(bot, scripted.output).run(input)
+	}()
+
+	return scripted
+}
+
+func (scripted *scriptedEngine) send(lines ...string) {
+	for _, line := range lines {
+		fmt.Fprintln(scripted.commands, line)
+	}
+}
+
+// Close the input and wait for the engine to stop its search and return
+func (scripted *scriptedEngine) quit() {
+	scripted.commands.Close()
+	<-scripted.finished
+}
+
+// The search finishes long before the GUI sends "stop", and the best move waits for it
+func TestBestMoveHeldUntilStop(t *testing.T) {
+	for _, command := range []string{"go infinite", "go ponder depth 1"} {
+		engine := startEngine(t)
+		engine.send(mateInOne, command)
+
+		engine.output.waitFor(t, "info depth 1 ")
+		time.Sleep(50 * time.Millisecond)
+		assert.Empty(t, engine.output.lines("bestmove"), command)
+
+		engine.send("stop")
+		engine.output.waitFor(t, "bestmove")
+		engine.quit()
+
+		assert.Equal(t, []string{"bestmove a1a8"}, engine.output.lines("bestmove"), command)
+	}
+}
+
+// The best move of the search started by "ponderhit" is sent, and the one of the search that was
+// pondering is not
+func TestPonderHit(t *testing.T) {
+	engine := startEngine(t)
+	engine.send(mateInOne, "go ponder depth 1")
+
+	engine.output.waitFor(t, "info depth 1 ")
+	engine.send("ponderhit")
+	engine.output.waitFor(t, "bestmove")
+
+	// Nor is a second best move sent when the engine stops
+	time.Sleep(50 * time.Millisecond)
+	engine.send("stop")
+	engine.quit()
+
+	assert.Equal(t, []string{"bestmove a1a8"}, engine.output.lines("bestmove"))
+}
+
+// A search with limits sends its best move without waiting for "stop"
+func TestBestMoveSentWhenSearchFinishes(t *testing.T) {
+	engine := startEngine(t)
+	engine.send(mateInOne, "go depth 1")
+
+	engine.output.waitFor(t, "bestmove")
+	engine.quit()
+
+	assert.Equal(t, []string{"bestmove a1a8"}, engine.output.lines("bestmove"))
+}
@@ -0,0 +1,15 @@
+module gogm/wasm
+
+go 1.22.5
+
+replace gogm/chess => ../chess
+
+replace gogm/botv1 => ../botv1
+
+replace gogm/jsonapi => ../jsonapi
+
+require (
+	gogm/botv1 v0.0.0-00010101000000-000000000000
+	gogm/chess v0.0.0-00010101000000-000000000000
+	gogm/jsonapi v0.0.0-00010101000000-000000000000
+)
@@ -0,0 +1,257 @@
+//go:build js && wasm
+
+package main
+
+// Exposes the rules and the bot to JavaScript when compiled to WebAssembly, so that a web page can
+// run the engine entirely in the browser. Build with
+//
+//   GOOS=js GOARCH=wasm go build -o gogm.wasm ./wasm
+//
+// and load gogm.wasm with the wasm_exec.js of the same Go version ($(go env GOROOT)/lib/wasm, or
+// misc/wasm before Go 1.24). Once started, it sets globalThis.gogm to an object holding a position
+// between calls, with the functions below. Positions, moves and searches are described by the
+// objects of the jsonapi package, as in the server's JSON API, and errors are returned as
+// {error: "..."}
+//
+//   loadFen(fen)           set up the position of the FEN, or the starting position if it is left out,
+//                          and return it
+//   legalMoves()           legal moves of the position, each as {uci, san}
+//   makeMove(move)         make the move, in UCI notation or SAN, and return the new position
+//   think(limits, onInfo)  a Promise of the bot's move for the position, searched within
+//                          {depth, nodes, movetime} (movetime in milliseconds, a second if no limit is
+//                          given), calling onInfo with the progress after each iteration
+//
+// A search holds the thread it runs on until it finishes, so pages should run gogm in a Web Worker
+// to stay responsive while the bot thinks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gogm/botv1"
+	"gogm/chess"
+	"gogm/jsonapi"
+	"sync"
+	"syscall/js"
+	"time"
+)
+
+// Time the bot searches for if think is given no limits
+const defaultMoveTime = time.Second
+
+func main() {
+    bindings := newBindings()
+
+    js.Global().Set("gogm", js.ValueOf(map[string]any {
+        "loadFen":    function(bindings.loadFen),
+        "legalMoves": function(bindings.legalMoves),
+        "makeMove":   function(bindings.makeMove),
+        "think":      function(bindings.think),
+    }))
+
+    // The functions are called from JavaScript for as long as the page is open
+    select {}
+}
+
+type bindings struct {
+    board *chess.Board
+
+    // Fullmove number of the position, which the board does not keep
+    fullmoveNumber int
+
+    // Held for the length of each search, so that a search started while another is running waits
+    // for it to finish
+    botLock sync.Mutex
+    bot     *botv1.BotV1
+}
+
+func newBindings() *bindings {
+    bindings := &bindings { fullmoveNumber: 1, bot: &botv1.BotV1{} }
+    bindings.board, _ = chess.LoadFen(chess.StartingPositionFen)
+    return bindings
+}
+
+// Returns a JavaScript function calling the Go function with its arguments, and converting the
+// result to a JavaScript value
+func function(call func(args []js.Value) (any, error)) js.Func {
+    return js.FuncOf(func(this js.Value, args []js.Value) any {
+        result, err := call(args)
+        if err != nil {
+            return toJS(errorJSON { Error: err.Error() })
+        }
+
+        return toJS(result)
+    })
+}
+
+// Returns the value as a JavaScript object with the same shape as its JSON encoding. Values that
+// are already JavaScript values are returned as they are
+func toJS(value any) any {
+    if value, ok := value.(js.Value); ok {
+        return value
+    }
+
+    encoded, err := json.Marshal(value)
+    if err != nil {
+        return toJS(errorJSON { Error: fmt.Sprintf("failed to encode result: %v", err) })
+    }
+
+    return js.Global().Get("JSON").Call("parse", string(encoded))
+}
+
+// Returns the argument at the index, or undefined if there are fewer arguments
+func argument(args []js.Value, index int) js.Value {
+    if index >= len(args) {
+        return js.Undefined()
+    }
+
+    return args[index]
+}
+
+type errorJSON struct {
+    Error string `json:"error"`
+}
+
+// Set up the position of the FEN given as the first argument after checking that it is legal, or
+// the starting position if there is no FEN, and return it
+func (bindings *bindings) loadFen(args []js.Value) (any, error) {
+    fen := ""
+    if arg := argument(args, 0); arg.Type() == js.TypeString {
+        fen = arg.String()
+    } else if !arg.IsUndefined() && !arg.IsNull() {
+        return nil, errors.New("expected a FEN")
+    }
+
+    board, fullmoveNumber, err := jsonapi.LoadPosition(fen)
+    if err != nil {
+        return nil, err
+    }
+
+    bindings.board, bindings.fullmoveNumber = board, fullmoveNumber
+    return jsonapi.NewPosition(bindings.board, bindings.fullmoveNumber), nil
+}
+
+func (bindings *bindings) legalMoves(args []js.Value) (any, error) {
+    return jsonapi.LegalMoves(bindings.board), nil
+}
+
+// Make the move given as the first argument, in UCI notation or SAN, and return the new position
+func (bindings *bindings) makeMove(args []js.Value) (any, error) {
+    arg := argument(args, 0)
+    if arg.Type() != js.TypeString {
+        return nil, errors.New("expected a move")
+    }
+
+    move, err := bindings.board.ParseMove(arg.String())
+    if err != nil {
+        return nil, err
+    }
+
+    if bindings.board.IsBlackToMove() {
+        bindings.fullmoveNumber++
+    }
+    bindings.board.MakeMove(move)
+
+    return jsonapi.NewPosition(bindings.board, bindings.fullmoveNumber), nil
+}
+
+// Returns a Promise of the bot's move for the position, searched within the limits given as the
+// first argument, calling the function given as the second argument, if any, with its progress
+func (bindings *bindings) think(args []js.Value) (any, error) {
+    limits, err := parseLimits(argument(args, 0))
+    if err != nil {
+        return nil, err
+    }
+
+    if len(bindings.board.GetLegalMoves(false)) == 0 {
+        return nil, errors.New(fmt.Sprintf("the game is over: %v", bindings.board.Outcome().Termination))
+    }
+
+    onInfo := argument(args, 1)
+    if !onInfo.IsUndefined() && !onInfo.IsNull() && onInfo.Type() != js.TypeFunction {
+        return nil, errors.New("expected a function to call with the progress of the search")
+    }
+
+    // The position may change before the search starts, as it only starts once control returns to
+    // JavaScript
+    board := bindings.board.Copy()
+
+    executor := js.FuncOf(func(this js.Value, promiseArgs []js.Value) any {
+        resolve := promiseArgs[0]
+
+        go func() {
+            bindings.botLock.Lock()
+            defer bindings.botLock.Unlock()
+
+            if onInfo.Type() == js.TypeFunction {
+                bindings.bot.SetSearchInfoCallback(func(info chess.SearchInfo) {
+                    onInfo.Invoke(toJS(jsonapi.NewSearch(&board, info.BestMove, info)))
+                })
+            } else {
+                bindings.bot.SetSearchInfoCallback(nil)
+            }
+
+            move, info := bindings.bot.ThinkContext(context.Background(), &board, limits)
+            resolve.Invoke(toJS(jsonapi.NewSearch(&board, move, info)))
+        }()
+
+        return nil
+    })
+    defer executor.Release()
+
+    return js.Global().Get("Promise").New(executor), nil
+}
+
+// Returns the limits given by a JavaScript object {depth, nodes, movetime}, any of which may be left
+// out, or a search of a second if there are none
+func parseLimits(value js.Value) (chess.SearchLimits, error) {
+    var limits chess.SearchLimits
+
+    if !value.IsUndefined() && !value.IsNull() {
+        if value.Type() != js.TypeObject {
+            return chess.SearchLimits{}, errors.New("expected limits {depth, nodes, movetime}")
+        }
+
+        // Returns the number in the field, or 0 if it is left out
+        field := func(name string) (float64, error) {
+            number := value.Get(name)
+            if number.IsUndefined() || number.IsNull() {
+                return 0, nil
+            }
+
+            if number.Type() != js.TypeNumber || number.Float() < 0 {
+                return 0, errors.New(fmt.Sprintf("%v must be a number that is not negative", name))
+            }
+
+            return number.Float(), nil
+        }
+
+        depth, err := field("depth")
+        if err != nil {
+            return chess.SearchLimits{}, err
+        }
+
+        nodes, err := field("nodes")
+        if err != nil {
+            return chess.SearchLimits{}, err
+        }
+
+        moveTime, err := field("movetime")
+        if err != nil {
+            return chess.SearchLimits{}, err
+        }
+
+        limits = chess.SearchLimits {
+            Depth:    int(depth),
+            Nodes:    uint64(nodes),
+            MoveTime: time.Duration(moveTime * float64(time.Millisecond)),
+        }
+    }
+
+    if limits == (chess.SearchLimits{}) {
+        limits.MoveTime = defaultMoveTime
+    }
+
+    return limits, nil
+}
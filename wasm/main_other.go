@@ -0,0 +1,14 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// The bindings only exist in WebAssembly, see main.go
+func main() {
+    fmt.Fprintln(os.Stderr, "build with GOOS=js GOARCH=wasm to run in a browser")
+    os.Exit(2)
+}